@@ -53,6 +53,7 @@ type Todo struct {
 	Summary      string
 	Description  string
 	Completed    time.Time
+	Status       string // NEEDS-ACTION, IN-PROCESS, COMPLETED or CANCELLED
 	Organizer    *user.User
 	Priority     int64 // 0-9, 1 is highest
 	RelatedToUID string
@@ -62,6 +63,7 @@ type Todo struct {
 	End      time.Time
 	DueDate  time.Time
 	Duration time.Duration
+	Alarms   []Alarm
 
 	Created time.Time
 	Updated time.Time // last-mod
@@ -196,10 +198,17 @@ DTEND:` + makeCalDavTimeFromTimeStamp(t.End)
 			caldavtodos += `
 DESCRIPTION:` + formattedDescription
 		}
+		status := t.Status
 		if t.Completed.Unix() > 0 {
 			caldavtodos += `
-COMPLETED:` + makeCalDavTimeFromTimeStamp(t.Completed) + `
-STATUS:COMPLETED`
+COMPLETED:` + makeCalDavTimeFromTimeStamp(t.Completed)
+			if status == "" {
+				status = "COMPLETED"
+			}
+		}
+		if status != "" {
+			caldavtodos += `
+STATUS:` + status
 		}
 		if t.Organizer != nil {
 			caldavtodos += `
@@ -229,6 +238,19 @@ PRIORITY:` + strconv.Itoa(mapPriorityToCaldav(t.Priority))
 		caldavtodos += `
 LAST-MODIFIED:` + makeCalDavTimeFromTimeStamp(t.Updated)
 
+		for _, a := range t.Alarms {
+			if a.Description == "" {
+				a.Description = t.Summary
+			}
+
+			caldavtodos += `
+BEGIN:VALARM
+TRIGGER;VALUE=DATE-TIME:` + makeCalDavUTCTimeFromTimeStamp(a.Time) + `
+ACTION:DISPLAY
+DESCRIPTION:` + a.Description + `
+END:VALARM`
+		}
+
 		caldavtodos += `
 END:VTODO`
 	}
@@ -243,6 +265,10 @@ func makeCalDavTimeFromTimeStamp(ts time.Time) (caldavtime string) {
 	return ts.In(config.GetTimeZone()).Format(DateFormat)
 }
 
+func makeCalDavUTCTimeFromTimeStamp(ts time.Time) (caldavtime string) {
+	return ts.UTC().Format(DateFormat) + "Z"
+}
+
 func calcAlarmDateFromReminder(eventStart, reminder time.Time) (alarmTime string) {
 	diff := reminder.Sub(eventStart)
 	diffStr := strings.ToUpper(diff.String())
@@ -375,6 +375,39 @@ COMPLETED:20181201T013024
 STATUS:COMPLETED
 LAST-MODIFIED:00010101T000000
 END:VTODO
+END:VCALENDAR`,
+		},
+		{
+			name: "with workflow status",
+			args: args{
+				config: &Config{
+					Name:   "test",
+					ProdID: "RandomProdID which is not random",
+				},
+				todos: []*Todo{
+					{
+						Summary:     "Todo #1",
+						Description: "Lorem Ipsum",
+						UID:         "randommduid",
+						Status:      "IN-PROCESS",
+						Timestamp:   time.Unix(1543626724, 0).In(config.GetTimeZone()),
+					},
+				},
+			},
+			wantCaldavtasks: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:test
+PRODID:-//RandomProdID which is not random//EN
+BEGIN:VTODO
+UID:randommduid
+DTSTAMP:20181201T011204
+SUMMARY:Todo #1
+DESCRIPTION:Lorem Ipsum
+STATUS:IN-PROCESS
+LAST-MODIFIED:00010101T000000
+END:VTODO
 END:VCALENDAR`,
 		},
 		{
@@ -408,6 +441,49 @@ DESCRIPTION:Lorem Ipsum
 PRIORITY:9
 LAST-MODIFIED:00010101T000000
 END:VTODO
+END:VCALENDAR`,
+		},
+		{
+			name: "with reminders",
+			args: args{
+				config: &Config{
+					Name:   "test",
+					ProdID: "RandomProdID which is not random",
+				},
+				todos: []*Todo{
+					{
+						Summary:   "Todo #1",
+						UID:       "randommduid",
+						Timestamp: time.Unix(1543626724, 0).In(config.GetTimeZone()),
+						Alarms: []Alarm{
+							{Time: time.Unix(1543626824, 0)},
+							{Time: time.Unix(1543626924, 0), Description: "Snoozed"},
+						},
+					},
+				},
+			},
+			wantCaldavtasks: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:test
+PRODID:-//RandomProdID which is not random//EN
+BEGIN:VTODO
+UID:randommduid
+DTSTAMP:20181201T011204
+SUMMARY:Todo #1
+LAST-MODIFIED:00010101T000000
+BEGIN:VALARM
+TRIGGER;VALUE=DATE-TIME:20181201T011344Z
+ACTION:DISPLAY
+DESCRIPTION:Todo #1
+END:VALARM
+BEGIN:VALARM
+TRIGGER;VALUE=DATE-TIME:20181201T011524Z
+ACTION:DISPLAY
+DESCRIPTION:Snoozed
+END:VALARM
+END:VTODO
 END:VCALENDAR`,
 		},
 	}
@@ -35,12 +35,27 @@ func GetCaldavTodosForTasks(list *models.ListWithTasksAndBuckets, listTasks []*m
 
 		duration := t.EndDate.Sub(t.StartDate)
 
+		// Snoozed reminders of the current user show up as additional alarms
+		var alarms []Alarm
+		for _, r := range t.Reminders {
+			alarms = append(alarms, Alarm{Time: r})
+		}
+		for _, r := range t.SnoozedReminders {
+			alarms = append(alarms, Alarm{Time: r})
+		}
+
+		var status string
+		if t.Status != nil {
+			status = string(t.Status.CaldavStatus)
+		}
+
 		caldavtodos = append(caldavtodos, &Todo{
 			Timestamp:   t.Updated,
 			UID:         t.UID,
 			Summary:     t.Title,
 			Description: t.Description,
 			Completed:   t.DoneAt,
+			Status:      status,
 			// Organizer:     &t.CreatedBy, // Disabled until we figure out how this works
 			Priority: t.Priority,
 			Start:    t.StartDate,
@@ -49,6 +64,7 @@ func GetCaldavTodosForTasks(list *models.ListWithTasksAndBuckets, listTasks []*m
 			Updated:  t.Updated,
 			DueDate:  t.DueDate,
 			Duration: duration,
+			Alarms:   alarms,
 		})
 	}
 
@@ -103,6 +119,7 @@ func ParseTaskFromVTODO(content string) (vTask *models.Task, err error) {
 	if task["STATUS"] == "COMPLETED" {
 		vTask.Done = true
 	}
+	vTask.CaldavStatus = models.TaskCaldavStatus(task["STATUS"])
 
 	if duration > 0 && !vTask.StartDate.IsZero() {
 		vTask.EndDate = vTask.StartDate.Add(duration)
@@ -0,0 +1,181 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/initialize"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/mail"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mailFlagStatus   string
+	mailFlagRetryAll bool
+)
+
+func init() {
+	mailListCmd.Flags().StringVarP(&mailFlagStatus, "status", "s", "failed", "Only show mails with this status. Can be one of pending, sending, sent or failed.")
+	mailRetryCmd.Flags().BoolVarP(&mailFlagRetryAll, "all", "a", false, "Retry all failed mails instead of a single one.")
+
+	mailCmd.AddCommand(mailListCmd, mailRetryCmd, mailDeleteCmd)
+	rootCmd.AddCommand(mailCmd)
+}
+
+func getQueuedMailStatusFromFlag() mail.QueuedMailStatus {
+	switch strings.ToLower(mailFlagStatus) {
+	case "pending":
+		return mail.QueuedMailStatusPending
+	case "sending":
+		return mail.QueuedMailStatusSending
+	case "sent":
+		return mail.QueuedMailStatusSent
+	case "failed":
+		return mail.QueuedMailStatusFailed
+	}
+
+	log.Fatalf("Invalid mail status: %s", mailFlagStatus)
+	return 0
+}
+
+func getQueuedMailIDFromArg(arg string) int64 {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid mail id: %s", err)
+	}
+	return id
+}
+
+var mailCmd = &cobra.Command{
+	Use:   "mail",
+	Short: "Manage the queue of outgoing mails.",
+}
+
+var mailListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Shows all mails in the queue with a status, by default the ones which could not be sent.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInit()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		s := db.NewSession()
+		defer s.Close()
+
+		mails, err := mail.GetQueuedMails(s, getQueuedMailStatusFromFlag())
+		if err != nil {
+			log.Fatalf("Error getting mails: %s", err)
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{
+			"ID",
+			"To",
+			"Subject",
+			"Status",
+			"Attempts",
+			"Last Error",
+			"Next Attempt",
+			"Created",
+		})
+
+		for _, m := range mails {
+			nextAttempt := ""
+			if m.Status == mail.QueuedMailStatusPending {
+				nextAttempt = m.NextAttemptAt.Format(time.RFC3339)
+			}
+
+			table.Append([]string{
+				strconv.FormatInt(m.ID, 10),
+				m.To,
+				m.Subject,
+				m.Status.String(),
+				strconv.Itoa(m.Attempts),
+				m.LastError,
+				nextAttempt,
+				m.Created.Format(time.RFC3339),
+			})
+		}
+
+		table.Render()
+	},
+}
+
+var mailRetryCmd = &cobra.Command{
+	Use:   "retry [mail id]",
+	Short: "Puts a failed mail back into the queue so it will be sent again.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInit()
+	},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if mailFlagRetryAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		s := db.NewSession()
+		defer s.Close()
+
+		ids := []int64{}
+		if mailFlagRetryAll {
+			mails, err := mail.GetQueuedMails(s, mail.QueuedMailStatusFailed)
+			if err != nil {
+				log.Fatalf("Error getting failed mails: %s", err)
+			}
+			for _, m := range mails {
+				ids = append(ids, m.ID)
+			}
+		} else {
+			ids = append(ids, getQueuedMailIDFromArg(args[0]))
+		}
+
+		for _, id := range ids {
+			if err := mail.RetryQueuedMail(s, id); err != nil {
+				log.Fatalf("Error retrying mail %d: %s", id, err)
+			}
+		}
+
+		log.Infof("Put %d mail(s) back into the queue.", len(ids))
+	},
+}
+
+var mailDeleteCmd = &cobra.Command{
+	Use:   "delete [mail id]",
+	Short: "Removes a mail from the queue without sending it.",
+	Args:  cobra.ExactArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInit()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		s := db.NewSession()
+		defer s.Close()
+
+		id := getQueuedMailIDFromArg(args[0])
+		if err := mail.DeleteQueuedMail(s, id); err != nil {
+			log.Fatalf("Error deleting mail %d: %s", id, err)
+		}
+
+		log.Infof("Deleted mail %d from the queue.", id)
+	},
+}
@@ -18,9 +18,11 @@ package cmd
 
 import (
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/initialize"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/mail"
+	"code.vikunja.io/api/pkg/migration"
 	"code.vikunja.io/api/pkg/notifications"
 	"github.com/spf13/cobra"
 )
@@ -36,20 +38,25 @@ var testmailCmd = &cobra.Command{
 	PreRun: func(cmd *cobra.Command, args []string) {
 		initialize.LightInit()
 
-		// Start the mail daemon
-		mail.StartMailDaemon()
+		// The test mail goes through the mail queue in the database
+		migration.Migrate(nil)
+		err := mail.InitDB()
+		if err != nil {
+			log.Fatal(err.Error())
+		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Info("Sending testmail...")
+		lang := config.DefaultSettingsLanguage.GetString()
 		message := notifications.NewMail().
 			From("Vikunja <"+config.MailerFromEmail.GetString()+">").
 			To(args[0]).
-			Subject("Test from Vikunja").
-			Line("This is a test mail!").
-			Line("If you received this, Vikunja is correctly set up to send emails.").
-			Action("Go to your instance", config.ServiceFrontendurl.GetString())
+			Subject(i18n.T(lang, "notifications.testmail.subject")).
+			Line(i18n.T(lang, "notifications.testmail.message")).
+			Line(i18n.T(lang, "notifications.testmail.success")).
+			Action(i18n.T(lang, "notifications.testmail.action"), config.ServiceFrontendurl.GetString())
 
-		opts, err := notifications.RenderMail(message)
+		opts, err := notifications.RenderMail(message, lang)
 		if err != nil {
 			log.Errorf("Error sending test mail: %s", err.Error())
 			return
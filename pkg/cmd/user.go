@@ -30,6 +30,7 @@ import (
 	"code.vikunja.io/api/pkg/initialize"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/keyvalue"
 	"code.vikunja.io/api/pkg/user"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -46,6 +47,7 @@ var (
 	userFlagEnableUser            bool
 	userFlagDisableUser           bool
 	userFlagDeleteNow             bool
+	userFlagAdmin                 bool
 )
 
 func init() {
@@ -61,6 +63,7 @@ func init() {
 	userUpdateCmd.Flags().StringVarP(&userFlagUsername, "username", "u", "", "The new username of the user.")
 	userUpdateCmd.Flags().StringVarP(&userFlagEmail, "email", "e", "", "The new email address of the user.")
 	userUpdateCmd.Flags().StringVarP(&userFlagAvatar, "avatar-provider", "a", "", "The new avatar provider of the new user.")
+	userUpdateCmd.Flags().BoolVar(&userFlagAdmin, "admin", false, "Make the user an instance admin. Use --admin=false to revoke admin rights.")
 
 	// Reset PW flags
 	userResetPasswordCmd.Flags().BoolVarP(&userFlagResetPasswordDirectly, "direct", "d", false, "If provided, reset the password directly instead of sending the user a reset mail.")
@@ -73,7 +76,7 @@ func init() {
 	// User deletion flags
 	userDeleteCmd.Flags().BoolVarP(&userFlagDeleteNow, "now", "n", false, "If provided, deletes the user immediately instead of sending them an email first.")
 
-	userCmd.AddCommand(userListCmd, userCreateCmd, userUpdateCmd, userResetPasswordCmd, userChangeEnabledCmd, userDeleteCmd)
+	userCmd.AddCommand(userListCmd, userCreateCmd, userUpdateCmd, userResetPasswordCmd, userResetTOTPCmd, userChangeEnabledCmd, userDeleteCmd)
 	rootCmd.AddCommand(userCmd)
 }
 
@@ -143,6 +146,7 @@ var userListCmd = &cobra.Command{
 			"Username",
 			"Email",
 			"Status",
+			"Admin",
 			"Created",
 			"Updated",
 		})
@@ -153,6 +157,7 @@ var userListCmd = &cobra.Command{
 				u.Username,
 				u.Email,
 				u.Status.String(),
+				strconv.FormatBool(u.IsAdmin),
 				u.Created.Format(time.RFC3339),
 				u.Updated.Format(time.RFC3339),
 			})
@@ -231,6 +236,14 @@ var userUpdateCmd = &cobra.Command{
 			log.Fatalf("Error updating the user: %s", err)
 		}
 
+		if cmd.Flags().Changed("admin") {
+			err = u.SetAdmin(s, userFlagAdmin)
+			if err != nil {
+				_ = s.Rollback()
+				log.Fatalf("Error updating the admin rights of the user: %s", err)
+			}
+		}
+
 		if err := s.Commit(); err != nil {
 			log.Fatalf("Error saving everything: %s", err)
 		}
@@ -275,6 +288,38 @@ var userResetPasswordCmd = &cobra.Command{
 	},
 }
 
+var userResetTOTPCmd = &cobra.Command{
+	Use:   "reset-totp [user id]",
+	Short: "Disable totp for a user who lost access to their authenticator.",
+	Long:  "Disables totp for a user so they can log in with only their password again. If totp was their last second factor, their recovery codes are removed as well. The user can set up totp again in their settings afterwards.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		initialize.FullInit()
+	},
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s := db.NewSession()
+		defer s.Close()
+
+		u := getUserFromArg(s, args[0])
+
+		err := user.DisableTOTP(s, u)
+		if err != nil {
+			_ = s.Rollback()
+			log.Fatalf("Could not reset totp: %s", err)
+		}
+
+		if err := s.Commit(); err != nil {
+			log.Fatalf("Error saving everything: %s", err)
+		}
+
+		if err := keyvalue.Del(u.GetFailedTOTPAttemptsKey()); err != nil {
+			log.Fatalf("Could not reset failed totp attempts: %s", err)
+		}
+
+		fmt.Println("TOTP was reset successfully.")
+	},
+}
+
 var userChangeEnabledCmd = &cobra.Command{
 	Use:   "change-status [user id]",
 	Short: "Enable or disable a user. Will toggle the current status if no flag (--enable or --disable) is provided.",
@@ -288,18 +333,19 @@ var userChangeEnabledCmd = &cobra.Command{
 
 		u := getUserFromArg(s, args[0])
 
+		status := u.Status
 		if userFlagEnableUser {
-			u.Status = user.StatusActive
+			status = user.StatusActive
 		} else if userFlagDisableUser {
-			u.Status = user.StatusDisabled
+			status = user.StatusDisabled
 		} else {
 			if u.Status == user.StatusActive {
-				u.Status = user.StatusDisabled
+				status = user.StatusDisabled
 			} else {
-				u.Status = user.StatusActive
+				status = user.StatusActive
 			}
 		}
-		_, err := user.UpdateUser(s, u)
+		err := u.SetStatus(s, status)
 		if err != nil {
 			_ = s.Rollback()
 			log.Fatalf("Could not enable the user")
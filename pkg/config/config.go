@@ -20,6 +20,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"os/exec"
 	"path"
@@ -41,6 +42,8 @@ const (
 	ServiceJWTSecret       Key = `service.JWTSecret`
 	ServiceJWTTTL          Key = `service.jwtttl`
 	ServiceJWTTTLLong      Key = `service.jwtttllong`
+	ServiceSessionTTL      Key = `service.sessionttl`
+	ServiceSessionTTLLong  Key = `service.sessionttllong`
 	ServiceInterface       Key = `service.interface`
 	ServiceUnixSocket      Key = `service.unixsocket`
 	ServiceUnixSocketMode  Key = `service.unixsocketmode`
@@ -58,17 +61,50 @@ const (
 	ServiceTimeZone              Key = `service.timezone`
 	ServiceEnableTaskComments    Key = `service.enabletaskcomments`
 	ServiceEnableTotp            Key = `service.enabletotp`
+	ServiceRequireSecondFactor   Key = `service.requiresecondfactor`
 	ServiceSentryDsn             Key = `service.sentrydsn`
 	ServiceTestingtoken          Key = `service.testingtoken`
 	ServiceEnableEmailReminders  Key = `service.enableemailreminders`
 	ServiceEnableUserDeletion    Key = `service.enableuserdeletion`
 	ServiceMaxAvatarSize         Key = `service.maxavatarsize`
+	ServiceNotificationRetention Key = `service.notificationretention`
+	ServiceEnableUserInvitations Key = `service.enableuserinvitations`
+	ServiceAllowedEmailDomains   Key = `service.allowedemaildomains`
+	ServiceDeniedEmailDomains    Key = `service.deniedemaildomains`
+	ServicePublicViewCacheTTL    Key = `service.publicviewcachettl`
+
+	ServicePreventCompletingBlockedTasks Key = `service.preventcompletingblockedtasks`
+	ServiceTrashRetention                Key = `service.trashretention`
+	ServiceTrustedProxies                Key = `service.trustedproxies`
 
 	AuthLocalEnabled      Key = `auth.local.enabled`
 	AuthOpenIDEnabled     Key = `auth.openid.enabled`
 	AuthOpenIDRedirectURL Key = `auth.openid.redirecturl`
 	AuthOpenIDProviders   Key = `auth.openid.providers`
 
+	AuthLDAPEnabled              Key = `auth.ldap.enabled`
+	AuthLDAPHost                 Key = `auth.ldap.host`
+	AuthLDAPPort                 Key = `auth.ldap.port`
+	AuthLDAPUseTLS               Key = `auth.ldap.usetls`
+	AuthLDAPVerifyTLS            Key = `auth.ldap.verifytls`
+	AuthLDAPBaseDN               Key = `auth.ldap.basedn`
+	AuthLDAPBindDN               Key = `auth.ldap.binddn`
+	AuthLDAPBindPassword         Key = `auth.ldap.bindpassword`
+	AuthLDAPUserFilter           Key = `auth.ldap.userfilter`
+	AuthLDAPAttributeUsername    Key = `auth.ldap.attribute.username`
+	AuthLDAPAttributeEmail       Key = `auth.ldap.attribute.email`
+	AuthLDAPAttributeDisplayname Key = `auth.ldap.attribute.displayname`
+	AuthLDAPGroupSyncEnabled     Key = `auth.ldap.groupsyncenabled`
+	AuthLDAPGroupFilter          Key = `auth.ldap.groupfilter`
+	AuthLDAPAttributeGroupName   Key = `auth.ldap.attribute.groupname`
+
+	AuthWebAuthnEnabled          Key = `auth.webauthn.enabled`
+	AuthWebAuthnRPID             Key = `auth.webauthn.rpid`
+	AuthWebAuthnRPDisplayName    Key = `auth.webauthn.rpdisplayname`
+	AuthWebAuthnOrigin           Key = `auth.webauthn.origin`
+	AuthWebAuthnUserVerification Key = `auth.webauthn.userverification`
+	AuthWebAuthnPasswordless     Key = `auth.webauthn.passwordless`
+
 	LegalImprintURL Key = `legal.imprinturl`
 	LegalPrivacyURL Key = `legal.privacyurl`
 
@@ -102,6 +138,8 @@ const (
 	MailerQueuelength   Key = `mailer.queuelength`
 	MailerQueueTimeout  Key = `mailer.queuetimeout`
 	MailerForceSSL      Key = `mailer.forcessl`
+	MailerTemplatesPath Key = `mailer.templatespath`
+	MailerMaxAttempts   Key = `mailer.maxattempts`
 
 	RedisEnabled  Key = `redis.enabled`
 	RedisHost     Key = `redis.host`
@@ -281,8 +319,10 @@ func InitDefaultConfig() {
 
 	// Service
 	ServiceJWTSecret.setDefault(random)
-	ServiceJWTTTL.setDefault(259200)      // 72 hours
-	ServiceJWTTTLLong.setDefault(2592000) // 30 days
+	ServiceJWTTTL.setDefault(259200)          // 72 hours
+	ServiceJWTTTLLong.setDefault(2592000)     // 30 days
+	ServiceSessionTTL.setDefault(2592000)     // 30 days
+	ServiceSessionTTLLong.setDefault(7776000) // 90 days
 	ServiceInterface.setDefault(":3456")
 	ServiceUnixSocket.setDefault("")
 	ServiceFrontendurl.setDefault("")
@@ -299,13 +339,37 @@ func InitDefaultConfig() {
 	ServiceTimeZone.setDefault("GMT")
 	ServiceEnableTaskComments.setDefault(true)
 	ServiceEnableTotp.setDefault(true)
+	ServiceRequireSecondFactor.setDefault(false)
 	ServiceEnableEmailReminders.setDefault(true)
 	ServiceEnableUserDeletion.setDefault(true)
 	ServiceMaxAvatarSize.setDefault(1024)
+	ServiceNotificationRetention.setDefault(90)
+	ServiceEnableUserInvitations.setDefault(true)
+	ServiceAllowedEmailDomains.setDefault([]string{})
+	ServiceDeniedEmailDomains.setDefault([]string{})
+	ServicePublicViewCacheTTL.setDefault(60)
+	ServicePreventCompletingBlockedTasks.setDefault(false)
+	ServiceTrashRetention.setDefault(30)
+	ServiceTrustedProxies.setDefault([]string{})
 
 	// Auth
 	AuthLocalEnabled.setDefault(true)
 	AuthOpenIDEnabled.setDefault(false)
+	AuthLDAPEnabled.setDefault(false)
+	AuthLDAPPort.setDefault(389)
+	AuthLDAPUseTLS.setDefault(false)
+	AuthLDAPVerifyTLS.setDefault(true)
+	AuthLDAPUserFilter.setDefault("(&(objectClass=inetOrgPerson)(uid=%[1]s))")
+	AuthLDAPAttributeUsername.setDefault("uid")
+	AuthLDAPAttributeEmail.setDefault("mail")
+	AuthLDAPAttributeDisplayname.setDefault("displayName")
+	AuthLDAPGroupSyncEnabled.setDefault(false)
+	AuthLDAPGroupFilter.setDefault("(&(objectClass=groupOfNames)(member=%[1]s))")
+	AuthLDAPAttributeGroupName.setDefault("cn")
+	AuthWebAuthnEnabled.setDefault(true)
+	AuthWebAuthnRPDisplayName.setDefault("Vikunja")
+	AuthWebAuthnUserVerification.setDefault("preferred")
+	AuthWebAuthnPasswordless.setDefault(true)
 
 	// Database
 	DatabaseType.setDefault("sqlite")
@@ -338,6 +402,8 @@ func InitDefaultConfig() {
 	MailerQueuelength.setDefault(100)
 	MailerQueueTimeout.setDefault(30)
 	MailerForceSSL.setDefault(false)
+	MailerTemplatesPath.setDefault("")
+	MailerMaxAttempts.setDefault(10)
 	MailerAuthType.setDefault("plain")
 	// Redis
 	RedisEnabled.setDefault(false)
@@ -442,6 +508,15 @@ func InitConfig() {
 		AuthOpenIDRedirectURL.Set(ServiceFrontendurl.GetString() + "auth/openid/")
 	}
 
+	if frontendURL, err := url.Parse(ServiceFrontendurl.GetString()); err == nil {
+		if AuthWebAuthnRPID.GetString() == "" {
+			AuthWebAuthnRPID.Set(frontendURL.Hostname())
+		}
+		if AuthWebAuthnOrigin.GetString() == "" {
+			AuthWebAuthnOrigin.Set(frontendURL.Scheme + "://" + frontendURL.Host)
+		}
+	}
+
 	if MigrationTodoistRedirectURL.GetString() == "" {
 		MigrationTodoistRedirectURL.Set(ServiceFrontendurl.GetString() + "migrate/todoist")
 	}
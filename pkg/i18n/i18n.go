@@ -0,0 +1,150 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+)
+
+// DefaultLanguage is the language all translations fall back to if a key does not exist in another language.
+const DefaultLanguage = "en"
+
+//go:embed lang/*.json
+var files embed.FS
+
+// language code => flattened translation key => translated string
+var translations map[string]map[string]string
+
+// Init loads all translations embedded into the binary.
+func Init() {
+	translations = make(map[string]map[string]string)
+
+	entries, err := files.ReadDir("lang")
+	if err != nil {
+		log.Fatalf("Could not read translation files: %s", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		content, err := files.ReadFile(path.Join("lang", entry.Name()))
+		if err != nil {
+			log.Fatalf("Could not read translation file %s: %s", entry.Name(), err)
+		}
+
+		raw := make(map[string]interface{})
+		if err := json.Unmarshal(content, &raw); err != nil {
+			log.Fatalf("Could not parse translation file %s: %s", entry.Name(), err)
+		}
+
+		lang := strings.TrimSuffix(entry.Name(), ".json")
+		translations[lang] = make(map[string]string)
+		flatten(translations[lang], "", raw)
+	}
+
+	log.Debugf("Loaded translations for %d languages", len(translations))
+}
+
+func flatten(into map[string]string, prefix string, raw map[string]interface{}) {
+	for key, value := range raw {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			into[fullKey] = v
+		case map[string]interface{}:
+			flatten(into, fullKey, v)
+		}
+	}
+}
+
+// HasLanguage checks whether a translation for the given language exists.
+func HasLanguage(lang string) bool {
+	return resolveLanguage(lang) != ""
+}
+
+// resolveLanguage returns the name of the loaded translation which matches the requested language best.
+// An exact match is preferred, after that a translation with the same base language is used, so "de" and
+// "de-CH" both resolve to "de-DE" if that is the only German translation available.
+func resolveLanguage(lang string) string {
+	if lang == "" {
+		return ""
+	}
+
+	if _, has := translations[lang]; has {
+		return lang
+	}
+
+	base := strings.ToLower(strings.SplitN(lang, "-", 2)[0])
+	for available := range translations {
+		if strings.ToLower(strings.SplitN(available, "-", 2)[0]) == base {
+			return available
+		}
+	}
+
+	return ""
+}
+
+// T translates a key into the given language and formats it with the given params.
+// If the key does not exist in the requested language, the configured default language and then
+// DefaultLanguage are tried. If the key does not exist at all, the key itself is returned.
+func T(lang, key string, params ...string) string {
+	if translations == nil {
+		Init()
+	}
+
+	candidates := []string{
+		resolveLanguage(lang),
+		resolveLanguage(config.DefaultSettingsLanguage.GetString()),
+		DefaultLanguage,
+	}
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+
+		translated, has := translations[candidate][key]
+		if !has {
+			continue
+		}
+
+		if len(params) == 0 {
+			return translated
+		}
+
+		args := make([]interface{}, 0, len(params))
+		for _, param := range params {
+			args = append(args, param)
+		}
+		return fmt.Sprintf(translated, args...)
+	}
+
+	return key
+}
@@ -0,0 +1,59 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestT(t *testing.T) {
+	Init()
+
+	t.Run("default language", func(t *testing.T) {
+		assert.Equal(t, "Hi John,", T("en", "notifications.greeting", "John"))
+	})
+	t.Run("other language", func(t *testing.T) {
+		assert.Equal(t, "Hallo John,", T("de-DE", "notifications.greeting", "John"))
+	})
+	t.Run("base language", func(t *testing.T) {
+		assert.Equal(t, "Hallo John,", T("de", "notifications.greeting", "John"))
+		assert.Equal(t, "Hallo John,", T("de-CH", "notifications.greeting", "John"))
+	})
+	t.Run("unknown language", func(t *testing.T) {
+		assert.Equal(t, "Hi John,", T("xx-XX", "notifications.greeting", "John"))
+		assert.Equal(t, "Hi John,", T("", "notifications.greeting", "John"))
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		assert.Equal(t, "notifications.does.not.exist", T("en", "notifications.does.not.exist"))
+	})
+	t.Run("reordered params", func(t *testing.T) {
+		assert.Equal(t, "Task (#1) has been assigned to Jane", T("en", "notifications.task.assigned.subject", "Task", "#1", "Jane"))
+	})
+}
+
+func TestAllLanguagesHaveAllKeys(t *testing.T) {
+	Init()
+
+	for lang, keys := range translations {
+		for key := range translations[DefaultLanguage] {
+			_, has := keys[key]
+			assert.True(t, has, "Translation %s is missing key %s", lang, key)
+		}
+	}
+}
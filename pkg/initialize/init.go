@@ -23,6 +23,7 @@ import (
 	"code.vikunja.io/api/pkg/cron"
 	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/mail"
 	"code.vikunja.io/api/pkg/migration"
@@ -47,6 +48,9 @@ func LightInit() {
 
 	// Set logger
 	log.InitLogger()
+
+	// Load translations
+	i18n.Init()
 }
 
 // InitEngines intializes all db connections
@@ -71,6 +75,10 @@ func InitEngines() {
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+	err = mail.InitDB()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
 }
 
 // FullInit initializes all kinds of things in the right order
@@ -95,9 +103,13 @@ func FullInit() {
 	models.RegisterReminderCron()
 	models.RegisterOverdueReminderCron()
 	user.RegisterTokenCleanupCron()
+	user.RegisterSessionCleanupCron()
 	user.RegisterDeletionNotificationCron()
 	models.RegisterUserDeletionCron()
 	models.RegisterOldExportCleanupCron()
+	models.RegisterTrashPurgeCron()
+	mail.RegisterSentMailCleanupCron()
+	notifications.RegisterOldNotificationCleanupCron()
 
 	// Start processing events
 	go func() {
@@ -118,9 +118,14 @@ func newTestRequest(t *testing.T, method string, handler func(ctx echo.Context)
 	return
 }
 
-func addUserTokenToContext(t *testing.T, user *user.User, c echo.Context) {
+func addUserTokenToContext(t *testing.T, u *user.User, c echo.Context) {
 	// Get the token as a string
-	token, err := auth.NewUserJWTAuthtoken(user, false)
+	s := db.NewSession()
+	defer s.Close()
+	session, err := user.CreateSession(s, u, "integration test", "127.0.0.1", false)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Commit())
+	token, err := auth.NewUserJWTAuthtoken(u, session)
 	assert.NoError(t, err)
 	// We send the string token through the parsing function to get a valid jwt.Token
 	tken, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
@@ -22,13 +22,30 @@ import (
 	"time"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/log"
 
 	"github.com/wneessen/go-mail"
+	"xorm.io/xorm"
 )
 
-// Queue is the mail queue
-var Queue chan *mail.Msg
+// How often the daemon checks the database for mails which are due for a retry.
+const queuePollInterval = 10 * time.Second
+
+// wake notifies the daemon about newly queued mails so they don't have to wait for the next poll.
+var wake chan struct{}
+
+func wakeDaemon() {
+	if wake == nil {
+		return
+	}
+
+	select {
+	case wake <- struct{}{}:
+	default:
+		// The daemon is already notified
+	}
+}
 
 func getClient() (*mail.Client, error) {
 
@@ -76,9 +93,9 @@ func getClient() (*mail.Client, error) {
 	)
 }
 
-// StartMailDaemon starts the mail daemon
+// StartMailDaemon starts the mail daemon which sends all mails in the queue and retries failed ones.
 func StartMailDaemon() {
-	Queue = make(chan *mail.Msg, config.MailerQueuelength.GetInt())
+	wake = make(chan struct{}, 1)
 
 	if !config.MailerEnabled.GetBool() {
 		return
@@ -94,41 +111,115 @@ func StartMailDaemon() {
 		log.Errorf("Could not create mail client: %v", err)
 		return
 	}
+
+	s := db.NewSession()
+	err = resetInterruptedMails(s)
+	s.Close()
+	if err != nil {
+		log.Errorf("Could not reset interrupted mails: %v", err)
+	}
+
 	go func() {
-		var err error
+		ticker := time.NewTicker(queuePollInterval)
+		defer ticker.Stop()
+
 		open := false
+		var lastSent time.Time
 		for {
 			select {
-			case m, ok := <-Queue:
-				if !ok {
-					return
-				}
-				if !open {
-					err = c.DialWithContext(context.Background())
-					if err != nil {
-						log.Errorf("Error during connect to smtp server: %s", err)
-						break
-					}
-					open = true
-				}
-				err = c.Send(m)
+			case <-wake:
+			case <-ticker.C:
+			}
+
+			sent := sendDueMails(c, &open)
+			if sent > 0 {
+				lastSent = time.Now()
+				continue
+			}
+
+			// Close the connection to the SMTP server if no email was sent in
+			// the last 30 seconds.
+			if open && time.Since(lastSent) > config.MailerQueueTimeout.GetDuration()*time.Second {
+				open = false
+				err := c.Close()
 				if err != nil {
-					log.Errorf("Error when sending mail: %s", err)
-					break
-				}
-				// Close the connection to the SMTP server if no email was sent in
-				// the last 30 seconds.
-			case <-time.After(config.MailerQueueTimeout.GetDuration() * time.Second):
-				if open {
-					open = false
-					err = c.Close()
-					if err != nil {
-						log.Errorf("Error closing the mail server connection: %s\n", err)
-						break
-					}
-					log.Info("Closed connection to mail server")
+					log.Errorf("Error closing the mail server connection: %s\n", err)
+					continue
 				}
+				log.Info("Closed connection to mail server")
 			}
 		}
 	}()
+
+	// Send everything which was queued while the daemon was not running
+	wakeDaemon()
+}
+
+// sendDueMails sends all mails from the queue which are due and returns how many were sent successfully.
+func sendDueMails(c *mail.Client, open *bool) (sent int) {
+	s := db.NewSession()
+	defer s.Close()
+
+	mails, err := getDueMails(s, config.MailerQueuelength.GetInt())
+	if err != nil {
+		log.Errorf("Could not get queued mails: %s", err)
+		return
+	}
+
+	for _, qm := range mails {
+		claimed, err := claimMail(s, qm)
+		if err != nil {
+			log.Errorf("Could not claim queued mail %d: %s", qm.ID, err)
+			continue
+		}
+		if !claimed {
+			// Another instance is already sending this mail
+			continue
+		}
+
+		if !*open {
+			err = c.DialWithContext(context.Background())
+			if err != nil {
+				log.Errorf("Error during connect to smtp server: %s", err)
+				if err := markMailFailed(s, qm, err); err != nil {
+					log.Errorf("Could not update queued mail %d: %s", qm.ID, err)
+				}
+				// No point in trying the other mails now, they'll be picked up again with the next run.
+				return
+			}
+			*open = true
+		}
+
+		err = sendQueuedMail(s, c, qm)
+		if err != nil {
+			log.Errorf("Error when sending mail %d: %s", qm.ID, err)
+			// The connection might be broken, reconnect for the next mail
+			_ = c.Close()
+			*open = false
+			continue
+		}
+
+		sent++
+	}
+
+	return
+}
+
+// sendQueuedMail sends a single claimed mail over an already open connection and records the result in the queue.
+// The returned error is the one from sending the mail.
+func sendQueuedMail(s *xorm.Session, c *mail.Client, qm *QueuedMail) (err error) {
+	sendErr := c.Send(getMessage(qm.toOpts()))
+	if sendErr != nil {
+		err = markMailFailed(s, qm, sendErr)
+		if err != nil {
+			log.Errorf("Could not update queued mail %d: %s", qm.ID, err)
+		}
+		return sendErr
+	}
+
+	err = markMailSent(s, qm)
+	if err != nil {
+		log.Errorf("Could not update queued mail %d: %s", qm.ID, err)
+	}
+	return nil
 }
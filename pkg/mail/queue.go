@@ -0,0 +1,359 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+
+	"github.com/wneessen/go-mail"
+	"xorm.io/xorm"
+)
+
+// QueuedMailStatus represents the state a mail in the queue is in
+type QueuedMailStatus int
+
+const (
+	// QueuedMailStatusPending means the mail is waiting to be sent, either for the first time or for a retry.
+	QueuedMailStatusPending QueuedMailStatus = iota
+	// QueuedMailStatusSending means the mail is currently being sent.
+	QueuedMailStatusSending
+	// QueuedMailStatusSent means the mail was sent successfully.
+	QueuedMailStatusSent
+	// QueuedMailStatusFailed means sending the mail failed too many times and it won't be retried automatically.
+	QueuedMailStatusFailed
+)
+
+func (s QueuedMailStatus) String() string {
+	switch s {
+	case QueuedMailStatusPending:
+		return "Pending"
+	case QueuedMailStatusSending:
+		return "Sending"
+	case QueuedMailStatusSent:
+		return "Sent"
+	case QueuedMailStatusFailed:
+		return "Failed"
+	}
+
+	return "Unknown"
+}
+
+// The first retry happens after this duration, every following one waits twice as long as the one before.
+const retryBackoffBase = time.Minute
+
+// The longest time to wait between two attempts.
+const retryBackoffMax = 6 * time.Hour
+
+// QueuedMail is a mail waiting in the database to be sent
+type QueuedMail struct {
+	ID int64 `xorm:"bigint autoincr not null unique pk"`
+
+	From        string            `xorm:"text not null"`
+	To          string            `xorm:"text not null"`
+	Subject     string            `xorm:"text not null"`
+	Message     string            `xorm:"longtext null"`
+	HTMLMessage string            `xorm:"longtext null"`
+	ContentType ContentType       `xorm:"int not null default 0"`
+	Boundary    string            `xorm:"varchar(250) null"`
+	Headers     map[string]string `xorm:"json null"`
+	Embeds      map[string][]byte `xorm:"json null"`
+
+	Status QueuedMailStatus `xorm:"int not null default 0 index"`
+	// How often sending this mail was attempted.
+	Attempts int `xorm:"int not null default 0"`
+	// The error returned by the last failed attempt.
+	LastError string `xorm:"text null"`
+	// The next time the daemon will try to send this mail.
+	NextAttemptAt time.Time `xorm:"datetime null index"`
+	SentAt        time.Time `xorm:"datetime null"`
+
+	Created time.Time `xorm:"created not null"`
+	Updated time.Time `xorm:"updated not null"`
+}
+
+// TableName returns the table name for queued mails
+func (*QueuedMail) TableName() string {
+	return "mail_queue"
+}
+
+// GetTables returns all structs which are also a table.
+func GetTables() []interface{} {
+	return []interface{}{
+		&QueuedMail{},
+	}
+}
+
+// InitDB sets up the database connection to use in this module
+func InitDB() (err error) {
+	// Cache
+	if config.CacheEnabled.GetBool() && config.CacheType.GetString() == "redis" {
+		db.RegisterTableStructsForCache(GetTables())
+	}
+
+	return nil
+}
+
+func newQueuedMail(opts *Opts) (*QueuedMail, error) {
+	if opts.From == "" {
+		opts.From = "Vikunja <" + config.MailerFromEmail.GetString() + ">"
+	}
+
+	qm := &QueuedMail{
+		From:          opts.From,
+		To:            opts.To,
+		Subject:       opts.Subject,
+		Message:       opts.Message,
+		HTMLMessage:   opts.HTMLMessage,
+		ContentType:   opts.ContentType,
+		Boundary:      opts.Boundary,
+		Headers:       make(map[string]string, len(opts.Headers)),
+		Embeds:        make(map[string][]byte, len(opts.Embeds)+len(opts.EmbedFS)),
+		Status:        QueuedMailStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+
+	for _, h := range opts.Headers {
+		qm.Headers[string(h.Field)] = h.Content
+	}
+
+	// Embedded files need to be stored with the mail because readers and file systems can't be persisted.
+	for name, content := range opts.Embeds {
+		buf, err := io.ReadAll(content)
+		if err != nil {
+			return nil, err
+		}
+		qm.Embeds[name] = buf
+	}
+
+	for name, fs := range opts.EmbedFS {
+		buf, err := fs.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		qm.Embeds[name] = buf
+	}
+
+	return qm, nil
+}
+
+func (qm *QueuedMail) toOpts() *Opts {
+	opts := &Opts{
+		From:        qm.From,
+		To:          qm.To,
+		Subject:     qm.Subject,
+		Message:     qm.Message,
+		HTMLMessage: qm.HTMLMessage,
+		ContentType: qm.ContentType,
+		Boundary:    qm.Boundary,
+		Embeds:      make(map[string]io.Reader, len(qm.Embeds)),
+	}
+
+	for field, content := range qm.Headers {
+		opts.Headers = append(opts.Headers, &header{Field: mail.Header(field), Content: content})
+	}
+
+	for name, content := range qm.Embeds {
+		opts.Embeds[name] = bytes.NewReader(content)
+	}
+
+	return opts
+}
+
+// enqueueMail saves a mail to the queue in the database.
+func enqueueMail(s *xorm.Session, opts *Opts) (qm *QueuedMail, err error) {
+	qm, err = newQueuedMail(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.Insert(qm)
+	return
+}
+
+// getNextRetryTime calculates when a mail should be retried after the given number of failed attempts.
+func getNextRetryTime(attempts int) time.Time {
+	backoff := retryBackoffMax
+	if attempts < 32 {
+		backoff = time.Duration(math.Pow(2, float64(attempts-1))) * retryBackoffBase
+	}
+	if backoff > retryBackoffMax {
+		backoff = retryBackoffMax
+	}
+
+	return time.Now().Add(backoff)
+}
+
+// claimMail marks a pending mail as being sent. It returns false if another process claimed it first.
+func claimMail(s *xorm.Session, qm *QueuedMail) (claimed bool, err error) {
+	qm.Status = QueuedMailStatusSending
+	affected, err := s.
+		Where("id = ? AND status = ?", qm.ID, QueuedMailStatusPending).
+		Cols("status").
+		NoAutoCondition().
+		Update(qm)
+	return affected == 1, err
+}
+
+// markMailSent updates a queued mail after it was sent successfully.
+func markMailSent(s *xorm.Session, qm *QueuedMail) (err error) {
+	qm.Status = QueuedMailStatusSent
+	qm.Attempts++
+	qm.LastError = ""
+	qm.SentAt = time.Now()
+	_, err = s.
+		Where("id = ?", qm.ID).
+		Cols("status", "attempts", "last_error", "sent_at").
+		NoAutoCondition().
+		Update(qm)
+	return
+}
+
+// markMailFailed updates a queued mail after an attempt to send it failed and schedules the next retry.
+// Once the configured maximum number of attempts is reached, the mail is marked as failed and won't be
+// retried automatically anymore.
+func markMailFailed(s *xorm.Session, qm *QueuedMail, sendErr error) (err error) {
+	qm.Attempts++
+	qm.LastError = sendErr.Error()
+	qm.Status = QueuedMailStatusPending
+	qm.NextAttemptAt = getNextRetryTime(qm.Attempts)
+
+	if qm.Attempts >= config.MailerMaxAttempts.GetInt() {
+		qm.Status = QueuedMailStatusFailed
+		log.Errorf("Giving up sending mail %d to %s after %d attempts, last error was: %s", qm.ID, qm.To, qm.Attempts, sendErr)
+	}
+
+	_, err = s.
+		Where("id = ?", qm.ID).
+		Cols("status", "attempts", "last_error", "next_attempt_at").
+		NoAutoCondition().
+		Update(qm)
+	return
+}
+
+// getDueMails returns all pending mails which should be sent now.
+func getDueMails(s *xorm.Session, limit int) (mails []*QueuedMail, err error) {
+	mails = []*QueuedMail{}
+	err = s.
+		Where("status = ? AND next_attempt_at <= ?", QueuedMailStatusPending, time.Now()).
+		OrderBy("id ASC").
+		Limit(limit).
+		Find(&mails)
+	return
+}
+
+// resetInterruptedMails puts all mails which were being sent when Vikunja was stopped back into the queue.
+func resetInterruptedMails(s *xorm.Session) (err error) {
+	_, err = s.
+		Where("status = ?", QueuedMailStatusSending).
+		Cols("status").
+		NoAutoCondition().
+		Update(&QueuedMail{Status: QueuedMailStatusPending})
+	return
+}
+
+// GetQueuedMails returns all mails in the queue with the given status.
+func GetQueuedMails(s *xorm.Session, status QueuedMailStatus) (mails []*QueuedMail, err error) {
+	mails = []*QueuedMail{}
+	err = s.
+		Where("status = ?", status).
+		OrderBy("id ASC").
+		Find(&mails)
+	return
+}
+
+// RetryQueuedMail puts a failed mail back into the queue so the daemon will try to send it again.
+// The attempts are reset, so the mail gets the full number of retries again.
+func RetryQueuedMail(s *xorm.Session, id int64) (err error) {
+	qm := &QueuedMail{}
+	exists, err := s.Where("id = ?", id).Get(qm)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &ErrQueuedMailDoesNotExist{ID: id}
+	}
+
+	qm.Status = QueuedMailStatusPending
+	qm.Attempts = 0
+	qm.NextAttemptAt = time.Now()
+	_, err = s.
+		Where("id = ?", qm.ID).
+		Cols("status", "attempts", "next_attempt_at").
+		NoAutoCondition().
+		Update(qm)
+	if err != nil {
+		return err
+	}
+
+	wakeDaemon()
+	return nil
+}
+
+// DeleteQueuedMail removes a mail from the queue without sending it.
+func DeleteQueuedMail(s *xorm.Session, id int64) (err error) {
+	deleted, err := s.Where("id = ?", id).Delete(&QueuedMail{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrQueuedMailDoesNotExist{ID: id}
+	}
+	return nil
+}
+
+// ErrQueuedMailDoesNotExist represents an error where a queued mail does not exist
+type ErrQueuedMailDoesNotExist struct {
+	ID int64
+}
+
+func (err *ErrQueuedMailDoesNotExist) Error() string {
+	return fmt.Sprintf("Queued mail does not exist [ID: %d]", err.ID)
+}
+
+// RegisterSentMailCleanupCron registers a cron function to remove all successfully sent mails older than seven days
+// from the queue.
+func RegisterSentMailCleanupCron() {
+	const logPrefix = "[Sent Mail Cleanup Cron] "
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		deleted, err := s.
+			Where("status = ? AND sent_at < ?", QueuedMailStatusSent, time.Now().Add(time.Hour*24*7*-1)).
+			Delete(&QueuedMail{})
+		if err != nil {
+			log.Errorf(logPrefix+"Error removing sent mails: %s", err)
+			return
+		}
+		if deleted > 0 {
+			log.Debugf(logPrefix+"Deleted %d sent mails", deleted)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register sent mail cleanup cron: %s", err)
+	}
+}
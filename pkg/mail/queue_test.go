@@ -0,0 +1,144 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mail
+
+import (
+	"embed"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//go:embed testing.go
+var testEmbedFS embed.FS
+
+func TestEnqueueMail(t *testing.T) {
+	s := db.NewSession()
+	defer s.Close()
+
+	qm, err := enqueueMail(s, &Opts{
+		To:          "user@example.com",
+		Subject:     "Test",
+		Message:     "Lorem Ipsum",
+		ContentType: ContentTypePlain,
+		EmbedFS: map[string]*embed.FS{
+			"testing.go": &testEmbedFS,
+		},
+	})
+	assert.NoError(t, err)
+	assert.NotZero(t, qm.ID)
+	assert.Equal(t, QueuedMailStatusPending, qm.Status)
+	assert.Equal(t, "Vikunja <"+config.MailerFromEmail.GetString()+">", qm.From)
+
+	due, err := getDueMails(s, 10)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+	assert.Equal(t, qm.ID, due[0].ID)
+
+	opts := due[0].toOpts()
+	assert.Equal(t, "Lorem Ipsum", opts.Message)
+	assert.Contains(t, opts.Embeds, "testing.go")
+	content, err := io.ReadAll(opts.Embeds["testing.go"])
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "package mail")
+
+	err = DeleteQueuedMail(s, qm.ID)
+	assert.NoError(t, err)
+}
+
+func TestQueuedMailRetries(t *testing.T) {
+	s := db.NewSession()
+	defer s.Close()
+
+	config.MailerMaxAttempts.Set(2)
+	defer config.MailerMaxAttempts.Set(10)
+
+	qm, err := enqueueMail(s, &Opts{To: "user@example.com", Subject: "Test"})
+	assert.NoError(t, err)
+
+	t.Run("claim", func(t *testing.T) {
+		claimed, err := claimMail(s, qm)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		// A second claim must fail because the mail is already being sent
+		claimed, err = claimMail(s, &QueuedMail{ID: qm.ID})
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+	})
+	t.Run("first failure schedules a retry", func(t *testing.T) {
+		err := markMailFailed(s, qm, errors.New("connection refused"))
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "mail_queue", map[string]interface{}{
+			"id":         qm.ID,
+			"status":     QueuedMailStatusPending,
+			"attempts":   1,
+			"last_error": "connection refused",
+		}, false)
+		assert.True(t, qm.NextAttemptAt.After(time.Now()))
+
+		// Not due yet
+		due, err := getDueMails(s, 10)
+		assert.NoError(t, err)
+		assert.Len(t, due, 0)
+	})
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		err := markMailFailed(s, qm, errors.New("connection refused"))
+		assert.NoError(t, err)
+
+		failed, err := GetQueuedMails(s, QueuedMailStatusFailed)
+		assert.NoError(t, err)
+		assert.Len(t, failed, 1)
+		assert.Equal(t, 2, failed[0].Attempts)
+	})
+	t.Run("retry", func(t *testing.T) {
+		err := RetryQueuedMail(s, qm.ID)
+		assert.NoError(t, err)
+
+		due, err := getDueMails(s, 10)
+		assert.NoError(t, err)
+		assert.Len(t, due, 1)
+		assert.Equal(t, 0, due[0].Attempts)
+	})
+	t.Run("retry nonexisting", func(t *testing.T) {
+		err := RetryQueuedMail(s, 9999)
+		assert.Error(t, err)
+		assert.IsType(t, &ErrQueuedMailDoesNotExist{}, err)
+	})
+	t.Run("sent", func(t *testing.T) {
+		err := markMailSent(s, qm)
+		assert.NoError(t, err)
+
+		sent, err := GetQueuedMails(s, QueuedMailStatusSent)
+		assert.NoError(t, err)
+		assert.Len(t, sent, 1)
+	})
+}
+
+func TestGetNextRetryTime(t *testing.T) {
+	assert.WithinDuration(t, time.Now().Add(time.Minute), getNextRetryTime(1), time.Second)
+	assert.WithinDuration(t, time.Now().Add(4*time.Minute), getNextRetryTime(3), time.Second)
+	assert.WithinDuration(t, time.Now().Add(retryBackoffMax), getNextRetryTime(20), time.Second)
+	assert.WithinDuration(t, time.Now().Add(retryBackoffMax), getNextRetryTime(100), time.Second)
+}
@@ -17,10 +17,12 @@
 package mail
 
 import (
+	"context"
 	"embed"
 	"io"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/version"
 
@@ -57,7 +59,7 @@ type header struct {
 }
 
 // SendTestMail sends a test mail to a recipient.
-// It works without a queue.
+// It goes through the queue like every other mail but is sent right away instead of waiting for the daemon.
 func SendTestMail(opts *Opts) error {
 	if config.MailerHost.GetString() == "" {
 		log.Warning("Mailer seems to be not configured! Please see the config docs for more details.")
@@ -69,9 +71,35 @@ func SendTestMail(opts *Opts) error {
 		return err
 	}
 
-	m := getMessage(opts)
+	s := db.NewSession()
+	defer s.Close()
 
-	return c.DialAndSend(m)
+	qm, err := enqueueMail(s, opts)
+	if err != nil {
+		return err
+	}
+
+	claimed, err := claimMail(s, qm)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		// The mail daemon picked up the mail before us and will send it
+		log.Debugf("Test mail %d is already being sent by the mail daemon", qm.ID)
+		return nil
+	}
+
+	err = c.DialWithContext(context.Background())
+	if err != nil {
+		if err := markMailFailed(s, qm, err); err != nil {
+			log.Errorf("Could not update queued mail %d: %s", qm.ID, err)
+		}
+		return err
+	}
+
+	err = sendQueuedMail(s, c, qm)
+	_ = c.Close()
+	return err
 }
 
 func getMessage(opts *Opts) *mail.Msg {
@@ -113,12 +141,24 @@ func getMessage(opts *Opts) *mail.Msg {
 }
 
 // SendMail puts a mail in the queue
-func SendMail(opts *Opts) {
+func SendMail(opts *Opts) error {
 	if isUnderTest {
 		sentMails = append(sentMails, opts)
-		return
+		return nil
+	}
+
+	if !config.MailerEnabled.GetBool() {
+		return nil
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := enqueueMail(s, opts)
+	if err != nil {
+		return err
 	}
 
-	m := getMessage(opts)
-	Queue <- m
+	wakeDaemon()
+	return nil
 }
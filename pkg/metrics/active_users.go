@@ -60,24 +60,28 @@ func init() {
 		Name: "vikunja_active_users",
 		Help: "The currently active users on this node",
 	}, func() float64 {
-
-		allActiveUsers, err := getActiveUsers()
+		activeUsersCount, err := CountActiveUsers()
 		if err != nil {
 			log.Error(err.Error())
 		}
-		if allActiveUsers == nil {
-			return 0
-		}
-		activeUsersCount := 0
-		for _, u := range allActiveUsers {
-			if time.Since(u.LastSeen) < SecondsUntilInactive*time.Second {
-				activeUsersCount++
-			}
-		}
 		return float64(activeUsersCount)
 	})
 }
 
+// CountActiveUsers returns how many users were active in the last SecondsUntilInactive seconds
+func CountActiveUsers() (count int64, err error) {
+	allActiveUsers, err := getActiveUsers()
+	if err != nil {
+		return 0, err
+	}
+	for _, u := range allActiveUsers {
+		if time.Since(u.LastSeen) < SecondsUntilInactive*time.Second {
+			count++
+		}
+	}
+	return
+}
+
 // SetUserActive sets a user as active and pushes it to redis
 func SetUserActive(a web.Auth) (err error) {
 	activeUsers.mutex.Lock()
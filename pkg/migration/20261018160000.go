@@ -0,0 +1,57 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type taskReminders20261018160000 struct {
+	UserID int64 `xorm:"bigint not null default 0 INDEX"`
+}
+
+func (taskReminders20261018160000) TableName() string {
+	return "task_reminders"
+}
+
+type notifications20261018160000 struct {
+	SnoozedUntil time.Time `xorm:"datetime null index"`
+}
+
+func (notifications20261018160000) TableName() string {
+	return "notifications"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018160000",
+		Description: "Add snoozed reminders and notifications",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(taskReminders20261018160000{})
+			if err != nil {
+				return err
+			}
+			return tx.Sync2(notifications20261018160000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,53 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type userSessions20261018180000 struct {
+	ID            int64     `xorm:"bigint autoincr not null unique pk"`
+	UserID        int64     `xorm:"bigint not null index"`
+	DeviceName    string    `xorm:"text null"`
+	IPAddress     string    `xorm:"varchar(250) null"`
+	IsLongSession bool      `xorm:"bool not null default false"`
+	RefreshToken  string    `xorm:"varchar(450) not null index"`
+	LastSeen      time.Time `xorm:"datetime not null"`
+	Expires       time.Time `xorm:"datetime not null index"`
+	Created       time.Time `xorm:"created not null"`
+}
+
+func (userSessions20261018180000) TableName() string {
+	return "user_sessions"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018180000",
+		Description: "Add user sessions table",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(userSessions20261018180000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(userSessions20261018180000{})
+		},
+	})
+}
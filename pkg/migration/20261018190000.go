@@ -0,0 +1,65 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type userWebAuthnCredentials20261018190000 struct {
+	ID              int64     `xorm:"bigint autoincr not null unique pk"`
+	UserID          int64     `xorm:"bigint not null index"`
+	Name            string    `xorm:"varchar(250) not null"`
+	CredentialID    string    `xorm:"varchar(450) not null unique"`
+	PublicKey       []byte    `xorm:"blob not null"`
+	AttestationType string    `xorm:"varchar(250) null"`
+	AAGUID          []byte    `xorm:"blob null"`
+	SignCount       int64     `xorm:"bigint not null default 0"`
+	LastUsed        time.Time `xorm:"datetime null"`
+	Created         time.Time `xorm:"created not null"`
+}
+
+func (userWebAuthnCredentials20261018190000) TableName() string {
+	return "user_webauthn_credentials"
+}
+
+type userRecoveryCodes20261018190000 struct {
+	ID      int64     `xorm:"bigint autoincr not null unique pk"`
+	UserID  int64     `xorm:"bigint not null index"`
+	Code    string    `xorm:"varchar(450) not null index"`
+	Created time.Time `xorm:"created not null"`
+}
+
+func (userRecoveryCodes20261018190000) TableName() string {
+	return "user_recovery_codes"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018190000",
+		Description: "Add webauthn credentials and recovery codes tables",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(userWebAuthnCredentials20261018190000{}, userRecoveryCodes20261018190000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(userWebAuthnCredentials20261018190000{}, userRecoveryCodes20261018190000{})
+		},
+	})
+}
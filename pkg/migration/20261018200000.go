@@ -0,0 +1,58 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type users20261018200000 struct {
+	IsAdmin bool `xorm:"bool default false"`
+}
+
+func (users20261018200000) TableName() string {
+	return "users"
+}
+
+type adminAuditLog20261018200000 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	AdminID      int64     `xorm:"bigint not null index"`
+	Action       string    `xorm:"varchar(250) not null index"`
+	TargetUserID int64     `xorm:"bigint null index"`
+	Details      string    `xorm:"text null"`
+	Created      time.Time `xorm:"created not null"`
+}
+
+func (adminAuditLog20261018200000) TableName() string {
+	return "admin_audit_log"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018200000",
+		Description: "Add instance admin flag to users and admin audit log",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(users20261018200000{}, adminAuditLog20261018200000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(adminAuditLog20261018200000{})
+		},
+	})
+}
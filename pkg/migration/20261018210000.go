@@ -0,0 +1,68 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type userInvitations20261018210000 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk"`
+	Token       string    `xorm:"varchar(450) not null unique index"`
+	Email       string    `xorm:"varchar(250) null"`
+	MaxUses     int64     `xorm:"bigint not null default 1"`
+	Uses        int64     `xorm:"bigint not null default 0"`
+	Expires     time.Time `xorm:"datetime null"`
+	CreatedByID int64     `xorm:"bigint not null index"`
+	Created     time.Time `xorm:"created not null"`
+}
+
+func (userInvitations20261018210000) TableName() string {
+	return "user_invitations"
+}
+
+type shareInvitations20261018210000 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	Email        string    `xorm:"varchar(250) not null index"`
+	ListID       int64     `xorm:"bigint null index"`
+	TeamID       int64     `xorm:"bigint null index"`
+	Right        int64     `xorm:"bigint INDEX not null default 0"`
+	Admin        bool      `xorm:"null"`
+	InvitationID int64     `xorm:"bigint not null"`
+	CreatedByID  int64     `xorm:"bigint not null"`
+	Created      time.Time `xorm:"created not null"`
+}
+
+func (shareInvitations20261018210000) TableName() string {
+	return "share_invitations"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018210000",
+		Description: "Add user invitations and share invitations",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(userInvitations20261018210000{}, shareInvitations20261018210000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(userInvitations20261018210000{}, shareInvitations20261018210000{})
+		},
+	})
+}
@@ -0,0 +1,103 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type roles20261018220000 struct {
+	ID               int64     `xorm:"bigint autoincr not null unique pk"`
+	Title            string    `xorm:"varchar(250) not null"`
+	Description      string    `xorm:"longtext null"`
+	CanComment       bool      `xorm:"bool not null default false"`
+	CanCreateTask    bool      `xorm:"bool not null default false"`
+	CanEditTask      bool      `xorm:"bool not null default false"`
+	CanDeleteTask    bool      `xorm:"bool not null default false"`
+	CanManageBuckets bool      `xorm:"bool not null default false"`
+	CanShare         bool      `xorm:"bool not null default false"`
+	OwnerID          int64     `xorm:"bigint not null INDEX"`
+	Created          time.Time `xorm:"created not null"`
+	Updated          time.Time `xorm:"updated not null"`
+}
+
+func (roles20261018220000) TableName() string {
+	return "roles"
+}
+
+type usersLists20261018220000 struct {
+	RoleID int64 `xorm:"bigint null INDEX"`
+}
+
+func (usersLists20261018220000) TableName() string {
+	return "users_lists"
+}
+
+type teamLists20261018220000 struct {
+	RoleID int64 `xorm:"bigint null INDEX"`
+}
+
+func (teamLists20261018220000) TableName() string {
+	return "team_lists"
+}
+
+type usersNamespaces20261018220000 struct {
+	RoleID int64 `xorm:"bigint null INDEX"`
+}
+
+func (usersNamespaces20261018220000) TableName() string {
+	return "users_namespaces"
+}
+
+type teamNamespaces20261018220000 struct {
+	RoleID int64 `xorm:"bigint null INDEX"`
+}
+
+func (teamNamespaces20261018220000) TableName() string {
+	return "team_namespaces"
+}
+
+type linkShares20261018220000 struct {
+	RoleID int64 `xorm:"bigint null INDEX"`
+}
+
+func (linkShares20261018220000) TableName() string {
+	return "link_shares"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261018220000",
+		Description: "Add custom roles and assign them to shares",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(
+				roles20261018220000{},
+				usersLists20261018220000{},
+				teamLists20261018220000{},
+				usersNamespaces20261018220000{},
+				teamNamespaces20261018220000{},
+				linkShares20261018220000{},
+			)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(roles20261018220000{})
+		},
+	})
+}
@@ -0,0 +1,55 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type buckets20261019010000 struct {
+	NamespaceID   int64 `xorm:"bigint null INDEX"`
+	SavedFilterID int64 `xorm:"bigint null INDEX"`
+}
+
+func (buckets20261019010000) TableName() string {
+	return "buckets"
+}
+
+type taskBuckets20261019010000 struct {
+	ID             int64   `xorm:"bigint autoincr not null unique pk"`
+	TaskID         int64   `xorm:"bigint not null INDEX"`
+	BucketID       int64   `xorm:"bigint not null INDEX"`
+	KanbanPosition float64 `xorm:"double null"`
+}
+
+func (taskBuckets20261019010000) TableName() string {
+	return "task_buckets"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261019010000",
+		Description: "Add kanban boards for namespaces and saved filters",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(buckets20261019010000{}, taskBuckets20261019010000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(taskBuckets20261019010000{})
+		},
+	})
+}
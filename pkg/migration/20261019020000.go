@@ -0,0 +1,69 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type bucketRules20261019020000 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk"`
+	BucketID    int64     `xorm:"bigint not null INDEX"`
+	Trigger     string    `xorm:"varchar(50) not null"`
+	Action      string    `xorm:"varchar(50) not null"`
+	Value       int64     `xorm:"bigint null"`
+	CreatedByID int64     `xorm:"bigint not null"`
+	Created     time.Time `xorm:"created not null"`
+	Updated     time.Time `xorm:"updated not null"`
+}
+
+func (bucketRules20261019020000) TableName() string {
+	return "bucket_rules"
+}
+
+type taskHistory20261019020000 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	TaskID       int64     `xorm:"bigint not null INDEX"`
+	Kind         string    `xorm:"varchar(50) not null"`
+	BucketID     int64     `xorm:"bigint null"`
+	BucketRuleID int64     `xorm:"bigint null"`
+	Trigger      string    `xorm:"varchar(50) null"`
+	Action       string    `xorm:"varchar(50) null"`
+	Value        int64     `xorm:"bigint null"`
+	CreatedByID  int64     `xorm:"bigint not null"`
+	Created      time.Time `xorm:"created not null"`
+}
+
+func (taskHistory20261019020000) TableName() string {
+	return "task_history"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261019020000",
+		Description: "Add bucket rules and task history",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(bucketRules20261019020000{}, taskHistory20261019020000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(bucketRules20261019020000{}, taskHistory20261019020000{})
+		},
+	})
+}
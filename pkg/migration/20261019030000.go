@@ -0,0 +1,65 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type buckets20261019030000 struct {
+	AssigneeLimit int64  `xorm:"bigint default 0"`
+	LimitMode     string `xorm:"varchar(10) not null default 'hard'"`
+}
+
+func (buckets20261019030000) TableName() string {
+	return "buckets"
+}
+
+type taskBucketEntries20261019030000 struct {
+	ID        int64     `xorm:"bigint autoincr not null unique pk"`
+	TaskID    int64     `xorm:"bigint not null INDEX"`
+	BucketID  int64     `xorm:"bigint not null INDEX"`
+	EnteredAt time.Time `xorm:"not null INDEX"`
+	LeftAt    time.Time `xorm:"null"`
+}
+
+func (taskBucketEntries20261019030000) TableName() string {
+	return "task_bucket_entries"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261019030000",
+		Description: "Add bucket limit modes, assignee limits and task bucket entries",
+		Migrate: func(tx *xorm.Engine) error {
+			err := tx.Sync2(buckets20261019030000{}, taskBucketEntries20261019030000{})
+			if err != nil {
+				return err
+			}
+
+			// We don't know when existing tasks entered their bucket, so we assume they did when they were created.
+			_, err = tx.Exec("INSERT INTO task_bucket_entries (task_id, bucket_id, entered_at) SELECT id, bucket_id, created FROM tasks WHERE bucket_id != 0")
+			return err
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(taskBucketEntries20261019030000{})
+		},
+	})
+}
@@ -0,0 +1,75 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type taskStatuses20261019040000 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	ListID       int64     `xorm:"bigint not null INDEX"`
+	Title        string    `xorm:"varchar(250) not null"`
+	CaldavStatus string    `xorm:"varchar(20) not null"`
+	BucketID     int64     `xorm:"bigint null"`
+	Position     float64   `xorm:"double null"`
+	CreatedByID  int64     `xorm:"bigint not null"`
+	Created      time.Time `xorm:"created not null"`
+	Updated      time.Time `xorm:"updated not null"`
+}
+
+func (taskStatuses20261019040000) TableName() string {
+	return "task_statuses"
+}
+
+type taskStatusTransitions20261019040000 struct {
+	ID           int64 `xorm:"bigint autoincr not null unique pk"`
+	FromStatusID int64 `xorm:"bigint not null INDEX"`
+	ToStatusID   int64 `xorm:"bigint not null INDEX"`
+}
+
+func (taskStatusTransitions20261019040000) TableName() string {
+	return "task_status_transitions"
+}
+
+type tasks20261019040000 struct {
+	StatusID int64 `xorm:"bigint null INDEX"`
+}
+
+func (tasks20261019040000) TableName() string {
+	return "tasks"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261019040000",
+		Description: "Add workflow statuses for tasks",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(
+				taskStatuses20261019040000{},
+				taskStatusTransitions20261019040000{},
+				tasks20261019040000{},
+			)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(taskStatuses20261019040000{}, taskStatusTransitions20261019040000{})
+		},
+	})
+}
@@ -0,0 +1,71 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type tasks20261019080000 struct {
+	Deleted           time.Time `xorm:"deleted null INDEX"`
+	DeletedByID       int64     `xorm:"bigint null"`
+	DeletedWithParent bool      `xorm:"null"`
+}
+
+func (tasks20261019080000) TableName() string {
+	return "tasks"
+}
+
+type lists20261019080000 struct {
+	Deleted           time.Time `xorm:"deleted null INDEX"`
+	DeletedByID       int64     `xorm:"bigint null"`
+	DeletedWithParent bool      `xorm:"null"`
+}
+
+func (lists20261019080000) TableName() string {
+	return "lists"
+}
+
+type namespaces20261019080000 struct {
+	Deleted           time.Time `xorm:"deleted null INDEX"`
+	DeletedByID       int64     `xorm:"bigint null"`
+	DeletedWithParent bool      `xorm:"null"`
+}
+
+func (namespaces20261019080000) TableName() string {
+	return "namespaces"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261019080000",
+		Description: "Add trash columns to tasks, lists and namespaces",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(
+				tasks20261019080000{},
+				lists20261019080000{},
+				namespaces20261019080000{},
+			)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
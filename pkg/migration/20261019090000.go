@@ -0,0 +1,47 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type instanceSettings20261019090000 struct {
+	Key     string    `xorm:"varchar(250) not null pk"`
+	Value   string    `xorm:"text null"`
+	Updated time.Time `xorm:"updated not null"`
+}
+
+func (instanceSettings20261019090000) TableName() string {
+	return "instance_settings"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20261019090000",
+		Description: "Add instance settings to keep the registration setting across restarts",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(instanceSettings20261019090000{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(instanceSettings20261019090000{})
+		},
+	})
+}
@@ -0,0 +1,407 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/metrics"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// checkInstanceAdmin returns an error if the authenticated user is not an instance admin.
+func checkInstanceAdmin(s *xorm.Session, a web.Auth) error {
+	if _, is := a.(*LinkSharing); is {
+		return ErrGenericForbidden{}
+	}
+
+	u, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return err
+	}
+	if !u.IsAdmin {
+		return &user.ErrUserIsNotAdmin{UserID: u.ID}
+	}
+	return nil
+}
+
+// AdminUser is a user as seen by instance admins.
+type AdminUser struct {
+	// The unique, numeric id of this user.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"user"`
+	// The full name of the user.
+	Name string `xorm:"text null" json:"name"`
+	// The username of the user.
+	Username string `xorm:"varchar(250) not null unique" json:"username"`
+	// The user's email address.
+	Email string `xorm:"varchar(250) null" json:"email"`
+	// The status of the user. 0 is active, 1 means the user still has to confirm their email address and 2 is disabled.
+	Status user.Status `xorm:"default 0" json:"status"`
+	// Whether the user is an instance admin.
+	IsAdmin bool `xorm:"bool default false" json:"is_admin"`
+	// Where the user authenticates, for example `local`, `ldap` or the url of an openid provider.
+	Issuer string `xorm:"text null" json:"issuer"`
+	// If set, the user requested the deletion of their account and it will be deleted at this time.
+	DeletionScheduledAt time.Time `xorm:"datetime null" json:"deletion_scheduled_at"`
+
+	// A timestamp when this user was created.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this user was last updated.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for admin users
+func (*AdminUser) TableName() string {
+	return "users"
+}
+
+// CanRead checks if the user is an instance admin
+func (au *AdminUser) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	err := checkInstanceAdmin(s, a)
+	return err == nil, 0, err
+}
+
+// CanUpdate checks if the user is an instance admin
+func (au *AdminUser) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	err := checkInstanceAdmin(s, a)
+	return err == nil, err
+}
+
+// CanDelete checks if the user is an instance admin
+func (au *AdminUser) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	err := checkInstanceAdmin(s, a)
+	return err == nil, err
+}
+
+// ReadAll returns all users of this instance
+// @Summary Get all users
+// @Description Returns all users of this instance, including their email address and status. Only instance admins can list users.
+// @tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search users by their username, name or email address."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.AdminUser "The users"
+// @Failure 403 {object} web.HTTPError "Only instance admins can list users."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /admin/users [get]
+func (au *AdminUser) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if err := checkInstanceAdmin(s, a); err != nil {
+		return nil, 0, 0, err
+	}
+
+	var cond builder.Cond = builder.NewCond()
+	if search != "" {
+		cond = builder.Or(
+			db.ILIKE("username", search),
+			db.ILIKE("name", search),
+			db.ILIKE("email", search),
+		)
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	users := []*AdminUser{}
+	query := s.Where(cond).OrderBy("id asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&users)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	numberOfTotalItems, err = s.Where(cond).Count(&AdminUser{})
+	return users, len(users), numberOfTotalItems, err
+}
+
+// ReadOne returns one user
+// @Summary Get one user
+// @Description Returns one user of this instance, including their email address and status. Only instance admins can see this.
+// @tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Security JWTKeyAuth
+// @Success 200 {object} models.AdminUser "The user"
+// @Failure 403 {object} web.HTTPError "Only instance admins can see this."
+// @Failure 404 {object} web.HTTPError "The user does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /admin/users/{id} [get]
+func (au *AdminUser) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	exists, err := s.Where("id = ?", au.ID).Get(au)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return user.ErrUserDoesNotExist{UserID: au.ID}
+	}
+	return nil
+}
+
+// Update changes the status of a user
+// @Summary Change the status of a user
+// @Description Enables or disables a user. Disabling a user revokes all their sessions. Only the status can be changed. Only instance admins can do this, the change is recorded in the audit log.
+// @tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param user body models.AdminUser true "The user with the new status."
+// @Security JWTKeyAuth
+// @Success 200 {object} models.AdminUser "The updated user"
+// @Failure 400 {object} web.HTTPError "The user status is invalid."
+// @Failure 403 {object} web.HTTPError "Only instance admins can do this."
+// @Failure 404 {object} web.HTTPError "The user does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /admin/users/{id} [post]
+func (au *AdminUser) Update(s *xorm.Session, a web.Auth) (err error) {
+	if au.Status != user.StatusActive &&
+		au.Status != user.StatusEmailConfirmationRequired &&
+		au.Status != user.StatusDisabled {
+		return &user.ErrInvalidUserStatus{Status: au.Status}
+	}
+
+	u, err := user.GetUserByID(s, au.ID)
+	if err != nil {
+		return err
+	}
+
+	oldStatus := u.Status
+	err = u.SetStatus(s, au.Status)
+	if err != nil {
+		return err
+	}
+
+	err = logAdminAction(s, a, AdminActionUserStatusChanged, u.ID, fmt.Sprintf("%s -> %s", oldStatus, u.Status))
+	if err != nil {
+		return err
+	}
+
+	return au.ReadOne(s, a)
+}
+
+// Delete removes a user and all their data immediately
+// @Summary Delete a user
+// @Description Deletes a user and all their lists, namespaces and tasks immediately without asking them first. USE WITH CAUTION. Only instance admins can do this, the deletion is recorded in the audit log.
+// @tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Security JWTKeyAuth
+// @Success 200 {object} models.Message "The user was deleted successfully."
+// @Failure 403 {object} web.HTTPError "Only instance admins can do this."
+// @Failure 404 {object} web.HTTPError "The user does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /admin/users/{id} [delete]
+func (au *AdminUser) Delete(s *xorm.Session, a web.Auth) (err error) {
+	u, err := user.GetUserByID(s, au.ID)
+	if err != nil {
+		return err
+	}
+
+	err = DeleteUser(s, u)
+	if err != nil {
+		return err
+	}
+
+	return logAdminAction(s, a, AdminActionUserDeleted, u.ID, u.Username)
+}
+
+// ResetUserPassword resets the password of a user. If no new password is provided, the user gets an email with a
+// link to reset their password instead.
+func ResetUserPassword(s *xorm.Session, a web.Auth, userID int64, newPassword string) (err error) {
+	if err := checkInstanceAdmin(s, a); err != nil {
+		return err
+	}
+
+	u, err := user.GetUserWithEmail(s, &user.User{ID: userID})
+	if err != nil {
+		return err
+	}
+	if u.Issuer != user.IssuerLocal {
+		return &user.ErrAccountIsNotLocal{UserID: u.ID}
+	}
+
+	details := "reset link sent by email"
+	if newPassword != "" {
+		details = "password set directly"
+		err = user.UpdateUserPassword(s, u, newPassword)
+	} else {
+		err = user.RequestUserPasswordResetToken(s, u)
+	}
+	if err != nil {
+		return err
+	}
+
+	return logAdminAction(s, a, AdminActionUserPasswordReset, u.ID, details)
+}
+
+// AdminStorageUsage holds how much file storage a user uses.
+type AdminStorageUsage struct {
+	// The id of the user.
+	UserID int64 `xorm:"'user_id'" json:"user_id"`
+	// The username of the user.
+	Username string `xorm:"-" json:"username"`
+	// How many files the user uploaded.
+	FileCount int64 `xorm:"'file_count'" json:"file_count"`
+	// The size of all files the user uploaded, in bytes.
+	TotalSize int64 `xorm:"'total_size'" json:"total_size"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// ReadAll returns the storage usage of all users, biggest first
+// @Summary Get the storage usage per user
+// @Description Returns how many files each user uploaded and how much space they use, ordered by the used space. Only instance admins can see this.
+// @tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.AdminStorageUsage "The storage usage"
+// @Failure 403 {object} web.HTTPError "Only instance admins can see this."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /admin/storage [get]
+func (su *AdminStorageUsage) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if err := checkInstanceAdmin(s, a); err != nil {
+		return nil, 0, 0, err
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	usages := []*AdminStorageUsage{}
+	query := s.
+		Table(&files.File{}).
+		Select("created_by_id AS user_id, COUNT(*) AS file_count, SUM(size) AS total_size").
+		GroupBy("created_by_id").
+		OrderBy("total_size DESC, user_id ASC")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&usages)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(usages))
+	for _, u := range usages {
+		userIDs = append(userIDs, u.UserID)
+	}
+	users, err := user.GetUsersByIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, u := range usages {
+		if us, has := users[u.UserID]; has {
+			u.Username = us.Username
+		}
+	}
+
+	_, err = s.SQL("SELECT COUNT(DISTINCT created_by_id) FROM files").Get(&numberOfTotalItems)
+	return usages, len(usages), numberOfTotalItems, err
+}
+
+// InstanceStats holds statistics about this instance.
+type InstanceStats struct {
+	Users      int64 `json:"users"`
+	Lists      int64 `json:"lists"`
+	Namespaces int64 `json:"namespaces"`
+	Tasks      int64 `json:"tasks"`
+	Teams      int64 `json:"teams"`
+	// The users active in the last minute. Only available if metrics are enabled.
+	ActiveUsers int64 `json:"active_users"`
+	Files       int64 `json:"files"`
+	// The size of all uploaded files, in bytes.
+	StorageUsed         int64 `json:"storage_used"`
+	RegistrationEnabled bool  `json:"registration_enabled"`
+}
+
+// GetInstanceStats returns statistics about this instance. If metrics are enabled, the counts come from the metrics
+// counters, otherwise they are counted in the database.
+func GetInstanceStats(s *xorm.Session, a web.Auth) (stats *InstanceStats, err error) {
+	if err := checkInstanceAdmin(s, a); err != nil {
+		return nil, err
+	}
+
+	stats = &InstanceStats{}
+
+	for key, c := range map[string]struct {
+		count *int64
+		table interface{}
+	}{
+		metrics.UserCountKey:      {&stats.Users, &user.User{}},
+		metrics.ListCountKey:      {&stats.Lists, &List{}},
+		metrics.NamespaceCountKey: {&stats.Namespaces, &Namespace{}},
+		metrics.TaskCountKey:      {&stats.Tasks, &Task{}},
+		metrics.TeamCountKey:      {&stats.Teams, &Team{}},
+	} {
+		if config.MetricsEnabled.GetBool() {
+			*c.count, err = metrics.GetCount(key)
+		} else {
+			*c.count, err = s.Count(c.table)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.MetricsEnabled.GetBool() {
+		stats.ActiveUsers, err = metrics.CountActiveUsers()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = s.SQL("SELECT COUNT(*), COALESCE(SUM(size), 0) FROM files").Get(&stats.Files, &stats.StorageUsed)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.RegistrationEnabled, err = user.IsRegistrationEnabled(s)
+	return
+}
+
+// SetRegistrationEnabled enables or disables registration for this instance at runtime.
+func SetRegistrationEnabled(s *xorm.Session, a web.Auth, enabled bool) (err error) {
+	if err := checkInstanceAdmin(s, a); err != nil {
+		return err
+	}
+
+	details := "disabled"
+	if enabled {
+		details = "enabled"
+	}
+	err = logAdminAction(s, a, AdminActionRegistrationChanged, 0, details)
+	if err != nil {
+		return err
+	}
+
+	return user.SetRegistrationEnabled(s, enabled)
+}
@@ -0,0 +1,128 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+const (
+	// AdminActionUserStatusChanged is logged when an admin changes the status of a user
+	AdminActionUserStatusChanged = `user.status.changed`
+	// AdminActionUserPasswordReset is logged when an admin resets the password of a user
+	AdminActionUserPasswordReset = `user.password.reset`
+	// AdminActionUserDeleted is logged when an admin deletes a user
+	AdminActionUserDeleted = `user.deleted`
+	// AdminActionRegistrationChanged is logged when an admin enables or disables registration
+	AdminActionRegistrationChanged = `registration.changed`
+)
+
+// AdminAuditLog is an entry in the log of everything instance admins changed.
+type AdminAuditLog struct {
+	// The unique, numeric id of this log entry.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The id of the admin who did the change.
+	AdminID int64 `xorm:"bigint not null index" json:"-"`
+	// The admin who did the change.
+	Admin *user.User `xorm:"-" json:"admin"`
+	// What the admin did, for example `user.deleted`.
+	Action string `xorm:"varchar(250) not null index" json:"action"`
+	// The id of the user who was changed, if any.
+	TargetUserID int64 `xorm:"bigint null index" json:"target_user_id"`
+	// Additional, human readable details about the change.
+	Details string `xorm:"text null" json:"details"`
+
+	// A timestamp when this log entry was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	// Only return log entries about this user when listing the audit log.
+	FilterUserID int64 `xorm:"-" json:"-" query:"user_id"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName holds the table name for the admin audit log
+func (*AdminAuditLog) TableName() string {
+	return "admin_audit_log"
+}
+
+func logAdminAction(s *xorm.Session, a web.Auth, action string, targetUserID int64, details string) (err error) {
+	_, err = s.Insert(&AdminAuditLog{
+		AdminID:      a.GetID(),
+		Action:       action,
+		TargetUserID: targetUserID,
+		Details:      details,
+	})
+	return
+}
+
+// ReadAll returns the audit log of all admin actions, newest first
+// @Summary Get the admin audit log
+// @Description Returns everything instance admins changed, newest first. Only instance admins can see the audit log.
+// @tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param user_id query int false "Only return log entries about this user."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.AdminAuditLog "The log entries"
+// @Failure 403 {object} web.HTTPError "Only instance admins can see the audit log."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /admin/audit [get]
+func (l *AdminAuditLog) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if err := checkInstanceAdmin(s, a); err != nil {
+		return nil, 0, 0, err
+	}
+
+	cond := &AdminAuditLog{}
+	if l.FilterUserID != 0 {
+		cond.TargetUserID = l.FilterUserID
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	entries := []*AdminAuditLog{}
+	query := s.OrderBy("id desc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&entries, cond)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	adminIDs := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		adminIDs = append(adminIDs, e.AdminID)
+	}
+	admins, err := user.GetUsersByIDs(s, adminIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, e := range entries {
+		e.Admin = admins[e.AdminID]
+	}
+
+	numberOfTotalItems, err = s.Count(cond)
+	return entries, len(entries), numberOfTotalItems, err
+}
@@ -0,0 +1,237 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminUser_ReadAll(t *testing.T) {
+	t.Run("as admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		au := &AdminUser{}
+		result, resultCount, total, err := au.ReadAll(s, &user.User{ID: 14}, "", 1, 50)
+		assert.NoError(t, err)
+		users := result.([]*AdminUser)
+		assert.Equal(t, len(users), resultCount)
+		assert.Equal(t, int64(resultCount), total)
+		assert.Equal(t, "user1", users[0].Username)
+		assert.Equal(t, "user1@example.com", users[0].Email)
+		assert.False(t, users[0].IsAdmin)
+	})
+	t.Run("search", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		au := &AdminUser{}
+		result, _, _, err := au.ReadAll(s, &user.User{ID: 14}, "user3@example", 1, 50)
+		assert.NoError(t, err)
+		users := result.([]*AdminUser)
+		assert.Len(t, users, 1)
+		assert.Equal(t, int64(3), users[0].ID)
+	})
+	t.Run("not an admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		au := &AdminUser{}
+		_, _, _, err := au.ReadAll(s, &user.User{ID: 2}, "", 1, 50)
+		assert.Error(t, err)
+		assert.True(t, user.IsErrUserIsNotAdmin(err))
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		au := &AdminUser{}
+		_, _, _, err := au.ReadAll(s, &LinkSharing{ID: 1}, "", 1, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestAdminUser_Update(t *testing.T) {
+	t.Run("disable", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		au := &AdminUser{ID: 2, Status: user.StatusDisabled}
+		can, err := au.CanUpdate(s, &user.User{ID: 14})
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = au.Update(s, &user.User{ID: 14})
+		assert.NoError(t, err)
+		assert.Equal(t, "user2", au.Username)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":     2,
+			"status": user.StatusDisabled,
+		}, false)
+		db.AssertExists(t, "admin_audit_log", map[string]interface{}{
+			"admin_id":       14,
+			"action":         AdminActionUserStatusChanged,
+			"target_user_id": 2,
+			"details":        "Active -> Disabled",
+		}, false)
+	})
+	t.Run("invalid status", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		au := &AdminUser{ID: 2, Status: 42}
+		err := au.Update(s, &user.User{ID: 14})
+		assert.Error(t, err)
+		assert.True(t, user.IsErrInvalidUserStatus(err))
+	})
+	t.Run("not an admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		au := &AdminUser{ID: 3, Status: user.StatusDisabled}
+		can, err := au.CanUpdate(s, &user.User{ID: 2})
+		assert.Error(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestResetUserPassword(t *testing.T) {
+	t.Run("directly", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ResetUserPassword(s, &user.User{ID: 14}, 2, "12345678")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "admin_audit_log", map[string]interface{}{
+			"action":         AdminActionUserPasswordReset,
+			"target_user_id": 2,
+			"details":        "password set directly",
+		}, false)
+	})
+	t.Run("by email", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ResetUserPassword(s, &user.User{ID: 14}, 2, "")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "user_tokens", map[string]interface{}{
+			"user_id": 2,
+			"kind":    user.TokenPasswordReset,
+		}, false)
+	})
+	t.Run("not an admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ResetUserPassword(s, &user.User{ID: 2}, 3, "12345678")
+		assert.Error(t, err)
+		assert.True(t, user.IsErrUserIsNotAdmin(err))
+	})
+}
+
+func TestAdminStorageUsage_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	su := &AdminStorageUsage{}
+	result, resultCount, total, err := su.ReadAll(s, &user.User{ID: 14}, "", 1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, resultCount)
+	assert.Equal(t, int64(1), total)
+	usages := result.([]*AdminStorageUsage)
+	assert.Equal(t, int64(1), usages[0].UserID)
+	assert.Equal(t, "user1", usages[0].Username)
+	assert.Equal(t, int64(1), usages[0].FileCount)
+	assert.Equal(t, int64(100), usages[0].TotalSize)
+}
+
+func TestGetInstanceStats(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	stats, err := GetInstanceStats(s, &user.User{ID: 14})
+	assert.NoError(t, err)
+	assert.NotZero(t, stats.Users)
+	assert.NotZero(t, stats.Tasks)
+	assert.Equal(t, int64(1), stats.Files)
+	assert.Equal(t, int64(100), stats.StorageUsed)
+	assert.True(t, stats.RegistrationEnabled)
+}
+
+func TestSetRegistrationEnabled(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	err := SetRegistrationEnabled(s, &user.User{ID: 14}, false)
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	enabled, err := user.IsRegistrationEnabled(s)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	db.AssertExists(t, "admin_audit_log", map[string]interface{}{
+		"action":  AdminActionRegistrationChanged,
+		"details": "disabled",
+	}, false)
+
+	err = user.SetRegistrationEnabled(s, true)
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+}
+
+func TestAdminAuditLog_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	l := &AdminAuditLog{FilterUserID: 2}
+	result, resultCount, _, err := l.ReadAll(s, &user.User{ID: 14}, "", 1, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, resultCount)
+	entries := result.([]*AdminAuditLog)
+	assert.Equal(t, AdminActionUserStatusChanged, entries[0].Action)
+	assert.Equal(t, "user14", entries[0].Admin.Username)
+}
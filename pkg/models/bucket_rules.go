@@ -0,0 +1,405 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// BucketRuleTrigger defines when a bucket rule is executed
+type BucketRuleTrigger string
+
+// All bucket rule triggers
+const (
+	// BucketRuleTriggerEnter executes the rule when a task is moved into the bucket.
+	BucketRuleTriggerEnter BucketRuleTrigger = `enter`
+	// BucketRuleTriggerLeave executes the rule when a task is moved out of the bucket.
+	BucketRuleTriggerLeave BucketRuleTrigger = `leave`
+)
+
+// BucketRuleAction defines what a bucket rule does with a task
+type BucketRuleAction string
+
+// All bucket rule actions
+const (
+	// BucketRuleActionAssign assigns the user with the id in value to the task.
+	BucketRuleActionAssign BucketRuleAction = `assign`
+	// BucketRuleActionUnassign removes the user with the id in value from the assignees of the task.
+	BucketRuleActionUnassign BucketRuleAction = `unassign`
+	// BucketRuleActionAddLabel adds the label with the id in value to the task.
+	BucketRuleActionAddLabel BucketRuleAction = `add_label`
+	// BucketRuleActionRemoveLabel removes the label with the id in value from the task.
+	BucketRuleActionRemoveLabel BucketRuleAction = `remove_label`
+	// BucketRuleActionSetDueDate sets the due date of the task to value seconds from now.
+	BucketRuleActionSetDueDate BucketRuleAction = `set_due_date`
+	// BucketRuleActionSetPercentDone sets the progress of the task to value percent.
+	BucketRuleActionSetPercentDone BucketRuleAction = `set_percent_done`
+	// BucketRuleActionNotifySubscribers notifies all subscribers of the task that it was moved.
+	BucketRuleActionNotifySubscribers BucketRuleAction = `notify_subscribers`
+)
+
+// BucketRule is an action which is executed every time a task is moved into or out of a bucket.
+type BucketRule struct {
+	// The unique, numeric id of this rule.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"rule"`
+	// The bucket this rule belongs to.
+	BucketID int64 `xorm:"bigint not null INDEX" json:"bucket_id" param:"bucket"`
+	// When the rule is executed. Can be either `enter` or `leave`.
+	Trigger BucketRuleTrigger `xorm:"varchar(50) not null" json:"trigger"`
+	// What the rule does. Can be one of `assign`, `unassign`, `add_label`, `remove_label`, `set_due_date`, `set_percent_done` or `notify_subscribers`.
+	Action BucketRuleAction `xorm:"varchar(50) not null" json:"action"`
+	// The value of the action: The id of the user for `assign` and `unassign`, the id of the label for `add_label` and `remove_label`, the number of seconds from the time the rule is executed for `set_due_date` and the progress in percent for `set_percent_done`.
+	Value int64 `xorm:"bigint null" json:"value"`
+
+	// The user who initially created the rule.
+	CreatedBy   *user.User `xorm:"-" json:"created_by" valid:"-"`
+	CreatedByID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this rule was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this rule was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for bucket rules
+func (*BucketRule) TableName() string {
+	return "bucket_rules"
+}
+
+func getBucketRuleByID(s *xorm.Session, id int64) (rule *BucketRule, err error) {
+	rule = &BucketRule{}
+	exists, err := s.Where("id = ?", id).Get(rule)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrBucketRuleDoesNotExist{RuleID: id}
+	}
+	return
+}
+
+func (r *BucketRule) validate(s *xorm.Session, a web.Auth) (err error) {
+	if r.Trigger != BucketRuleTriggerEnter && r.Trigger != BucketRuleTriggerLeave {
+		return &ErrInvalidBucketRule{Trigger: r.Trigger, Action: r.Action}
+	}
+
+	switch r.Action {
+	case BucketRuleActionAssign, BucketRuleActionUnassign:
+		_, err = user.GetUserByID(s, r.Value)
+		return err
+	case BucketRuleActionAddLabel, BucketRuleActionRemoveLabel:
+		label, err := getLabelByIDSimple(s, r.Value)
+		if err != nil {
+			return err
+		}
+		can, _, err := label.hasAccessToLabel(s, a)
+		if err != nil {
+			return err
+		}
+		if !can {
+			return ErrGenericForbidden{}
+		}
+		return nil
+	case BucketRuleActionSetDueDate, BucketRuleActionNotifySubscribers:
+		return nil
+	case BucketRuleActionSetPercentDone:
+		if r.Value >= 0 && r.Value <= 100 {
+			return nil
+		}
+	}
+
+	return &ErrInvalidBucketRule{Trigger: r.Trigger, Action: r.Action}
+}
+
+// Create adds a new rule to a bucket
+// @Summary Create a new bucket rule
+// @Description Creates a new rule which is executed every time a task is moved into or out of a bucket.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Param bucketID path int true "Bucket Id"
+// @Param rule body models.BucketRule true "The rule object"
+// @Success 201 {object} models.BucketRule "The created rule."
+// @Failure 400 {object} web.HTTPError "Invalid rule object provided."
+// @Failure 404 {object} web.HTTPError "The bucket does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/buckets/{bucketID}/rules [put]
+func (r *BucketRule) Create(s *xorm.Session, a web.Auth) (err error) {
+	r.ID = 0
+	if err := r.validate(s, a); err != nil {
+		return err
+	}
+
+	r.CreatedBy, err = GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return
+	}
+	r.CreatedByID = r.CreatedBy.ID
+
+	_, err = s.Insert(r)
+	return
+}
+
+// ReadAll returns all rules of a bucket
+// @Summary Get all rules of a bucket
+// @Description Returns all rules of a bucket.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Param bucketID path int true "Bucket Id"
+// @Success 200 {array} models.BucketRule "The rules."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the bucket."
+// @Failure 404 {object} web.HTTPError "The bucket does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/buckets/{bucketID}/rules [get]
+func (r *BucketRule) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	bucket, err := getBucketByID(s, r.BucketID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	can, err := bucket.canReadBoard(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	rules := []*BucketRule{}
+	err = s.
+		Where("bucket_id = ?", r.BucketID).
+		OrderBy("id asc").
+		Find(&rules)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(rules))
+	for _, rule := range rules {
+		userIDs = append(userIDs, rule.CreatedByID)
+	}
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, rule := range rules {
+		rule.CreatedBy = users[rule.CreatedByID]
+	}
+
+	return rules, len(rules), int64(len(rules)), nil
+}
+
+// Update changes an existing bucket rule
+// @Summary Update a bucket rule
+// @Description Updates the trigger, action and value of a bucket rule.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Param bucketID path int true "Bucket Id"
+// @Param ruleID path int true "Rule Id"
+// @Param rule body models.BucketRule true "The rule object"
+// @Success 200 {object} models.BucketRule "The updated rule."
+// @Failure 400 {object} web.HTTPError "Invalid rule object provided."
+// @Failure 404 {object} web.HTTPError "The rule does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/buckets/{bucketID}/rules/{ruleID} [post]
+func (r *BucketRule) Update(s *xorm.Session, a web.Auth) (err error) {
+	if err := r.validate(s, a); err != nil {
+		return err
+	}
+
+	_, err = s.
+		Where("id = ?", r.ID).
+		Cols("trigger", "action", "value").
+		Update(r)
+	return
+}
+
+// Delete removes a bucket rule
+// @Summary Delete a bucket rule
+// @Description Deletes a bucket rule. Tasks which were already changed by it stay as they are.
+// @tags task
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Param bucketID path int true "Bucket Id"
+// @Param ruleID path int true "Rule Id"
+// @Success 200 {object} models.Message "Successfully deleted."
+// @Failure 404 {object} web.HTTPError "The rule does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/buckets/{bucketID}/rules/{ruleID} [delete]
+func (r *BucketRule) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ?", r.ID).Delete(&BucketRule{})
+	return
+}
+
+// applyBucketRules executes the leave rules of the bucket a task was moved out of and the enter rules of the bucket
+// it was moved into. Every executed rule is recorded in the history of the task.
+func applyBucketRules(s *xorm.Session, a web.Auth, task *Task, oldBucketID, newBucketID int64) (err error) {
+	if oldBucketID == newBucketID {
+		return nil
+	}
+
+	allRules := []*BucketRule{}
+	err = s.
+		In("bucket_id", oldBucketID, newBucketID).
+		OrderBy("id asc").
+		Find(&allRules)
+	if err != nil {
+		return err
+	}
+
+	// Leave rules run before enter rules
+	rules := make([]*BucketRule, 0, len(allRules))
+	for _, rule := range allRules {
+		if rule.BucketID == oldBucketID && rule.Trigger == BucketRuleTriggerLeave {
+			rules = append(rules, rule)
+		}
+	}
+	for _, rule := range allRules {
+		if rule.BucketID == newBucketID && rule.Trigger == BucketRuleTriggerEnter {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		applied, err := rule.apply(s, a, doer, task, newBucketID)
+		if err != nil {
+			return err
+		}
+		if !applied {
+			continue
+		}
+
+		_, err = s.Insert(&TaskHistory{
+			TaskID:       task.ID,
+			Kind:         TaskHistoryKindBucketRule,
+			BucketID:     rule.BucketID,
+			BucketRuleID: rule.ID,
+			Trigger:      rule.Trigger,
+			Action:       rule.Action,
+			Value:        rule.Value,
+			CreatedByID:  doer.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply executes a single rule. Returns false if the rule did not change anything, for example because the user
+// which should be assigned is already assigned.
+func (r *BucketRule) apply(s *xorm.Session, a web.Auth, doer *user.User, task *Task, newBucketID int64) (applied bool, err error) {
+	switch r.Action {
+	case BucketRuleActionAssign:
+		exists, err := s.Exist(&TaskAssginee{TaskID: task.ID, UserID: r.Value})
+		if err != nil || exists {
+			return false, err
+		}
+		list, err := GetListSimpleByID(s, task.ListID)
+		if err != nil {
+			return false, err
+		}
+		err = checkAssigneeBucketLimits(s, task.ID, r.Value)
+		if err == nil {
+			err = task.addNewAssigneeByID(s, r.Value, list, a)
+		}
+		if IsErrUserDoesNotHaveAccessToList(err) || user.IsErrUserDoesNotExist(err) || IsErrBucketAssigneeLimitExceeded(err) {
+			log.Debugf("Bucket rule %d could not assign user %d to task %d: %s", r.ID, r.Value, task.ID, err)
+			return false, nil
+		}
+		return err == nil, err
+	case BucketRuleActionUnassign:
+		deleted, err := s.Delete(&TaskAssginee{TaskID: task.ID, UserID: r.Value})
+		return deleted > 0, err
+	case BucketRuleActionAddLabel:
+		exists, err := s.Exist(&LabelTask{TaskID: task.ID, LabelID: r.Value})
+		if err != nil || exists {
+			return false, err
+		}
+		_, err = s.Insert(&LabelTask{TaskID: task.ID, LabelID: r.Value})
+		return err == nil, err
+	case BucketRuleActionRemoveLabel:
+		deleted, err := s.Delete(&LabelTask{TaskID: task.ID, LabelID: r.Value})
+		return deleted > 0, err
+	case BucketRuleActionSetDueDate:
+		task.DueDate = time.Now().Add(time.Duration(r.Value) * time.Second).Round(time.Second)
+		_, err = s.ID(task.ID).Cols("due_date").Update(task)
+		return err == nil, err
+	case BucketRuleActionSetPercentDone:
+		task.PercentDone = float64(r.Value) / 100
+		_, err = s.ID(task.ID).Cols("percent_done").Update(task)
+		return err == nil, err
+	case BucketRuleActionNotifySubscribers:
+		bucket, err := getBucketByID(s, newBucketID)
+		if err != nil {
+			return false, err
+		}
+		return true, notifyTaskMovedSubscribers(s, doer, task, bucket)
+	}
+
+	return false, nil
+}
+
+func notifyTaskMovedSubscribers(s *xorm.Session, doer *user.User, task *Task, bucket *Bucket) (err error) {
+	subscribers, err := getSubscribersForEntity(s, SubscriptionEntityTask, task.ID, SubscriptionEventTaskChanges)
+	if err != nil {
+		return err
+	}
+
+	for _, subscriber := range subscribers {
+		if subscriber.UserID == doer.ID {
+			continue
+		}
+
+		err = notifications.Notify(subscriber.User, &TaskMovedNotification{
+			Doer:   doer,
+			Task:   task,
+			Bucket: bucket,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if a user can add a rule to a bucket
+func (r *BucketRule) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	b := &Bucket{ID: r.BucketID}
+	return b.canDoBucket(s, a)
+}
+
+// CanUpdate checks if a user can change a bucket rule
+func (r *BucketRule) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return r.canDoBucketRule(s, a)
+}
+
+// CanDelete checks if a user can delete a bucket rule
+func (r *BucketRule) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return r.canDoBucketRule(s, a)
+}
+
+func (r *BucketRule) canDoBucketRule(s *xorm.Session, a web.Auth) (bool, error) {
+	rule, err := getBucketRuleByID(s, r.ID)
+	if err != nil {
+		return false, err
+	}
+	if rule.BucketID != r.BucketID {
+		return false, &ErrBucketRuleDoesNotExist{RuleID: r.ID}
+	}
+
+	b := &Bucket{ID: rule.BucketID}
+	return b.canDoBucket(s, a)
+}
@@ -0,0 +1,240 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketRule_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &BucketRule{BucketID: 2, Trigger: BucketRuleTriggerEnter, Action: BucketRuleActionSetDueDate, Value: 3 * 24 * 60 * 60}
+		can, err := r.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = r.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "bucket_rules", map[string]interface{}{
+			"id":            r.ID,
+			"bucket_id":     2,
+			"action":        BucketRuleActionSetDueDate,
+			"created_by_id": 1,
+		}, false)
+	})
+	t.Run("invalid trigger", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &BucketRule{BucketID: 2, Trigger: "stay", Action: BucketRuleActionNotifySubscribers}
+		err := r.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidBucketRule(err))
+	})
+	t.Run("invalid percent done", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &BucketRule{BucketID: 2, Trigger: BucketRuleTriggerEnter, Action: BucketRuleActionSetPercentDone, Value: 150}
+		err := r.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidBucketRule(err))
+	})
+	t.Run("nonexisting user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &BucketRule{BucketID: 2, Trigger: BucketRuleTriggerEnter, Action: BucketRuleActionAssign, Value: 9999}
+		err := r.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, user.IsErrUserDoesNotExist(err))
+	})
+	t.Run("no access to the list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &BucketRule{BucketID: 2}
+		can, err := r.CanCreate(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestBucketRule_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	r := &BucketRule{BucketID: 3}
+	result, _, _, err := r.ReadAll(s, &user.User{ID: 1}, "", 0, 0)
+	assert.NoError(t, err)
+	rules := result.([]*BucketRule)
+	assert.Len(t, rules, 3)
+	assert.Equal(t, int64(1), rules[0].ID)
+	assert.Equal(t, int64(1), rules[0].CreatedBy.ID)
+
+	_, _, _, err = r.ReadAll(s, &user.User{ID: 2}, "", 0, 0)
+	assert.Error(t, err)
+}
+
+func TestBucketRule_Update(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &BucketRule{ID: 1, BucketID: 3, Trigger: BucketRuleTriggerLeave, Action: BucketRuleActionSetPercentDone, Value: 0}
+		can, err := r.CanUpdate(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = r.Update(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "bucket_rules", map[string]interface{}{
+			"id":    1,
+			"value": 0,
+		}, false)
+	})
+	t.Run("rule of another bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &BucketRule{ID: 1, BucketID: 1}
+		_, err := r.CanUpdate(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketRuleDoesNotExist(err))
+	})
+}
+
+func TestBucketRule_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	r := &BucketRule{ID: 3, BucketID: 1}
+	can, err := r.CanDelete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, can)
+	err = r.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "bucket_rules", map[string]interface{}{"id": 3})
+}
+
+func TestApplyBucketRules(t *testing.T) {
+	t.Run("moving a task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1, Title: "test", ListID: 1, BucketID: 3}
+		err := task.Update(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":           1,
+			"bucket_id":    3,
+			"percent_done": 1,
+		}, false)
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{"task_id": 1, "label_id": 4})
+		db.AssertExists(t, "task_assignees", map[string]interface{}{"task_id": 1, "user_id": 1}, false)
+		for _, action := range []BucketRuleAction{
+			BucketRuleActionRemoveLabel,
+			BucketRuleActionSetPercentDone,
+			BucketRuleActionAssign,
+			BucketRuleActionNotifySubscribers,
+		} {
+			db.AssertExists(t, "task_history", map[string]interface{}{
+				"task_id":       1,
+				"kind":          TaskHistoryKindBucketRule,
+				"action":        action,
+				"created_by_id": 1,
+			}, false)
+		}
+	})
+	t.Run("staying in the same bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1, Title: "test", ListID: 1, BucketID: 1}
+		err := task.Update(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "label_tasks", map[string]interface{}{"task_id": 1, "label_id": 4}, false)
+		db.AssertMissing(t, "task_history", map[string]interface{}{"task_id": 1})
+	})
+	t.Run("kanban move", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 9, BucketID: 3}
+		err := m.Update(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_assignees", map[string]interface{}{"task_id": 9, "user_id": 1}, false)
+		db.AssertExists(t, "task_history", map[string]interface{}{
+			"task_id": 9,
+			"action":  BucketRuleActionAssign,
+		}, false)
+	})
+}
+
+func TestTaskHistory_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	th := &TaskHistory{TaskID: 6}
+	result, _, _, err := th.ReadAll(s, &user.User{ID: 1}, "", 0, 0)
+	assert.NoError(t, err)
+	entries := result.([]*TaskHistory)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, BucketRuleActionSetPercentDone, entries[0].Action)
+	assert.Equal(t, int64(1), entries[0].CreatedBy.ID)
+
+	_, _, _, err = th.ReadAll(s, &user.User{ID: 2}, "", 0, 0)
+	assert.Error(t, err)
+}
@@ -81,6 +81,12 @@ func (bt *BulkTask) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
 func (bt *BulkTask) Update(s *xorm.Session, a web.Auth) (err error) {
 	for _, oldtask := range bt.Tasks {
 
+		if bt.Task.Done && !oldtask.Done {
+			if err := checkTaskIsNotBlocked(s, oldtask.ID); err != nil {
+				return err
+			}
+		}
+
 		// When a repeating task is marked as done, we update all deadlines and reminders and set it as undone
 		updateDone(oldtask, &bt.Task)
 
@@ -0,0 +1,217 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CriticalPath holds the critical path of all undone tasks of a list for its gantt view. Tasks depend on each other
+// through `blocking` and `precedes` relations. The duration of a task is the time between its start and end date.
+type CriticalPath struct {
+	// The list the critical path was computed for.
+	ListID int64 `json:"list_id" param:"list"`
+	// The ids of all tasks on the critical path, in the order they have to be done.
+	TaskIDs []int64 `json:"task_ids"`
+	// The time in seconds it takes to do all tasks on the critical path.
+	Duration int64 `json:"duration"`
+	// The schedule of all undone tasks of the list.
+	Tasks []*CriticalPathTask `json:"tasks"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CriticalPathTask holds when a task can be done at the earliest and latest without delaying the whole list. All times
+// are in seconds from the start of the first task.
+type CriticalPathTask struct {
+	TaskID         int64 `json:"task_id"`
+	Duration       int64 `json:"duration"`
+	EarliestStart  int64 `json:"earliest_start"`
+	EarliestFinish int64 `json:"earliest_finish"`
+	LatestStart    int64 `json:"latest_start"`
+	LatestFinish   int64 `json:"latest_finish"`
+	// How much the task can be delayed without delaying the whole list.
+	Slack int64 `json:"slack"`
+	// Whether the task is on the critical path.
+	Critical bool `json:"critical"`
+
+	dependsOn  []int64
+	dependents []int64
+}
+
+// CanRead checks if a user can see the critical path of a list
+func (cp *CriticalPath) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	l := &List{ID: cp.ListID}
+	return l.CanRead(s, a)
+}
+
+// ReadOne computes the critical path of a list
+// @Summary Get the critical path of a list
+// @Description Returns the critical path of all undone tasks of a list for its gantt view. Tasks depend on each other through `blocking` and `precedes` relations, only relations between tasks of the same list are taken into account. The duration of a task is the time between its start and end date.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "List ID"
+// @Success 200 {object} models.CriticalPath "The critical path."
+// @Failure 400 {object} web.HTTPError "The tasks of the list depend on each other."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{id}/critical-path [get]
+func (cp *CriticalPath) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	cp.TaskIDs = []int64{}
+	cp.Tasks = []*CriticalPathTask{}
+
+	tasks := []*Task{}
+	err = s.
+		Where("list_id = ? AND done = ?", cp.ListID, false).
+		OrderBy("id asc").
+		Find(&tasks)
+	if err != nil || len(tasks) == 0 {
+		return err
+	}
+
+	taskMap := make(map[int64]*CriticalPathTask, len(tasks))
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		cpt := &CriticalPathTask{TaskID: t.ID}
+		if !t.StartDate.IsZero() && t.EndDate.After(t.StartDate) {
+			cpt.Duration = int64(t.EndDate.Sub(t.StartDate).Seconds())
+		}
+		taskMap[t.ID] = cpt
+		taskIDs = append(taskIDs, t.ID)
+		cp.Tasks = append(cp.Tasks, cpt)
+	}
+
+	dependents, err := getDependentTaskIDs(s, taskIDs)
+	if err != nil {
+		return err
+	}
+	for before, ids := range dependents {
+		for _, after := range ids {
+			if _, has := taskMap[after]; !has {
+				continue
+			}
+			taskMap[before].dependents = append(taskMap[before].dependents, after)
+			taskMap[after].dependsOn = append(taskMap[after].dependsOn, before)
+		}
+	}
+
+	ordered, err := sortTasksByDependencies(cp.Tasks)
+	if err != nil {
+		return err
+	}
+
+	// Forward pass: every task starts as soon as all tasks it depends on are done
+	for _, t := range ordered {
+		for _, id := range t.dependsOn {
+			if taskMap[id].EarliestFinish > t.EarliestStart {
+				t.EarliestStart = taskMap[id].EarliestFinish
+			}
+		}
+		t.EarliestFinish = t.EarliestStart + t.Duration
+		if t.EarliestFinish > cp.Duration {
+			cp.Duration = t.EarliestFinish
+		}
+	}
+
+	// Backward pass: every task finishes at the latest when the first task depending on it has to start
+	for i := len(ordered) - 1; i >= 0; i-- {
+		t := ordered[i]
+		t.LatestFinish = cp.Duration
+		for _, id := range t.dependents {
+			if taskMap[id].LatestStart < t.LatestFinish {
+				t.LatestFinish = taskMap[id].LatestStart
+			}
+		}
+		t.LatestStart = t.LatestFinish - t.Duration
+		t.Slack = t.LatestStart - t.EarliestStart
+		t.Critical = t.Slack == 0
+	}
+
+	cp.TaskIDs = getCriticalPathTaskIDs(cp.Tasks, taskMap, cp.Duration)
+	return nil
+}
+
+// sortTasksByDependencies sorts tasks so that every task comes after all tasks it depends on.
+func sortTasksByDependencies(tasks []*CriticalPathTask) (ordered []*CriticalPathTask, err error) {
+	taskMap := make(map[int64]*CriticalPathTask, len(tasks))
+	remaining := make(map[int64]int, len(tasks))
+	ready := []int64{}
+	for _, t := range tasks {
+		taskMap[t.TaskID] = t
+		remaining[t.TaskID] = len(t.dependsOn)
+		if len(t.dependsOn) == 0 {
+			ready = append(ready, t.TaskID)
+		}
+	}
+
+	ordered = make([]*CriticalPathTask, 0, len(tasks))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return ready[i] < ready[j] })
+		t := taskMap[ready[0]]
+		ready = ready[1:]
+		ordered = append(ordered, t)
+
+		for _, id := range t.dependents {
+			remaining[id]--
+			if remaining[id] == 0 {
+				ready = append(ready, id)
+			}
+		}
+	}
+
+	if len(ordered) != len(tasks) {
+		for _, t := range tasks {
+			if remaining[t.TaskID] > 0 {
+				return nil, &ErrRelationCreatesCycle{TaskID: t.TaskID, OtherTaskID: t.dependsOn[0], Kind: RelationKindFollows}
+			}
+		}
+	}
+
+	return ordered, nil
+}
+
+// getCriticalPathTaskIDs follows the critical tasks back from the one which finishes last.
+func getCriticalPathTaskIDs(tasks []*CriticalPathTask, taskMap map[int64]*CriticalPathTask, duration int64) []int64 {
+	var current *CriticalPathTask
+	for _, t := range tasks {
+		if t.Critical && t.EarliestFinish == duration && (current == nil || t.TaskID < current.TaskID) {
+			current = t
+		}
+	}
+
+	path := []int64{}
+	for current != nil {
+		path = append([]int64{current.TaskID}, path...)
+
+		var previous *CriticalPathTask
+		for _, id := range current.dependsOn {
+			t := taskMap[id]
+			if t.Critical && t.EarliestFinish == current.EarliestStart && (previous == nil || t.TaskID < previous.TaskID) {
+				previous = t
+			}
+		}
+		current = previous
+	}
+
+	return path
+}
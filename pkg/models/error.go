@@ -875,6 +875,90 @@ func (err ErrUserAlreadyAssigned) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrRelationCreatesCycle represents an error where a dependency between two tasks would create a cycle.
+type ErrRelationCreatesCycle struct {
+	TaskID      int64
+	OtherTaskID int64
+	Kind        RelationKind
+}
+
+// IsErrRelationCreatesCycle checks if an error is ErrRelationCreatesCycle.
+func IsErrRelationCreatesCycle(err error) bool {
+	_, ok := err.(*ErrRelationCreatesCycle)
+	return ok
+}
+
+func (err *ErrRelationCreatesCycle) Error() string {
+	return fmt.Sprintf("Task relation creates a dependency cycle [TaskID: %d, OtherTaskID: %d, Kind: %s]", err.TaskID, err.OtherTaskID, err.Kind)
+}
+
+// ErrCodeRelationCreatesCycle holds the unique world-error code of this error
+const ErrCodeRelationCreatesCycle = 4022
+
+// HTTPError holds the http error description
+func (err *ErrRelationCreatesCycle) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeRelationCreatesCycle,
+		Message:  "This relation would make the tasks depend on each other.",
+	}
+}
+
+// ErrTaskIsBlocked represents an error where a task is marked as done while other tasks are still blocking it.
+type ErrTaskIsBlocked struct {
+	TaskID          int64
+	BlockingTaskIDs []int64
+}
+
+// IsErrTaskIsBlocked checks if an error is ErrTaskIsBlocked.
+func IsErrTaskIsBlocked(err error) bool {
+	_, ok := err.(*ErrTaskIsBlocked)
+	return ok
+}
+
+func (err *ErrTaskIsBlocked) Error() string {
+	return fmt.Sprintf("Task is blocked by other tasks [TaskID: %d, BlockingTaskIDs: %v]", err.TaskID, err.BlockingTaskIDs)
+}
+
+// ErrCodeTaskIsBlocked holds the unique world-error code of this error
+const ErrCodeTaskIsBlocked = 4023
+
+// HTTPError holds the http error description
+func (err *ErrTaskIsBlocked) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTaskIsBlocked,
+		Message:  "This task cannot be marked as done as long as the tasks blocking it are not done.",
+	}
+}
+
+// ErrTaskIdentifierDoesNotExist represents an error where no task exists for an identifier.
+type ErrTaskIdentifierDoesNotExist struct {
+	Identifier string
+}
+
+// IsErrTaskIdentifierDoesNotExist checks if an error is ErrTaskIdentifierDoesNotExist.
+func IsErrTaskIdentifierDoesNotExist(err error) bool {
+	_, ok := err.(*ErrTaskIdentifierDoesNotExist)
+	return ok
+}
+
+func (err *ErrTaskIdentifierDoesNotExist) Error() string {
+	return fmt.Sprintf("Task identifier does not exist [Identifier: %s]", err.Identifier)
+}
+
+// ErrCodeTaskIdentifierDoesNotExist holds the unique world-error code of this error
+const ErrCodeTaskIdentifierDoesNotExist = 4024
+
+// HTTPError holds the http error description
+func (err *ErrTaskIdentifierDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTaskIdentifierDoesNotExist,
+		Message:  "There is no task with this identifier.",
+	}
+}
+
 // =================
 // Namespace errors
 // =================
@@ -1045,6 +1129,62 @@ func (err ErrNamespaceIsArchived) HTTPError() web.HTTPError {
 	return web.HTTPError{HTTPCode: http.StatusPreconditionFailed, Code: ErrCodeNamespaceIsArchived, Message: "This namespaces is archived. Editing or creating new lists is not possible."}
 }
 
+// ErrNamespaceParentCreatesCycle represents an error where a namespace would be moved below itself or one of its children.
+type ErrNamespaceParentCreatesCycle struct {
+	NamespaceID int64
+	ParentID    int64
+}
+
+// IsErrNamespaceParentCreatesCycle checks if an error is ErrNamespaceParentCreatesCycle.
+func IsErrNamespaceParentCreatesCycle(err error) bool {
+	_, ok := err.(*ErrNamespaceParentCreatesCycle)
+	return ok
+}
+
+func (err *ErrNamespaceParentCreatesCycle) Error() string {
+	return fmt.Sprintf("Namespace parent creates a cycle [NamespaceID: %d, ParentID: %d]", err.NamespaceID, err.ParentID)
+}
+
+// ErrCodeNamespaceParentCreatesCycle holds the unique world-error code of this error
+const ErrCodeNamespaceParentCreatesCycle = 5013
+
+// HTTPError holds the http error description
+func (err *ErrNamespaceParentCreatesCycle) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeNamespaceParentCreatesCycle,
+		Message:  "A namespace cannot be moved into itself or one of its sub namespaces.",
+	}
+}
+
+// ErrNamespaceCannotHavePseudoParent represents an error where a namespace would be moved into a pseudo namespace.
+type ErrNamespaceCannotHavePseudoParent struct {
+	NamespaceID int64
+	ParentID    int64
+}
+
+// IsErrNamespaceCannotHavePseudoParent checks if an error is ErrNamespaceCannotHavePseudoParent.
+func IsErrNamespaceCannotHavePseudoParent(err error) bool {
+	_, ok := err.(*ErrNamespaceCannotHavePseudoParent)
+	return ok
+}
+
+func (err *ErrNamespaceCannotHavePseudoParent) Error() string {
+	return fmt.Sprintf("Namespace cannot have a pseudo namespace as parent [NamespaceID: %d, ParentID: %d]", err.NamespaceID, err.ParentID)
+}
+
+// ErrCodeNamespaceCannotHavePseudoParent holds the unique world-error code of this error
+const ErrCodeNamespaceCannotHavePseudoParent = 5014
+
+// HTTPError holds the http error description
+func (err *ErrNamespaceCannotHavePseudoParent) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeNamespaceCannotHavePseudoParent,
+		Message:  "A namespace cannot be a sub namespace of a pseudo namespace.",
+	}
+}
+
 // ============
 // Team errors
 // ============
@@ -1191,6 +1331,30 @@ func (err ErrTeamDoesNotHaveAccessToList) HTTPError() web.HTTPError {
 	return web.HTTPError{HTTPCode: http.StatusForbidden, Code: ErrCodeTeamDoesNotHaveAccessToList, Message: "This team does not have access to the list."}
 }
 
+// ErrTeamIsExternallyManaged represents an error where a team managed by an external identity provider should be
+// changed locally.
+type ErrTeamIsExternallyManaged struct {
+	TeamID int64
+}
+
+// IsErrTeamIsExternallyManaged checks if an error is ErrTeamIsExternallyManaged.
+func IsErrTeamIsExternallyManaged(err error) bool {
+	_, ok := err.(ErrTeamIsExternallyManaged)
+	return ok
+}
+
+func (err ErrTeamIsExternallyManaged) Error() string {
+	return fmt.Sprintf("Team is managed by an external identity provider [TeamID: %d]", err.TeamID)
+}
+
+// ErrCodeTeamIsExternallyManaged holds the unique world-error code of this error
+const ErrCodeTeamIsExternallyManaged = 6008
+
+// HTTPError holds the http error description
+func (err ErrTeamIsExternallyManaged) HTTPError() web.HTTPError {
+	return web.HTTPError{HTTPCode: http.StatusPreconditionFailed, Code: ErrCodeTeamIsExternallyManaged, Message: "This team is managed by an external identity provider and cannot be changed in Vikunja."}
+}
+
 // ====================
 // User <-> List errors
 // ====================
@@ -1506,6 +1670,204 @@ func (err *ErrOnlyOneDoneBucketPerList) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrInvalidSwimlaneGroup represents an error where kanban swimlanes are grouped by an unknown property.
+type ErrInvalidSwimlaneGroup struct {
+	GroupBy string
+}
+
+// IsErrInvalidSwimlaneGroup checks if an error is ErrInvalidSwimlaneGroup.
+func IsErrInvalidSwimlaneGroup(err error) bool {
+	_, ok := err.(*ErrInvalidSwimlaneGroup)
+	return ok
+}
+
+func (err *ErrInvalidSwimlaneGroup) Error() string {
+	return fmt.Sprintf("Invalid swimlane group [GroupBy: %s]", err.GroupBy)
+}
+
+// ErrCodeInvalidSwimlaneGroup holds the unique world-error code of this error
+const ErrCodeInvalidSwimlaneGroup = 10006
+
+// HTTPError holds the http error description
+func (err *ErrInvalidSwimlaneGroup) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidSwimlaneGroup,
+		Message:  "Swimlanes can only be grouped by assignee, label, priority or parent.",
+	}
+}
+
+// ErrTaskNotInSwimlane represents an error where a task is moved out of a swimlane it is not part of.
+type ErrTaskNotInSwimlane struct {
+	TaskID  int64
+	GroupBy string
+	LaneID  int64
+}
+
+// IsErrTaskNotInSwimlane checks if an error is ErrTaskNotInSwimlane.
+func IsErrTaskNotInSwimlane(err error) bool {
+	_, ok := err.(*ErrTaskNotInSwimlane)
+	return ok
+}
+
+func (err *ErrTaskNotInSwimlane) Error() string {
+	return fmt.Sprintf("Task is not in this swimlane [TaskID: %d, GroupBy: %s, LaneID: %d]", err.TaskID, err.GroupBy, err.LaneID)
+}
+
+// ErrCodeTaskNotInSwimlane holds the unique world-error code of this error
+const ErrCodeTaskNotInSwimlane = 10007
+
+// HTTPError holds the http error description
+func (err *ErrTaskNotInSwimlane) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTaskNotInSwimlane,
+		Message:  "The task is not part of the swimlane it should be moved from.",
+	}
+}
+
+// ErrInvalidBucketRule represents an error where a bucket rule has an invalid trigger, action or value.
+type ErrInvalidBucketRule struct {
+	Trigger BucketRuleTrigger
+	Action  BucketRuleAction
+}
+
+// IsErrInvalidBucketRule checks if an error is ErrInvalidBucketRule.
+func IsErrInvalidBucketRule(err error) bool {
+	_, ok := err.(*ErrInvalidBucketRule)
+	return ok
+}
+
+func (err *ErrInvalidBucketRule) Error() string {
+	return fmt.Sprintf("Invalid bucket rule [Trigger: %s, Action: %s]", err.Trigger, err.Action)
+}
+
+// ErrCodeInvalidBucketRule holds the unique world-error code of this error
+const ErrCodeInvalidBucketRule = 10008
+
+// HTTPError holds the http error description
+func (err *ErrInvalidBucketRule) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidBucketRule,
+		Message:  "The bucket rule has an invalid trigger, action or value.",
+	}
+}
+
+// ErrBucketRuleDoesNotExist represents an error where a bucket rule does not exist.
+type ErrBucketRuleDoesNotExist struct {
+	RuleID int64
+}
+
+// IsErrBucketRuleDoesNotExist checks if an error is ErrBucketRuleDoesNotExist.
+func IsErrBucketRuleDoesNotExist(err error) bool {
+	_, ok := err.(*ErrBucketRuleDoesNotExist)
+	return ok
+}
+
+func (err *ErrBucketRuleDoesNotExist) Error() string {
+	return fmt.Sprintf("Bucket rule does not exist [RuleID: %d]", err.RuleID)
+}
+
+// ErrCodeBucketRuleDoesNotExist holds the unique world-error code of this error
+const ErrCodeBucketRuleDoesNotExist = 10009
+
+// HTTPError holds the http error description
+func (err *ErrBucketRuleDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeBucketRuleDoesNotExist,
+		Message:  "This bucket rule does not exist.",
+	}
+}
+
+// ErrInvalidBucketLimitMode represents an error where a bucket has an invalid limit mode.
+type ErrInvalidBucketLimitMode struct {
+	BucketID  int64
+	LimitMode BucketLimitMode
+}
+
+// IsErrInvalidBucketLimitMode checks if an error is ErrInvalidBucketLimitMode.
+func IsErrInvalidBucketLimitMode(err error) bool {
+	_, ok := err.(*ErrInvalidBucketLimitMode)
+	return ok
+}
+
+func (err *ErrInvalidBucketLimitMode) Error() string {
+	return fmt.Sprintf("Invalid bucket limit mode [BucketID: %d, LimitMode: %s]", err.BucketID, err.LimitMode)
+}
+
+// ErrCodeInvalidBucketLimitMode holds the unique world-error code of this error
+const ErrCodeInvalidBucketLimitMode = 10010
+
+// HTTPError holds the http error description
+func (err *ErrInvalidBucketLimitMode) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidBucketLimitMode,
+		Message:  "The bucket limit mode must be either hard or soft.",
+	}
+}
+
+// ErrBucketAssigneeLimitExceeded represents an error where a task is moved to a bucket which already holds the maximum
+// number of tasks for one of the task's assignees.
+type ErrBucketAssigneeLimitExceeded struct {
+	BucketID int64
+	Limit    int64
+	TaskID   int64
+	UserID   int64
+}
+
+// IsErrBucketAssigneeLimitExceeded checks if an error is ErrBucketAssigneeLimitExceeded.
+func IsErrBucketAssigneeLimitExceeded(err error) bool {
+	_, ok := err.(*ErrBucketAssigneeLimitExceeded)
+	return ok
+}
+
+func (err *ErrBucketAssigneeLimitExceeded) Error() string {
+	return fmt.Sprintf("Cannot add a task to this bucket because it would exceed the limit of an assignee [BucketID: %d, Limit: %d, TaskID: %d, UserID: %d]", err.BucketID, err.Limit, err.TaskID, err.UserID)
+}
+
+// ErrCodeBucketAssigneeLimitExceeded holds the unique world-error code of this error
+const ErrCodeBucketAssigneeLimitExceeded = 10011
+
+// HTTPError holds the http error description
+func (err *ErrBucketAssigneeLimitExceeded) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeBucketAssigneeLimitExceeded,
+		Message:  "You cannot add the task to this bucket as one of its assignees already has the maximum number of tasks in it.",
+	}
+}
+
+// ErrInvalidKanbanReportRange represents an error where the date range of a kanban report is invalid.
+type ErrInvalidKanbanReportRange struct {
+	From string
+	To   string
+}
+
+// IsErrInvalidKanbanReportRange checks if an error is ErrInvalidKanbanReportRange.
+func IsErrInvalidKanbanReportRange(err error) bool {
+	_, ok := err.(*ErrInvalidKanbanReportRange)
+	return ok
+}
+
+func (err *ErrInvalidKanbanReportRange) Error() string {
+	return fmt.Sprintf("Invalid kanban report range [From: %s, To: %s]", err.From, err.To)
+}
+
+// ErrCodeInvalidKanbanReportRange holds the unique world-error code of this error
+const ErrCodeInvalidKanbanReportRange = 10012
+
+// HTTPError holds the http error description
+func (err *ErrInvalidKanbanReportRange) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidKanbanReportRange,
+		Message:  "The report range is invalid. Both dates must be valid, from must be before to and the range cannot be longer than a year.",
+	}
+}
+
 // =============
 // Saved Filters
 // =============
@@ -1625,6 +1987,33 @@ func (err ErrSubscriptionAlreadyExists) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrUnknownSubscriptionEvent represents an error where a subscription event is unknown
+type ErrUnknownSubscriptionEvent struct {
+	Event string
+}
+
+// IsErrUnknownSubscriptionEvent checks if an error is ErrUnknownSubscriptionEvent.
+func IsErrUnknownSubscriptionEvent(err error) bool {
+	_, ok := err.(*ErrUnknownSubscriptionEvent)
+	return ok
+}
+
+func (err *ErrUnknownSubscriptionEvent) Error() string {
+	return fmt.Sprintf("Subscription event is unknown [Event: %s]", err.Event)
+}
+
+// ErrCodeUnknownSubscriptionEvent holds the unique world-error code of this error
+const ErrCodeUnknownSubscriptionEvent = 12003
+
+// HTTPError holds the http error description
+func (err ErrUnknownSubscriptionEvent) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeUnknownSubscriptionEvent,
+		Message:  "The subscription event '" + err.Event + "' is invalid.",
+	}
+}
+
 // =================
 // Link Share errors
 // =================
@@ -1682,3 +2071,603 @@ func (err ErrLinkSharePasswordInvalid) HTTPError() web.HTTPError {
 		Message:  "The provided link share password is invalid.",
 	}
 }
+
+// ErrLinkShareDisabled represents an error where a link share was disabled.
+type ErrLinkShareDisabled struct {
+	ShareID int64
+}
+
+// IsErrLinkShareDisabled checks if an error is ErrLinkShareDisabled.
+func IsErrLinkShareDisabled(err error) bool {
+	_, ok := err.(*ErrLinkShareDisabled)
+	return ok
+}
+
+func (err *ErrLinkShareDisabled) Error() string {
+	return fmt.Sprintf("Link share is disabled [ShareID: %d]", err.ShareID)
+}
+
+// ErrCodeLinkShareDisabled holds the unique world-error code of this error
+const ErrCodeLinkShareDisabled = 13003
+
+// HTTPError holds the http error description
+func (err *ErrLinkShareDisabled) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareDisabled,
+		Message:  "This link share is disabled.",
+	}
+}
+
+// ErrLinkShareExpired represents an error where a link share is expired.
+type ErrLinkShareExpired struct {
+	ShareID int64
+}
+
+// IsErrLinkShareExpired checks if an error is ErrLinkShareExpired.
+func IsErrLinkShareExpired(err error) bool {
+	_, ok := err.(*ErrLinkShareExpired)
+	return ok
+}
+
+func (err *ErrLinkShareExpired) Error() string {
+	return fmt.Sprintf("Link share is expired [ShareID: %d]", err.ShareID)
+}
+
+// ErrCodeLinkShareExpired holds the unique world-error code of this error
+const ErrCodeLinkShareExpired = 13004
+
+// HTTPError holds the http error description
+func (err *ErrLinkShareExpired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareExpired,
+		Message:  "This link share is expired.",
+	}
+}
+
+// ErrLinkShareUsageLimitReached represents an error where a link share was used more often than allowed.
+type ErrLinkShareUsageLimitReached struct {
+	ShareID int64
+}
+
+// IsErrLinkShareUsageLimitReached checks if an error is ErrLinkShareUsageLimitReached.
+func IsErrLinkShareUsageLimitReached(err error) bool {
+	_, ok := err.(*ErrLinkShareUsageLimitReached)
+	return ok
+}
+
+func (err *ErrLinkShareUsageLimitReached) Error() string {
+	return fmt.Sprintf("Link share usage limit reached [ShareID: %d]", err.ShareID)
+}
+
+// ErrCodeLinkShareUsageLimitReached holds the unique world-error code of this error
+const ErrCodeLinkShareUsageLimitReached = 13005
+
+// HTTPError holds the http error description
+func (err *ErrLinkShareUsageLimitReached) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareUsageLimitReached,
+		Message:  "This link share was already used as often as allowed.",
+	}
+}
+
+// ErrLinkShareIPNotAllowed represents an error where someone tries to use a link share from an ip address which is not allowed.
+type ErrLinkShareIPNotAllowed struct {
+	ShareID int64
+}
+
+// IsErrLinkShareIPNotAllowed checks if an error is ErrLinkShareIPNotAllowed.
+func IsErrLinkShareIPNotAllowed(err error) bool {
+	_, ok := err.(*ErrLinkShareIPNotAllowed)
+	return ok
+}
+
+func (err *ErrLinkShareIPNotAllowed) Error() string {
+	return fmt.Sprintf("Link share cannot be used from this ip address [ShareID: %d]", err.ShareID)
+}
+
+// ErrCodeLinkShareIPNotAllowed holds the unique world-error code of this error
+const ErrCodeLinkShareIPNotAllowed = 13006
+
+// HTTPError holds the http error description
+func (err *ErrLinkShareIPNotAllowed) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareIPNotAllowed,
+		Message:  "This link share cannot be used from your ip address.",
+	}
+}
+
+// ErrInvalidIPAllowlistEntry represents an error where an entry of a link share ip allowlist is neither an ip address
+// nor a cidr range.
+type ErrInvalidIPAllowlistEntry struct {
+	Entry string
+}
+
+// IsErrInvalidIPAllowlistEntry checks if an error is ErrInvalidIPAllowlistEntry.
+func IsErrInvalidIPAllowlistEntry(err error) bool {
+	_, ok := err.(*ErrInvalidIPAllowlistEntry)
+	return ok
+}
+
+func (err *ErrInvalidIPAllowlistEntry) Error() string {
+	return fmt.Sprintf("Invalid ip allowlist entry [Entry: %s]", err.Entry)
+}
+
+// ErrCodeInvalidIPAllowlistEntry holds the unique world-error code of this error
+const ErrCodeInvalidIPAllowlistEntry = 13007
+
+// HTTPError holds the http error description
+func (err *ErrInvalidIPAllowlistEntry) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidIPAllowlistEntry,
+		Message:  "The ip allowlist entry '" + err.Entry + "' is neither an ip address nor a cidr range.",
+	}
+}
+
+// ===================
+// Notification errors
+// ===================
+
+// ErrInvalidNotificationSnooze represents an error where a notification snooze option is unknown
+type ErrInvalidNotificationSnooze struct {
+	Snooze string
+}
+
+// IsErrInvalidNotificationSnooze checks if an error is ErrInvalidNotificationSnooze.
+func IsErrInvalidNotificationSnooze(err error) bool {
+	_, ok := err.(*ErrInvalidNotificationSnooze)
+	return ok
+}
+
+func (err *ErrInvalidNotificationSnooze) Error() string {
+	return fmt.Sprintf("Notification snooze option is invalid [Snooze: %s]", err.Snooze)
+}
+
+// ErrCodeInvalidNotificationSnooze holds the unique world-error code of this error
+const ErrCodeInvalidNotificationSnooze = 14001
+
+// HTTPError holds the http error description
+func (err ErrInvalidNotificationSnooze) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidNotificationSnooze,
+		Message:  "The snooze option '" + err.Snooze + "' is invalid.",
+	}
+}
+
+// ErrNotificationSnoozeTimeInPast represents an error where a notification should be snoozed until a time in the past
+type ErrNotificationSnoozeTimeInPast struct {
+	NotificationID int64
+}
+
+// IsErrNotificationSnoozeTimeInPast checks if an error is ErrNotificationSnoozeTimeInPast.
+func IsErrNotificationSnoozeTimeInPast(err error) bool {
+	_, ok := err.(*ErrNotificationSnoozeTimeInPast)
+	return ok
+}
+
+func (err *ErrNotificationSnoozeTimeInPast) Error() string {
+	return fmt.Sprintf("Notification snooze time is in the past [NotificationID: %d]", err.NotificationID)
+}
+
+// ErrCodeNotificationSnoozeTimeInPast holds the unique world-error code of this error
+const ErrCodeNotificationSnoozeTimeInPast = 14002
+
+// HTTPError holds the http error description
+func (err ErrNotificationSnoozeTimeInPast) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeNotificationSnoozeTimeInPast,
+		Message:  "A notification can only be snoozed until a time in the future.",
+	}
+}
+
+// =========================
+// Share invitation errors
+// =========================
+
+// ErrShareInvitationUserExists represents an error where someone tries to invite an email address which already
+// belongs to a user.
+type ErrShareInvitationUserExists struct {
+	Email string
+}
+
+// IsErrShareInvitationUserExists checks if an error is ErrShareInvitationUserExists.
+func IsErrShareInvitationUserExists(err error) bool {
+	_, ok := err.(*ErrShareInvitationUserExists)
+	return ok
+}
+
+func (err *ErrShareInvitationUserExists) Error() string {
+	return fmt.Sprintf("A user with this email address already exists [Email: %s]", err.Email)
+}
+
+// ErrCodeShareInvitationUserExists holds the unique world-error code of this error
+const ErrCodeShareInvitationUserExists = 15001
+
+// HTTPError holds the http error description
+func (err *ErrShareInvitationUserExists) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeShareInvitationUserExists,
+		Message:  "A user with this email address already exists, please share with them directly.",
+	}
+}
+
+// ErrShareInvitationAlreadyExists represents an error where an email address was already invited to a list or team.
+type ErrShareInvitationAlreadyExists struct {
+	Email string
+}
+
+// IsErrShareInvitationAlreadyExists checks if an error is ErrShareInvitationAlreadyExists.
+func IsErrShareInvitationAlreadyExists(err error) bool {
+	_, ok := err.(*ErrShareInvitationAlreadyExists)
+	return ok
+}
+
+func (err *ErrShareInvitationAlreadyExists) Error() string {
+	return fmt.Sprintf("This email address was already invited [Email: %s]", err.Email)
+}
+
+// ErrCodeShareInvitationAlreadyExists holds the unique world-error code of this error
+const ErrCodeShareInvitationAlreadyExists = 15002
+
+// HTTPError holds the http error description
+func (err *ErrShareInvitationAlreadyExists) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusConflict,
+		Code:     ErrCodeShareInvitationAlreadyExists,
+		Message:  "This email address was already invited.",
+	}
+}
+
+// ErrShareInvitationDoesNotExist represents an error where a share invitation does not exist.
+type ErrShareInvitationDoesNotExist struct {
+	ID int64
+}
+
+// IsErrShareInvitationDoesNotExist checks if an error is ErrShareInvitationDoesNotExist.
+func IsErrShareInvitationDoesNotExist(err error) bool {
+	_, ok := err.(*ErrShareInvitationDoesNotExist)
+	return ok
+}
+
+func (err *ErrShareInvitationDoesNotExist) Error() string {
+	return fmt.Sprintf("Share invitation does not exist [ID: %d]", err.ID)
+}
+
+// ErrCodeShareInvitationDoesNotExist holds the unique world-error code of this error
+const ErrCodeShareInvitationDoesNotExist = 15003
+
+// HTTPError holds the http error description
+func (err *ErrShareInvitationDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeShareInvitationDoesNotExist,
+		Message:  "The invitation does not exist.",
+	}
+}
+
+// =========================
+// Role errors
+// =========================
+
+// ErrRoleDoesNotExist represents an error where a role does not exist.
+type ErrRoleDoesNotExist struct {
+	RoleID int64
+}
+
+// IsErrRoleDoesNotExist checks if an error is ErrRoleDoesNotExist.
+func IsErrRoleDoesNotExist(err error) bool {
+	_, ok := err.(*ErrRoleDoesNotExist)
+	return ok
+}
+
+func (err *ErrRoleDoesNotExist) Error() string {
+	return fmt.Sprintf("Role does not exist [RoleID: %d]", err.RoleID)
+}
+
+// ErrCodeRoleDoesNotExist holds the unique world-error code of this error
+const ErrCodeRoleDoesNotExist = 16001
+
+// HTTPError holds the http error description
+func (err *ErrRoleDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeRoleDoesNotExist,
+		Message:  "The role does not exist.",
+	}
+}
+
+// ErrRoleInUse represents an error where a role which is already assigned to a share would grant more permissions.
+type ErrRoleInUse struct {
+	RoleID int64
+}
+
+// IsErrRoleInUse checks if an error is ErrRoleInUse.
+func IsErrRoleInUse(err error) bool {
+	_, ok := err.(*ErrRoleInUse)
+	return ok
+}
+
+func (err *ErrRoleInUse) Error() string {
+	return fmt.Sprintf("Role is in use and cannot grant more permissions [RoleID: %d]", err.RoleID)
+}
+
+// ErrCodeRoleInUse holds the unique world-error code of this error
+const ErrCodeRoleInUse = 16002
+
+// HTTPError holds the http error description
+func (err *ErrRoleInUse) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeRoleInUse,
+		Message:  "The role is already assigned to a share and cannot grant more permissions. Create a new role instead.",
+	}
+}
+
+// =========================
+// Task status errors
+// =========================
+
+// ErrTaskStatusDoesNotExist represents an error where a task status does not exist.
+type ErrTaskStatusDoesNotExist struct {
+	StatusID int64
+}
+
+// IsErrTaskStatusDoesNotExist checks if an error is ErrTaskStatusDoesNotExist.
+func IsErrTaskStatusDoesNotExist(err error) bool {
+	_, ok := err.(*ErrTaskStatusDoesNotExist)
+	return ok
+}
+
+func (err *ErrTaskStatusDoesNotExist) Error() string {
+	return fmt.Sprintf("Task status does not exist [StatusID: %d]", err.StatusID)
+}
+
+// ErrCodeTaskStatusDoesNotExist holds the unique world-error code of this error
+const ErrCodeTaskStatusDoesNotExist = 17001
+
+// HTTPError holds the http error description
+func (err *ErrTaskStatusDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTaskStatusDoesNotExist,
+		Message:  "The task status does not exist.",
+	}
+}
+
+// ErrInvalidTaskStatus represents an error where a task status has an invalid caldav status, bucket or transition.
+type ErrInvalidTaskStatus struct {
+	StatusID     int64
+	CaldavStatus TaskCaldavStatus
+}
+
+// IsErrInvalidTaskStatus checks if an error is ErrInvalidTaskStatus.
+func IsErrInvalidTaskStatus(err error) bool {
+	_, ok := err.(*ErrInvalidTaskStatus)
+	return ok
+}
+
+func (err *ErrInvalidTaskStatus) Error() string {
+	return fmt.Sprintf("Invalid task status [StatusID: %d, CaldavStatus: %s]", err.StatusID, err.CaldavStatus)
+}
+
+// ErrCodeInvalidTaskStatus holds the unique world-error code of this error
+const ErrCodeInvalidTaskStatus = 17002
+
+// HTTPError holds the http error description
+func (err *ErrInvalidTaskStatus) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTaskStatus,
+		Message:  "The task status is invalid. The caldav status must be one of NEEDS-ACTION, IN-PROCESS, COMPLETED or CANCELLED and its bucket and transitions must belong to the same list.",
+	}
+}
+
+// ErrTaskStatusDoesNotBelongToList represents an error where a task is given a status of another list.
+type ErrTaskStatusDoesNotBelongToList struct {
+	StatusID int64
+	ListID   int64
+}
+
+// IsErrTaskStatusDoesNotBelongToList checks if an error is ErrTaskStatusDoesNotBelongToList.
+func IsErrTaskStatusDoesNotBelongToList(err error) bool {
+	_, ok := err.(*ErrTaskStatusDoesNotBelongToList)
+	return ok
+}
+
+func (err *ErrTaskStatusDoesNotBelongToList) Error() string {
+	return fmt.Sprintf("Task status does not belong to list [StatusID: %d, ListID: %d]", err.StatusID, err.ListID)
+}
+
+// ErrCodeTaskStatusDoesNotBelongToList holds the unique world-error code of this error
+const ErrCodeTaskStatusDoesNotBelongToList = 17003
+
+// HTTPError holds the http error description
+func (err *ErrTaskStatusDoesNotBelongToList) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeTaskStatusDoesNotBelongToList,
+		Message:  "The task status does not belong to the list of the task.",
+	}
+}
+
+// ErrTaskStatusTransitionNotAllowed represents an error where a task is moved to a status which cannot follow its current one.
+type ErrTaskStatusTransitionNotAllowed struct {
+	TaskID       int64
+	FromStatusID int64
+	ToStatusID   int64
+}
+
+// IsErrTaskStatusTransitionNotAllowed checks if an error is ErrTaskStatusTransitionNotAllowed.
+func IsErrTaskStatusTransitionNotAllowed(err error) bool {
+	_, ok := err.(*ErrTaskStatusTransitionNotAllowed)
+	return ok
+}
+
+func (err *ErrTaskStatusTransitionNotAllowed) Error() string {
+	return fmt.Sprintf("Task status transition is not allowed [TaskID: %d, FromStatusID: %d, ToStatusID: %d]", err.TaskID, err.FromStatusID, err.ToStatusID)
+}
+
+// ErrCodeTaskStatusTransitionNotAllowed holds the unique world-error code of this error
+const ErrCodeTaskStatusTransitionNotAllowed = 17004
+
+// HTTPError holds the http error description
+func (err *ErrTaskStatusTransitionNotAllowed) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeTaskStatusTransitionNotAllowed,
+		Message:  "The task cannot be moved from its current status to this one.",
+	}
+}
+
+// =========================
+// List template errors
+// =========================
+
+// ErrListTemplateDoesNotExist represents an error where a list template does not exist.
+type ErrListTemplateDoesNotExist struct {
+	TemplateID int64
+}
+
+// IsErrListTemplateDoesNotExist checks if an error is ErrListTemplateDoesNotExist.
+func IsErrListTemplateDoesNotExist(err error) bool {
+	_, ok := err.(*ErrListTemplateDoesNotExist)
+	return ok
+}
+
+func (err *ErrListTemplateDoesNotExist) Error() string {
+	return fmt.Sprintf("List template does not exist [TemplateID: %d]", err.TemplateID)
+}
+
+// ErrCodeListTemplateDoesNotExist holds the unique world-error code of this error
+const ErrCodeListTemplateDoesNotExist = 18001
+
+// HTTPError holds the http error description
+func (err *ErrListTemplateDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeListTemplateDoesNotExist,
+		Message:  "This list template does not exist.",
+	}
+}
+
+// ErrInvalidListTemplateVisibility represents an error where a list template has an invalid visibility.
+type ErrInvalidListTemplateVisibility struct {
+	Visibility ListTemplateVisibility
+	TeamID     int64
+}
+
+// IsErrInvalidListTemplateVisibility checks if an error is ErrInvalidListTemplateVisibility.
+func IsErrInvalidListTemplateVisibility(err error) bool {
+	_, ok := err.(*ErrInvalidListTemplateVisibility)
+	return ok
+}
+
+func (err *ErrInvalidListTemplateVisibility) Error() string {
+	return fmt.Sprintf("List template visibility is invalid [Visibility: %s, TeamID: %d]", err.Visibility, err.TeamID)
+}
+
+// ErrCodeInvalidListTemplateVisibility holds the unique world-error code of this error
+const ErrCodeInvalidListTemplateVisibility = 18002
+
+// HTTPError holds the http error description
+func (err *ErrInvalidListTemplateVisibility) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidListTemplateVisibility,
+		Message:  "The visibility of a list template must be one of private, team or instance. A team template needs a team.",
+	}
+}
+
+// ErrListTemplateVariableMissing represents an error where a list template is used without a value for one of its variables.
+type ErrListTemplateVariableMissing struct {
+	TemplateID int64
+	Variables  []string
+}
+
+// IsErrListTemplateVariableMissing checks if an error is ErrListTemplateVariableMissing.
+func IsErrListTemplateVariableMissing(err error) bool {
+	_, ok := err.(*ErrListTemplateVariableMissing)
+	return ok
+}
+
+func (err *ErrListTemplateVariableMissing) Error() string {
+	return fmt.Sprintf("List template variable is missing [TemplateID: %d, Variables: %v]", err.TemplateID, err.Variables)
+}
+
+// ErrCodeListTemplateVariableMissing holds the unique world-error code of this error
+const ErrCodeListTemplateVariableMissing = 18003
+
+// HTTPError holds the http error description
+func (err *ErrListTemplateVariableMissing) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeListTemplateVariableMissing,
+		Message:  "All variables of the list template need a value.",
+	}
+}
+
+// =============
+// Trash errors
+// =============
+
+// ErrTrashItemDoesNotExist represents an error where an item is not in the trash.
+type ErrTrashItemDoesNotExist struct {
+	Kind TrashItemKind
+	ID   int64
+}
+
+// IsErrTrashItemDoesNotExist checks if an error is ErrTrashItemDoesNotExist.
+func IsErrTrashItemDoesNotExist(err error) bool {
+	_, ok := err.(*ErrTrashItemDoesNotExist)
+	return ok
+}
+
+func (err *ErrTrashItemDoesNotExist) Error() string {
+	return fmt.Sprintf("Trash item does not exist [Kind: %s, ID: %d]", err.Kind, err.ID)
+}
+
+// ErrCodeTrashItemDoesNotExist holds the unique world-error code of this error
+const ErrCodeTrashItemDoesNotExist = 19001
+
+// HTTPError holds the http error description
+func (err *ErrTrashItemDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTrashItemDoesNotExist,
+		Message:  "This item is not in the trash.",
+	}
+}
+
+// ErrInvalidTrashItemKind represents an error where a trash item kind is invalid.
+type ErrInvalidTrashItemKind struct {
+	Kind TrashItemKind
+}
+
+// IsErrInvalidTrashItemKind checks if an error is ErrInvalidTrashItemKind.
+func IsErrInvalidTrashItemKind(err error) bool {
+	_, ok := err.(*ErrInvalidTrashItemKind)
+	return ok
+}
+
+func (err *ErrInvalidTrashItemKind) Error() string {
+	return fmt.Sprintf("Trash item kind is invalid [Kind: %s]", err.Kind)
+}
+
+// ErrCodeInvalidTrashItemKind holds the unique world-error code of this error
+const ErrCodeInvalidTrashItemKind = 19002
+
+// HTTPError holds the http error description
+func (err *ErrInvalidTrashItemKind) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidTrashItemKind,
+		Message:  "The kind of a trash item must be one of task, list or namespace.",
+	}
+}
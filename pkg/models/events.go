@@ -48,8 +48,9 @@ func (t *TaskCreatedEvent) Name() string {
 
 // TaskUpdatedEvent represents an event where a task has been updated
 type TaskUpdatedEvent struct {
-	Task *Task
-	Doer *user.User
+	Task    *Task
+	OldTask *Task
+	Doer    *user.User
 }
 
 // Name defines the name for TaskUpdatedEvent
@@ -68,6 +69,41 @@ func (t *TaskDeletedEvent) Name() string {
 	return "task.deleted"
 }
 
+// TaskRestoredEvent represents an event where a task has been restored from the trash
+type TaskRestoredEvent struct {
+	Task *Task
+	Doer *user.User
+}
+
+// Name defines the name for TaskRestoredEvent
+func (t *TaskRestoredEvent) Name() string {
+	return "task.restored"
+}
+
+// TaskAttachmentCreatedEvent represents an event where an attachment has been added to a task
+type TaskAttachmentCreatedEvent struct {
+	Task       *Task
+	Attachment *TaskAttachment
+	Doer       *user.User
+}
+
+// Name defines the name for TaskAttachmentCreatedEvent
+func (t *TaskAttachmentCreatedEvent) Name() string {
+	return "task.attachment.created"
+}
+
+// TaskAttachmentDeletedEvent represents an event where an attachment has been removed from a task
+type TaskAttachmentDeletedEvent struct {
+	Task       *Task
+	Attachment *TaskAttachment
+	Doer       *user.User
+}
+
+// Name defines the name for TaskAttachmentDeletedEvent
+func (t *TaskAttachmentDeletedEvent) Name() string {
+	return "task.attachment.deleted"
+}
+
 // TaskAssigneeCreatedEvent represents an event where a task has been assigned to a user
 type TaskAssigneeCreatedEvent struct {
 	Task     *Task
@@ -141,6 +177,17 @@ func (t *NamespaceDeletedEvent) Name() string {
 	return "namespace.deleted"
 }
 
+// NamespaceRestoredEvent represents an event where a namespace has been restored from the trash
+type NamespaceRestoredEvent struct {
+	Namespace *Namespace
+	Doer      web.Auth
+}
+
+// Name defines the name for NamespaceRestoredEvent
+func (t *NamespaceRestoredEvent) Name() string {
+	return "namespace.restored"
+}
+
 /////////////////
 // List Events //
 /////////////////
@@ -178,6 +225,17 @@ func (t *ListDeletedEvent) Name() string {
 	return "list.deleted"
 }
 
+// ListRestoredEvent represents an event where a list has been restored from the trash
+type ListRestoredEvent struct {
+	List *List
+	Doer web.Auth
+}
+
+// Name defines the name for ListRestoredEvent
+func (t *ListRestoredEvent) Name() string {
+	return "list.restored"
+}
+
 ////////////////////
 // Sharing Events //
 ////////////////////
@@ -22,6 +22,7 @@ import (
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -33,11 +34,21 @@ type Bucket struct {
 	Title string `xorm:"text not null" valid:"required" minLength:"1" json:"title"`
 	// The list this bucket belongs to.
 	ListID int64 `xorm:"bigint not null" json:"list_id" param:"list"`
+	// The namespace this bucket belongs to if it is part of a board across all lists of a namespace.
+	NamespaceID int64 `xorm:"bigint null INDEX" json:"namespace_id" param:"namespace"`
+	// The saved filter this bucket belongs to if it is part of the board of a saved filter.
+	SavedFilterID int64 `xorm:"bigint null INDEX" json:"saved_filter_id"`
 	// All tasks which belong to this bucket.
 	Tasks []*Task `xorm:"-" json:"tasks"`
 
 	// How many tasks can be at the same time on this board max
 	Limit int64 `xorm:"default 0" json:"limit" minimum:"0" valid:"range(0|9223372036854775807)"`
+	// How many tasks assigned to the same user can be in this bucket at the same time max.
+	AssigneeLimit int64 `xorm:"bigint default 0" json:"assignee_limit" minimum:"0" valid:"range(0|9223372036854775807)"`
+	// How the limits of this bucket are enforced. Can be `hard` to reject tasks once a limit is reached or `soft` to allow them and only mark the bucket as over its limit. Defaults to `hard`.
+	LimitMode BucketLimitMode `xorm:"varchar(10) not null default 'hard'" json:"limit_mode"`
+	// Whether this bucket currently holds more tasks than its limit or assignee limit allows. You cannot change this value.
+	LimitExceeded bool `xorm:"-" json:"limit_exceeded"`
 	// If this bucket is the "done bucket". All tasks moved into this bucket will automatically marked as done. All tasks marked as done from elsewhere will be moved into this bucket.
 	IsDoneBucket bool `xorm:"BOOL" json:"is_done_bucket"`
 
@@ -55,6 +66,8 @@ type Bucket struct {
 
 	// Including the task collection type so we can use task filters on kanban
 	TaskCollection `xorm:"-" json:"-"`
+	// If set, the buckets are split into swimlanes grouped by this property. Can be one of assignee, label, priority or parent.
+	GroupBy string `xorm:"-" json:"-" query:"group_by"`
 
 	web.Rights   `xorm:"-" json:"-"`
 	web.CRUDable `xorm:"-" json:"-"`
@@ -77,19 +90,53 @@ func getBucketByID(s *xorm.Session, id int64) (b *Bucket, err error) {
 	return
 }
 
+// setBoardFromListID moves the pseudo list id of a saved filter to the saved filter id of the bucket.
+func (b *Bucket) setBoardFromListID() {
+	if filterID := getSavedFilterIDFromListID(b.ListID); filterID > 0 {
+		b.SavedFilterID = filterID
+		b.ListID = 0
+	}
+}
+
+// boardCond returns the condition to find all buckets of the same board.
+func (b *Bucket) boardCond() builder.Cond {
+	if b.NamespaceID != 0 {
+		return builder.Eq{"namespace_id": b.NamespaceID}
+	}
+	if b.SavedFilterID != 0 {
+		return builder.Eq{"saved_filter_id": b.SavedFilterID}
+	}
+	return builder.Eq{"list_id": b.ListID}
+}
+
+// isBoardBucket returns true if the bucket is part of the board of a namespace or saved filter. The tasks in these
+// buckets are stored as TaskBucket instead of the task's bucket id.
+func (b *Bucket) isBoardBucket() bool {
+	return b.NamespaceID != 0 || b.SavedFilterID != 0
+}
+
 func getDefaultBucket(s *xorm.Session, listID int64) (bucket *Bucket, err error) {
+	return getDefaultBucketForBoard(s, &Bucket{ListID: listID})
+}
+
+func getDefaultBucketForBoard(s *xorm.Session, board *Bucket) (bucket *Bucket, err error) {
 	bucket = &Bucket{}
 	_, err = s.
-		Where("list_id = ?", listID).
+		Where(board.boardCond()).
 		OrderBy("id asc").
 		Get(bucket)
 	return
 }
 
 func getDoneBucketForList(s *xorm.Session, listID int64) (bucket *Bucket, err error) {
+	return getDoneBucketForBoard(s, &Bucket{ListID: listID})
+}
+
+func getDoneBucketForBoard(s *xorm.Session, board *Bucket) (bucket *Bucket, err error) {
 	bucket = &Bucket{}
 	exists, err := s.
-		Where("list_id = ? and is_done_bucket = ?", listID, true).
+		Where(board.boardCond()).
+		And("is_done_bucket = ?", true).
 		Get(bucket)
 	if err != nil {
 		return nil, err
@@ -103,7 +150,7 @@ func getDoneBucketForList(s *xorm.Session, listID int64) (bucket *Bucket, err er
 
 // ReadAll returns all buckets with their tasks for a certain list
 // @Summary Get all kanban buckets of a list
-// @Description Returns all kanban buckets with belong to a list including their tasks.
+// @Description Returns all kanban buckets with belong to a list including their tasks. Use the pseudo list id of a saved filter to get the board of that saved filter. The boards of saved filters and namespaces are not paginated.
 // @tags task
 // @Accept json
 // @Produce json
@@ -117,17 +164,21 @@ func getDoneBucketForList(s *xorm.Session, listID int64) (bucket *Bucket, err er
 // @Param filter_comparator query string false "The comparator to use for a filter. Available values are `equals`, `greater`, `greater_equals`, `less`, `less_equals`, `like` and `in`. `in` expects comma-separated values in `filter_value`. Defaults to `equals`"
 // @Param filter_concat query string false "The concatinator to use for filters. Available values are `and` or `or`. Defaults to `or`."
 // @Param filter_include_nulls query string false "If set to true the result will include filtered fields whose value is set to `null`. Available values are `true` or `false`. Defaults to `false`."
+// @Param group_by query string false "If set, the buckets are split into swimlanes grouped by this property. Available values are `assignee`, `label`, `priority` and `parent`. A list of swimlanes is returned instead of a list of buckets in that case."
 // @Success 200 {array} models.Bucket "The buckets with their tasks"
 // @Failure 500 {object} models.Message "Internal server error"
 // @Router /lists/{id}/buckets [get]
+// @Router /namespaces/{id}/buckets [get]
 func (b *Bucket) ReadAll(s *xorm.Session, auth web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
 
-	list, err := GetListSimpleByID(s, b.ListID)
-	if err != nil {
-		return nil, 0, 0, err
+	if b.GroupBy != "" {
+		if err := validateSwimlaneGroup(b.GroupBy); err != nil {
+			return nil, 0, 0, err
+		}
 	}
 
-	can, _, err := list.CanRead(s, auth)
+	b.setBoardFromListID()
+	can, err := b.canReadBoard(s, auth)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -135,10 +186,10 @@ func (b *Bucket) ReadAll(s *xorm.Session, auth web.Auth, search string, page int
 		return nil, 0, 0, ErrGenericForbidden{}
 	}
 
-	// Get all buckets for this list
+	// Get all buckets for this board
 	buckets := []*Bucket{}
 	err = s.
-		Where("list_id = ?", b.ListID).
+		Where(b.boardCond()).
 		OrderBy("position").
 		Find(&buckets)
 	if err != nil {
@@ -161,6 +212,18 @@ func (b *Bucket) ReadAll(s *xorm.Session, auth web.Auth, search string, page int
 
 	for _, bb := range buckets {
 		bb.CreatedBy = users[bb.CreatedByID]
+		bb.LimitExceeded, err = bb.isOverLimit(s)
+		if err != nil {
+			return
+		}
+	}
+
+	if b.isBoardBucket() {
+		err = b.addTasksToBoardBuckets(s, auth, search, buckets)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return b.bucketsOrSwimlanes(buckets)
 	}
 
 	tasks := []*Task{}
@@ -232,12 +295,21 @@ func (b *Bucket) ReadAll(s *xorm.Session, auth web.Auth, search string, page int
 		bucketMap[task.BucketID].Tasks = append(bucketMap[task.BucketID].Tasks, task)
 	}
 
+	return b.bucketsOrSwimlanes(buckets)
+}
+
+func (b *Bucket) bucketsOrSwimlanes(buckets []*Bucket) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if b.GroupBy != "" {
+		lanes := groupBucketsIntoSwimlanes(buckets, b.GroupBy)
+		return lanes, len(lanes), int64(len(lanes)), nil
+	}
+
 	return buckets, len(buckets), int64(len(buckets)), nil
 }
 
 // Create creates a new bucket
 // @Summary Create a new bucket
-// @Description Creates a new kanban bucket on a list.
+// @Description Creates a new kanban bucket on a list, the board of a saved filter (using its pseudo list id) or a namespace.
 // @tags task
 // @Accept json
 // @Produce json
@@ -249,7 +321,21 @@ func (b *Bucket) ReadAll(s *xorm.Session, auth web.Auth, search string, page int
 // @Failure 404 {object} web.HTTPError "The list does not exist."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /lists/{id}/buckets [put]
+// @Router /namespaces/{id}/buckets [put]
 func (b *Bucket) Create(s *xorm.Session, a web.Auth) (err error) {
+	b.setBoardFromListID()
+	if b.NamespaceID != 0 {
+		b.SavedFilterID = 0
+	}
+	if b.isBoardBucket() {
+		b.ListID = 0
+	}
+
+	err = b.validateLimitMode()
+	if err != nil {
+		return
+	}
+
 	b.CreatedBy, err = GetUserOrLinkShareUser(s, a)
 	if err != nil {
 		return
@@ -281,8 +367,14 @@ func (b *Bucket) Create(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 404 {object} web.HTTPError "The bucket does not exist."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /lists/{listID}/buckets/{bucketID} [post]
+// @Router /namespaces/{namespaceID}/buckets/{bucketID} [post]
 func (b *Bucket) Update(s *xorm.Session, a web.Auth) (err error) {
-	doneBucket, err := getDoneBucketForList(s, b.ListID)
+	err = b.validateLimitMode()
+	if err != nil {
+		return
+	}
+
+	doneBucket, err := getDoneBucketForBoard(s, b)
 	if err != nil {
 		return err
 	}
@@ -300,6 +392,8 @@ func (b *Bucket) Update(s *xorm.Session, a web.Auth) (err error) {
 		Cols(
 			"title",
 			"limit",
+			"assignee_limit",
+			"limit_mode",
 			"is_done_bucket",
 			"position",
 		).
@@ -320,10 +414,11 @@ func (b *Bucket) Update(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 404 {object} web.HTTPError "The bucket does not exist."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /lists/{listID}/buckets/{bucketID} [delete]
+// @Router /namespaces/{namespaceID}/buckets/{bucketID} [delete]
 func (b *Bucket) Delete(s *xorm.Session, a web.Auth) (err error) {
 
 	// Prevent removing the last bucket
-	total, err := s.Where("list_id = ?", b.ListID).Count(&Bucket{})
+	total, err := s.Where(b.boardCond()).Count(&Bucket{})
 	if err != nil {
 		return
 	}
@@ -340,12 +435,42 @@ func (b *Bucket) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	_, err = s.Where("bucket_id = ?", b.ID).Delete(&BucketRule{})
+	if err != nil {
+		return
+	}
+
+	// Statuses mapped to the bucket stay, but without a bucket
+	_, err = s.Where("bucket_id = ?", b.ID).Cols("bucket_id").Update(&TaskStatus{})
+	if err != nil {
+		return
+	}
+
+	// Tasks on the board of a namespace or saved filter without a bucket are shown in the default bucket
+	if b.isBoardBucket() {
+		_, err = s.Where("bucket_id = ?", b.ID).Delete(&TaskBucket{})
+		return
+	}
+
 	// Get the default bucket
 	defaultBucket, err := getDefaultBucket(s, b.ListID)
 	if err != nil {
 		return
 	}
 
+	taskIDs := []int64{}
+	// Tasks in the trash are moved to the default bucket when they are restored
+	err = s.Table("tasks").Where("bucket_id = ? AND deleted IS NULL", b.ID).Cols("id").Find(&taskIDs)
+	if err != nil {
+		return
+	}
+	for _, taskID := range taskIDs {
+		err = recordBucketTransition(s, taskID, b.ID, defaultBucket.ID)
+		if err != nil {
+			return
+		}
+	}
+
 	// Remove all associations of tasks to that bucket
 	_, err = s.
 		Where("bucket_id = ?", b.ID).
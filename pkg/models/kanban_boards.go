@@ -0,0 +1,223 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskBucket holds the bucket a task is in on the kanban board of a namespace or saved filter. A task can only be in
+// one bucket per board. Tasks without a bucket on a board are shown in its default bucket.
+// The buckets of list boards are still stored as the bucket id of the task.
+type TaskBucket struct {
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"-"`
+	// The task which is in the bucket.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id"`
+	// The bucket the task is in.
+	BucketID int64 `xorm:"bigint not null INDEX" json:"bucket_id"`
+	// The position of the task in the bucket.
+	KanbanPosition float64 `xorm:"double null" json:"kanban_position"`
+}
+
+// TableName returns the table name for task buckets
+func (*TaskBucket) TableName() string {
+	return "task_buckets"
+}
+
+// boardBucketIDs returns a subquery for the ids of all buckets of the board.
+func (b *Bucket) boardBucketIDs() *builder.Builder {
+	return builder.Select("id").From("buckets").Where(b.boardCond())
+}
+
+// getBoardListsAndOpts returns all lists and the filter options to get the tasks shown on the board of a namespace or
+// saved filter.
+func (b *Bucket) getBoardListsAndOpts(s *xorm.Session, a web.Auth) (lists []*List, opts *taskOptions, err error) {
+	tc := &b.TaskCollection
+	if b.SavedFilterID != 0 {
+		sf, err := getSavedFilterSimpleByID(s, b.SavedFilterID)
+		if err != nil {
+			return nil, nil, err
+		}
+		tc = sf.getTaskCollection()
+		lists, _, _, err = getRawListsForUser(s, &listOptions{
+			user: &user.User{ID: a.GetID()},
+			page: -1,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		err = s.
+			Where("namespace_id = ? AND is_archived = ?", b.NamespaceID, false).
+			Find(&lists)
+		if err != nil {
+			return
+		}
+	}
+
+	opts, err = getTaskFilterOptsFromCollection(tc)
+	return
+}
+
+// addTasksToBoardBuckets puts all tasks of the board of a namespace or saved filter in their buckets.
+func (b *Bucket) addTasksToBoardBuckets(s *xorm.Session, a web.Auth, search string, buckets []*Bucket) (err error) {
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	lists, opts, err := b.getBoardListsAndOpts(s, a)
+	if err != nil {
+		return err
+	}
+	opts.search = search
+
+	tasks, _, _, err := getRawTasksForLists(s, lists, a, opts)
+	if err != nil || len(tasks) == 0 {
+		return err
+	}
+
+	bucketMap := make(map[int64]*Bucket, len(buckets))
+	defaultBucket := buckets[0]
+	bucketIDs := make([]int64, 0, len(buckets))
+	for _, bb := range buckets {
+		bucketMap[bb.ID] = bb
+		bucketIDs = append(bucketIDs, bb.ID)
+		if bb.ID < defaultBucket.ID {
+			defaultBucket = bb
+		}
+	}
+
+	taskMap := make(map[int64]*Task, len(tasks))
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		taskMap[t.ID] = t
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	taskBuckets := []*TaskBucket{}
+	err = s.
+		In("bucket_id", bucketIDs).
+		In("task_id", taskIDs).
+		Find(&taskBuckets)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		t.BucketID = defaultBucket.ID
+	}
+	for _, tb := range taskBuckets {
+		taskMap[tb.TaskID].BucketID = tb.BucketID
+		taskMap[tb.TaskID].KanbanPosition = tb.KanbanPosition
+	}
+
+	err = addMoreInfoToTasks(s, taskMap, a)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].KanbanPosition != tasks[j].KanbanPosition {
+			return tasks[i].KanbanPosition < tasks[j].KanbanPosition
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	for _, t := range tasks {
+		bucketMap[t.BucketID].Tasks = append(bucketMap[t.BucketID].Tasks, t)
+	}
+
+	return nil
+}
+
+// moveTaskOnBoard puts a task into a bucket on the board of a namespace or saved filter. Returns the bucket the task
+// was in before.
+func moveTaskOnBoard(s *xorm.Session, a web.Auth, task *Task, bucket *Bucket, position float64) (oldBucketID int64, err error) {
+	if bucket.NamespaceID != 0 {
+		list, err := GetListSimpleByID(s, task.ListID)
+		if err != nil {
+			return 0, err
+		}
+		if list.NamespaceID != bucket.NamespaceID {
+			return 0, ErrBucketDoesNotBelongToList{ListID: task.ListID, BucketID: bucket.ID}
+		}
+	}
+
+	can, err := bucket.canReadBoard(s, a)
+	if err != nil {
+		return 0, err
+	}
+	if !can {
+		return 0, ErrGenericForbidden{}
+	}
+
+	current := &TaskBucket{}
+	exists, err := s.
+		Where("task_id = ?", task.ID).
+		And(builder.In("bucket_id", bucket.boardBucketIDs())).
+		Get(current)
+	if err != nil {
+		return 0, err
+	}
+
+	oldBucketID = current.BucketID
+	if !exists {
+		defaultBucket, err := getDefaultBucketForBoard(s, bucket)
+		if err != nil {
+			return 0, err
+		}
+		oldBucketID = defaultBucket.ID
+	}
+
+	if oldBucketID != bucket.ID {
+		err = checkBucketLimit(s, task, bucket)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if !exists {
+		_, err = s.Insert(&TaskBucket{TaskID: task.ID, BucketID: bucket.ID, KanbanPosition: position})
+		return oldBucketID, err
+	}
+
+	current.BucketID = bucket.ID
+	current.KanbanPosition = position
+	_, err = s.ID(current.ID).Cols("bucket_id", "kanban_position").Update(current)
+	return oldBucketID, err
+}
+
+// deleteBoardBuckets removes all buckets of the board of a namespace or saved filter.
+func deleteBoardBuckets(s *xorm.Session, board *Bucket) (err error) {
+	_, err = s.In("bucket_id", board.boardBucketIDs()).Delete(&TaskBucket{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.In("bucket_id", board.boardBucketIDs()).Delete(&BucketRule{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where(board.boardCond()).Delete(&Bucket{})
+	return err
+}
@@ -0,0 +1,224 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucket_ReadAll_Boards(t *testing.T) {
+	t.Run("namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{NamespaceID: 1}
+		result, _, _, err := b.ReadAll(s, &user.User{ID: 1}, "", 0, 0)
+		assert.NoError(t, err)
+		buckets := result.([]*Bucket)
+		assert.Len(t, buckets, 2)
+		assert.Equal(t, int64(36), buckets[0].ID)
+		assert.Equal(t, int64(37), buckets[1].ID)
+		// All tasks of the non-archived lists 1 and 2, the ones without a bucket are in the default bucket
+		assert.Len(t, buckets[0].Tasks, 19)
+		assert.Len(t, buckets[1].Tasks, 1)
+		assert.Equal(t, int64(1), buckets[1].Tasks[0].ID)
+		assert.Equal(t, int64(37), buckets[1].Tasks[0].BucketID)
+		assert.Equal(t, float64(5), buckets[1].Tasks[0].KanbanPosition)
+	})
+	t.Run("saved filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{ListID: -2}
+		result, _, _, err := b.ReadAll(s, &user.User{ID: 1}, "", 0, 0)
+		assert.NoError(t, err)
+		buckets := result.([]*Bucket)
+		assert.Len(t, buckets, 1)
+		assert.Equal(t, int64(38), buckets[0].ID)
+		ids := []int64{}
+		for _, task := range buckets[0].Tasks {
+			ids = append(ids, task.ID)
+		}
+		assert.Equal(t, []int64{5, 6, 7, 8, 9}, ids)
+	})
+	t.Run("saved filter of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{ListID: -2}
+		_, _, _, err := b.ReadAll(s, &user.User{ID: 2}, "", 0, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestBucket_Create_Boards(t *testing.T) {
+	t.Run("saved filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{Title: "Doing", ListID: -2}
+		can, err := b.CanCreate(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = b.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "buckets", map[string]interface{}{"id": b.ID, "saved_filter_id": 1, "list_id": 0}, false)
+	})
+	t.Run("saved filter of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{Title: "Doing", ListID: -2}
+		can, err := b.CanCreate(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{Title: "Doing", NamespaceID: 1}
+		can, err := b.CanCreate(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = b.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "buckets", map[string]interface{}{"id": b.ID, "namespace_id": 1, "list_id": 0}, false)
+	})
+}
+
+func TestKanbanTaskMove_Update_Boards(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("namespace board", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: 36, KanbanPosition: 4}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+		m = &KanbanTaskMove{TaskID: 2, BucketID: 37, KanbanPosition: 3}
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_buckets", map[string]interface{}{"task_id": 1, "bucket_id": 36, "kanban_position": 4}, false)
+		db.AssertMissing(t, "task_buckets", map[string]interface{}{"task_id": 1, "bucket_id": 37})
+		db.AssertExists(t, "task_buckets", map[string]interface{}{"task_id": 2, "bucket_id": 37, "kanban_position": 3}, false)
+		// The bucket on the board of the list stays the same
+		db.AssertExists(t, "tasks", map[string]interface{}{"id": 1, "bucket_id": 1}, false)
+	})
+	t.Run("task of another namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bucket := &Bucket{Title: "Other", NamespaceID: 2, CreatedByID: 2}
+		_, err := s.Insert(bucket)
+		assert.NoError(t, err)
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: bucket.ID}
+		err = m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketDoesNotBelongToList(err))
+	})
+	t.Run("done bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{ID: 38, Title: "Done", SavedFilterID: 1, IsDoneBucket: true}
+		err := b.Update(s, u)
+		assert.NoError(t, err)
+
+		m := &KanbanTaskMove{TaskID: 5, BucketID: 38}
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		assert.True(t, m.Task.Done)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_buckets", map[string]interface{}{"task_id": 5, "bucket_id": 38}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{"id": 5, "done": true}, false)
+	})
+}
+
+func TestBucket_Delete_Boards(t *testing.T) {
+	t.Run("bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{ID: 37, NamespaceID: 1}
+		err := b.Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		b = &Bucket{ID: 36, NamespaceID: 1}
+		err = b.Delete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrCannotRemoveLastBucket(err))
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "task_buckets", map[string]interface{}{"bucket_id": 37})
+		db.AssertExists(t, "buckets", map[string]interface{}{"id": 36}, false)
+	})
+	t.Run("with saved filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sf := &SavedFilter{ID: 1}
+		err := sf.Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "buckets", map[string]interface{}{"id": 38})
+	})
+	t.Run("with namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		n := &Namespace{ID: 1}
+		err := purgeNamespace(s, n, true)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "buckets", map[string]interface{}{"namespace_id": 1})
+		db.AssertMissing(t, "task_buckets", map[string]interface{}{"id": 1})
+	})
+}
@@ -0,0 +1,197 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// BucketLimitMode defines how the limits of a bucket are enforced.
+type BucketLimitMode string
+
+const (
+	// BucketLimitModeHard rejects tasks which would exceed one of the limits of the bucket.
+	BucketLimitModeHard BucketLimitMode = "hard"
+	// BucketLimitModeSoft allows exceeding the limits of the bucket and only marks it as over its limit.
+	BucketLimitModeSoft BucketLimitMode = "soft"
+)
+
+func (b *Bucket) validateLimitMode() error {
+	switch b.LimitMode {
+	case "":
+		b.LimitMode = BucketLimitModeHard
+	case BucketLimitModeHard, BucketLimitModeSoft:
+	default:
+		return &ErrInvalidBucketLimitMode{BucketID: b.ID, LimitMode: b.LimitMode}
+	}
+	return nil
+}
+
+// bucketTasks returns a query for the tasks of a bucket. Tasks in the trash don't count.
+func (b *Bucket) bucketTasks(s *xorm.Session) *xorm.Session {
+	if b.isBoardBucket() {
+		return s.
+			Table("task_buckets").
+			Join("INNER", "tasks", "tasks.id = task_buckets.task_id").
+			Where("task_buckets.bucket_id = ? AND tasks.deleted IS NULL", b.ID)
+	}
+	return s.
+		Table("tasks").
+		Where("tasks.bucket_id = ? AND tasks.deleted IS NULL", b.ID)
+}
+
+// countTasks returns how many tasks are in this bucket.
+func (b *Bucket) countTasks(s *xorm.Session) (int64, error) {
+	return b.bucketTasks(s).Count()
+}
+
+type assigneeTaskCount struct {
+	UserID    int64
+	TaskCount int64
+}
+
+// countTasksPerAssignee returns how many tasks assigned to each user are in this bucket.
+func (b *Bucket) countTasksPerAssignee(s *xorm.Session) (counts map[int64]int64, err error) {
+	rows := []*assigneeTaskCount{}
+	err = b.bucketTasks(s).
+		Select("task_assignees.user_id AS user_id, COUNT(*) AS task_count").
+		Join("INNER", "task_assignees", "task_assignees.task_id = tasks.id").
+		GroupBy("task_assignees.user_id").
+		Find(&rows)
+	if err != nil {
+		return
+	}
+
+	counts = make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.TaskCount
+	}
+	return
+}
+
+// isOverLimit returns true if the bucket holds more tasks than its limit or more tasks of a single assignee than its
+// assignee limit allow. This can happen with soft limits or when a limit was lowered after tasks were added.
+func (b *Bucket) isOverLimit(s *xorm.Session) (bool, error) {
+	if b.Limit > 0 {
+		count, err := b.countTasks(s)
+		if err != nil {
+			return false, err
+		}
+		if count > b.Limit {
+			return true, nil
+		}
+	}
+
+	if b.AssigneeLimit > 0 {
+		counts, err := b.countTasksPerAssignee(s)
+		if err != nil {
+			return false, err
+		}
+		for _, count := range counts {
+			if count > b.AssigneeLimit {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// Checks if adding a new task would exceed the bucket limit or the limit of one of the task's assignees.
+// Soft limits are never enforced.
+func checkBucketLimit(s *xorm.Session, t *Task, bucket *Bucket) (err error) {
+	if bucket.LimitMode == BucketLimitModeSoft {
+		return nil
+	}
+
+	if bucket.Limit > 0 {
+		taskCount, err := bucket.countTasks(s)
+		if err != nil {
+			return err
+		}
+		if taskCount >= bucket.Limit {
+			return ErrBucketLimitExceeded{TaskID: t.ID, BucketID: bucket.ID, Limit: bucket.Limit}
+		}
+	}
+
+	if bucket.AssigneeLimit == 0 || t.ID == 0 {
+		return nil
+	}
+
+	assignees := []*TaskAssginee{}
+	err = s.Where("task_id = ?", t.ID).Find(&assignees)
+	if err != nil || len(assignees) == 0 {
+		return err
+	}
+
+	counts, err := bucket.countTasksPerAssignee(s)
+	if err != nil {
+		return err
+	}
+	for _, assignee := range assignees {
+		if counts[assignee.UserID] >= bucket.AssigneeLimit {
+			return &ErrBucketAssigneeLimitExceeded{
+				BucketID: bucket.ID,
+				Limit:    bucket.AssigneeLimit,
+				TaskID:   t.ID,
+				UserID:   assignee.UserID,
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkAssigneeBucketLimits checks if assigning a user to a task would exceed the assignee limit of one of the buckets
+// the task is in. Soft limits are never enforced.
+func checkAssigneeBucketLimits(s *xorm.Session, taskID int64, userID int64) (err error) {
+	buckets := []*Bucket{}
+	err = s.
+		Where(builder.Or(
+			builder.In("id", builder.Select("bucket_id").From("tasks").Where(builder.Eq{"id": taskID}.And(builder.IsNull{"deleted"}))),
+			builder.In("id", builder.
+				Select("task_buckets.bucket_id").
+				From("task_buckets").
+				Join("INNER", "tasks", "tasks.id = task_buckets.task_id").
+				Where(builder.Eq{"task_buckets.task_id": taskID}.And(builder.IsNull{"tasks.deleted"})),
+			),
+		)).
+		And("assignee_limit > 0").
+		And("limit_mode != ?", BucketLimitModeSoft).
+		Find(&buckets)
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		counts, err := bucket.countTasksPerAssignee(s)
+		if err != nil {
+			return err
+		}
+		if counts[userID] >= bucket.AssigneeLimit {
+			return &ErrBucketAssigneeLimitExceeded{
+				BucketID: bucket.ID,
+				Limit:    bucket.AssigneeLimit,
+				TaskID:   taskID,
+				UserID:   userID,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,339 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/web"
+	"github.com/vectordotdev/go-datemath"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// The longest range a kanban report can cover, in days.
+const maxKanbanReportDays = 366
+
+// TaskBucketEntry records when a task entered and left a bucket of its list.
+type TaskBucketEntry struct {
+	// The unique, numeric id of this entry.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The task which was moved.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id"`
+	// The bucket the task was in.
+	BucketID int64 `xorm:"bigint not null INDEX" json:"bucket_id"`
+	// When the task was moved into the bucket.
+	EnteredAt time.Time `xorm:"not null INDEX" json:"entered_at"`
+	// When the task was moved out of the bucket. Null while the task is still in it.
+	LeftAt time.Time `xorm:"null" json:"left_at"`
+}
+
+// TableName holds the table name for task bucket entries
+func (TaskBucketEntry) TableName() string {
+	return "task_bucket_entries"
+}
+
+// recordBucketTransition closes the entry of the bucket a task was moved out of and opens one for the bucket it was
+// moved to.
+func recordBucketTransition(s *xorm.Session, taskID, oldBucketID, newBucketID int64) (err error) {
+	if oldBucketID == newBucketID {
+		return nil
+	}
+
+	now := time.Now()
+	_, err = s.
+		Where("task_id = ? AND left_at IS NULL", taskID).
+		Cols("left_at").
+		Update(&TaskBucketEntry{LeftAt: now})
+	if err != nil || newBucketID == 0 {
+		return err
+	}
+
+	_, err = s.Insert(&TaskBucketEntry{
+		TaskID:    taskID,
+		BucketID:  newBucketID,
+		EnteredAt: now,
+	})
+	return err
+}
+
+// KanbanReport holds flow metrics of the kanban board of a list over a date range.
+type KanbanReport struct {
+	// The list this report is about.
+	ListID int64 `json:"list_id" param:"list"`
+	// The start of the range as passed in the request.
+	From string `json:"-" query:"from"`
+	// The end of the range as passed in the request.
+	To string `json:"-" query:"to"`
+
+	// The start of the range this report covers.
+	PeriodStart time.Time `json:"period_start"`
+	// The end of the range this report covers.
+	PeriodEnd time.Time `json:"period_end"`
+
+	// How many tasks were done in the range.
+	CompletedTasks int64 `json:"completed_tasks"`
+	// The average time in seconds from creating a task until it was done.
+	AverageLeadTime int64 `json:"average_lead_time"`
+	// The average time in seconds from a task first leaving the default bucket until it was done. Only tasks which left
+	// the default bucket are taken into account.
+	AverageCycleTime int64 `json:"average_cycle_time"`
+	// The lead and cycle time of all tasks done in the range.
+	Tasks []*KanbanReportTask `json:"tasks"`
+
+	// All buckets of the list, in the order they are shown on the board.
+	Buckets []*KanbanReportBucket `json:"buckets"`
+	// How many tasks were in each bucket at the end of every day in the range.
+	CumulativeFlow []*CumulativeFlowDay `json:"cumulative_flow"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// KanbanReportTask holds the lead and cycle time of a single task.
+type KanbanReportTask struct {
+	TaskID int64     `json:"task_id"`
+	DoneAt time.Time `json:"done_at"`
+	// The time in seconds from creating the task until it was done.
+	LeadTime int64 `json:"lead_time"`
+	// The time in seconds from the task first leaving the default bucket until it was done. 0 if it never left it.
+	CycleTime int64 `json:"cycle_time"`
+}
+
+// KanbanReportBucket is a bucket as it appears in a report.
+type KanbanReportBucket struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// CumulativeFlowDay holds how many tasks were in each bucket at the end of a day.
+type CumulativeFlowDay struct {
+	Date    time.Time              `json:"date"`
+	Buckets []*CumulativeFlowCount `json:"buckets"`
+}
+
+// CumulativeFlowCount is the number of tasks in a bucket.
+type CumulativeFlowCount struct {
+	BucketID int64 `json:"bucket_id"`
+	Tasks    int64 `json:"tasks"`
+}
+
+// CanRead checks if a user can see the report of a list
+func (r *KanbanReport) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	l := &List{ID: r.ListID}
+	return l.CanRead(s, a)
+}
+
+func parseKanbanReportDate(raw string, isEnd bool) (t time.Time, err error) {
+	// A date without a time includes the whole day
+	t, err = time.ParseInLocation("2006-01-02", raw, config.GetTimeZone())
+	if err == nil {
+		if isEnd {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	expr, err := datemath.Parse(raw)
+	if err == nil {
+		return expr.Time(datemath.WithLocation(config.GetTimeZone())), nil
+	}
+
+	return time.Parse(time.RFC3339, raw)
+}
+
+func (r *KanbanReport) parseRange() (err error) {
+	invalid := &ErrInvalidKanbanReportRange{From: r.From, To: r.To}
+
+	r.PeriodEnd = time.Now().In(config.GetTimeZone())
+	if r.To != "" {
+		r.PeriodEnd, err = parseKanbanReportDate(r.To, true)
+		if err != nil {
+			return invalid
+		}
+	}
+
+	r.PeriodStart = r.PeriodEnd.AddDate(0, 0, -30)
+	if r.From != "" {
+		r.PeriodStart, err = parseKanbanReportDate(r.From, false)
+		if err != nil {
+			return invalid
+		}
+	}
+
+	if !r.PeriodStart.Before(r.PeriodEnd) || r.PeriodEnd.Sub(r.PeriodStart) > maxKanbanReportDays*24*time.Hour {
+		return invalid
+	}
+
+	return nil
+}
+
+// ReadOne returns the flow metrics of a list
+// @Summary Get kanban flow metrics of a list
+// @Description Returns the lead and cycle time of all tasks done in a date range and how many tasks were in each bucket at the end of every day in that range. Bucket changes are tracked for the buckets of the list, not for the boards of namespaces or saved filters.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "List ID"
+// @Param from query string false "The start of the range. Accepts a date, a RFC3339 timestamp or a date math expression like `now-7d`. Defaults to 30 days before the end."
+// @Param to query string false "The end of the range. Accepts the same formats as `from`, a date includes the whole day. Defaults to now."
+// @Success 200 {object} models.KanbanReport "The report."
+// @Failure 400 {object} web.HTTPError "Invalid range."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{id}/kanban/report [get]
+func (r *KanbanReport) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	err = r.parseRange()
+	if err != nil {
+		return err
+	}
+
+	buckets := []*Bucket{}
+	err = s.Where("list_id = ?", r.ListID).OrderBy("position").Find(&buckets)
+	if err != nil {
+		return err
+	}
+
+	r.Buckets = make([]*KanbanReportBucket, 0, len(buckets))
+	bucketIDs := make([]int64, 0, len(buckets))
+	for _, b := range buckets {
+		r.Buckets = append(r.Buckets, &KanbanReportBucket{ID: b.ID, Title: b.Title})
+		bucketIDs = append(bucketIDs, b.ID)
+	}
+
+	err = r.addTaskTimes(s, bucketIDs)
+	if err != nil {
+		return err
+	}
+
+	return r.addCumulativeFlow(s, bucketIDs)
+}
+
+func (r *KanbanReport) addTaskTimes(s *xorm.Session, bucketIDs []int64) (err error) {
+	tasks := []*Task{}
+	err = s.
+		Where("list_id = ? AND done = ?", r.ListID, true).
+		And("done_at >= ? AND done_at <= ?", r.PeriodStart, r.PeriodEnd).
+		OrderBy("done_at asc, id asc").
+		Find(&tasks)
+	if err != nil {
+		return err
+	}
+
+	r.Tasks = make([]*KanbanReportTask, 0, len(tasks))
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	defaultBucket, err := getDefaultBucket(s, r.ListID)
+	if err != nil {
+		return err
+	}
+
+	entries := []*TaskBucketEntry{}
+	err = s.
+		In("task_id", taskIDs).
+		In("bucket_id", bucketIDs).
+		And(builder.Neq{"bucket_id": defaultBucket.ID}).
+		OrderBy("entered_at asc").
+		Find(&entries)
+	if err != nil {
+		return err
+	}
+
+	started := make(map[int64]time.Time, len(entries))
+	for _, e := range entries {
+		if _, has := started[e.TaskID]; !has {
+			started[e.TaskID] = e.EnteredAt
+		}
+	}
+
+	var totalLeadTime, totalCycleTime, cycleTimeTasks int64
+	for _, t := range tasks {
+		rt := &KanbanReportTask{
+			TaskID:   t.ID,
+			DoneAt:   t.DoneAt,
+			LeadTime: int64(t.DoneAt.Sub(t.Created).Seconds()),
+		}
+		if start, has := started[t.ID]; has && !start.After(t.DoneAt) {
+			rt.CycleTime = int64(t.DoneAt.Sub(start).Seconds())
+			totalCycleTime += rt.CycleTime
+			cycleTimeTasks++
+		}
+		totalLeadTime += rt.LeadTime
+		r.Tasks = append(r.Tasks, rt)
+	}
+
+	r.CompletedTasks = int64(len(tasks))
+	r.AverageLeadTime = totalLeadTime / r.CompletedTasks
+	if cycleTimeTasks > 0 {
+		r.AverageCycleTime = totalCycleTime / cycleTimeTasks
+	}
+
+	return nil
+}
+
+func (r *KanbanReport) addCumulativeFlow(s *xorm.Session, bucketIDs []int64) (err error) {
+	entries := []*TaskBucketEntry{}
+	if len(bucketIDs) > 0 {
+		err = s.
+			In("bucket_id", bucketIDs).
+			And("entered_at <= ?", r.PeriodEnd).
+			And(builder.Or(builder.IsNull{"left_at"}, builder.Gt{"left_at": r.PeriodStart})).
+			Find(&entries)
+		if err != nil {
+			return err
+		}
+	}
+
+	loc := config.GetTimeZone()
+	start := r.PeriodStart.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+
+	r.CumulativeFlow = []*CumulativeFlowDay{}
+	for ; day.Before(r.PeriodEnd); day = day.AddDate(0, 0, 1) {
+		snapshot := day.AddDate(0, 0, 1)
+		if snapshot.After(r.PeriodEnd) {
+			snapshot = r.PeriodEnd
+		}
+
+		counts := make(map[int64]int64, len(bucketIDs))
+		for _, e := range entries {
+			if !e.EnteredAt.After(snapshot) && (e.LeftAt.IsZero() || e.LeftAt.After(snapshot)) {
+				counts[e.BucketID]++
+			}
+		}
+
+		flowDay := &CumulativeFlowDay{
+			Date:    day,
+			Buckets: make([]*CumulativeFlowCount, 0, len(r.Buckets)),
+		}
+		for _, b := range r.Buckets {
+			flowDay.Buckets = append(flowDay.Buckets, &CumulativeFlowCount{BucketID: b.ID, Tasks: counts[b.ID]})
+		}
+		r.CumulativeFlow = append(r.CumulativeFlow, flowDay)
+	}
+
+	return nil
+}
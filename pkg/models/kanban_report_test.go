@@ -0,0 +1,255 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucket_Limits(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("soft limit", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Bucket 2 already has 3 tasks and a limit of 3
+		_, err := s.ID(2).Cols("limit_mode").Update(&Bucket{LimitMode: BucketLimitModeSoft})
+		assert.NoError(t, err)
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: 2}
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		assert.True(t, m.LimitExceeded)
+		assert.Equal(t, int64(2), m.Task.BucketID)
+
+		b := &Bucket{ListID: 1}
+		result, _, _, err := b.ReadAll(s, u, "", 0, 0)
+		assert.NoError(t, err)
+		for _, bucket := range result.([]*Bucket) {
+			assert.Equal(t, bucket.ID == 2, bucket.LimitExceeded, "bucket %d", bucket.ID)
+		}
+	})
+	t.Run("hard limit", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: 2}
+		err := m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketLimitExceeded(err))
+	})
+	t.Run("tasks in the trash", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Task 3 is one of the 3 tasks in bucket 2
+		err := (&Task{ID: 3}).Delete(s, u)
+		assert.NoError(t, err)
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: 2}
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		assert.False(t, m.LimitExceeded)
+	})
+	t.Run("assignee limit with tasks in the trash", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(3).Cols("assignee_limit").Update(&Bucket{AssigneeLimit: 1})
+		assert.NoError(t, err)
+		_, err = s.Insert(&TaskAssginee{TaskID: 6, UserID: 1})
+		assert.NoError(t, err)
+		err = (&Task{ID: 6}).Delete(s, u)
+		assert.NoError(t, err)
+
+		// Task 7 is in bucket 3 as well
+		err = checkAssigneeBucketLimits(s, 7, 1)
+		assert.NoError(t, err)
+	})
+	t.Run("assignee limit", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(3).Cols("assignee_limit").Update(&Bucket{AssigneeLimit: 1})
+		assert.NoError(t, err)
+		_, err = s.Insert(&TaskAssginee{TaskID: 6, UserID: 1})
+		assert.NoError(t, err)
+
+		// Task 30 is assigned to user 1 who already has task 6 in bucket 3
+		m := &KanbanTaskMove{TaskID: 30, BucketID: 3}
+		err = m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketAssigneeLimitExceeded(err))
+
+		// Task 1 has no assignees. The rules of bucket 3 would assign user 1 to it once it was moved,
+		// but that would exceed the limit.
+		m = &KanbanTaskMove{TaskID: 1, BucketID: 3}
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		assert.False(t, m.LimitExceeded)
+		exists, err := s.Exist(&TaskAssginee{TaskID: 1, UserID: 1})
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+	t.Run("assignee limit when assigning", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(3).Cols("assignee_limit").Update(&Bucket{AssigneeLimit: 1})
+		assert.NoError(t, err)
+		_, err = s.Insert(&TaskAssginee{TaskID: 6, UserID: 1})
+		assert.NoError(t, err)
+
+		// Task 7 is in bucket 3 as well
+		la := &TaskAssginee{TaskID: 7, UserID: 1}
+		err = la.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketAssigneeLimitExceeded(err))
+
+		task := &Task{ID: 7, ListID: 1}
+		err = task.updateTaskAssignees(s, []*user.User{{ID: 1}}, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketAssigneeLimitExceeded(err))
+
+		// Other users are still fine
+		err = checkAssigneeBucketLimits(s, 7, 2)
+		assert.NoError(t, err)
+	})
+	t.Run("assignee limit with soft limits", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(3).Cols("assignee_limit", "limit_mode").Update(&Bucket{AssigneeLimit: 1, LimitMode: BucketLimitModeSoft})
+		assert.NoError(t, err)
+		_, err = s.Insert(&TaskAssginee{TaskID: 6, UserID: 1})
+		assert.NoError(t, err)
+
+		la := &TaskAssginee{TaskID: 7, UserID: 1}
+		err = la.Create(s, u)
+		assert.NoError(t, err)
+	})
+	t.Run("invalid limit mode", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{ID: 1, ListID: 1, Title: "testbucket1", LimitMode: "sometimes"}
+		err := b.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidBucketLimitMode(err))
+	})
+}
+
+func TestKanbanReport_ReadOne(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("cumulative flow", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &KanbanReport{ListID: 1, From: "2018-12-01", To: "2018-12-03"}
+		err := r.ReadOne(s, u)
+		assert.NoError(t, err)
+		assert.Len(t, r.Buckets, 3)
+		// Buckets are sorted by position
+		assert.Equal(t, int64(2), r.Buckets[0].ID)
+		assert.Equal(t, int64(1), r.Buckets[1].ID)
+		assert.Equal(t, int64(3), r.Buckets[2].ID)
+
+		counts := func(day *CumulativeFlowDay) (c []int64) {
+			for _, b := range day.Buckets {
+				c = append(c, b.Tasks)
+			}
+			return
+		}
+		assert.Len(t, r.CumulativeFlow, 3)
+		assert.Equal(t, []int64{0, 2, 0}, counts(r.CumulativeFlow[0]))
+		assert.Equal(t, []int64{1, 1, 0}, counts(r.CumulativeFlow[1]))
+		assert.Equal(t, []int64{1, 1, 1}, counts(r.CumulativeFlow[2]))
+		assert.Equal(t, int64(0), r.CompletedTasks)
+	})
+	t.Run("lead and cycle time", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Moving task 3 to the done bucket marks it as done
+		m := &KanbanTaskMove{TaskID: 3, BucketID: 3}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+		db.AssertExists(t, "task_bucket_entries", map[string]interface{}{
+			"task_id":   3,
+			"bucket_id": 3,
+		}, false)
+
+		r := &KanbanReport{ListID: 1}
+		err = r.ReadOne(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), r.CompletedTasks)
+		assert.Len(t, r.Tasks, 1)
+		assert.Equal(t, int64(3), r.Tasks[0].TaskID)
+		// Task 3 was created on 2018-12-01 and left the default bucket on 2018-12-02
+		assert.Greater(t, r.Tasks[0].CycleTime, int64(0))
+		assert.Greater(t, r.Tasks[0].LeadTime, r.Tasks[0].CycleTime)
+		assert.Equal(t, r.Tasks[0].LeadTime, r.AverageLeadTime)
+		assert.Equal(t, r.Tasks[0].CycleTime, r.AverageCycleTime)
+		assert.Len(t, r.CumulativeFlow, 31)
+	})
+	t.Run("invalid range", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &KanbanReport{ListID: 1, From: "2018-12-03", To: "2018-12-01"}
+		err := r.ReadOne(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidKanbanReportRange(err))
+
+		r = &KanbanReport{ListID: 1, From: "lorem"}
+		err = r.ReadOne(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidKanbanReportRange(err))
+
+		r = &KanbanReport{ListID: 1, From: "2018-01-01", To: "2020-01-01"}
+		err = r.ReadOne(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidKanbanReportRange(err))
+	})
+	t.Run("rights", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &KanbanReport{ListID: 1}
+		can, _, err := r.CanRead(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
@@ -23,8 +23,8 @@ import (
 
 // CanCreate checks if a user can create a new bucket
 func (b *Bucket) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	l := &List{ID: b.ListID}
-	return l.CanWrite(s, a)
+	b.setBoardFromListID()
+	return b.canManageBoard(s, a)
 }
 
 // CanUpdate checks if a user can update an existing bucket
@@ -43,6 +43,42 @@ func (b *Bucket) canDoBucket(s *xorm.Session, a web.Auth) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	l := &List{ID: bb.ListID}
-	return l.CanWrite(s, a)
+	b.ListID = bb.ListID
+	b.NamespaceID = bb.NamespaceID
+	b.SavedFilterID = bb.SavedFilterID
+	return b.canManageBoard(s, a)
+}
+
+// canManageBoard checks if the user can add, change or remove buckets of the board the bucket belongs to.
+func (b *Bucket) canManageBoard(s *xorm.Session, a web.Auth) (bool, error) {
+	if b.NamespaceID != 0 {
+		n := &Namespace{ID: b.NamespaceID}
+		can, err := n.CanWrite(s, a)
+		if err != nil || can {
+			return can, err
+		}
+		return n.hasRolePermission(s, a, PermissionManageBuckets)
+	}
+	if b.SavedFilterID != 0 {
+		sf := &SavedFilter{ID: b.SavedFilterID}
+		return sf.CanUpdate(s, a)
+	}
+	l := &List{ID: b.ListID}
+	return l.checkPermission(s, a, PermissionManageBuckets)
+}
+
+// canReadBoard checks if the user can see the board the bucket belongs to.
+func (b *Bucket) canReadBoard(s *xorm.Session, a web.Auth) (can bool, err error) {
+	switch {
+	case b.NamespaceID != 0:
+		n := &Namespace{ID: b.NamespaceID}
+		can, _, err = n.CanRead(s, a)
+	case b.SavedFilterID != 0:
+		sf := &SavedFilter{ID: b.SavedFilterID}
+		can, _, err = sf.CanRead(s, a)
+	default:
+		l := &List{ID: b.ListID}
+		can, _, err = l.CanRead(s, a)
+	}
+	return
 }
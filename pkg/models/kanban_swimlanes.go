@@ -0,0 +1,460 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// All properties kanban swimlanes can be grouped by
+const (
+	SwimlaneGroupAssignee = "assignee"
+	SwimlaneGroupLabel    = "label"
+	SwimlaneGroupPriority = "priority"
+	SwimlaneGroupParent   = "parent"
+)
+
+func validateSwimlaneGroup(groupBy string) error {
+	switch groupBy {
+	case SwimlaneGroupAssignee, SwimlaneGroupLabel, SwimlaneGroupPriority, SwimlaneGroupParent:
+		return nil
+	}
+	return &ErrInvalidSwimlaneGroup{GroupBy: groupBy}
+}
+
+// KanbanSwimlane is a horizontal lane on a kanban board. Every lane contains all buckets of the board, but only the
+// tasks matching the lane.
+type KanbanSwimlane struct {
+	// The id of the thing this lane groups by: A user id, label id, priority or the id of the parent task.
+	// Tasks without a value are in the lane with the id 0.
+	ID int64 `json:"id"`
+	// The title of this lane.
+	Title string `json:"title"`
+	// All buckets of the board with the tasks of this lane.
+	Buckets []*Bucket `json:"buckets"`
+}
+
+type swimlaneKey struct {
+	id    int64
+	title string
+}
+
+// swimlaneKeysForTask returns all lanes a task belongs to. A task can be part of multiple lanes, for example if it
+// has more than one assignee.
+func swimlaneKeysForTask(t *Task, groupBy string) (keys []swimlaneKey) {
+	switch groupBy {
+	case SwimlaneGroupAssignee:
+		for _, a := range t.Assignees {
+			keys = append(keys, swimlaneKey{id: a.ID, title: a.GetName()})
+		}
+	case SwimlaneGroupLabel:
+		for _, l := range t.Labels {
+			keys = append(keys, swimlaneKey{id: l.ID, title: l.Title})
+		}
+	case SwimlaneGroupPriority:
+		if t.Priority != 0 {
+			keys = append(keys, swimlaneKey{id: t.Priority, title: strconv.FormatInt(t.Priority, 10)})
+		}
+	case SwimlaneGroupParent:
+		for _, p := range t.RelatedTasks[RelationKindParenttask] {
+			keys = append(keys, swimlaneKey{id: p.ID, title: p.Title})
+		}
+	}
+
+	if len(keys) == 0 {
+		keys = append(keys, swimlaneKey{})
+	}
+	return
+}
+
+// groupBucketsIntoSwimlanes splits the tasks of all buckets into swimlanes. The order of the tasks in each bucket is kept.
+// Lanes are sorted by title, priority lanes from the highest to the lowest priority. The lane with tasks without a
+// value always comes last.
+func groupBucketsIntoSwimlanes(buckets []*Bucket, groupBy string) []*KanbanSwimlane {
+	lanes := make(map[int64]*KanbanSwimlane)
+	laneBuckets := make(map[int64]map[int64]*Bucket)
+
+	getLane := func(key swimlaneKey) *KanbanSwimlane {
+		lane, exists := lanes[key.id]
+		if exists {
+			return lane
+		}
+		lane = &KanbanSwimlane{ID: key.id, Title: key.title, Buckets: make([]*Bucket, 0, len(buckets))}
+		laneBuckets[key.id] = make(map[int64]*Bucket, len(buckets))
+		for _, b := range buckets {
+			lb := *b
+			lb.Tasks = []*Task{}
+			lane.Buckets = append(lane.Buckets, &lb)
+			laneBuckets[key.id][b.ID] = &lb
+		}
+		lanes[key.id] = lane
+		return lane
+	}
+
+	for _, b := range buckets {
+		for _, t := range b.Tasks {
+			for _, key := range swimlaneKeysForTask(t, groupBy) {
+				getLane(key)
+				lb := laneBuckets[key.id][b.ID]
+				lb.Tasks = append(lb.Tasks, t)
+			}
+		}
+	}
+
+	result := make([]*KanbanSwimlane, 0, len(lanes))
+	for _, lane := range lanes {
+		result = append(result, lane)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ID == 0 || result[j].ID == 0 {
+			return result[j].ID == 0 && result[i].ID != 0
+		}
+		if groupBy == SwimlaneGroupPriority {
+			return result[i].ID > result[j].ID
+		}
+		ti, tj := strings.ToLower(result[i].Title), strings.ToLower(result[j].Title)
+		if ti != tj {
+			return ti < tj
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// KanbanTaskMove moves a task on a kanban board with swimlanes.
+type KanbanTaskMove struct {
+	// The id of the task to move.
+	TaskID int64 `json:"-" param:"listtask"`
+	// The bucket the task should be moved to.
+	BucketID int64 `json:"bucket_id"`
+	// The new kanban position of the task in its bucket.
+	KanbanPosition float64 `json:"kanban_position"`
+	// The property the swimlanes are grouped by. Can be one of assignee, label, priority or parent.
+	GroupBy string `json:"group_by"`
+	// The id of the lane the task is moved from. 0 is the lane of tasks without a value.
+	FromLaneID int64 `json:"from_lane_id"`
+	// The id of the lane the task is moved to. 0 is the lane of tasks without a value.
+	ToLaneID int64 `json:"to_lane_id"`
+
+	// The task after it was moved.
+	Task *Task `json:"task"`
+	// Whether the bucket the task was moved to is now over one of its soft limits.
+	LimitExceeded bool `json:"limit_exceeded"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanUpdate checks if a user can move a task
+func (m *KanbanTaskMove) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	t := &Task{ID: m.TaskID}
+	return t.CanUpdate(s, a)
+}
+
+// Update moves a task
+// @Summary Move a task on a kanban board with swimlanes
+// @Description Moves a task to another bucket and swimlane. The bucket can also be part of the board of a namespace or saved filter. The bucket, position and the property the swimlanes are grouped by are changed together: Moving a task to another assignee lane replaces the assignee of the lane it was moved from with the one of the new lane, same for labels and parent tasks. Moving it to another priority lane sets the priority.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Task ID"
+// @Param move body models.KanbanTaskMove true "Where to move the task to"
+// @Success 200 {object} models.KanbanTaskMove "The move including the updated task."
+// @Failure 400 {object} web.HTTPError "Invalid swimlane group or bucket."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 412 {object} web.HTTPError "The task is not part of the lane it should be moved from or the bucket limit is exceeded."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{id}/kanban [post]
+func (m *KanbanTaskMove) Update(s *xorm.Session, a web.Auth) (err error) {
+	if m.GroupBy != "" {
+		if err := validateSwimlaneGroup(m.GroupBy); err != nil {
+			return err
+		}
+	}
+
+	ot, err := GetTaskByIDSimple(s, m.TaskID)
+	if err != nil {
+		return err
+	}
+	oldTask := ot
+
+	task := ot
+	var bucket *Bucket
+	var oldBoardBucketID int64
+	if m.BucketID != 0 {
+		bucket, err = getBucketByID(s, m.BucketID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if bucket != nil && bucket.isBoardBucket() {
+		// Moving a task on the board of a namespace or saved filter does not change its bucket on the board of its list
+		oldBoardBucketID, err = moveTaskOnBoard(s, a, &task, bucket, m.KanbanPosition)
+		if err != nil {
+			return err
+		}
+		if bucket.IsDoneBucket {
+			task.Done = true
+		}
+	} else {
+		if bucket != nil {
+			task.BucketID = bucket.ID
+		}
+		task.KanbanPosition = m.KanbanPosition
+	}
+
+	if err := setTaskBucket(s, &task, &ot, task.BucketID != ot.BucketID); err != nil {
+		return err
+	}
+
+	if err := setTaskStatusFromBucket(s, &task, &ot); err != nil {
+		return err
+	}
+
+	colsToUpdate := []string{"bucket_id", "kanban_position", "status_id"}
+	doneCols, err := updateTaskDoneAfterMove(s, &task, &ot)
+	if err != nil {
+		return err
+	}
+	colsToUpdate = append(colsToUpdate, doneCols...)
+
+	if m.GroupBy == SwimlaneGroupPriority && m.FromLaneID != m.ToLaneID {
+		if ot.Priority != m.FromLaneID {
+			return m.notInLane()
+		}
+		task.Priority = m.ToLaneID
+		colsToUpdate = append(colsToUpdate, "priority")
+	}
+
+	_, err = s.ID(task.ID).Cols(colsToUpdate...).Update(&task)
+	if err != nil {
+		return err
+	}
+
+	if m.FromLaneID != m.ToLaneID {
+		switch m.GroupBy {
+		case SwimlaneGroupAssignee:
+			err = m.moveAssignee(s, a, &task)
+		case SwimlaneGroupLabel:
+			err = m.moveLabel(s, a)
+		case SwimlaneGroupParent:
+			err = m.moveParent(s, a)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	err = recordBucketTransition(s, task.ID, ot.BucketID, task.BucketID)
+	if err != nil {
+		return err
+	}
+
+	err = applyBucketRules(s, a, &task, ot.BucketID, task.BucketID)
+	if err != nil {
+		return err
+	}
+	if bucket != nil && bucket.isBoardBucket() {
+		err = applyBucketRules(s, a, &task, oldBoardBucketID, bucket.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	updated, err := GetTaskByIDSimple(s, task.ID)
+	if err != nil {
+		return err
+	}
+	m.Task = &updated
+
+	destination := bucket
+	if destination == nil || (!destination.isBoardBucket() && destination.ID != task.BucketID) {
+		destination, err = getBucketByID(s, task.BucketID)
+		if err != nil {
+			return err
+		}
+	}
+	m.LimitExceeded, err = destination.isOverLimit(s)
+	if err != nil {
+		return err
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	err = events.Dispatch(&TaskUpdatedEvent{
+		Task:    m.Task,
+		OldTask: &oldTask,
+		Doer:    doer,
+	})
+	if err != nil {
+		return err
+	}
+
+	return updateListLastUpdated(s, &List{ID: task.ListID})
+}
+
+// updateTaskDoneAfterMove updates the dates and reminders of a task which was marked as done or undone by moving it
+// into or out of a done bucket. It returns the columns which need to be saved.
+func updateTaskDoneAfterMove(s *xorm.Session, task *Task, ot *Task) (cols []string, err error) {
+	if task.Done == ot.Done {
+		return nil, nil
+	}
+
+	if task.Done {
+		if err := checkTaskIsNotBlocked(s, task.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	reminders, err := getRemindersForTasks(s, []int64{task.ID})
+	if err != nil {
+		return nil, err
+	}
+	ot.Reminders = make([]time.Time, 0, len(reminders))
+	for _, r := range reminders {
+		ot.Reminders = append(ot.Reminders, r.Reminder)
+	}
+	task.Reminders = ot.Reminders
+
+	updateDone(ot, task)
+	if err := ot.updateReminders(s, task.Reminders); err != nil {
+		return nil, err
+	}
+
+	return []string{"done", "done_at", "due_date", "start_date", "end_date"}, nil
+}
+
+func (m *KanbanTaskMove) notInLane() error {
+	return &ErrTaskNotInSwimlane{TaskID: m.TaskID, GroupBy: m.GroupBy, LaneID: m.FromLaneID}
+}
+
+func (m *KanbanTaskMove) moveAssignee(s *xorm.Session, a web.Auth, task *Task) (err error) {
+	if m.FromLaneID != 0 {
+		deleted, err := s.Delete(&TaskAssginee{TaskID: m.TaskID, UserID: m.FromLaneID})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return m.notInLane()
+		}
+	}
+
+	if m.ToLaneID == 0 {
+		return nil
+	}
+
+	exists, err := s.Exist(&TaskAssginee{TaskID: m.TaskID, UserID: m.ToLaneID})
+	if err != nil || exists {
+		return err
+	}
+
+	err = checkAssigneeBucketLimits(s, m.TaskID, m.ToLaneID)
+	if err != nil {
+		return err
+	}
+
+	list, err := GetListSimpleByID(s, task.ListID)
+	if err != nil {
+		return err
+	}
+	return task.addNewAssigneeByID(s, m.ToLaneID, list, a)
+}
+
+func (m *KanbanTaskMove) moveLabel(s *xorm.Session, a web.Auth) (err error) {
+	if m.FromLaneID != 0 {
+		deleted, err := s.Delete(&LabelTask{TaskID: m.TaskID, LabelID: m.FromLaneID})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return m.notInLane()
+		}
+	}
+
+	if m.ToLaneID == 0 {
+		return nil
+	}
+
+	label, err := getLabelByIDSimple(s, m.ToLaneID)
+	if err != nil {
+		return err
+	}
+	canRead, _, err := label.hasAccessToLabel(s, a)
+	if err != nil {
+		return err
+	}
+	if !canRead {
+		return ErrGenericForbidden{}
+	}
+
+	exists, err := s.Exist(&LabelTask{TaskID: m.TaskID, LabelID: m.ToLaneID})
+	if err != nil || exists {
+		return err
+	}
+	_, err = s.Insert(&LabelTask{TaskID: m.TaskID, LabelID: m.ToLaneID})
+	return err
+}
+
+func (m *KanbanTaskMove) moveParent(s *xorm.Session, a web.Auth) (err error) {
+	if m.FromLaneID != 0 {
+		deleted, err := s.
+			Where(builder.Or(
+				builder.Eq{"task_id": m.TaskID, "other_task_id": m.FromLaneID, "relation_kind": RelationKindParenttask},
+				builder.Eq{"task_id": m.FromLaneID, "other_task_id": m.TaskID, "relation_kind": RelationKindSubtask},
+			)).
+			Delete(&TaskRelation{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return m.notInLane()
+		}
+	}
+
+	if m.ToLaneID == 0 {
+		return nil
+	}
+
+	parent := &Task{ID: m.ToLaneID}
+	canRead, _, err := parent.CanRead(s, a)
+	if err != nil {
+		return err
+	}
+	if !canRead {
+		return ErrGenericForbidden{}
+	}
+
+	exists, err := s.Exist(&TaskRelation{TaskID: m.TaskID, OtherTaskID: m.ToLaneID, RelationKind: RelationKindParenttask})
+	if err != nil || exists {
+		return err
+	}
+
+	rel := &TaskRelation{TaskID: m.TaskID, OtherTaskID: m.ToLaneID, RelationKind: RelationKindParenttask}
+	return rel.Create(s, a)
+}
@@ -0,0 +1,206 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucket_ReadAll_Swimlanes(t *testing.T) {
+	readLanes := func(t *testing.T, groupBy string) []*KanbanSwimlane {
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{ListID: 1, GroupBy: groupBy}
+		result, _, _, err := b.ReadAll(s, &user.User{ID: 1}, "", 0, 0)
+		assert.NoError(t, err)
+		lanes, is := result.([]*KanbanSwimlane)
+		assert.True(t, is)
+		return lanes
+	}
+	taskIDsInLane := func(lane *KanbanSwimlane) (ids []int64) {
+		for _, b := range lane.Buckets {
+			for _, t := range b.Tasks {
+				ids = append(ids, t.ID)
+			}
+		}
+		return
+	}
+
+	t.Run("by assignee", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		lanes := readLanes(t, SwimlaneGroupAssignee)
+		assert.Len(t, lanes, 3)
+		assert.Equal(t, int64(1), lanes[0].ID)
+		assert.Equal(t, int64(2), lanes[1].ID)
+		assert.Equal(t, int64(0), lanes[2].ID)
+		assert.Equal(t, []int64{30}, taskIDsInLane(lanes[0]))
+		assert.Equal(t, []int64{30}, taskIDsInLane(lanes[1]))
+		assert.NotContains(t, taskIDsInLane(lanes[2]), int64(30))
+		// Every lane has all buckets of the board
+		for _, lane := range lanes {
+			assert.Len(t, lane.Buckets, 3)
+		}
+	})
+	t.Run("by priority", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		lanes := readLanes(t, SwimlaneGroupPriority)
+		assert.Len(t, lanes, 3)
+		assert.Equal(t, int64(100), lanes[0].ID)
+		assert.Equal(t, int64(1), lanes[1].ID)
+		assert.Equal(t, int64(0), lanes[2].ID)
+		assert.Equal(t, []int64{3}, taskIDsInLane(lanes[0]))
+		assert.Equal(t, []int64{4}, taskIDsInLane(lanes[1]))
+	})
+	t.Run("by label", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		lanes := readLanes(t, SwimlaneGroupLabel)
+		assert.Len(t, lanes, 2)
+		assert.Equal(t, int64(4), lanes[0].ID)
+		assert.ElementsMatch(t, []int64{1, 2}, taskIDsInLane(lanes[0]))
+	})
+	t.Run("by parent", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		lanes := readLanes(t, SwimlaneGroupParent)
+		assert.Len(t, lanes, 2)
+		assert.Equal(t, int64(1), lanes[0].ID)
+		assert.Equal(t, []int64{29}, taskIDsInLane(lanes[0]))
+	})
+	t.Run("invalid group", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{ListID: 1, GroupBy: "color"}
+		_, _, _, err := b.ReadAll(s, &user.User{ID: 1}, "", 0, 0)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidSwimlaneGroup(err))
+	})
+}
+
+func TestKanbanTaskMove_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("assignee", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 30, BucketID: 3, KanbanPosition: 10, GroupBy: SwimlaneGroupAssignee, FromLaneID: 2, ToLaneID: 0}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), m.Task.BucketID)
+		// Bucket 3 is the done bucket
+		assert.True(t, m.Task.Done)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{"id": 30, "bucket_id": 3, "kanban_position": 10, "done": true}, false)
+		db.AssertMissing(t, "task_assignees", map[string]interface{}{"task_id": 30, "user_id": 2})
+		db.AssertExists(t, "task_assignees", map[string]interface{}{"task_id": 30, "user_id": 1}, false)
+	})
+	t.Run("assignee to another lane", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 1, GroupBy: SwimlaneGroupAssignee, FromLaneID: 0, ToLaneID: 1}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_assignees", map[string]interface{}{"task_id": 1, "user_id": 1}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{"id": 1, "bucket_id": 1}, false)
+	})
+	t.Run("task not in lane", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 1, GroupBy: SwimlaneGroupAssignee, FromLaneID: 2, ToLaneID: 1}
+		err := m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskNotInSwimlane(err))
+	})
+	t.Run("label", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 1, GroupBy: SwimlaneGroupLabel, FromLaneID: 4, ToLaneID: 0}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{"task_id": 1, "label_id": 4})
+	})
+	t.Run("priority", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 4, BucketID: 3, GroupBy: SwimlaneGroupPriority, FromLaneID: 1, ToLaneID: 3}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{"id": 4, "bucket_id": 3, "priority": 3}, false)
+	})
+	t.Run("parent", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 29, GroupBy: SwimlaneGroupParent, FromLaneID: 1, ToLaneID: 2}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "task_relations", map[string]interface{}{"task_id": 29, "other_task_id": 1})
+		db.AssertMissing(t, "task_relations", map[string]interface{}{"task_id": 1, "other_task_id": 29})
+		db.AssertExists(t, "task_relations", map[string]interface{}{"task_id": 29, "other_task_id": 2, "relation_kind": RelationKindParenttask}, false)
+		db.AssertExists(t, "task_relations", map[string]interface{}{"task_id": 2, "other_task_id": 29, "relation_kind": RelationKindSubtask}, false)
+	})
+	t.Run("bucket of another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: 4}
+		err := m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketDoesNotBelongToList(err))
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 1}
+		can, err := m.CanUpdate(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
@@ -73,11 +73,19 @@ func (l *Label) hasAccessToLabel(s *xorm.Session, a web.Auth) (has bool, maxRigh
 		return false, 0, err
 	}
 
+	userLists, err := getUserListsStatement(s, u.ID)
+	if err != nil {
+		return false, 0, err
+	}
+
 	cond := builder.In("label_tasks.task_id",
 		builder.
 			Select("id").
 			From("tasks").
-			Where(builder.In("list_id", getUserListsStatement(u.ID).Select("l.id"))),
+			Where(builder.And(
+				builder.In("list_id", userLists.Select("l.id")),
+				builder.IsNull{"deleted"},
+			)),
 	)
 
 	ll := &LabelTask{}
@@ -176,11 +176,18 @@ func getLabelsByTaskIDs(s *xorm.Session, opts *LabelByTaskIDsOptions) (ls []*lab
 		cond = builder.And(builder.In("label_tasks.task_id", opts.TaskIDs), cond)
 	}
 	if opts.GetForUser != 0 {
+		userLists, err := getUserListsStatement(s, opts.GetForUser)
+		if err != nil {
+			return nil, 0, 0, err
+		}
 		cond = builder.And(builder.In("label_tasks.task_id",
 			builder.
 				Select("id").
 				From("tasks").
-				Where(builder.In("list_id", getUserListsStatement(opts.GetForUser).Select("l.id"))),
+				Where(builder.And(
+					builder.In("list_id", userLists.Select("l.id")),
+					builder.IsNull{"deleted"},
+				)),
 		), cond)
 	}
 	if opts.GetUnusedLabels {
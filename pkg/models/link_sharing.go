@@ -18,6 +18,7 @@ package models
 
 import (
 	"errors"
+	"net"
 	"time"
 
 	"code.vikunja.io/api/pkg/db"
@@ -54,6 +55,8 @@ type LinkSharing struct {
 	ListID int64 `xorm:"bigint not null" json:"-" param:"list"`
 	// The right this list is shared with. 0 = Read only, 1 = Read & Write, 2 = Admin. See the docs for more details.
 	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|2)" maximum:"2" default:"0"`
+	// The custom role this share has. If set, the right is always read only and everything else is allowed by the role.
+	RoleID int64 `xorm:"bigint null INDEX" json:"role_id"`
 
 	// The kind of this link. 0 = undefined, 1 = without password, 2 = with password.
 	SharingType SharingType `xorm:"bigint INDEX not null default 0" json:"sharing_type" valid:"length(0|2)" maximum:"2" default:"0"`
@@ -61,6 +64,20 @@ type LinkSharing struct {
 	// The password of this link share. You can only set it, not retrieve it after the link share has been created.
 	Password string `xorm:"text null" json:"password"`
 
+	// If set, the link share cannot be used after this date.
+	Expires time.Time `xorm:"datetime null" json:"expires"`
+	// How often this link share can be used to authenticate. 0 means unlimited.
+	MaxUses int64 `xorm:"bigint not null default 0" json:"max_uses"`
+	// How often this link share was used to authenticate. You cannot change this value.
+	Uses int64 `xorm:"bigint not null default 0" json:"uses"`
+	// If set, the link share can only be used from these ip addresses or cidr ranges.
+	IPAllowlist []string `xorm:"JSON null" json:"ip_allowlist"`
+	// Whether the link share is disabled. Disabled link shares cannot be used until they are enabled again.
+	IsDisabled bool `xorm:"bool not null default false" json:"is_disabled"`
+	// Whether the list can be viewed read-only through the public view of this link share, without authenticating.
+	// Link shares protected by a password have no public view.
+	IsPublic bool `xorm:"bool not null default false" json:"is_public"`
+
 	// The user who shared this list
 	SharedBy   *user.User `xorm:"-" json:"shared_by"`
 	SharedByID int64      `xorm:"bigint INDEX not null" json:"-"`
@@ -136,6 +153,17 @@ func (share *LinkSharing) Create(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	err = checkShareRole(s, share.RoleID, &share.Right)
+	if err != nil {
+		return
+	}
+
+	err = validateIPAllowlist(share.IPAllowlist)
+	if err != nil {
+		return
+	}
+
+	share.Uses = 0
 	share.SharedByID = a.GetID()
 	share.Hash = utils.MakeRandomString(40)
 
@@ -255,6 +283,58 @@ func (share *LinkSharing) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 	return shares, len(shares), totalItems, err
 }
 
+// Update updates a link share
+// @Summary Update a link share
+// @Description Updates a link share. Use this to change its name, right, expiry date, usage limit or ip allowlist or to disable and enable it again. The user needs to have write-access to the list to be able do this.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param list path int true "List ID"
+// @Param share path int true "Share ID"
+// @Param share body models.LinkSharing true "The link share with updated values"
+// @Success 200 {object} models.LinkSharing "The updated link share object."
+// @Failure 400 {object} web.HTTPError "Invalid link share object provided."
+// @Failure 403 {object} web.HTTPError "Not allowed to update the link share."
+// @Failure 404 {object} web.HTTPError "Share Link not found."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/shares/{share} [post]
+func (share *LinkSharing) Update(s *xorm.Session, a web.Auth) (err error) {
+	err = share.Right.isValid()
+	if err != nil {
+		return
+	}
+
+	err = checkShareRole(s, share.RoleID, &share.Right)
+	if err != nil {
+		return
+	}
+
+	err = validateIPAllowlist(share.IPAllowlist)
+	if err != nil {
+		return
+	}
+
+	_, err = s.
+		Where("id = ? AND list_id = ?", share.ID, share.ListID).
+		Cols(
+			"name",
+			"right",
+			"role_id",
+			"expires",
+			"max_uses",
+			"ip_allowlist",
+			"is_disabled",
+			"is_public",
+		).
+		Update(share)
+	if err != nil {
+		return
+	}
+
+	return share.ReadOne(s, a)
+}
+
 // Delete removes a link share
 // @Summary Remove a link share
 // @Description Remove a link share. The user needs to have write-access to the list to be able do this.
@@ -270,6 +350,11 @@ func (share *LinkSharing) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /lists/{list}/shares/{share} [delete]
 func (share *LinkSharing) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("link_share_id = ?", share.ID).Delete(&LinkShareAccessLog{})
+	if err != nil {
+		return
+	}
+
 	_, err = s.Where("id = ?", share.ID).Delete(share)
 	return
 }
@@ -334,3 +419,89 @@ func VerifyLinkSharePassword(share *LinkSharing, password string) (err error) {
 
 	return nil
 }
+
+func validateIPAllowlist(allowlist []string) error {
+	for _, entry := range allowlist {
+		if net.ParseIP(entry) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(entry); err != nil {
+			return &ErrInvalidIPAllowlistEntry{Entry: entry}
+		}
+	}
+	return nil
+}
+
+func (share *LinkSharing) isIPAllowed(ip string) bool {
+	if len(share.IPAllowlist) == 0 {
+		return true
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, entry := range share.IPAllowlist {
+		if allowed := net.ParseIP(entry); allowed != nil {
+			if allowed.Equal(parsed) {
+				return true
+			}
+			continue
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckAccess checks if the link share can currently be used from an ip address.
+func (share *LinkSharing) CheckAccess(ip string) error {
+	if share.IsDisabled {
+		return &ErrLinkShareDisabled{ShareID: share.ID}
+	}
+
+	if !share.Expires.IsZero() && share.Expires.Before(time.Now()) {
+		return &ErrLinkShareExpired{ShareID: share.ID}
+	}
+
+	if !share.isIPAllowed(ip) {
+		return &ErrLinkShareIPNotAllowed{ShareID: share.ID}
+	}
+
+	return nil
+}
+
+// UseLinkShare checks if someone can authenticate with a link share and counts the use.
+func UseLinkShare(s *xorm.Session, share *LinkSharing, password string, ip string) (err error) {
+	err = share.CheckAccess(ip)
+	if err != nil {
+		return err
+	}
+
+	if share.SharingType == SharingTypeWithPassword {
+		err = VerifyLinkSharePassword(share, password)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Checking the limit and counting the use in one statement makes sure concurrent requests
+	// can't use the share more often than allowed.
+	affected, err := s.
+		Where("id = ?", share.ID).
+		And("max_uses = 0 OR uses < max_uses").
+		Incr("uses").
+		Update(&LinkSharing{})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &ErrLinkShareUsageLimitReached{ShareID: share.ID}
+	}
+	share.Uses++
+
+	return nil
+}
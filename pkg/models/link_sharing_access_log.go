@@ -0,0 +1,117 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// LinkShareAccessLog is an entry in the access log of a link share. Every attempt to authenticate with a link share
+// is logged, whether it was successful or not.
+type LinkShareAccessLog struct {
+	// The unique, numeric id of this log entry.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The link share which was used.
+	LinkShareID int64 `xorm:"bigint not null index" json:"link_share_id" param:"share"`
+	// The ip address the link share was used from.
+	IP string `xorm:"varchar(250) null" json:"ip"`
+	// The user agent of the client which used the link share.
+	UserAgent string `xorm:"text null" json:"user_agent"`
+	// Whether the authentication was successful.
+	Successful bool `xorm:"bool not null default false" json:"successful"`
+
+	// Used to check the list when retrieving the log
+	ListID int64 `xorm:"-" json:"-" param:"list"`
+
+	// A timestamp when the link share was used.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for link share access logs
+func (*LinkShareAccessLog) TableName() string {
+	return "link_share_access_log"
+}
+
+// LogLinkShareAccess saves an attempt to authenticate with a link share in its access log.
+func LogLinkShareAccess(s *xorm.Session, share *LinkSharing, ip string, userAgent string, successful bool) (err error) {
+	_, err = s.Insert(&LinkShareAccessLog{
+		LinkShareID: share.ID,
+		IP:          ip,
+		UserAgent:   userAgent,
+		Successful:  successful,
+	})
+	return
+}
+
+// ReadAll returns the access log of a link share
+// @Summary Get the access log of a link share
+// @Description Returns every attempt to authenticate with a link share, newest first. The user needs to have write-access to the list to be able do this.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Param list path int true "List ID"
+// @Param share path int true "Share ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.LinkShareAccessLog "The access log entries."
+// @Failure 403 {object} web.HTTPError "Not allowed to see the access log."
+// @Failure 404 {object} web.HTTPError "Share Link not found."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{list}/shares/{share}/log [get]
+func (al *LinkShareAccessLog) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	share, err := GetLinkShareByID(s, al.LinkShareID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if share.ListID != al.ListID {
+		return nil, 0, 0, ErrListShareDoesNotExist{ID: al.LinkShareID}
+	}
+
+	can, err := share.canDoLinkShare(s, a, share.Right, share.RoleID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	entries := []*LinkShareAccessLog{}
+	query := s.
+		Where("link_share_id = ?", share.ID).
+		OrderBy("id desc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&entries)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	numberOfTotalItems, err = s.
+		Where("link_share_id = ?", share.ID).
+		Count(&LinkShareAccessLog{})
+	return entries, len(entries), numberOfTotalItems, err
+}
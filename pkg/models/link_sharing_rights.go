@@ -37,20 +37,36 @@ func (share *LinkSharing) CanRead(s *xorm.Session, a web.Auth) (bool, int, error
 
 // CanDelete implements the delete right check for a link share
 func (share *LinkSharing) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
-	return share.canDoLinkShare(s, a)
+	stored, err := share.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return share.canDoLinkShare(s, a, stored.Right, stored.RoleID)
 }
 
 // CanUpdate implements the update right check for a link share
 func (share *LinkSharing) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
-	return share.canDoLinkShare(s, a)
+	can, err := share.canDoLinkShare(s, a, share.Right, share.RoleID)
+	if err != nil || !can {
+		return can, err
+	}
+
+	// Whoever changes a share must be allowed to give the right it had before as well
+	stored, err := share.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return share.canDoLinkShare(s, a, stored.Right, stored.RoleID)
 }
 
 // CanCreate implements the create right check for a link share
 func (share *LinkSharing) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	return share.canDoLinkShare(s, a)
+	return share.canDoLinkShare(s, a, share.Right, share.RoleID)
 }
 
-func (share *LinkSharing) canDoLinkShare(s *xorm.Session, a web.Auth) (bool, error) {
+func (share *LinkSharing) canDoLinkShare(s *xorm.Session, a web.Auth, right Right, roleID int64) (bool, error) {
 	// Don't allow creating link shares if the user itself authenticated with a link share
 	if _, is := a.(*LinkSharing); is {
 		return false, nil
@@ -62,9 +78,24 @@ func (share *LinkSharing) canDoLinkShare(s *xorm.Session, a web.Auth) (bool, err
 	}
 
 	// Check if the user is admin when the link right is admin
-	if share.Right == RightAdmin {
+	if right == RightAdmin {
 		return l.IsAdmin(s, a)
 	}
 
-	return l.CanWrite(s, a)
+	can, err := l.CanWrite(s, a)
+	if err != nil || can {
+		return can, err
+	}
+
+	return l.canShare(s, a, right, roleID)
+}
+
+// getStoredShare returns the share as it is saved right now. If there is none, it returns an empty share
+// so the update or delete can fail with the proper error.
+func (share *LinkSharing) getStoredShare(s *xorm.Session) (stored *LinkSharing, err error) {
+	stored = &LinkSharing{}
+	_, err = s.
+		Where("id = ? AND list_id = ?", share.ID, share.ListID).
+		Get(stored)
+	return
 }
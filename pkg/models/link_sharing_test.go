@@ -18,6 +18,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/user"
@@ -60,6 +61,21 @@ func TestLinkSharing_Create(t *testing.T) {
 		assert.Error(t, err)
 		assert.True(t, IsErrInvalidRight(err))
 	})
+	t.Run("invalid ip allowlist", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ListID:      1,
+			Right:       RightRead,
+			IPAllowlist: []string{"10.0.0.0/8", "not an ip"},
+		}
+		err := share.Create(s, doer)
+
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidIPAllowlistEntry(err))
+	})
 	t.Run("password should be hashed", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
@@ -153,3 +169,187 @@ func TestLinkSharing_ReadOne(t *testing.T) {
 		assert.Empty(t, share.Password)
 	})
 }
+
+func TestLinkSharing_Update(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	share := &LinkSharing{
+		ID:          1,
+		ListID:      1,
+		Right:       RightRead,
+		MaxUses:     10,
+		IPAllowlist: []string{"192.168.0.0/16"},
+		IsDisabled:  true,
+	}
+	err := share.Update(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "test", share.Hash)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertExists(t, "link_shares", map[string]interface{}{
+		"id":          1,
+		"max_uses":    10,
+		"is_disabled": true,
+	}, false)
+}
+
+func TestLinkSharing_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	share := &LinkSharing{ID: 1}
+	err := share.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "link_shares", map[string]interface{}{
+		"id": 1,
+	})
+	db.AssertMissing(t, "link_share_access_log", map[string]interface{}{
+		"link_share_id": 1,
+	})
+}
+
+func TestLinkSharing_CheckAccess(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		share := &LinkSharing{ID: 1}
+		assert.NoError(t, share.CheckAccess("127.0.0.1"))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		share := &LinkSharing{ID: 1, IsDisabled: true}
+		assert.True(t, IsErrLinkShareDisabled(share.CheckAccess("127.0.0.1")))
+	})
+	t.Run("expired", func(t *testing.T) {
+		share := &LinkSharing{ID: 1, Expires: time.Now().Add(-time.Hour)}
+		assert.True(t, IsErrLinkShareExpired(share.CheckAccess("127.0.0.1")))
+	})
+	t.Run("not expired yet", func(t *testing.T) {
+		share := &LinkSharing{ID: 1, Expires: time.Now().Add(time.Hour)}
+		assert.NoError(t, share.CheckAccess("127.0.0.1"))
+	})
+	t.Run("ip allowlist", func(t *testing.T) {
+		share := &LinkSharing{ID: 1, IPAllowlist: []string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"}}
+		assert.NoError(t, share.CheckAccess("10.1.2.3"))
+		assert.NoError(t, share.CheckAccess("192.168.1.10"))
+		assert.NoError(t, share.CheckAccess("2001:db8::1"))
+		assert.True(t, IsErrLinkShareIPNotAllowed(share.CheckAccess("192.168.1.11")))
+		assert.True(t, IsErrLinkShareIPNotAllowed(share.CheckAccess("")))
+	})
+}
+
+func TestUseLinkShare(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share, err := GetLinkShareByID(s, 1)
+		assert.NoError(t, err)
+		err = UseLinkShare(s, share, "", "127.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), share.Uses)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":   1,
+			"uses": 1,
+		}, false)
+	})
+	t.Run("usage limit reached", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("max_uses", "uses").Update(&LinkSharing{MaxUses: 2, Uses: 2})
+		assert.NoError(t, err)
+
+		share, err := GetLinkShareByID(s, 1)
+		assert.NoError(t, err)
+		err = UseLinkShare(s, share, "", "127.0.0.1")
+		assert.Error(t, err)
+		assert.True(t, IsErrLinkShareUsageLimitReached(err))
+	})
+	t.Run("usage limit reached concurrently", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("max_uses").Update(&LinkSharing{MaxUses: 1})
+		assert.NoError(t, err)
+
+		// Both requests loaded the share before either of them used it
+		first, err := GetLinkShareByID(s, 1)
+		assert.NoError(t, err)
+		second, err := GetLinkShareByID(s, 1)
+		assert.NoError(t, err)
+
+		err = UseLinkShare(s, first, "", "127.0.0.1")
+		assert.NoError(t, err)
+		err = UseLinkShare(s, second, "", "127.0.0.1")
+		assert.Error(t, err)
+		assert.True(t, IsErrLinkShareUsageLimitReached(err))
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":   1,
+			"uses": 1,
+		}, false)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share, err := GetLinkShareByID(s, 4)
+		assert.NoError(t, err)
+		err = UseLinkShare(s, share, "wrong", "127.0.0.1")
+		assert.Error(t, err)
+		assert.True(t, IsErrLinkSharePasswordInvalid(err))
+		assert.Equal(t, int64(0), share.Uses)
+	})
+}
+
+func TestLinkShareAccessLog_ReadAll(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := LogLinkShareAccess(s, &LinkSharing{ID: 1}, "10.0.0.1", "curl", true)
+		assert.NoError(t, err)
+
+		al := &LinkShareAccessLog{LinkShareID: 1, ListID: 1}
+		result, _, total, err := al.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		assert.NoError(t, err)
+		entries := result.([]*LinkShareAccessLog)
+		assert.Len(t, entries, 3)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, "10.0.0.1", entries[0].IP)
+	})
+	t.Run("wrong list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		al := &LinkShareAccessLog{LinkShareID: 1, ListID: 2}
+		_, _, _, err := al.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		assert.Error(t, err)
+		assert.True(t, IsErrListShareDoesNotExist(err))
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		al := &LinkShareAccessLog{LinkShareID: 1, ListID: 1}
+		_, _, _, err := al.ReadAll(s, &user.User{ID: 2}, "", 1, 50)
+		assert.Error(t, err)
+	})
+}
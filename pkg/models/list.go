@@ -77,6 +77,13 @@ type List struct {
 	// A timestamp when this list was last updated. You cannot change this value.
 	Updated time.Time `xorm:"updated not null" json:"updated"`
 
+	// A timestamp when this list was moved to the trash. Zero if it is not in the trash.
+	Deleted time.Time `xorm:"deleted null INDEX" json:"-"`
+	// The user who moved this list to the trash.
+	DeletedByID int64 `xorm:"bigint null" json:"-"`
+	// Whether this list was moved to the trash together with its namespace.
+	DeletedWithParent bool `xorm:"null" json:"-"`
+
 	web.CRUDable `xorm:"-" json:"-"`
 	web.Rights   `xorm:"-" json:"-"`
 }
@@ -351,12 +358,18 @@ type listOptions struct {
 	isArchived bool
 }
 
-func getUserListsStatement(userID int64) *builder.Builder {
+func getUserListsStatement(s *xorm.Session, userID int64) (*builder.Builder, error) {
 	dialect := config.DatabaseType.GetString()
 	if dialect == "sqlite" {
 		dialect = builder.SQLITE
 	}
 
+	// Lists in sub namespaces inherit the shares of their parents
+	_, subNamespaceIDs, err := getNamespaceIDsForUser(s, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	return builder.Dialect(dialect).
 		Select("l.*").
 		From("lists", "l").
@@ -373,9 +386,10 @@ func getUserListsStatement(userID int64) *builder.Builder {
 			builder.Eq{"ul.user_id": userID},
 			builder.Eq{"un.user_id": userID},
 			builder.Eq{"l.owner_id": userID},
+			builder.In("l.namespace_id", subNamespaceIDs),
 		)).
 		OrderBy("position").
-		GroupBy("l.id")
+		GroupBy("l.id"), nil
 }
 
 // Gets the lists only, without any tasks or so
@@ -418,7 +432,11 @@ func getRawListsForUser(s *xorm.Session, opts *listOptions) (lists []*List, resu
 	// Gets all Lists where the user is either owner or in a team which has access to the list
 	// Or in a team which has namespace read access
 
-	query := getUserListsStatement(fullUser.ID).
+	query, err := getUserListsStatement(s, fullUser.ID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	query = query.
 		Where(filterCond).
 		Where(isArchivedCond)
 	if limit > 0 {
@@ -429,7 +447,11 @@ func getRawListsForUser(s *xorm.Session, opts *listOptions) (lists []*List, resu
 		return nil, 0, 0, err
 	}
 
-	query = getUserListsStatement(fullUser.ID).
+	query, err = getUserListsStatement(s, fullUser.ID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	query = query.
 		Where(filterCond).
 		Where(isArchivedCond)
 	totalItems, err = s.
@@ -647,6 +669,17 @@ func UpdateList(s *xorm.Session, list *List, auth web.Auth, updateListBackground
 		}
 	}
 
+	original, err := GetListSimpleByID(s, list.ID)
+	if err != nil {
+		return err
+	}
+	if original.NamespaceID != list.NamespaceID {
+		err = moveListBoardBuckets(s, list.ID, original.NamespaceID, list.NamespaceID)
+		if err != nil {
+			return err
+		}
+	}
+
 	// We need to specify the cols we want to update here to be able to un-archive lists
 	colsToUpdate := []string{
 		"title",
@@ -783,7 +816,7 @@ func (l *List) Create(s *xorm.Session, a web.Auth) (err error) {
 
 // Delete implements the delete method of CRUDable
 // @Summary Deletes a list
-// @Description Delets a list
+// @Description Moves a list with all of its tasks to the trash. The list can be restored from the trash until it is purged after the configured retention period.
 // @tags list
 // @Produce json
 // @Security JWTKeyAuth
@@ -795,30 +828,59 @@ func (l *List) Create(s *xorm.Session, a web.Auth) (err error) {
 // @Router /lists/{id} [delete]
 func (l *List) Delete(s *xorm.Session, a web.Auth) (err error) {
 
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	err = trashList(s, l, doer.ID, false)
+	if err != nil {
+		return
+	}
+
+	return events.Dispatch(&ListDeletedEvent{
+		List: l,
+		Doer: a,
+	})
+}
+
+// purge permanently removes a list with all of its tasks, no matter if they are in the trash or not.
+func (l *List) purge(s *xorm.Session) (err error) {
+
 	// Delete the list
-	_, err = s.ID(l.ID).Delete(&List{})
+	_, err = s.Unscoped().ID(l.ID).Delete(&List{})
 	if err != nil {
 		return
 	}
 
 	// Delete all tasks on that list
 	// Using the loop to make sure all related entities to all tasks are properly deleted as well.
-	tasks, _, _, err := getRawTasksForLists(s, []*List{l}, a, &taskOptions{})
+	tasks := []*Task{}
+	err = s.Unscoped().Where("list_id = ?", l.ID).Find(&tasks)
 	if err != nil {
 		return
 	}
 
 	for _, task := range tasks {
-		err = task.Delete(s, a)
+		err = task.purge(s)
 		if err != nil {
 			return err
 		}
 	}
 
-	return events.Dispatch(&ListDeletedEvent{
-		List: l,
-		Doer: a,
-	})
+	err = deleteShareInvitations(s, &ShareInvitation{ListID: l.ID})
+	if err != nil {
+		return err
+	}
+
+	err = deleteTaskStatusesForList(s, l.ID)
+	if err != nil {
+		return err
+	}
+
+	// Delete the redirects from identifiers of tasks which were moved away from the list
+	_, err = s.Where("list_id = ?", l.ID).Delete(&TaskIdentifierRedirect{})
+	return
 }
 
 // SetListBackground sets a background file as list background in the db
@@ -0,0 +1,171 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strings"
+
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// ListMove represents a move of a list to another namespace.
+type ListMove struct {
+	// The id of the list to move.
+	ListID int64 `json:"-" param:"list"`
+	// The namespace the list should be moved to.
+	NamespaceID int64 `json:"namespace_id"`
+
+	// The list after it was moved.
+	List *List `json:"list"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanUpdate checks if a user can move a list. The user needs to be admin of the list and have write access to the
+// new namespace.
+func (m *ListMove) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	l := &List{ID: m.ListID}
+	is, err := l.IsAdmin(s, a)
+	if err != nil || !is {
+		return is, err
+	}
+
+	n := &Namespace{ID: m.NamespaceID}
+	return n.CanWrite(s, a)
+}
+
+// Update moves a list to another namespace
+// @Summary Move a list to another namespace
+// @Description Moves a list with all of its tasks to another namespace. Tasks which are in a bucket on the kanban board of the old namespace are put in the bucket with the same title on the board of the new namespace. The user needs to be admin of the list and have write access to the new namespace.
+// @tags list
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "List ID"
+// @Param move body models.ListMove true "Where to move the list to"
+// @Success 200 {object} models.ListMove "The move including the updated list."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list or the namespace."
+// @Failure 412 {object} web.HTTPError "The list or the namespace is archived or the namespace is a pseudo namespace."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{id}/move [post]
+func (m *ListMove) Update(s *xorm.Session, a web.Auth) (err error) {
+	l, err := GetListSimpleByID(s, m.ListID)
+	if err != nil {
+		return err
+	}
+
+	if m.NamespaceID < 0 {
+		return &ErrListCannotBelongToAPseudoNamespace{ListID: l.ID, NamespaceID: m.NamespaceID}
+	}
+
+	if err := l.CheckIsArchived(s); err != nil {
+		return err
+	}
+
+	n, err := getNamespaceSimpleByID(s, m.NamespaceID)
+	if err != nil {
+		return err
+	}
+	if err := n.CheckIsArchived(s); err != nil {
+		return err
+	}
+
+	m.List = l
+	if l.NamespaceID == n.ID {
+		return nil
+	}
+
+	err = moveListBoardBuckets(s, l.ID, l.NamespaceID, n.ID)
+	if err != nil {
+		return err
+	}
+
+	l.NamespaceID = n.ID
+	_, err = s.
+		ID(l.ID).
+		Cols("namespace_id").
+		Update(l)
+	if err != nil {
+		return err
+	}
+
+	return events.Dispatch(&ListUpdatedEvent{
+		List: l,
+		Doer: a,
+	})
+}
+
+// moveListBoardBuckets puts the tasks of a list which is moved to another namespace in the matching buckets on the
+// kanban board of the new namespace. The done bucket matches the done bucket, all other buckets the bucket with the
+// same title. Tasks without a matching bucket show up in the default bucket of the new board.
+func moveListBoardBuckets(s *xorm.Session, listID, oldNamespaceID, newNamespaceID int64) (err error) {
+	oldBuckets := make(map[int64]*Bucket)
+	err = s.Where("namespace_id = ?", oldNamespaceID).Find(&oldBuckets)
+	if err != nil || len(oldBuckets) == 0 {
+		return err
+	}
+
+	newBuckets := []*Bucket{}
+	err = s.
+		Where("namespace_id = ?", newNamespaceID).
+		OrderBy("position asc").
+		Find(&newBuckets)
+	if err != nil {
+		return err
+	}
+
+	oldBucketIDs := make([]int64, 0, len(oldBuckets))
+	for id := range oldBuckets {
+		oldBucketIDs = append(oldBucketIDs, id)
+	}
+
+	taskBuckets := []*TaskBucket{}
+	err = s.
+		In("bucket_id", oldBucketIDs).
+		In("task_id", builder.Select("id").From("tasks").Where(builder.Eq{"list_id": listID})).
+		Find(&taskBuckets)
+	if err != nil {
+		return err
+	}
+
+	for _, tb := range taskBuckets {
+		old := oldBuckets[tb.BucketID]
+		var newBucketID int64
+		for _, b := range newBuckets {
+			if (old.IsDoneBucket && b.IsDoneBucket) || (!old.IsDoneBucket && strings.EqualFold(b.Title, old.Title)) {
+				newBucketID = b.ID
+				break
+			}
+		}
+
+		if newBucketID == 0 {
+			_, err = s.ID(tb.ID).Delete(&TaskBucket{})
+		} else {
+			tb.BucketID = newBucketID
+			_, err = s.ID(tb.ID).Cols("bucket_id").Update(tb)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
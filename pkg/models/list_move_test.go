@@ -0,0 +1,125 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListMove_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Namespace 11 is shared with user 1 with write access
+		m := &ListMove{ListID: 1, NamespaceID: 11}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), m.List.NamespaceID)
+
+		db.AssertExists(t, "lists", map[string]interface{}{
+			"id":           1,
+			"namespace_id": 11,
+		}, false)
+		// Namespace 11 has no bucket matching bucket 37 of namespace 1
+		db.AssertMissing(t, "task_buckets", map[string]interface{}{
+			"task_id": 1,
+		})
+		// The identifiers of the tasks don't change
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":      1,
+			"list_id": 1,
+			"index":   1,
+		}, false)
+	})
+	t.Run("matching board bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{Title: "TESTBUCKET37", NamespaceID: 11, CreatedByID: 1}
+		_, err := s.Insert(b)
+		assert.NoError(t, err)
+
+		m := &ListMove{ListID: 1, NamespaceID: 11}
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_buckets", map[string]interface{}{
+			"task_id":   1,
+			"bucket_id": b.ID,
+		}, false)
+	})
+	t.Run("no access to the new namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &ListMove{ListID: 1, NamespaceID: 6}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("no admin rights on the list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// User 1 has write access to namespace 11 and its lists
+		m := &ListMove{ListID: 16, NamespaceID: 1}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("pseudo namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &ListMove{ListID: 1, NamespaceID: SharedListsPseudoNamespace.ID}
+		err := m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrListCannotBelongToAPseudoNamespace(err))
+	})
+	t.Run("archived namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(11).Cols("is_archived").Update(&Namespace{IsArchived: true})
+		assert.NoError(t, err)
+
+		m := &ListMove{ListID: 1, NamespaceID: 11}
+		err = m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrNamespaceIsArchived(err))
+	})
+}
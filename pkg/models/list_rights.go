@@ -193,6 +193,105 @@ func (l *List) IsAdmin(s *xorm.Session, a web.Auth) (bool, error) {
 	return is, err
 }
 
+// checkPermission checks if the user can do something on a list. Everyone with write access can do everything,
+// everyone else needs a role which grants the permission.
+func (l *List) checkPermission(s *xorm.Session, a web.Auth, p Permission) (bool, error) {
+	can, err := l.CanWrite(s, a)
+	if err != nil || can {
+		return can, err
+	}
+
+	return l.hasRolePermission(s, a, p)
+}
+
+// canShare checks if the user can share the list with the given right and role. Users who are only allowed to share
+// because of their role can't give anyone a right or permission they don't have themselves.
+func (l *List) canShare(s *xorm.Session, a web.Auth, right Right, roleID int64) (bool, error) {
+	is, err := l.IsAdmin(s, a)
+	if err != nil || is {
+		return is, err
+	}
+
+	can, err := l.hasRolePermission(s, a, PermissionShare)
+	if err != nil || !can {
+		return can, err
+	}
+
+	canWrite, _, err := l.checkRight(s, a, RightWrite)
+	if err != nil {
+		return false, err
+	}
+	maxRight := RightRead
+	if canWrite {
+		maxRight = RightWrite
+	}
+
+	return canGrantShare(s, right, roleID, maxRight, func(p Permission) (bool, error) {
+		return l.checkPermission(s, a, p)
+	})
+}
+
+// hasRolePermission checks if any share of the list or its namespace gives the user a role with the permission.
+func (l *List) hasRolePermission(s *xorm.Session, a web.Auth, p Permission) (bool, error) {
+	roles := rolesWithPermission(p)
+
+	if shareAuth, is := a.(*LinkSharing); is {
+		return s.
+			Where(builder.And(
+				builder.Eq{"id": shareAuth.ID},
+				builder.Eq{"list_id": l.ID},
+				builder.In("role_id", roles),
+			)).
+			Exist(&LinkSharing{})
+	}
+
+	has, err := s.
+		Table([]string{"lists", "l"}).
+		Join("LEFT", []string{"users_namespaces", "un"}, "un.namespace_id = l.namespace_id").
+		Join("LEFT", []string{"users_lists", "ul"}, "ul.list_id = l.id").
+		Join("LEFT", []string{"team_namespaces", "tn"}, "l.namespace_id = tn.namespace_id").
+		Join("LEFT", []string{"team_members", "tm"}, "tm.team_id = tn.team_id").
+		Join("LEFT", []string{"team_lists", "tl"}, "l.id = tl.list_id").
+		Join("LEFT", []string{"team_members", "tm2"}, "tm2.team_id = tl.team_id").
+		Where(builder.And(
+			builder.Or(
+				builder.And(builder.Eq{"ul.user_id": a.GetID()}, builder.In("ul.role_id", roles)),
+				builder.And(builder.Eq{"un.user_id": a.GetID()}, builder.In("un.role_id", roles)),
+				builder.And(builder.Eq{"tm2.user_id": a.GetID()}, builder.In("tl.role_id", roles)),
+				builder.And(builder.Eq{"tm.user_id": a.GetID()}, builder.In("tn.role_id", roles)),
+			),
+			builder.Eq{"l.id": l.ID},
+		)).
+		Exist()
+	if err != nil || has {
+		return has, err
+	}
+
+	ancestorIDs, err := l.getNamespaceAncestorIDs(s)
+	if err != nil || len(ancestorIDs) == 0 {
+		return false, err
+	}
+
+	parent := &Namespace{ID: ancestorIDs[0]}
+	return parent.hasRolePermission(s, a, p)
+}
+
+// getNamespaceAncestorIDs returns the ids of all parents of the namespace the list belongs to.
+// Rights on these namespaces are inherited by the list.
+func (l *List) getNamespaceAncestorIDs(s *xorm.Session) ([]int64, error) {
+	var namespaceID int64
+	_, err := s.
+		Table("lists").
+		Cols("namespace_id").
+		Where("id = ?", l.ID).
+		Get(&namespaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getNamespaceAncestorIDs(s, namespaceID)
+}
+
 // Little helper function to check if a user is list owner
 func (l *List) isOwner(u *user.User) bool {
 	return l.OwnerID == u.ID
@@ -291,6 +390,22 @@ func (l *List) checkRight(s *xorm.Session, a web.Auth, rights ...Right) (bool, i
 	if r.NamespaceOwnerID == a.GetID() {
 		maxRight = int(RightAdmin)
 	}
+	if err != nil || maxRight == int(RightAdmin) {
+		return exists, maxRight, err
+	}
+
+	ancestorIDs, err := l.getNamespaceAncestorIDs(s)
+	if err != nil {
+		return false, 0, err
+	}
+
+	inherited, inheritedRight, err := checkInheritedNamespaceRight(s, a, ancestorIDs, rights...)
+	if err != nil {
+		return false, 0, err
+	}
+	if inheritedRight > maxRight {
+		maxRight = inheritedRight
+	}
 
-	return exists, maxRight, err
+	return exists || inherited, maxRight, nil
 }
@@ -21,27 +21,53 @@ import (
 	"xorm.io/xorm"
 )
 
-// CanCreate checks if the user can create a team <-> list relation
+// CanCreate checks if the user can create a new team <-> list relation
 func (tl *TeamList) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	return tl.canDoTeamList(s, a)
+	return tl.canDoTeamList(s, a, tl.Right, tl.RoleID)
 }
 
 // CanDelete checks if the user can delete a team <-> list relation
 func (tl *TeamList) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
-	return tl.canDoTeamList(s, a)
+	stored, err := tl.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return tl.canDoTeamList(s, a, stored.Right, stored.RoleID)
 }
 
 // CanUpdate checks if the user can update a team <-> list relation
 func (tl *TeamList) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
-	return tl.canDoTeamList(s, a)
+	can, err := tl.canDoTeamList(s, a, tl.Right, tl.RoleID)
+	if err != nil || !can {
+		return can, err
+	}
+
+	// Whoever changes a share must be allowed to give the right it had before as well
+	stored, err := tl.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return tl.canDoTeamList(s, a, stored.Right, stored.RoleID)
 }
 
-func (tl *TeamList) canDoTeamList(s *xorm.Session, a web.Auth) (bool, error) {
+func (tl *TeamList) canDoTeamList(s *xorm.Session, a web.Auth, right Right, roleID int64) (bool, error) {
 	// Link shares aren't allowed to do anything
 	if _, is := a.(*LinkSharing); is {
 		return false, nil
 	}
 
 	l := List{ID: tl.ListID}
-	return l.IsAdmin(s, a)
+	return l.canShare(s, a, right, roleID)
+}
+
+// getStoredShare returns the share as it is saved right now. If there is none, it returns an empty share
+// so the update or delete can fail with the proper error.
+func (tl *TeamList) getStoredShare(s *xorm.Session) (stored *TeamList, err error) {
+	stored = &TeamList{}
+	_, err = s.
+		Where("list_id = ? AND team_id = ?", tl.ListID, tl.TeamID).
+		Get(stored)
+	return
 }
@@ -0,0 +1,310 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// ListTemplateInstance holds everything needed to create a list or tasks from a list template
+type ListTemplateInstance struct {
+	// The id of the template to use
+	TemplateID int64 `json:"-" param:"template"`
+	// The namespace the new list is created in.
+	NamespaceID int64 `json:"namespace_id,omitempty"`
+	// An existing list the tasks of the template are added to. If set, no new list is created and the buckets of
+	// the template are matched with the buckets of the list by their title.
+	ListID int64 `json:"list_id,omitempty"`
+	// The title of the new list. Defaults to the title of the template. Can contain variables.
+	Title string `json:"title"`
+	// All task dates of the template are relative to this date. Defaults to the beginning of today.
+	StartDate time.Time `json:"start_date"`
+	// The values for all variables of the template, with the variable name as key.
+	Variables map[string]string `json:"variables"`
+	// The users replacing the assignee placeholders of the template, with the placeholder as key and the user id
+	// as value. Placeholders without a user are not assigned.
+	Assignees map[string]int64 `json:"assignees"`
+
+	// The list created from the template or the existing list the tasks were added to
+	List *List `json:"list,omitempty"`
+
+	web.Rights   `json:"-"`
+	web.CRUDable `json:"-"`
+}
+
+// CanCreate checks if a user can use a list template. The user needs access to the template and write access to
+// the namespace of the new list or the existing list.
+func (ti *ListTemplateInstance) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	lt := &ListTemplate{ID: ti.TemplateID}
+	canRead, _, err := lt.CanRead(s, a)
+	if err != nil || !canRead {
+		return canRead, err
+	}
+
+	if ti.ListID != 0 {
+		l := &List{ID: ti.ListID}
+		return l.CanWrite(s, a)
+	}
+
+	l := &List{NamespaceID: ti.NamespaceID}
+	return l.CanCreate(s, a)
+}
+
+// Create uses a list template
+// @Summary Use a list template
+// @Description Creates a new list with all buckets and tasks of the template in a namespace or adds the tasks of the template to an existing list. All task dates are moved relative to the start date, all variables are replaced with the provided values and all assignee placeholders with the provided users. Labels and assignees without access are skipped.
+// @tags list
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Template ID"
+// @Param instance body models.ListTemplateInstance true "The target namespace or list, the start date, variables and assignees."
+// @Success 201 {object} models.ListTemplateInstance "The list created from the template."
+// @Failure 400 {object} web.HTTPError "A variable is missing a value."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the template, the namespace or the list."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/{id}/instantiate [put]
+func (ti *ListTemplateInstance) Create(s *xorm.Session, a web.Auth) (err error) {
+	lt, err := getListTemplateByID(s, ti.TemplateID)
+	if err != nil {
+		return err
+	}
+	lt.setVariablesAndPlaceholders()
+
+	err = lt.checkVariables(ti.Variables)
+	if err != nil {
+		return err
+	}
+
+	if ti.StartDate.IsZero() {
+		now := time.Now()
+		ti.StartDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	}
+
+	// Template bucket id as key, id of the bucket in the list as value
+	var bucketMap map[int64]int64
+	if ti.ListID != 0 {
+		ti.List, err = GetListSimpleByID(s, ti.ListID)
+		if err != nil {
+			return err
+		}
+		bucketMap, err = ti.matchTemplateBuckets(s, lt)
+	} else {
+		bucketMap, err = ti.createListFromTemplate(s, a, lt)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ti.createTasksFromTemplate(s, a, lt, bucketMap)
+}
+
+func (ti *ListTemplateInstance) createListFromTemplate(s *xorm.Session, a web.Auth, lt *ListTemplate) (bucketMap map[int64]int64, err error) {
+	title := ti.Title
+	if title == "" {
+		title = lt.Title
+	}
+
+	ti.List = &List{
+		Title:       replaceListTemplateVariables(title, ti.Variables),
+		Description: replaceListTemplateVariables(lt.Description, ti.Variables),
+		HexColor:    lt.HexColor,
+		NamespaceID: ti.NamespaceID,
+	}
+	err = CreateList(s, ti.List, a)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketMap = make(map[int64]int64, len(lt.Buckets))
+	if len(lt.Buckets) == 0 {
+		return bucketMap, nil
+	}
+
+	// The template buckets replace the default bucket of the new list
+	_, err = s.Where("list_id = ?", ti.List.ID).Delete(&Bucket{})
+	if err != nil {
+		return nil, err
+	}
+
+	// The first bucket created is the default bucket of the list, it has to be the first one by position.
+	buckets := make([]*ListTemplateBucket, len(lt.Buckets))
+	copy(buckets, lt.Buckets)
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].Position < buckets[j].Position
+	})
+
+	for _, tb := range buckets {
+		b := &Bucket{
+			ListID:       ti.List.ID,
+			Title:        replaceListTemplateVariables(tb.Title, ti.Variables),
+			Limit:        tb.Limit,
+			IsDoneBucket: tb.IsDoneBucket,
+			Position:     tb.Position,
+		}
+		err = b.Create(s, a)
+		if err != nil {
+			return nil, err
+		}
+		if !tb.IsDoneBucket {
+			bucketMap[tb.ID] = b.ID
+		}
+	}
+
+	return bucketMap, nil
+}
+
+// Maps the buckets of the template to the buckets of an existing list with the same title.
+func (ti *ListTemplateInstance) matchTemplateBuckets(s *xorm.Session, lt *ListTemplate) (bucketMap map[int64]int64, err error) {
+	buckets := []*Bucket{}
+	err = s.
+		Where("list_id = ?", ti.List.ID).
+		OrderBy("position asc").
+		Find(&buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketMap = make(map[int64]int64, len(lt.Buckets))
+	for _, tb := range lt.Buckets {
+		if tb.IsDoneBucket {
+			continue
+		}
+		title := replaceListTemplateVariables(tb.Title, ti.Variables)
+		for _, b := range buckets {
+			if !b.IsDoneBucket && strings.EqualFold(b.Title, title) {
+				bucketMap[tb.ID] = b.ID
+				break
+			}
+		}
+	}
+
+	return bucketMap, nil
+}
+
+func (ti *ListTemplateInstance) getTemplateDate(offset *int64) time.Time {
+	if offset == nil {
+		return time.Time{}
+	}
+	return ti.StartDate.Add(time.Duration(*offset) * time.Second)
+}
+
+//nolint:gocyclo
+func (ti *ListTemplateInstance) createTasksFromTemplate(s *xorm.Session, a web.Auth, lt *ListTemplate, bucketMap map[int64]int64) (err error) {
+	// Template task id as key, id of the created task as value
+	taskMap := make(map[int64]int64, len(lt.Tasks))
+	for _, tt := range lt.Tasks {
+		t := &Task{
+			Title:          replaceListTemplateVariables(tt.Title, ti.Variables),
+			Description:    replaceListTemplateVariables(tt.Description, ti.Variables),
+			Priority:       tt.Priority,
+			HexColor:       tt.HexColor,
+			RepeatAfter:    tt.RepeatAfter,
+			RepeatMode:     tt.RepeatMode,
+			Position:       tt.Position,
+			KanbanPosition: tt.KanbanPosition,
+			ListID:         ti.List.ID,
+			// Tasks of the done bucket end up in the default bucket since all tasks start undone
+			BucketID:  bucketMap[tt.BucketID],
+			DueDate:   ti.getTemplateDate(tt.DueDateOffset),
+			StartDate: ti.getTemplateDate(tt.StartDateOffset),
+			EndDate:   ti.getTemplateDate(tt.EndDateOffset),
+			Reminders: make([]time.Time, 0, len(tt.ReminderOffsets)),
+		}
+		for _, offset := range tt.ReminderOffsets {
+			o := offset
+			t.Reminders = append(t.Reminders, ti.getTemplateDate(&o))
+		}
+
+		err = createTask(s, t, a, false)
+		if err != nil {
+			return err
+		}
+		taskMap[tt.ID] = t.ID
+	}
+
+	// Only add labels the user has access to
+	labelAccess := make(map[int64]bool)
+	for _, tt := range lt.Tasks {
+		for _, labelID := range tt.LabelIDs {
+			has, checked := labelAccess[labelID]
+			if !checked {
+				l := &Label{ID: labelID}
+				has, _, err = l.hasAccessToLabel(s, a)
+				if err != nil {
+					return err
+				}
+				labelAccess[labelID] = has
+			}
+			if !has {
+				continue
+			}
+			_, err = s.Insert(&LabelTask{TaskID: taskMap[tt.ID], LabelID: labelID})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Only assign users who have access to the list
+	for _, tt := range lt.Tasks {
+		for _, placeholder := range tt.Assignees {
+			userID, exists := ti.Assignees[placeholder]
+			if !exists || userID == 0 {
+				continue
+			}
+			t := &Task{ID: taskMap[tt.ID], ListID: ti.List.ID}
+			err = t.addNewAssigneeByID(s, userID, ti.List, a)
+			if err != nil {
+				if IsErrUserDoesNotHaveAccessToList(err) || IsErrUserAlreadyAssigned(err) {
+					continue
+				}
+				return err
+			}
+		}
+	}
+
+	// Creating a relation creates the other direction as well, which may already be part of the template.
+	for _, tt := range lt.Tasks {
+		for _, r := range tt.Relations {
+			otherTaskID, exists := taskMap[r.OtherTaskID]
+			if !exists {
+				continue
+			}
+			rel := &TaskRelation{
+				TaskID:       taskMap[tt.ID],
+				OtherTaskID:  otherTaskID,
+				RelationKind: r.RelationKind,
+			}
+			err = rel.Create(s, a)
+			if err != nil {
+				if IsErrRelationAlreadyExists(err) {
+					continue
+				}
+				return err
+			}
+		}
+	}
+
+	return nil
+}
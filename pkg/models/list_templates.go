@@ -0,0 +1,635 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// ListTemplateVisibility defines who can see and use a list template.
+type ListTemplateVisibility string
+
+// All valid list template visibilities
+const (
+	// Only the owner of the template can see and use it.
+	ListTemplateVisibilityPrivate ListTemplateVisibility = `private`
+	// All members of the template's team can see and use it.
+	ListTemplateVisibilityTeam ListTemplateVisibility = `team`
+	// Everyone on this instance can see and use it.
+	ListTemplateVisibilityInstance ListTemplateVisibility = `instance`
+)
+
+func (v ListTemplateVisibility) isValid() bool {
+	return v == ListTemplateVisibilityPrivate ||
+		v == ListTemplateVisibilityTeam ||
+		v == ListTemplateVisibilityInstance
+}
+
+// ListTemplate is a reusable blueprint of a list with its buckets and tasks.
+type ListTemplate struct {
+	// The unique, numeric id of this template.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"template"`
+	// The title of the template. Lists created from this template get this title unless another one is provided.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The description of the template. Lists created from this template get it as description.
+	Description string `xorm:"longtext null" json:"description"`
+	// The hex color lists created from this template get.
+	HexColor string `xorm:"varchar(6) null" json:"hex_color" valid:"runelength(0|6)" maxLength:"6"`
+
+	// The list to save as a template. Only used when creating a template, changes to the list afterwards are not
+	// reflected in the template.
+	ListID int64 `xorm:"-" json:"list_id,omitempty"`
+	// If true, the done tasks of the list are saved in the template as well. Only used together with list_id.
+	IncludeDoneTasks bool `xorm:"-" json:"include_done_tasks,omitempty"`
+
+	// Who can see and use this template. One of private, team or instance.
+	Visibility ListTemplateVisibility `xorm:"varchar(10) not null default 'private'" json:"visibility"`
+	// The team this template is shared with. Only used if the visibility is team.
+	TeamID int64 `xorm:"bigint null INDEX" json:"team_id"`
+
+	// The kanban buckets of the template.
+	Buckets []*ListTemplateBucket `xorm:"JSON null" json:"buckets"`
+	// The tasks of the template.
+	Tasks []*ListTemplateTask `xorm:"JSON null" json:"tasks"`
+
+	// All variables used in the template as {{name}}. Each of them needs a value when the template is used.
+	Variables []string `xorm:"-" json:"variables"`
+	// All assignee placeholders used in the tasks of the template. Each of them can be replaced with a user when the template is used.
+	AssigneePlaceholders []string `xorm:"-" json:"assignee_placeholders"`
+
+	OwnerID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The user who owns this template.
+	Owner *user.User `xorm:"-" json:"owner" valid:"-"`
+
+	// A timestamp when this template was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this template was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for list templates
+func (ListTemplate) TableName() string {
+	return "list_templates"
+}
+
+// ListTemplateBucket is a kanban bucket of a list template.
+type ListTemplateBucket struct {
+	// The id of the bucket inside the template. Tasks use it to reference their bucket.
+	ID int64 `json:"id"`
+	// The title of the bucket. Can contain variables.
+	Title string `json:"title"`
+	// How many tasks can be at the same time in this bucket.
+	Limit int64 `json:"limit"`
+	// If this bucket is the "done bucket" of the list.
+	IsDoneBucket bool `json:"is_done_bucket"`
+	// The position of the bucket.
+	Position float64 `json:"position"`
+}
+
+// ListTemplateTaskRelation is a relation between two tasks of a list template.
+type ListTemplateTaskRelation struct {
+	// The template id of the other task.
+	OtherTaskID int64 `json:"other_task_id"`
+	// The kind of the relation, seen from the task holding the relation.
+	RelationKind RelationKind `json:"relation_kind"`
+}
+
+// ListTemplateTask is a task of a list template. All dates are stored as offsets in seconds relative to the start
+// date the template is used with.
+type ListTemplateTask struct {
+	// The id of the task inside the template. Relations use it to reference other tasks.
+	ID int64 `json:"id"`
+	// The title of the task. Can contain variables.
+	Title string `json:"title"`
+	// The description of the task. Can contain variables.
+	Description string `json:"description"`
+	// The priority of the task.
+	Priority int64 `json:"priority"`
+	// The hex color of the task.
+	HexColor string `json:"hex_color"`
+	// The task repeat interval in seconds.
+	RepeatAfter int64 `json:"repeat_after"`
+	// The repeat mode of the task.
+	RepeatMode TaskRepeatMode `json:"repeat_mode"`
+	// The position of the task in the list.
+	Position float64 `json:"position"`
+	// The position of the task in its kanban bucket.
+	KanbanPosition float64 `json:"kanban_position"`
+	// The template id of the bucket this task belongs to.
+	BucketID int64 `json:"bucket_id"`
+
+	// The due date of the task in seconds after the start date. Null if the task has no due date.
+	DueDateOffset *int64 `json:"due_date_offset"`
+	// The start date of the task in seconds after the start date. Null if the task has no start date.
+	StartDateOffset *int64 `json:"start_date_offset"`
+	// The end date of the task in seconds after the start date. Null if the task has no end date.
+	EndDateOffset *int64 `json:"end_date_offset"`
+	// The reminders of the task in seconds after the start date.
+	ReminderOffsets []int64 `json:"reminder_offsets"`
+
+	// The ids of the labels the task gets.
+	LabelIDs []int64 `json:"label_ids"`
+	// The assignee placeholders of the task.
+	Assignees []string `json:"assignees"`
+	// The relations of this task to other tasks of the template. Subtasks are modeled with this.
+	Relations []*ListTemplateTaskRelation `json:"relations"`
+}
+
+var listTemplateVariableRegex = regexp.MustCompile(`{{\s*([\w-]+)\s*}}`)
+
+func addListTemplateVariablesFromText(vars map[string]bool, text string) {
+	for _, match := range listTemplateVariableRegex.FindAllStringSubmatch(text, -1) {
+		vars[match[1]] = true
+	}
+}
+
+// Fills the computed variables and assignee placeholders of the template.
+func (lt *ListTemplate) setVariablesAndPlaceholders() {
+	vars := make(map[string]bool)
+	placeholders := make(map[string]bool)
+
+	addListTemplateVariablesFromText(vars, lt.Title)
+	addListTemplateVariablesFromText(vars, lt.Description)
+	for _, b := range lt.Buckets {
+		addListTemplateVariablesFromText(vars, b.Title)
+	}
+	for _, t := range lt.Tasks {
+		addListTemplateVariablesFromText(vars, t.Title)
+		addListTemplateVariablesFromText(vars, t.Description)
+		for _, a := range t.Assignees {
+			placeholders[a] = true
+		}
+	}
+
+	lt.Variables = make([]string, 0, len(vars))
+	for v := range vars {
+		lt.Variables = append(lt.Variables, v)
+	}
+	sort.Strings(lt.Variables)
+
+	lt.AssigneePlaceholders = make([]string, 0, len(placeholders))
+	for p := range placeholders {
+		lt.AssigneePlaceholders = append(lt.AssigneePlaceholders, p)
+	}
+	sort.Strings(lt.AssigneePlaceholders)
+}
+
+func (lt *ListTemplate) validate(s *xorm.Session, a web.Auth) (err error) {
+	if lt.Visibility == "" {
+		lt.Visibility = ListTemplateVisibilityPrivate
+	}
+	if !lt.Visibility.isValid() {
+		return &ErrInvalidListTemplateVisibility{Visibility: lt.Visibility, TeamID: lt.TeamID}
+	}
+	if lt.Visibility != ListTemplateVisibilityTeam {
+		lt.TeamID = 0
+		return nil
+	}
+	if lt.TeamID == 0 {
+		return &ErrInvalidListTemplateVisibility{Visibility: lt.Visibility, TeamID: lt.TeamID}
+	}
+
+	// Only members of a team can share a template with it
+	isMember, err := isListTemplateTeamMember(s, lt.TeamID, a.GetID())
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrGenericForbidden{}
+	}
+
+	return nil
+}
+
+// Keeps only relations between tasks which exist in the template.
+func (lt *ListTemplate) cleanTaskRelations() error {
+	taskIDs := make(map[int64]bool, len(lt.Tasks))
+	for _, t := range lt.Tasks {
+		taskIDs[t.ID] = true
+	}
+
+	for _, t := range lt.Tasks {
+		relations := make([]*ListTemplateTaskRelation, 0, len(t.Relations))
+		for _, r := range t.Relations {
+			if !r.RelationKind.isValid() {
+				return ErrInvalidRelationKind{Kind: r.RelationKind}
+			}
+			if !taskIDs[r.OtherTaskID] || r.OtherTaskID == t.ID {
+				continue
+			}
+			relations = append(relations, r)
+		}
+		t.Relations = relations
+	}
+
+	return nil
+}
+
+func getEarliestTaskDate(tasks []*Task) (earliest time.Time) {
+	check := func(d time.Time) {
+		if d.IsZero() {
+			return
+		}
+		if earliest.IsZero() || d.Before(earliest) {
+			earliest = d
+		}
+	}
+	for _, t := range tasks {
+		check(t.StartDate)
+		check(t.DueDate)
+		check(t.EndDate)
+		for _, r := range t.Reminders {
+			check(r)
+		}
+	}
+	return
+}
+
+func getListTemplateDateOffset(base, d time.Time) *int64 {
+	if d.IsZero() {
+		return nil
+	}
+	offset := int64(d.Sub(base).Seconds())
+	return &offset
+}
+
+// Builds the buckets and tasks of the template from an existing list. All dates are stored relative to the
+// beginning of the day of the earliest date in the list, the time of the day is preserved that way.
+func (lt *ListTemplate) fillFromList(s *xorm.Session, a web.Auth) (err error) {
+	list, err := GetListSimpleByID(s, lt.ListID)
+	if err != nil {
+		return err
+	}
+	if lt.Title == "" {
+		lt.Title = list.Title
+	}
+	if lt.Description == "" {
+		lt.Description = list.Description
+	}
+	if lt.HexColor == "" {
+		lt.HexColor = list.HexColor
+	}
+
+	buckets := []*Bucket{}
+	err = s.
+		Where("list_id = ?", lt.ListID).
+		OrderBy("position asc, id asc").
+		Find(&buckets)
+	if err != nil {
+		return err
+	}
+
+	// Bucket id in the list as key, id in the template as value
+	bucketMap := make(map[int64]int64, len(buckets))
+	lt.Buckets = make([]*ListTemplateBucket, 0, len(buckets))
+	for i, b := range buckets {
+		templateBucket := &ListTemplateBucket{
+			ID:           int64(i + 1),
+			Title:        b.Title,
+			Limit:        b.Limit,
+			IsDoneBucket: b.IsDoneBucket,
+			Position:     b.Position,
+		}
+		bucketMap[b.ID] = templateBucket.ID
+		lt.Buckets = append(lt.Buckets, templateBucket)
+	}
+
+	allTasks, _, _, err := getTasksForLists(s, []*List{{ID: lt.ListID}}, a, &taskOptions{
+		sortby: []*sortParam{{sortBy: taskPropertyID, orderBy: orderAscending}},
+	})
+	if err != nil {
+		return err
+	}
+
+	tasks := make([]*Task, 0, len(allTasks))
+	for _, t := range allTasks {
+		if t.Done && !lt.IncludeDoneTasks {
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+
+	base := getEarliestTaskDate(tasks)
+	base = time.Date(base.Year(), base.Month(), base.Day(), 0, 0, 0, 0, base.Location())
+
+	// Task id in the list as key, id in the template as value
+	taskMap := make(map[int64]int64, len(tasks))
+	for i, t := range tasks {
+		taskMap[t.ID] = int64(i + 1)
+	}
+
+	// The done state is not part of the template, all tasks start undone when the template is used.
+	lt.Tasks = make([]*ListTemplateTask, 0, len(tasks))
+	for _, t := range tasks {
+		templateTask := &ListTemplateTask{
+			ID:              taskMap[t.ID],
+			Title:           t.Title,
+			Description:     t.Description,
+			Priority:        t.Priority,
+			HexColor:        t.HexColor,
+			RepeatAfter:     t.RepeatAfter,
+			RepeatMode:      t.RepeatMode,
+			Position:        t.Position,
+			KanbanPosition:  t.KanbanPosition,
+			BucketID:        bucketMap[t.BucketID],
+			DueDateOffset:   getListTemplateDateOffset(base, t.DueDate),
+			StartDateOffset: getListTemplateDateOffset(base, t.StartDate),
+			EndDateOffset:   getListTemplateDateOffset(base, t.EndDate),
+			ReminderOffsets: make([]int64, 0, len(t.Reminders)),
+			LabelIDs:        make([]int64, 0, len(t.Labels)),
+			Assignees:       make([]string, 0, len(t.Assignees)),
+			Relations:       []*ListTemplateTaskRelation{},
+		}
+		for _, r := range t.Reminders {
+			templateTask.ReminderOffsets = append(templateTask.ReminderOffsets, *getListTemplateDateOffset(base, r))
+		}
+		for _, l := range t.Labels {
+			templateTask.LabelIDs = append(templateTask.LabelIDs, l.ID)
+		}
+		// Assignees are saved as placeholders with the username, they can be replaced by other users when the
+		// template is used.
+		for _, u := range t.Assignees {
+			templateTask.Assignees = append(templateTask.Assignees, u.Username)
+		}
+		// Each relation exists in both directions, the other direction is recreated when the template is used.
+		// Relations to tasks in other lists are not part of the template.
+		for kind, related := range t.RelatedTasks {
+			for _, other := range related {
+				otherID, exists := taskMap[other.ID]
+				if !exists || otherID < templateTask.ID {
+					continue
+				}
+				templateTask.Relations = append(templateTask.Relations, &ListTemplateTaskRelation{
+					OtherTaskID:  otherID,
+					RelationKind: kind,
+				})
+			}
+		}
+		sort.Slice(templateTask.Relations, func(i, j int) bool {
+			if templateTask.Relations[i].OtherTaskID == templateTask.Relations[j].OtherTaskID {
+				return templateTask.Relations[i].RelationKind < templateTask.Relations[j].RelationKind
+			}
+			return templateTask.Relations[i].OtherTaskID < templateTask.Relations[j].OtherTaskID
+		})
+		lt.Tasks = append(lt.Tasks, templateTask)
+	}
+
+	return nil
+}
+
+func getListTemplateByID(s *xorm.Session, id int64) (lt *ListTemplate, err error) {
+	lt = &ListTemplate{}
+	exists, err := s.
+		Where("id = ?", id).
+		Get(lt)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrListTemplateDoesNotExist{TemplateID: id}
+	}
+	return
+}
+
+func addOwnersToListTemplates(s *xorm.Session, templates []*ListTemplate) error {
+	ownerIDs := make([]int64, 0, len(templates))
+	for _, lt := range templates {
+		ownerIDs = append(ownerIDs, lt.OwnerID)
+	}
+	owners, err := user.GetUsersByIDs(s, ownerIDs)
+	if err != nil {
+		return err
+	}
+	for _, lt := range templates {
+		lt.Owner = owners[lt.OwnerID]
+		lt.setVariablesAndPlaceholders()
+	}
+	return nil
+}
+
+// Create saves a new list template
+// @Summary Create a list template
+// @Description Creates a new list template. If a list id is provided, the buckets, tasks, labels, assignees, relations and dates of that list are saved in the template. Dates are saved relative to the earliest date in the list. The user needs read access to the list.
+// @tags list
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param template body models.ListTemplate true "The template you want to create."
+// @Success 201 {object} models.ListTemplate "The created template."
+// @Failure 400 {object} web.HTTPError "Invalid template object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates [put]
+func (lt *ListTemplate) Create(s *xorm.Session, a web.Auth) (err error) {
+	err = lt.validate(s, a)
+	if err != nil {
+		return err
+	}
+
+	if lt.ListID != 0 {
+		err = lt.fillFromList(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = lt.cleanTaskRelations()
+	if err != nil {
+		return err
+	}
+
+	lt.ID = 0
+	lt.OwnerID = a.GetID()
+	_, err = s.Insert(lt)
+	if err != nil {
+		return err
+	}
+
+	return addOwnersToListTemplates(s, []*ListTemplate{lt})
+}
+
+// ReadOne returns one list template
+// @Summary Get one list template
+// @Description Returns one list template with all its buckets and tasks.
+// @tags list
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.ListTemplate "The template."
+// @Failure 403 {object} web.HTTPError "The user does not have access to that template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/{id} [get]
+func (lt *ListTemplate) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	template, err := getListTemplateByID(s, lt.ID)
+	if err != nil {
+		return err
+	}
+	*lt = *template
+	return addOwnersToListTemplates(s, []*ListTemplate{lt})
+}
+
+// ReadAll returns all list templates the user can use
+// @Summary Get all list templates
+// @Description Returns all list templates the user owns, all templates shared with one of the user's teams and all templates shared with the whole instance.
+// @tags list
+// @Accept json
+// @Produce json
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search templates by title."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.ListTemplate "The templates."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates [get]
+func (lt *ListTemplate) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	cond := builder.Or(
+		builder.Eq{"owner_id": a.GetID()},
+		builder.Eq{"visibility": ListTemplateVisibilityInstance},
+		builder.And(
+			builder.Eq{"visibility": ListTemplateVisibilityTeam},
+			builder.In("team_id", builder.
+				Select("team_id").
+				From("team_members").
+				Where(builder.Eq{"user_id": a.GetID()}),
+			),
+		),
+	)
+	if search != "" {
+		cond = builder.And(cond, db.ILIKE("title", search))
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	templates := []*ListTemplate{}
+	query := s.Where(cond).OrderBy("id asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&templates)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	err = addOwnersToListTemplates(s, templates)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	numberOfTotalItems, err = s.Where(cond).Count(&ListTemplate{})
+	return templates, len(templates), numberOfTotalItems, err
+}
+
+// Update updates an existing list template
+// @Summary Update a list template
+// @Description Updates the title, description, color, visibility, buckets and tasks of a list template. Only the owner of a template can update it.
+// @tags list
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Template ID"
+// @Param template body models.ListTemplate true "The template with updated values you want to change."
+// @Success 200 {object} models.ListTemplate "The updated template."
+// @Failure 400 {object} web.HTTPError "Invalid template object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/{id} [post]
+func (lt *ListTemplate) Update(s *xorm.Session, a web.Auth) (err error) {
+	err = lt.validate(s, a)
+	if err != nil {
+		return err
+	}
+
+	err = lt.cleanTaskRelations()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		Where("id = ?", lt.ID).
+		Cols(
+			"title",
+			"description",
+			"hex_color",
+			"visibility",
+			"team_id",
+			"buckets",
+			"tasks",
+		).
+		Update(lt)
+	if err != nil {
+		return err
+	}
+
+	return lt.ReadOne(s, a)
+}
+
+// Delete removes a list template
+// @Summary Delete a list template
+// @Description Deletes a list template. Lists created from it are not affected. Only the owner of a template can delete it.
+// @tags list
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.Message "The template was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the template."
+// @Failure 404 {object} web.HTTPError "The template does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /templates/{id} [delete]
+func (lt *ListTemplate) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ?", lt.ID).Delete(&ListTemplate{})
+	return
+}
+
+// Replaces all variables in a text with their values.
+func replaceListTemplateVariables(text string, values map[string]string) string {
+	return listTemplateVariableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		name := listTemplateVariableRegex.FindStringSubmatch(match)[1]
+		return values[name]
+	})
+}
+
+func (lt *ListTemplate) checkVariables(values map[string]string) error {
+	missing := []string{}
+	for _, v := range lt.Variables {
+		if strings.TrimSpace(values[v]) == "" {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return &ErrListTemplateVariableMissing{TemplateID: lt.ID, Variables: missing}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+func isListTemplateTeamMember(s *xorm.Session, teamID int64, userID int64) (bool, error) {
+	return s.
+		Where("team_id = ? AND user_id = ?", teamID, userID).
+		Exist(&TeamMember{})
+}
+
+// CanRead checks if a user can see and use a list template
+func (lt *ListTemplate) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, 0, nil
+	}
+
+	template, err := getListTemplateByID(s, lt.ID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if template.OwnerID == a.GetID() {
+		return true, int(RightAdmin), nil
+	}
+
+	switch template.Visibility {
+	case ListTemplateVisibilityInstance:
+		return true, int(RightRead), nil
+	case ListTemplateVisibilityTeam:
+		isMember, err := isListTemplateTeamMember(s, template.TeamID, a.GetID())
+		return isMember, int(RightRead), err
+	case ListTemplateVisibilityPrivate:
+	}
+
+	return false, 0, nil
+}
+
+// CanCreate checks if a user can create a list template. If the template is created from a list, the user needs
+// read access to that list.
+func (lt *ListTemplate) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	if lt.ListID == 0 {
+		return true, nil
+	}
+
+	l := &List{ID: lt.ListID}
+	can, _, err := l.CanRead(s, a)
+	return can, err
+}
+
+// CanUpdate checks if a user can update a list template. Only the owner can do that.
+func (lt *ListTemplate) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return lt.isOwner(s, a)
+}
+
+// CanDelete checks if a user can delete a list template. Only the owner can do that.
+func (lt *ListTemplate) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return lt.isOwner(s, a)
+}
+
+func (lt *ListTemplate) isOwner(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	template, err := getListTemplateByID(s, lt.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return template.OwnerID == a.GetID(), nil
+}
@@ -0,0 +1,352 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTemplate_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("from list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &ListTemplate{ListID: 1}
+		can, err := lt.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = lt.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Test1", lt.Title)
+		assert.Equal(t, ListTemplateVisibilityPrivate, lt.Visibility)
+		assert.Len(t, lt.Buckets, 3)
+		assert.True(t, lt.Buckets[2].IsDoneBucket)
+		assert.NotEmpty(t, lt.Tasks)
+		for _, task := range lt.Tasks {
+			// Task #2 is done
+			assert.NotEqual(t, "task #2 done", task.Title)
+			for _, r := range task.Relations {
+				assert.Greater(t, r.OtherTaskID, task.ID)
+			}
+		}
+		db.AssertExists(t, "list_templates", map[string]interface{}{
+			"id":       lt.ID,
+			"title":    "Test1",
+			"owner_id": 1,
+		}, false)
+	})
+	t.Run("from list with done tasks", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &ListTemplate{ListID: 1, IncludeDoneTasks: true}
+		err := lt.Create(s, u)
+		assert.NoError(t, err)
+
+		var found bool
+		for _, task := range lt.Tasks {
+			if task.Title == "task #2 done" {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+	t.Run("no access to list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &ListTemplate{ListID: 24}
+		can, err := lt.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("invalid visibility", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &ListTemplate{Title: "test", Visibility: "everyone"}
+		err := lt.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidListTemplateVisibility(err))
+	})
+	t.Run("team visibility without team", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &ListTemplate{Title: "test", Visibility: ListTemplateVisibilityTeam}
+		err := lt.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidListTemplateVisibility(err))
+	})
+	t.Run("team the user is not a member of", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &ListTemplate{Title: "test", Visibility: ListTemplateVisibilityTeam, TeamID: 9}
+		err := lt.Create(s, u)
+		assert.Error(t, err)
+		assert.IsType(t, ErrGenericForbidden{}, err)
+	})
+}
+
+func TestListTemplate_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	lt := &ListTemplate{}
+	result, _, total, err := lt.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+	assert.NoError(t, err)
+	templates := result.([]*ListTemplate)
+	// Own, team 1 and instance templates
+	assert.Len(t, templates, 3)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, int64(1), templates[0].ID)
+	assert.Equal(t, []string{"sprint"}, templates[0].Variables)
+	assert.Equal(t, []string{"lead", "reviewer"}, templates[0].AssigneePlaceholders)
+	assert.Equal(t, int64(2), templates[1].ID)
+	assert.Equal(t, int64(4), templates[2].ID)
+}
+
+func TestListTemplate_Rights(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("read", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		for id, expected := range map[int64]bool{1: true, 2: true, 3: false, 4: true, 5: false} {
+			lt := &ListTemplate{ID: id}
+			can, _, err := lt.CanRead(s, u)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, can, "template %d", id)
+		}
+	})
+	t.Run("update by a team member", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &ListTemplate{ID: 2}
+		can, err := lt.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lt := &ListTemplate{ID: 9999}
+		_, _, err := lt.CanRead(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrListTemplateDoesNotExist(err))
+	})
+}
+
+func TestListTemplateInstance_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+	start := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("new list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &ListTemplateInstance{
+			TemplateID:  1,
+			NamespaceID: 1,
+			StartDate:   start,
+			Variables:   map[string]string{"sprint": "42"},
+			Assignees:   map[string]int64{"lead": 1},
+		}
+		can, err := ti.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = ti.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Sprint 42", ti.List.Title)
+		db.AssertExists(t, "lists", map[string]interface{}{
+			"id":           ti.List.ID,
+			"title":        "Sprint 42",
+			"description":  "Everything for sprint 42",
+			"namespace_id": 1,
+		}, false)
+		// The template buckets replace the default bucket
+		db.AssertMissing(t, "buckets", map[string]interface{}{
+			"list_id": ti.List.ID,
+			"title":   "Backlog",
+		})
+		db.AssertExists(t, "buckets", map[string]interface{}{
+			"list_id":        ti.List.ID,
+			"title":          "Done",
+			"is_done_bucket": true,
+		}, false)
+
+		review := &Bucket{}
+		has, err := s.Where("list_id = ? AND title = ?", ti.List.ID, "Review 42").Get(review)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		todo := &Bucket{}
+		has, err = s.Where("list_id = ? AND title = ?", ti.List.ID, "To Do").Get(todo)
+		assert.NoError(t, err)
+		assert.True(t, has)
+
+		plan := &Task{}
+		has, err = s.Where("list_id = ? AND title = ?", ti.List.ID, "Plan 42").Get(plan)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		assert.False(t, plan.Done)
+		assert.Equal(t, review.ID, plan.BucketID)
+		assert.Equal(t, start.Add(24*time.Hour).Unix(), plan.DueDate.Unix())
+
+		sub := &Task{}
+		has, err = s.Where("list_id = ? AND title = ?", ti.List.ID, "Subtask").Get(sub)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		// Tasks of the done bucket start undone in the default bucket
+		assert.False(t, sub.Done)
+		assert.Equal(t, todo.ID, sub.BucketID)
+		assert.Equal(t, start.Unix(), sub.StartDate.Unix())
+		assert.Equal(t, start.Add(48*time.Hour).Unix(), sub.EndDate.Unix())
+
+		db.AssertExists(t, "task_reminders", map[string]interface{}{
+			"task_id": plan.ID,
+		}, false)
+		// The user has no access to label 3
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  plan.ID,
+			"label_id": 1,
+		}, false)
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{
+			"task_id":  plan.ID,
+			"label_id": 3,
+		})
+		// Only placeholders with a user are assigned
+		db.AssertExists(t, "task_assignees", map[string]interface{}{
+			"task_id": plan.ID,
+			"user_id": 1,
+		}, false)
+		db.AssertExists(t, "task_assignees", map[string]interface{}{
+			"task_id": sub.ID,
+			"user_id": 1,
+		}, false)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       plan.ID,
+			"other_task_id": sub.ID,
+			"relation_kind": RelationKindParenttask,
+		}, false)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       sub.ID,
+			"other_task_id": plan.ID,
+			"relation_kind": RelationKindSubtask,
+		}, false)
+	})
+	t.Run("existing list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &ListTemplateInstance{
+			TemplateID: 1,
+			ListID:     1,
+			StartDate:  start,
+			Variables:  map[string]string{"sprint": "42"},
+		}
+		can, err := ti.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = ti.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(1), ti.List.ID)
+		// No bucket of list 1 matches, the tasks end up in the default bucket
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"list_id":   1,
+			"title":     "Plan 42",
+			"bucket_id": 1,
+		}, false)
+		plan := &Task{}
+		has, err := s.Where("list_id = ? AND title = ?", 1, "Plan 42").Get(plan)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		db.AssertMissing(t, "task_assignees", map[string]interface{}{
+			"task_id": plan.ID,
+		})
+	})
+	t.Run("missing variable", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &ListTemplateInstance{
+			TemplateID:  1,
+			NamespaceID: 1,
+		}
+		err := ti.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrListTemplateVariableMissing(err))
+	})
+	t.Run("no access to the template", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &ListTemplateInstance{
+			TemplateID:  5,
+			NamespaceID: 1,
+		}
+		can, err := ti.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("no access to the namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &ListTemplateInstance{
+			TemplateID:  4,
+			NamespaceID: 6,
+		}
+		can, err := ti.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
@@ -255,9 +255,13 @@ func TestList_Delete(t *testing.T) {
 	assert.NoError(t, err)
 	err = s.Commit()
 	assert.NoError(t, err)
-	db.AssertMissing(t, "lists", map[string]interface{}{
-		"id": 1,
-	})
+	db.AssertExists(t, "lists", map[string]interface{}{
+		"id":                  1,
+		"deleted_by_id":       1,
+		"deleted_with_parent": false,
+	}, false)
+	assertTrashed(t, "lists", map[string]interface{}{"id": 1}, true)
+	assertTrashed(t, "tasks", map[string]interface{}{"list_id": 1}, true)
 }
 
 func TestList_ReadAll(t *testing.T) {
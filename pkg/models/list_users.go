@@ -40,6 +40,8 @@ type ListUser struct {
 	ListID int64 `xorm:"bigint not null INDEX" json:"-" param:"list"`
 	// The right this user has. 0 = Read only, 1 = Read & Write, 2 = Admin. See the docs for more details.
 	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|2)" maximum:"2" default:"0"`
+	// The custom role this share has. If set, the right is always read only and everything else is allowed by the role.
+	RoleID int64 `xorm:"bigint null INDEX" json:"role_id"`
 
 	// A timestamp when this relation was created. You cannot change this value.
 	Created time.Time `xorm:"created not null" json:"created"`
@@ -59,6 +61,7 @@ func (ListUser) TableName() string {
 type UserWithRight struct {
 	user.User `xorm:"extends"`
 	Right     Right `json:"right"`
+	RoleID    int64 `json:"role_id"`
 }
 
 // Create creates a new list <-> user relation
@@ -83,6 +86,10 @@ func (lu *ListUser) Create(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	if err := checkShareRole(s, lu.RoleID, &lu.Right); err != nil {
+		return err
+	}
+
 	// Check if the list exists
 	l, err := GetListSimpleByID(s, lu.ListID)
 	if err != nil {
@@ -251,6 +258,10 @@ func (lu *ListUser) Update(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	if err := checkShareRole(s, lu.RoleID, &lu.Right); err != nil {
+		return err
+	}
+
 	// Check if the user exists
 	u, err := user.GetUserByUsername(s, lu.Username)
 	if err != nil {
@@ -260,7 +271,7 @@ func (lu *ListUser) Update(s *xorm.Session, a web.Auth) (err error) {
 
 	_, err = s.
 		Where("list_id = ? AND user_id = ?", lu.ListID, lu.UserID).
-		Cols("right").
+		Cols("right", "role_id").
 		Update(lu)
 	if err != nil {
 		return err
@@ -17,32 +17,66 @@
 package models
 
 import (
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
 	"xorm.io/xorm"
 )
 
 // CanCreate checks if the user can create a new user <-> list relation
 func (lu *ListUser) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	return lu.canDoListUser(s, a)
+	return lu.canDoListUser(s, a, lu.Right, lu.RoleID)
 }
 
 // CanDelete checks if the user can delete a user <-> list relation
 func (lu *ListUser) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
-	return lu.canDoListUser(s, a)
+	stored, err := lu.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return lu.canDoListUser(s, a, stored.Right, stored.RoleID)
 }
 
 // CanUpdate checks if the user can update a user <-> list relation
 func (lu *ListUser) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
-	return lu.canDoListUser(s, a)
+	can, err := lu.canDoListUser(s, a, lu.Right, lu.RoleID)
+	if err != nil || !can {
+		return can, err
+	}
+
+	// Whoever changes a share must be allowed to give the right it had before as well
+	stored, err := lu.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return lu.canDoListUser(s, a, stored.Right, stored.RoleID)
 }
 
-func (lu *ListUser) canDoListUser(s *xorm.Session, a web.Auth) (bool, error) {
+func (lu *ListUser) canDoListUser(s *xorm.Session, a web.Auth, right Right, roleID int64) (bool, error) {
 	// Link shares aren't allowed to do anything
 	if _, is := a.(*LinkSharing); is {
 		return false, nil
 	}
 
-	// Get the list and check if the user has write access on it
 	l := List{ID: lu.ListID}
-	return l.IsAdmin(s, a)
+	return l.canShare(s, a, right, roleID)
+}
+
+// getStoredShare returns the share as it is saved right now. If there is none, it returns an empty share
+// so the update or delete can fail with the proper error.
+func (lu *ListUser) getStoredShare(s *xorm.Session) (stored *ListUser, err error) {
+	stored = &ListUser{}
+	u, err := user.GetUserByUsername(s, lu.Username)
+	if user.IsErrUserDoesNotExist(err) {
+		return stored, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.
+		Where("list_id = ? AND user_id = ?", lu.ListID, u.ID).
+		Get(stored)
+	return
 }
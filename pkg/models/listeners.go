@@ -39,6 +39,9 @@ func RegisterListeners() {
 	events.RegisterListener((&NamespaceDeletedEvent{}).Name(), &DecreaseNamespaceCounter{})
 	events.RegisterListener((&TaskCreatedEvent{}).Name(), &IncreaseTaskCounter{})
 	events.RegisterListener((&TaskDeletedEvent{}).Name(), &DecreaseTaskCounter{})
+	events.RegisterListener((&ListRestoredEvent{}).Name(), &IncreaseListCounter{})
+	events.RegisterListener((&NamespaceRestoredEvent{}).Name(), &IncreaseNamespaceCounter{})
+	events.RegisterListener((&TaskRestoredEvent{}).Name(), &IncreaseTaskCounter{})
 	events.RegisterListener((&TeamDeletedEvent{}).Name(), &DecreaseTeamCounter{})
 	events.RegisterListener((&TeamCreatedEvent{}).Name(), &IncreaseTeamCounter{})
 	events.RegisterListener((&TaskCommentCreatedEvent{}).Name(), &SendTaskCommentNotification{})
@@ -50,6 +53,9 @@ func RegisterListeners() {
 	events.RegisterListener((&TaskCommentUpdatedEvent{}).Name(), &HandleTaskCommentEditMentions{})
 	events.RegisterListener((&TaskCreatedEvent{}).Name(), &HandleTaskCreateMentions{})
 	events.RegisterListener((&TaskUpdatedEvent{}).Name(), &HandleTaskUpdatedMentions{})
+	events.RegisterListener((&TaskUpdatedEvent{}).Name(), &SendTaskUpdatedNotification{})
+	events.RegisterListener((&TaskAttachmentCreatedEvent{}).Name(), &SendTaskAttachmentCreatedNotification{})
+	events.RegisterListener((&TaskAttachmentDeletedEvent{}).Name(), &SendTaskAttachmentDeletedNotification{})
 	events.RegisterListener((&UserDataExportRequestedEvent{}).Name(), &HandleUserDataExport{})
 }
 
@@ -160,7 +166,7 @@ func (s *SendTaskCommentNotification) Handle(msg *message.Message) (err error) {
 		return err
 	}
 
-	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityTask, event.Task.ID)
+	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityTask, event.Task.ID, SubscriptionEventComments)
 	if err != nil {
 		return err
 	}
@@ -240,7 +246,7 @@ func (s *SendTaskAssignedNotification) Handle(msg *message.Message) (err error)
 	sess := db.NewSession()
 	defer sess.Close()
 
-	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityTask, event.Task.ID)
+	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityTask, event.Task.ID, SubscriptionEventAssigneeChanges)
 	if err != nil {
 		return err
 	}
@@ -291,7 +297,7 @@ func (s *SendTaskDeletedNotification) Handle(msg *message.Message) (err error) {
 	sess := db.NewSession()
 	defer sess.Close()
 
-	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityTask, event.Task.ID)
+	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityTask, event.Task.ID, SubscriptionEventTaskDeleted)
 	if err != nil {
 		return err
 	}
@@ -408,6 +414,142 @@ func (s *HandleTaskUpdatedMentions) Handle(msg *message.Message) (err error) {
 
 }
 
+// SendTaskUpdatedNotification  represents a listener
+type SendTaskUpdatedNotification struct {
+}
+
+// Name defines the name for the SendTaskUpdatedNotification listener
+func (s *SendTaskUpdatedNotification) Name() string {
+	return "task.updated.notification.send"
+}
+
+// Handle is executed when the event SendTaskUpdatedNotification listens on is fired
+func (s *SendTaskUpdatedNotification) Handle(msg *message.Message) (err error) {
+	event := &TaskUpdatedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	if event.OldTask == nil {
+		return nil
+	}
+
+	changes := getTaskChanges(event.OldTask, event.Task)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	sess := db.NewSession()
+	defer sess.Close()
+
+	var events SubscriptionEvents
+	for _, c := range changes {
+		events |= c.event
+	}
+
+	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityTask, event.Task.ID, events)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Sending task updated notifications to %d subscribers for task %d", len(subscribers), event.Task.ID)
+
+	for _, subscriber := range subscribers {
+		if event.Doer != nil && subscriber.UserID == event.Doer.ID {
+			continue
+		}
+
+		subscriberChanges := filterTaskChanges(changes, subscriber.EventMask)
+		if len(subscriberChanges) == 0 {
+			continue
+		}
+
+		n := &TaskUpdatedNotification{
+			Doer:    event.Doer,
+			Task:    event.Task,
+			Changes: subscriberChanges,
+		}
+		err = notifications.Notify(subscriber.User, n)
+		if err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+func notifyTaskAttachmentSubscribers(task *Task, attachment *TaskAttachment, doer *user.User, deleted bool) (err error) {
+	sess := db.NewSession()
+	defer sess.Close()
+
+	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityTask, task.ID, SubscriptionEventAttachments)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Sending task attachment notifications to %d subscribers for task %d", len(subscribers), task.ID)
+
+	for _, subscriber := range subscribers {
+		if doer != nil && subscriber.UserID == doer.ID {
+			continue
+		}
+
+		n := &TaskAttachmentNotification{
+			Doer:       doer,
+			Task:       task,
+			Attachment: attachment,
+			Deleted:    deleted,
+		}
+		err = notifications.Notify(subscriber.User, n)
+		if err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+// SendTaskAttachmentCreatedNotification  represents a listener
+type SendTaskAttachmentCreatedNotification struct {
+}
+
+// Name defines the name for the SendTaskAttachmentCreatedNotification listener
+func (s *SendTaskAttachmentCreatedNotification) Name() string {
+	return "task.attachment.created.notification.send"
+}
+
+// Handle is executed when the event SendTaskAttachmentCreatedNotification listens on is fired
+func (s *SendTaskAttachmentCreatedNotification) Handle(msg *message.Message) (err error) {
+	event := &TaskAttachmentCreatedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	return notifyTaskAttachmentSubscribers(event.Task, event.Attachment, event.Doer, false)
+}
+
+// SendTaskAttachmentDeletedNotification  represents a listener
+type SendTaskAttachmentDeletedNotification struct {
+}
+
+// Name defines the name for the SendTaskAttachmentDeletedNotification listener
+func (s *SendTaskAttachmentDeletedNotification) Name() string {
+	return "task.attachment.deleted.notification.send"
+}
+
+// Handle is executed when the event SendTaskAttachmentDeletedNotification listens on is fired
+func (s *SendTaskAttachmentDeletedNotification) Handle(msg *message.Message) (err error) {
+	event := &TaskAttachmentDeletedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	return notifyTaskAttachmentSubscribers(event.Task, event.Attachment, event.Doer, true)
+}
+
 ///////
 // List Event Listeners
 
@@ -453,7 +595,7 @@ func (s *SendListCreatedNotification) Handle(msg *message.Message) (err error) {
 	sess := db.NewSession()
 	defer sess.Close()
 
-	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityList, event.List.ID)
+	subscribers, err := getSubscribersForEntity(sess, SubscriptionEntityList, event.List.ID, SubscriptionEventListCreated)
 	if err != nil {
 		return err
 	}
@@ -61,6 +61,18 @@ func GetTables() []interface{} {
 		&SavedFilter{},
 		&Subscription{},
 		&Favorite{},
+		&AdminAuditLog{},
+		&ShareInvitation{},
+		&Role{},
+		&LinkShareAccessLog{},
+		&TaskBucket{},
+		&BucketRule{},
+		&TaskHistory{},
+		&TaskBucketEntry{},
+		&TaskStatus{},
+		&TaskStatusTransition{},
+		&TaskIdentifierRedirect{},
+		&ListTemplate{},
 	}
 }
 
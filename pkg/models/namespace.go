@@ -43,6 +43,10 @@ type Namespace struct {
 	Description string `xorm:"longtext null" json:"description"`
 	OwnerID     int64  `xorm:"bigint not null INDEX" json:"-"`
 
+	// The id of the parent namespace. Sub namespaces inherit all shares of their parents. 0 if this is a top-level namespace.
+	// This is ignored when updating a namespace, use the move endpoint to change it.
+	ParentID int64 `xorm:"bigint null INDEX" json:"parent_id"`
+
 	// The hex color of this namespace
 	HexColor string `xorm:"varchar(6) null" json:"hex_color" valid:"runelength(0|6)" maxLength:"6"`
 
@@ -61,6 +65,13 @@ type Namespace struct {
 	// A timestamp when this namespace was last updated. You cannot change this value.
 	Updated time.Time `xorm:"updated not null" json:"updated"`
 
+	// A timestamp when this namespace was moved to the trash. Zero if it is not in the trash.
+	Deleted time.Time `xorm:"deleted null INDEX" json:"-"`
+	// The user who moved this namespace to the trash.
+	DeletedByID int64 `xorm:"bigint null" json:"-"`
+	// Whether this namespace was moved to the trash together with its parent namespace.
+	DeletedWithParent bool `xorm:"null" json:"-"`
+
 	// If set to true, will only return the namespaces, not their lists.
 	NamespacesOnly bool `xorm:"-" json:"-" query:"namespaces_only"`
 
@@ -257,16 +268,19 @@ func getNamespacesWithLists(s *xorm.Session, namespaces *map[int64]*NamespaceWit
 	isArchivedCond := getNamespaceArchivedCond(isArchived)
 	filterCond := getNamespaceFilterCond(search)
 
+	namespaceIDs, subNamespaceIDs, err := getNamespaceIDsForUser(s, userID)
+	if err != nil {
+		return 0, err
+	}
+	namespaceIDs = append(namespaceIDs, subNamespaceIDs...)
+	if len(namespaceIDs) == 0 {
+		return 0, nil
+	}
+
 	limit, start := getLimitFromPageIndex(page, perPage)
 	query := s.Select("namespaces.*").
 		Table("namespaces").
-		Join("LEFT", "team_namespaces", "namespaces.id = team_namespaces.namespace_id").
-		Join("LEFT", "team_members", "team_members.team_id = team_namespaces.team_id").
-		Join("LEFT", "users_namespaces", "users_namespaces.namespace_id = namespaces.id").
-		Where("team_members.user_id = ?", userID).
-		Or("namespaces.owner_id = ?", userID).
-		Or("users_namespaces.user_id = ?", userID).
-		GroupBy("namespaces.id").
+		In("namespaces.id", namespaceIDs).
 		Where(filterCond).
 		Where(isArchivedCond)
 	if limit > 0 {
@@ -279,14 +293,8 @@ func getNamespacesWithLists(s *xorm.Session, namespaces *map[int64]*NamespaceWit
 
 	numberOfTotalItems, err = s.
 		Table("namespaces").
-		Join("LEFT", "team_namespaces", "namespaces.id = team_namespaces.namespace_id").
-		Join("LEFT", "team_members", "team_members.team_id = team_namespaces.team_id").
-		Join("LEFT", "users_namespaces", "users_namespaces.namespace_id = namespaces.id").
-		Where("team_members.user_id = ?", userID).
-		Or("namespaces.owner_id = ?", userID).
-		Or("users_namespaces.user_id = ?", userID).
+		In("namespaces.id", namespaceIDs).
 		And("namespaces.is_archived = false").
-		GroupBy("namespaces.id").
 		Where(filterCond).
 		Where(isArchivedCond).
 		Count(&NamespaceWithLists{})
@@ -616,6 +624,11 @@ func (n *Namespace) Create(s *xorm.Session, a web.Auth) (err error) {
 		return ErrNamespaceNameCannotBeEmpty{NamespaceID: 0, UserID: a.GetID()}
 	}
 
+	err = n.checkParent(s)
+	if err != nil {
+		return err
+	}
+
 	n.Owner, err = user.GetUserByID(s, a.GetID())
 	if err != nil {
 		return
@@ -649,7 +662,7 @@ func CreateNewNamespaceForUser(s *xorm.Session, user *user.User) (err error) {
 
 // Delete deletes a namespace
 // @Summary Deletes a namespace
-// @Description Delets a namespace
+// @Description Moves a namespace with all of its sub namespaces, lists and tasks to the trash. The namespace can be restored from the trash until it is purged after the configured retention period.
 // @tags namespace
 // @Produce json
 // @Security JWTKeyAuth
@@ -660,50 +673,62 @@ func CreateNewNamespaceForUser(s *xorm.Session, user *user.User) (err error) {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /namespaces/{id} [delete]
 func (n *Namespace) Delete(s *xorm.Session, a web.Auth) (err error) {
-	return deleteNamespace(s, n, a, true)
-}
-
-func deleteNamespace(s *xorm.Session, n *Namespace, a web.Auth, withLists bool) (err error) {
 	// Check if the namespace exists
 	_, err = GetNamespaceByID(s, n.ID)
 	if err != nil {
 		return
 	}
 
-	// Delete the namespace
-	_, err = s.ID(n.ID).Delete(&Namespace{})
+	err = trashNamespace(s, n, a.GetID(), false)
 	if err != nil {
 		return
 	}
 
-	namespaceDeleted := &NamespaceDeletedEvent{
+	return events.Dispatch(&NamespaceDeletedEvent{
 		Namespace: n,
 		Doer:      a,
+	})
+}
+
+// purgeNamespace permanently removes a namespace, no matter if it is in the trash or not. If withLists is true, all
+// of its sub namespaces and lists are removed as well.
+func purgeNamespace(s *xorm.Session, n *Namespace, withLists bool) (err error) {
+	// Delete the namespace
+	_, err = s.Unscoped().ID(n.ID).Delete(&Namespace{})
+	if err != nil {
+		return
 	}
 
-	if !withLists {
-		return events.Dispatch(namespaceDeleted)
+	err = deleteBoardBuckets(s, &Bucket{NamespaceID: n.ID})
+	if err != nil {
+		return
 	}
 
-	// Delete all lists with their tasks
-	lists, err := GetListsByNamespaceID(s, n.ID, &user.User{})
+	err = purgeSubNamespaces(s, n, withLists)
 	if err != nil {
 		return
 	}
 
-	if len(lists) == 0 {
-		return events.Dispatch(namespaceDeleted)
+	if !withLists {
+		return nil
+	}
+
+	// Delete all lists with their tasks
+	lists := []*List{}
+	err = s.Unscoped().Where("namespace_id = ?", n.ID).Find(&lists)
+	if err != nil {
+		return
 	}
 
 	// Looping over all lists to let the list handle properly cleaning up the tasks and everything else associated with it.
 	for _, list := range lists {
-		err = list.Delete(s, a)
+		err = list.purge(s)
 		if err != nil {
 			return err
 		}
 	}
 
-	return events.Dispatch(namespaceDeleted)
+	return nil
 }
 
 // Update implements the update method via the interface
@@ -737,6 +762,14 @@ func (n *Namespace) Update(s *xorm.Session, a web.Auth) (err error) {
 		return ErrNamespaceIsArchived{NamespaceID: n.ID}
 	}
 
+	// Moving a namespace has its own endpoint. Checking the parent makes sure a sub namespace can't be
+	// un-archived while its parent is archived.
+	n.ParentID = currentNamespace.ParentID
+	err = n.checkParent(s)
+	if err != nil {
+		return err
+	}
+
 	// Check if the (new) owner exists
 	if n.Owner != nil {
 		n.OwnerID = n.Owner.ID
@@ -767,6 +800,14 @@ func (n *Namespace) Update(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	// Archiving a namespace archives all of its sub namespaces as well
+	if currentNamespace.IsArchived != n.IsArchived {
+		err = setNamespaceBranchArchived(s, n.ID, n.IsArchived)
+		if err != nil {
+			return err
+		}
+	}
+
 	return events.Dispatch(&NamespaceUpdatedEvent{
 		Namespace: n,
 		Doer:      a,
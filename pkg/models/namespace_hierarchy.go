@@ -0,0 +1,209 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// getNamespaceAncestorIDs returns the ids of all parents of a namespace, starting with its direct parent.
+func getNamespaceAncestorIDs(s *xorm.Session, namespaceID int64) (ancestorIDs []int64, err error) {
+	if namespaceID <= 0 {
+		return nil, nil
+	}
+
+	seen := map[int64]bool{namespaceID: true}
+	current := namespaceID
+	for {
+		var parentID int64
+		_, err = s.
+			Table("namespaces").
+			Cols("parent_id").
+			Where("id = ?", current).
+			Get(&parentID)
+		if err != nil {
+			return nil, err
+		}
+
+		// The check for seen ids protects us from looping forever in case the tree is broken
+		if parentID <= 0 || seen[parentID] {
+			return ancestorIDs, nil
+		}
+
+		seen[parentID] = true
+		ancestorIDs = append(ancestorIDs, parentID)
+		current = parentID
+	}
+}
+
+// getNamespaceDescendantIDs returns the ids of all sub namespaces of the given namespaces, no matter how deep
+// they are nested.
+func getNamespaceDescendantIDs(s *xorm.Session, namespaceIDs []int64) (descendantIDs []int64, err error) {
+	seen := make(map[int64]bool, len(namespaceIDs))
+	for _, id := range namespaceIDs {
+		seen[id] = true
+	}
+
+	parentIDs := namespaceIDs
+	for len(parentIDs) > 0 {
+		childIDs := []int64{}
+		err = s.
+			Table("namespaces").
+			Cols("id").
+			In("parent_id", parentIDs).
+			Find(&childIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		parentIDs = []int64{}
+		for _, id := range childIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			descendantIDs = append(descendantIDs, id)
+			parentIDs = append(parentIDs, id)
+		}
+	}
+
+	return
+}
+
+// getNamespaceIDsForUser returns the ids of all namespaces a user owns or was given access to directly and,
+// separately, the ids of all of their sub namespaces the user has access to because of that.
+func getNamespaceIDsForUser(s *xorm.Session, userID int64) (namespaceIDs, subNamespaceIDs []int64, err error) {
+	namespaceIDs = []int64{}
+	err = s.
+		Select("namespaces.id").
+		Table("namespaces").
+		Join("LEFT", "team_namespaces", "namespaces.id = team_namespaces.namespace_id").
+		Join("LEFT", "team_members", "team_members.team_id = team_namespaces.team_id").
+		Join("LEFT", "users_namespaces", "users_namespaces.namespace_id = namespaces.id").
+		Where(builder.Or(
+			builder.Eq{"team_members.user_id": userID},
+			builder.Eq{"namespaces.owner_id": userID},
+			builder.Eq{"users_namespaces.user_id": userID},
+		)).
+		GroupBy("namespaces.id").
+		Find(&namespaceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subNamespaceIDs, err = getNamespaceDescendantIDs(s, namespaceIDs)
+	return
+}
+
+// checkParent makes sure the parent of a namespace exists, is not archived and that the namespace would not end up
+// being its own parent.
+func (n *Namespace) checkParent(s *xorm.Session) error {
+	if n.ParentID == 0 {
+		return nil
+	}
+
+	if n.ParentID < 0 {
+		return &ErrNamespaceCannotHavePseudoParent{NamespaceID: n.ID, ParentID: n.ParentID}
+	}
+
+	if n.ParentID == n.ID {
+		return &ErrNamespaceParentCreatesCycle{NamespaceID: n.ID, ParentID: n.ParentID}
+	}
+
+	parent, err := getNamespaceSimpleByID(s, n.ParentID)
+	if err != nil {
+		return err
+	}
+
+	if n.ID != 0 {
+		ancestorIDs, err := getNamespaceAncestorIDs(s, parent.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range ancestorIDs {
+			if id == n.ID {
+				return &ErrNamespaceParentCreatesCycle{NamespaceID: n.ID, ParentID: n.ParentID}
+			}
+		}
+	}
+
+	if parent.IsArchived {
+		return ErrNamespaceIsArchived{NamespaceID: parent.ID}
+	}
+
+	return nil
+}
+
+// setNamespaceBranchArchived archives or un-archives all sub namespaces of a namespace.
+func setNamespaceBranchArchived(s *xorm.Session, namespaceID int64, archived bool) error {
+	descendantIDs, err := getNamespaceDescendantIDs(s, []int64{namespaceID})
+	if err != nil {
+		return err
+	}
+
+	if len(descendantIDs) == 0 {
+		return nil
+	}
+
+	_, err = s.
+		In("id", descendantIDs).
+		Cols("is_archived").
+		NoAutoTime().
+		Update(&Namespace{IsArchived: archived})
+	return err
+}
+
+// purgeSubNamespaces permanently removes all direct sub namespaces of a namespace along with their own sub namespaces.
+// If their lists should be kept, the sub namespaces are moved to the top level instead. Sub namespaces which were
+// moved to the trash together with the namespace stay in the trash as items on their own.
+func purgeSubNamespaces(s *xorm.Session, n *Namespace, withLists bool) (err error) {
+	if !withLists {
+		_, err = s.
+			Unscoped().
+			Where("parent_id = ? AND deleted IS NOT NULL", n.ID).
+			Cols("deleted_with_parent").
+			NoAutoTime().
+			Update(&Namespace{})
+		if err != nil {
+			return err
+		}
+
+		_, err = s.
+			Unscoped().
+			Where("parent_id = ?", n.ID).
+			Cols("parent_id").
+			NoAutoTime().
+			Update(&Namespace{})
+		return err
+	}
+
+	children := []*Namespace{}
+	err = s.Unscoped().Where("parent_id = ?", n.ID).Find(&children)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		err = purgeNamespace(s, child, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
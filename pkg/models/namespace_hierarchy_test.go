@@ -0,0 +1,291 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+)
+
+// setNamespaceParent puts a namespace below another one without going through any checks.
+func setNamespaceParent(t *testing.T, s *xorm.Session, namespaceID, parentID int64) {
+	_, err := s.ID(namespaceID).Cols("parent_id").Update(&Namespace{ParentID: parentID})
+	assert.NoError(t, err)
+}
+
+func TestNamespace_Hierarchy(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	// Namespace 6 is not shared with user 1, namespace 11 is shared with user 1 with write access.
+	t.Run("inherit rights of parent", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		n := &Namespace{ID: 6}
+		can, _, err := n.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		setNamespaceParent(t, s, 6, 11)
+
+		can, maxRight, err := n.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		assert.Equal(t, int(RightWrite), maxRight)
+		can, err = n.IsAdmin(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		l := &List{ID: 24}
+		can, err = l.CanWrite(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		_, maxRight, err = l.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int(RightWrite), maxRight)
+	})
+	t.Run("owner of parent is admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setNamespaceParent(t, s, 6, 1)
+
+		can, err := (&Namespace{ID: 6}).IsAdmin(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		can, err = (&List{ID: 24}).IsAdmin(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("read all", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setNamespaceParent(t, s, 6, 11)
+
+		n := &Namespace{}
+		result, _, _, err := n.ReadAll(s, u, "", 1, -1)
+		assert.NoError(t, err)
+		var found bool
+		for _, nn := range result.([]*NamespaceWithLists) {
+			if nn.ID == 6 {
+				found = true
+				assert.Equal(t, int64(11), nn.ParentID)
+			}
+		}
+		assert.True(t, found)
+
+		lists, _, _, err := getRawListsForUser(s, &listOptions{user: u, page: -1})
+		assert.NoError(t, err)
+		var foundList bool
+		for _, l := range lists {
+			if l.ID == 24 {
+				foundList = true
+			}
+		}
+		assert.True(t, foundList)
+	})
+	t.Run("create sub namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		n := &Namespace{Title: "Sub", ParentID: 11}
+		can, err := n.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = n.Create(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "namespaces", map[string]interface{}{
+			"id":        n.ID,
+			"parent_id": 11,
+		}, false)
+	})
+	t.Run("create sub namespace without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		n := &Namespace{Title: "Sub", ParentID: 6}
+		can, err := n.CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("create sub namespace in pseudo namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		n := &Namespace{Title: "Sub", ParentID: SharedListsPseudoNamespace.ID}
+		err := n.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrNamespaceCannotHavePseudoParent(err))
+	})
+	t.Run("move namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &NamespaceMove{NamespaceID: 1, ParentID: 11}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), m.Namespace.ParentID)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "namespaces", map[string]interface{}{
+			"id":        1,
+			"parent_id": 11,
+		}, false)
+	})
+	t.Run("move namespace to the top level", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setNamespaceParent(t, s, 1, 11)
+
+		m := &NamespaceMove{NamespaceID: 1}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "namespaces", map[string]interface{}{
+			"id":        1,
+			"parent_id": 0,
+		}, false)
+	})
+	t.Run("move namespace without access to the new parent", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &NamespaceMove{NamespaceID: 1, ParentID: 6}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("move namespace into its own sub namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setNamespaceParent(t, s, 6, 1)
+		setNamespaceParent(t, s, 7, 6)
+
+		m := &NamespaceMove{NamespaceID: 1, ParentID: 7}
+		err := m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrNamespaceParentCreatesCycle(err))
+
+		m = &NamespaceMove{NamespaceID: 1, ParentID: 1}
+		err = m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrNamespaceParentCreatesCycle(err))
+	})
+	t.Run("updating keeps the parent", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setNamespaceParent(t, s, 1, 11)
+
+		// Clients which don't know about sub namespaces don't send the parent
+		n := &Namespace{ID: 1, Title: "renamed"}
+		can, err := n.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = n.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), n.ParentID)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "namespaces", map[string]interface{}{
+			"id":        1,
+			"title":     "renamed",
+			"parent_id": 11,
+		}, false)
+	})
+	t.Run("archive branch", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setNamespaceParent(t, s, 6, 1)
+		setNamespaceParent(t, s, 7, 6)
+
+		n := &Namespace{ID: 1, Title: "testnamespace", IsArchived: true}
+		err := n.Update(s, u)
+		assert.NoError(t, err)
+
+		err = (&List{ID: 24}).CheckIsArchived(s)
+		assert.True(t, IsErrNamespaceIsArchived(err))
+
+		// A sub namespace can't be un-archived while its parent is archived
+		n6 := &Namespace{ID: 6, Title: "testnamespace6"}
+		err = n6.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrNamespaceIsArchived(err))
+
+		n.IsArchived = false
+		err = n.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "namespaces", map[string]interface{}{
+			"id":          7,
+			"is_archived": false,
+		}, false)
+	})
+	t.Run("delete branch", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		setNamespaceParent(t, s, 6, 1)
+		setNamespaceParent(t, s, 7, 6)
+
+		err := (&Namespace{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "namespaces", map[string]interface{}{"id": 6, "deleted_with_parent": true}, false)
+		db.AssertExists(t, "namespaces", map[string]interface{}{"id": 7, "deleted_with_parent": true}, false)
+		assertTrashed(t, "namespaces", map[string]interface{}{"id": 6}, true)
+		assertTrashed(t, "namespaces", map[string]interface{}{"id": 7}, true)
+	})
+}
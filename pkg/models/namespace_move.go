@@ -0,0 +1,100 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// NamespaceMove represents a move of a namespace below another namespace or to the top level.
+type NamespaceMove struct {
+	// The id of the namespace to move.
+	NamespaceID int64 `json:"-" param:"namespace"`
+	// The namespace this namespace should become a sub namespace of. 0 to make it a top-level namespace.
+	ParentID int64 `json:"parent_id"`
+
+	// The namespace after it was moved.
+	Namespace *Namespace `json:"namespace"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanUpdate checks if a user can move a namespace. The user needs to be admin of the namespace and have write access
+// to the new parent.
+func (m *NamespaceMove) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	n := &Namespace{ID: m.NamespaceID}
+	is, err := n.IsAdmin(s, a)
+	if err != nil || !is || m.ParentID <= 0 {
+		return is, err
+	}
+
+	parent := &Namespace{ID: m.ParentID}
+	return parent.CanWrite(s, a)
+}
+
+// Update moves a namespace
+// @Summary Move a namespace
+// @Description Moves a namespace with all of its sub namespaces below another namespace or to the top level. The user needs to be admin of the namespace and have write access to the new parent.
+// @tags namespace
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Namespace ID"
+// @Param move body models.NamespaceMove true "Where to move the namespace to"
+// @Success 200 {object} models.NamespaceMove "The move including the updated namespace."
+// @Failure 400 {object} web.HTTPError "The new parent is a pseudo namespace or one of the namespace's own sub namespaces."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the namespace or the new parent."
+// @Failure 412 {object} web.HTTPError "The namespace or the new parent is archived."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /namespaces/{id}/move [post]
+func (m *NamespaceMove) Update(s *xorm.Session, a web.Auth) (err error) {
+	n, err := getNamespaceSimpleByID(s, m.NamespaceID)
+	if err != nil {
+		return err
+	}
+
+	if err := n.CheckIsArchived(s); err != nil {
+		return err
+	}
+
+	m.Namespace = n
+	if n.ParentID == m.ParentID {
+		return nil
+	}
+
+	n.ParentID = m.ParentID
+	err = n.checkParent(s)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		ID(n.ID).
+		Cols("parent_id").
+		Update(n)
+	if err != nil {
+		return err
+	}
+
+	return events.Dispatch(&NamespaceUpdatedEvent{
+		Namespace: n,
+		Doer:      a,
+	})
+}
@@ -55,10 +55,82 @@ func (n *Namespace) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
 		return false, nil
 	}
 
+	// A user can create a sub namespace if they have write access to the parent
+	if n.ParentID > 0 {
+		parent := &Namespace{ID: n.ParentID}
+		return parent.CanWrite(s, a)
+	}
+
 	// This is currently a dummy function, later on we could imagine global limits etc.
 	return true, nil
 }
 
+// checkPermission checks if the user can do something in a namespace. Everyone with write access can do everything,
+// everyone else needs a role which grants the permission.
+func (n *Namespace) checkPermission(s *xorm.Session, a web.Auth, p Permission) (bool, error) {
+	can, err := n.CanWrite(s, a)
+	if err != nil || can {
+		return can, err
+	}
+
+	return n.hasRolePermission(s, a, p)
+}
+
+// canShare checks if the user can share the namespace with the given right and role. Users who are only allowed to
+// share because of their role can't give anyone a right or permission they don't have themselves.
+func (n *Namespace) canShare(s *xorm.Session, a web.Auth, right Right, roleID int64) (bool, error) {
+	is, err := n.IsAdmin(s, a)
+	if err != nil || is {
+		return is, err
+	}
+
+	can, err := n.hasRolePermission(s, a, PermissionShare)
+	if err != nil || !can {
+		return can, err
+	}
+
+	canWrite, _, err := n.checkRight(s, a, RightWrite)
+	if err != nil {
+		return false, err
+	}
+	maxRight := RightRead
+	if canWrite {
+		maxRight = RightWrite
+	}
+
+	return canGrantShare(s, right, roleID, maxRight, func(p Permission) (bool, error) {
+		return n.checkPermission(s, a, p)
+	})
+}
+
+// hasRolePermission checks if any share of the namespace gives the user a role with the permission.
+func (n *Namespace) hasRolePermission(s *xorm.Session, a web.Auth, p Permission) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	// Shares of a parent namespace are inherited by all of its sub namespaces
+	ancestorIDs, err := getNamespaceAncestorIDs(s, n.ID)
+	if err != nil {
+		return false, err
+	}
+
+	roles := rolesWithPermission(p)
+	return s.
+		Table("namespaces").
+		Join("LEFT", "users_namespaces", "users_namespaces.namespace_id = namespaces.id").
+		Join("LEFT", "team_namespaces", "namespaces.id = team_namespaces.namespace_id").
+		Join("LEFT", "team_members", "team_members.team_id = team_namespaces.team_id").
+		Where(builder.And(
+			builder.Or(
+				builder.And(builder.Eq{"users_namespaces.user_id": a.GetID()}, builder.In("users_namespaces.role_id", roles)),
+				builder.And(builder.Eq{"team_members.user_id": a.GetID()}, builder.In("team_namespaces.role_id", roles)),
+			),
+			builder.In("namespaces.id", append([]int64{n.ID}, ancestorIDs...)),
+		)).
+		Exist()
+}
+
 func (n *Namespace) checkRight(s *xorm.Session, a web.Auth, rights ...Right) (bool, int, error) {
 
 	// If the auth is a link share, don't do anything
@@ -140,6 +212,78 @@ func (n *Namespace) checkRight(s *xorm.Session, a web.Auth, rights ...Right) (bo
 	if int(r.TeamNamespace.Right) > maxRights {
 		maxRights = int(r.TeamNamespace.Right)
 	}
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Shares of a parent namespace are inherited by all of its sub namespaces
+	ancestorIDs, err := getNamespaceAncestorIDs(s, n.ID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	inherited, inheritedRight, err := checkInheritedNamespaceRight(s, a, ancestorIDs, rights...)
+	if err != nil {
+		return false, 0, err
+	}
+	if inheritedRight > maxRights {
+		maxRights = inheritedRight
+	}
+
+	return exists || inherited, maxRights, nil
+}
+
+// checkInheritedNamespaceRight checks if the user was given one of the rights on any of the namespaces, either as
+// their owner or through a user or team share. It also returns the highest right the user has on these namespaces.
+func checkInheritedNamespaceRight(s *xorm.Session, a web.Auth, namespaceIDs []int64, rights ...Right) (bool, int, error) {
+	if len(namespaceIDs) == 0 {
+		return false, 0, nil
+	}
+
+	isOwner, err := s.
+		In("id", namespaceIDs).
+		And("owner_id = ?", a.GetID()).
+		Exist(&Namespace{})
+	if err != nil || isOwner {
+		return isOwner, int(RightAdmin), err
+	}
+
+	given := []Right{}
+	err = s.
+		Table("users_namespaces").
+		Cols("right").
+		In("namespace_id", namespaceIDs).
+		And("user_id = ?", a.GetID()).
+		Find(&given)
+	if err != nil {
+		return false, 0, err
+	}
+
+	teamRights := []Right{}
+	err = s.
+		Table("team_namespaces").
+		Select("team_namespaces.right").
+		Join("INNER", "team_members", "team_members.team_id = team_namespaces.team_id").
+		In("team_namespaces.namespace_id", namespaceIDs).
+		And("team_members.user_id = ?", a.GetID()).
+		Find(&teamRights)
+	if err != nil {
+		return false, 0, err
+	}
+	given = append(given, teamRights...)
+
+	var has bool
+	var maxRight = 0
+	for _, g := range given {
+		for _, r := range rights {
+			if g == r {
+				has = true
+			}
+		}
+		if int(g) > maxRight {
+			maxRight = int(g)
+		}
+	}
 
-	return exists, maxRights, err
+	return has, maxRight, nil
 }
@@ -37,6 +37,8 @@ type TeamNamespace struct {
 	NamespaceID int64 `xorm:"bigint not null INDEX" json:"-" param:"namespace"`
 	// The right this team has. 0 = Read only, 1 = Read & Write, 2 = Admin. See the docs for more details.
 	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|2)" maximum:"2" default:"0"`
+	// The custom role this share has. If set, the right is always read only and everything else is allowed by the role.
+	RoleID int64 `xorm:"bigint null INDEX" json:"role_id"`
 
 	// A timestamp when this relation was created. You cannot change this value.
 	Created time.Time `xorm:"created not null" json:"created"`
@@ -74,6 +76,10 @@ func (tn *TeamNamespace) Create(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	if err = checkShareRole(s, tn.RoleID, &tn.Right); err != nil {
+		return
+	}
+
 	// Check if the team exists
 	team, err := GetTeamByID(s, tn.TeamID)
 	if err != nil {
@@ -236,9 +242,13 @@ func (tn *TeamNamespace) Update(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	if err := checkShareRole(s, tn.RoleID, &tn.Right); err != nil {
+		return err
+	}
+
 	_, err = s.
 		Where("namespace_id = ? AND team_id = ?", tn.NamespaceID, tn.TeamID).
-		Cols("right").
+		Cols("right", "role_id").
 		Update(tn)
 	return
 }
@@ -23,18 +23,46 @@ import (
 
 // CanCreate checks if one can create a new team <-> namespace relation
 func (tn *TeamNamespace) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	n := &Namespace{ID: tn.NamespaceID}
-	return n.IsAdmin(s, a)
+	return tn.canDoTeamNamespace(s, a, tn.Right, tn.RoleID)
 }
 
-// CanDelete checks if a user can remove a team from a namespace. Only namespace admins can do that.
+// CanDelete checks if a user can remove a team from a namespace. Only namespace admins and users whose role allows sharing can do that.
 func (tn *TeamNamespace) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
-	n := &Namespace{ID: tn.NamespaceID}
-	return n.IsAdmin(s, a)
+	stored, err := tn.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return tn.canDoTeamNamespace(s, a, stored.Right, stored.RoleID)
 }
 
-// CanUpdate checks if a user can update a team from a  Only namespace admins can do that.
+// CanUpdate checks if a user can update a team from a namespace. Only namespace admins and users whose role allows sharing can do that.
 func (tn *TeamNamespace) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	can, err := tn.canDoTeamNamespace(s, a, tn.Right, tn.RoleID)
+	if err != nil || !can {
+		return can, err
+	}
+
+	// Whoever changes a share must be allowed to give the right it had before as well
+	stored, err := tn.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return tn.canDoTeamNamespace(s, a, stored.Right, stored.RoleID)
+}
+
+func (tn *TeamNamespace) canDoTeamNamespace(s *xorm.Session, a web.Auth, right Right, roleID int64) (bool, error) {
 	n := &Namespace{ID: tn.NamespaceID}
-	return n.IsAdmin(s, a)
+	return n.canShare(s, a, right, roleID)
+}
+
+// getStoredShare returns the share as it is saved right now. If there is none, it returns an empty share
+// so the update or delete can fail with the proper error.
+func (tn *TeamNamespace) getStoredShare(s *xorm.Session) (stored *TeamNamespace, err error) {
+	stored = &TeamNamespace{}
+	_, err = s.
+		Where("namespace_id = ? AND team_id = ?", tn.NamespaceID, tn.TeamID).
+		Get(stored)
+	return
 }
@@ -175,9 +175,13 @@ func TestNamespace_Delete(t *testing.T) {
 		err = s.Commit()
 		assert.NoError(t, err)
 
-		db.AssertMissing(t, "namespaces", map[string]interface{}{
-			"id": 1,
-		})
+		db.AssertExists(t, "namespaces", map[string]interface{}{
+			"id":                  1,
+			"deleted_by_id":       1,
+			"deleted_with_parent": false,
+		}, false)
+		assertTrashed(t, "namespaces", map[string]interface{}{"id": 1}, true)
+		assertTrashed(t, "lists", map[string]interface{}{"namespace_id": 1}, true)
 	})
 	t.Run("nonexisting", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
@@ -39,6 +39,8 @@ type NamespaceUser struct {
 	NamespaceID int64 `xorm:"bigint not null INDEX" json:"-" param:"namespace"`
 	// The right this user has. 0 = Read only, 1 = Read & Write, 2 = Admin. See the docs for more details.
 	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|2)" maximum:"2" default:"0"`
+	// The custom role this share has. If set, the right is always read only and everything else is allowed by the role.
+	RoleID int64 `xorm:"bigint null INDEX" json:"role_id"`
 
 	// A timestamp when this relation was created. You cannot change this value.
 	Created time.Time `xorm:"created not null" json:"created"`
@@ -78,6 +80,10 @@ func (nu *NamespaceUser) Create(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	if err := checkShareRole(s, nu.RoleID, &nu.Right); err != nil {
+		return err
+	}
+
 	// Check if the namespace exists
 	n, err := GetNamespaceByID(s, nu.NamespaceID)
 	if err != nil {
@@ -236,6 +242,10 @@ func (nu *NamespaceUser) Update(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 
+	if err := checkShareRole(s, nu.RoleID, &nu.Right); err != nil {
+		return err
+	}
+
 	// Check if the user exists
 	user, err := user2.GetUserByUsername(s, nu.Username)
 	if err != nil {
@@ -245,7 +255,7 @@ func (nu *NamespaceUser) Update(s *xorm.Session, a web.Auth) (err error) {
 
 	_, err = s.
 		Where("namespace_id = ? AND user_id = ?", nu.NamespaceID, nu.UserID).
-		Cols("right").
+		Cols("right", "role_id").
 		Update(nu)
 	return
 }
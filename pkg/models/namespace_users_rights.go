@@ -17,26 +17,61 @@
 package models
 
 import (
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
 	"xorm.io/xorm"
 )
 
 // CanCreate checks if the user can create a new user <-> namespace relation
 func (nu *NamespaceUser) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	return nu.canDoNamespaceUser(s, a)
+	return nu.canDoNamespaceUser(s, a, nu.Right, nu.RoleID)
 }
 
 // CanDelete checks if the user can delete a user <-> namespace relation
 func (nu *NamespaceUser) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
-	return nu.canDoNamespaceUser(s, a)
+	stored, err := nu.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return nu.canDoNamespaceUser(s, a, stored.Right, stored.RoleID)
 }
 
 // CanUpdate checks if the user can update a user <-> namespace relation
 func (nu *NamespaceUser) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
-	return nu.canDoNamespaceUser(s, a)
+	can, err := nu.canDoNamespaceUser(s, a, nu.Right, nu.RoleID)
+	if err != nil || !can {
+		return can, err
+	}
+
+	// Whoever changes a share must be allowed to give the right it had before as well
+	stored, err := nu.getStoredShare(s)
+	if err != nil {
+		return false, err
+	}
+
+	return nu.canDoNamespaceUser(s, a, stored.Right, stored.RoleID)
 }
 
-func (nu *NamespaceUser) canDoNamespaceUser(s *xorm.Session, a web.Auth) (bool, error) {
+func (nu *NamespaceUser) canDoNamespaceUser(s *xorm.Session, a web.Auth, right Right, roleID int64) (bool, error) {
 	n := &Namespace{ID: nu.NamespaceID}
-	return n.IsAdmin(s, a)
+	return n.canShare(s, a, right, roleID)
+}
+
+// getStoredShare returns the share as it is saved right now. If there is none, it returns an empty share
+// so the update or delete can fail with the proper error.
+func (nu *NamespaceUser) getStoredShare(s *xorm.Session) (stored *NamespaceUser, err error) {
+	stored = &NamespaceUser{}
+	u, err := user.GetUserByUsername(s, nu.Username)
+	if user.IsErrUserDoesNotExist(err) {
+		return stored, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.
+		Where("namespace_id = ? AND user_id = ?", nu.NamespaceID, u.ID).
+		Get(stored)
+	return
 }
@@ -26,6 +26,7 @@ import (
 	"code.vikunja.io/api/pkg/utils"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
 	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/user"
 )
@@ -34,27 +35,35 @@ import (
 type ReminderDueNotification struct {
 	User *user.User `json:"user"`
 	Task *Task      `json:"task"`
+
+	// The id of the snoozed reminder which triggered this notification, if any
+	snoozedReminderID int64
 }
 
 // ToMail returns the mail notification for ReminderDueNotification
-func (n *ReminderDueNotification) ToMail() *notifications.Mail {
+func (n *ReminderDueNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
 		To(n.User.Email).
-		Subject(`Reminder for "`+n.Task.Title+`"`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line(`This is a friendly reminder of the task "`+n.Task.Title+`".`).
-		Action("Open Task", config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.task.reminder.subject", n.Task.Title)).
+		Greeting(i18n.T(lang, "notifications.greeting", n.User.GetName())).
+		Line(i18n.T(lang, "notifications.task.reminder.message", n.Task.Title)).
+		Action(i18n.T(lang, "notifications.common.actions.open_task"), config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
 }
 
 // ToDB returns the ReminderDueNotification notification in a format which can be saved in the db
 func (n *ReminderDueNotification) ToDB() interface{} {
-	return nil
+	return n
 }
 
 // Name returns the name of the notification
 func (n *ReminderDueNotification) Name() string {
-	return ""
+	return "task.reminder"
+}
+
+// SubjectID returns the id of the task the reminder is about
+func (n *ReminderDueNotification) SubjectID() int64 {
+	return n.Task.ID
 }
 
 // TaskCommentNotification represents a TaskCommentNotification notification
@@ -70,15 +79,15 @@ func (n *TaskCommentNotification) SubjectID() int64 {
 }
 
 // ToMail returns the mail notification for TaskCommentNotification
-func (n *TaskCommentNotification) ToMail() *notifications.Mail {
+func (n *TaskCommentNotification) ToMail(lang string) *notifications.Mail {
 
 	mail := notifications.NewMail().
 		From(n.Doer.GetNameAndFromEmail())
 
-	subject := "Re: " + n.Task.Title
+	subject := i18n.T(lang, "notifications.task.comment.subject", n.Task.Title)
 	if n.Mentioned {
-		subject = n.Doer.GetName() + ` mentioned you in a comment in "` + n.Task.Title + `"`
-		mail.Line("**" + n.Doer.GetName() + "** mentioned you in a comment:")
+		subject = i18n.T(lang, "notifications.task.comment.mentioned_subject", n.Doer.GetName(), n.Task.Title)
+		mail.Line(i18n.T(lang, "notifications.task.comment.mentioned_message", n.Doer.GetName()))
 	}
 
 	mail.Subject(subject)
@@ -89,7 +98,7 @@ func (n *TaskCommentNotification) ToMail() *notifications.Mail {
 	}
 
 	return mail.
-		Action("View Task", n.Task.GetFrontendURL())
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the TaskCommentNotification notification in a format which can be saved in the db
@@ -110,11 +119,11 @@ type TaskAssignedNotification struct {
 }
 
 // ToMail returns the mail notification for TaskAssignedNotification
-func (n *TaskAssignedNotification) ToMail() *notifications.Mail {
+func (n *TaskAssignedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Task.Title+"("+n.Task.GetFullIdentifier()+")"+" has been assigned to "+n.Assignee.GetName()).
-		Line(n.Doer.GetName()+" has assigned this task to "+n.Assignee.GetName()+".").
-		Action("View Task", n.Task.GetFrontendURL())
+		Subject(i18n.T(lang, "notifications.task.assigned.subject", n.Task.Title, n.Task.GetFullIdentifier(), n.Assignee.GetName())).
+		Line(i18n.T(lang, "notifications.task.assigned.message", n.Doer.GetName(), n.Assignee.GetName())).
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the TaskAssignedNotification notification in a format which can be saved in the db
@@ -134,10 +143,10 @@ type TaskDeletedNotification struct {
 }
 
 // ToMail returns the mail notification for TaskDeletedNotification
-func (n *TaskDeletedNotification) ToMail() *notifications.Mail {
+func (n *TaskDeletedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Task.Title + "(" + n.Task.GetFullIdentifier() + ")" + " has been delete").
-		Line(n.Doer.GetName() + " has deleted the task " + n.Task.Title + "(" + n.Task.GetFullIdentifier() + ")")
+		Subject(i18n.T(lang, "notifications.task.deleted.subject", n.Task.Title, n.Task.GetFullIdentifier())).
+		Line(i18n.T(lang, "notifications.task.deleted.message", n.Doer.GetName(), n.Task.Title, n.Task.GetFullIdentifier()))
 }
 
 // ToDB returns the TaskDeletedNotification notification in a format which can be saved in the db
@@ -150,6 +159,165 @@ func (n *TaskDeletedNotification) Name() string {
 	return "task.deleted"
 }
 
+// TaskChange holds the old and new value of a single task property which was changed
+type TaskChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+
+	event SubscriptionEvents
+}
+
+func formatTaskChangeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC1123)
+}
+
+// getTaskChanges compares two versions of a task and returns all properties which changed between them
+func getTaskChanges(oldTask, newTask *Task) (changes []*TaskChange) {
+	add := func(field, o, n string, event SubscriptionEvents) {
+		if o == n {
+			return
+		}
+		changes = append(changes, &TaskChange{Field: field, Old: o, New: n, event: event})
+	}
+
+	add("title", oldTask.Title, newTask.Title, SubscriptionEventTaskChanges)
+	add("description", oldTask.Description, newTask.Description, SubscriptionEventTaskChanges)
+	add("done", strconv.FormatBool(oldTask.Done), strconv.FormatBool(newTask.Done), SubscriptionEventStatusChanges)
+	add("due_date", formatTaskChangeTime(oldTask.DueDate), formatTaskChangeTime(newTask.DueDate), SubscriptionEventDueDateChanges)
+	add("start_date", formatTaskChangeTime(oldTask.StartDate), formatTaskChangeTime(newTask.StartDate), SubscriptionEventTaskChanges)
+	add("end_date", formatTaskChangeTime(oldTask.EndDate), formatTaskChangeTime(newTask.EndDate), SubscriptionEventTaskChanges)
+	add("priority", strconv.FormatInt(oldTask.Priority, 10), strconv.FormatInt(newTask.Priority, 10), SubscriptionEventTaskChanges)
+	add("percent_done", strconv.FormatFloat(oldTask.PercentDone, 'f', -1, 64), strconv.FormatFloat(newTask.PercentDone, 'f', -1, 64), SubscriptionEventTaskChanges)
+	add("repeat_after", strconv.FormatInt(oldTask.RepeatAfter, 10), strconv.FormatInt(newTask.RepeatAfter, 10), SubscriptionEventTaskChanges)
+	add("hex_color", oldTask.HexColor, newTask.HexColor, SubscriptionEventTaskChanges)
+	add("list_id", strconv.FormatInt(oldTask.ListID, 10), strconv.FormatInt(newTask.ListID, 10), SubscriptionEventTaskChanges)
+
+	return
+}
+
+// filterTaskChanges returns only those changes a subscription with the given events wants to be notified about
+func filterTaskChanges(changes []*TaskChange, events SubscriptionEvents) (filtered []*TaskChange) {
+	for _, c := range changes {
+		if events.Has(c.event) {
+			filtered = append(filtered, c)
+		}
+	}
+	return
+}
+
+// TaskUpdatedNotification represents a TaskUpdatedNotification notification
+type TaskUpdatedNotification struct {
+	Doer    *user.User    `json:"doer"`
+	Task    *Task         `json:"task"`
+	Changes []*TaskChange `json:"changes"`
+}
+
+// ToMail returns the mail notification for TaskUpdatedNotification
+func (n *TaskUpdatedNotification) ToMail(lang string) *notifications.Mail {
+	mail := notifications.NewMail().
+		Subject(i18n.T(lang, "notifications.task.updated.subject", n.Task.Title, n.Task.GetFullIdentifier())).
+		Line(i18n.T(lang, "notifications.task.updated.message", n.Doer.GetName()))
+
+	for _, c := range n.Changes {
+		field := i18n.T(lang, "notifications.task.updated.fields."+c.Field)
+		switch {
+		case c.Field == "done" && c.New == "true":
+			mail.Line(i18n.T(lang, "notifications.task.updated.marked_done"))
+		case c.Field == "done":
+			mail.Line(i18n.T(lang, "notifications.task.updated.marked_undone"))
+		case c.Field == "description":
+			mail.Line(i18n.T(lang, "notifications.task.updated.description_changed"))
+		case c.Old == "":
+			mail.Line(i18n.T(lang, "notifications.task.updated.set", field, c.New))
+		case c.New == "":
+			mail.Line(i18n.T(lang, "notifications.task.updated.removed", field))
+		default:
+			mail.Line(i18n.T(lang, "notifications.task.updated.changed", field, c.Old, c.New))
+		}
+	}
+
+	return mail.
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
+}
+
+// ToDB returns the TaskUpdatedNotification notification in a format which can be saved in the db
+func (n *TaskUpdatedNotification) ToDB() interface{} {
+	return n
+}
+
+// Name returns the name of the notification
+func (n *TaskUpdatedNotification) Name() string {
+	return "task.updated"
+}
+
+// TaskAttachmentNotification represents a TaskAttachmentNotification notification
+type TaskAttachmentNotification struct {
+	Doer       *user.User      `json:"doer"`
+	Task       *Task           `json:"task"`
+	Attachment *TaskAttachment `json:"attachment"`
+	Deleted    bool            `json:"deleted"`
+}
+
+// ToMail returns the mail notification for TaskAttachmentNotification
+func (n *TaskAttachmentNotification) ToMail(lang string) *notifications.Mail {
+	key := "notifications.task.attachment.added"
+	if n.Deleted {
+		key = "notifications.task.attachment.deleted"
+	}
+
+	fileName := ""
+	if n.Attachment.File != nil {
+		fileName = n.Attachment.File.Name
+	}
+
+	return notifications.NewMail().
+		Subject(i18n.T(lang, key+"_subject", n.Task.Title, n.Task.GetFullIdentifier())).
+		Line(i18n.T(lang, key+"_message", n.Doer.GetName(), fileName)).
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
+}
+
+// ToDB returns the TaskAttachmentNotification notification in a format which can be saved in the db
+func (n *TaskAttachmentNotification) ToDB() interface{} {
+	return n
+}
+
+// Name returns the name of the notification
+func (n *TaskAttachmentNotification) Name() string {
+	if n.Deleted {
+		return "task.attachment.deleted"
+	}
+	return "task.attachment.created"
+}
+
+// TaskMovedNotification represents a TaskMovedNotification notification
+type TaskMovedNotification struct {
+	Doer   *user.User `json:"doer"`
+	Task   *Task      `json:"task"`
+	Bucket *Bucket    `json:"bucket"`
+}
+
+// ToMail returns the mail notification for TaskMovedNotification
+func (n *TaskMovedNotification) ToMail(lang string) *notifications.Mail {
+	return notifications.NewMail().
+		Subject(i18n.T(lang, "notifications.task.moved.subject", n.Task.Title, n.Task.GetFullIdentifier(), n.Bucket.Title)).
+		Line(i18n.T(lang, "notifications.task.moved.message", n.Doer.GetName(), n.Bucket.Title)).
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
+}
+
+// ToDB returns the TaskMovedNotification notification in a format which can be saved in the db
+func (n *TaskMovedNotification) ToDB() interface{} {
+	return n
+}
+
+// Name returns the name of the notification
+func (n *TaskMovedNotification) Name() string {
+	return "task.moved"
+}
+
 // ListCreatedNotification represents a ListCreatedNotification notification
 type ListCreatedNotification struct {
 	Doer *user.User `json:"doer"`
@@ -157,11 +325,11 @@ type ListCreatedNotification struct {
 }
 
 // ToMail returns the mail notification for ListCreatedNotification
-func (n *ListCreatedNotification) ToMail() *notifications.Mail {
+func (n *ListCreatedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Doer.GetName()+` created the list "`+n.List.Title+`"`).
-		Line(n.Doer.GetName()+` created the list "`+n.List.Title+`"`).
-		Action("View List", config.ServiceFrontendurl.GetString()+"lists/")
+		Subject(i18n.T(lang, "notifications.list.created.subject", n.Doer.GetName(), n.List.Title)).
+		Line(i18n.T(lang, "notifications.list.created.message", n.Doer.GetName(), n.List.Title)).
+		Action(i18n.T(lang, "notifications.common.actions.view_list"), config.ServiceFrontendurl.GetString()+"lists/")
 }
 
 // ToDB returns the ListCreatedNotification notification in a format which can be saved in the db
@@ -182,13 +350,13 @@ type TeamMemberAddedNotification struct {
 }
 
 // ToMail returns the mail notification for TeamMemberAddedNotification
-func (n *TeamMemberAddedNotification) ToMail() *notifications.Mail {
+func (n *TeamMemberAddedNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject(n.Doer.GetName()+" added you to the "+n.Team.Name+" team in Vikunja").
+		Subject(i18n.T(lang, "notifications.team.member_added.subject", n.Doer.GetName(), n.Team.Name)).
 		From(n.Doer.GetNameAndFromEmail()).
-		Greeting("Hi "+n.Member.GetName()+",").
-		Line(n.Doer.GetName()+" has just added you to the "+n.Team.Name+" team in Vikunja.").
-		Action("View Team", config.ServiceFrontendurl.GetString()+"teams/"+strconv.FormatInt(n.Team.ID, 10)+"/edit")
+		Greeting(i18n.T(lang, "notifications.greeting", n.Member.GetName())).
+		Line(i18n.T(lang, "notifications.team.member_added.message", n.Doer.GetName(), n.Team.Name)).
+		Action(i18n.T(lang, "notifications.common.actions.view_team"), config.ServiceFrontendurl.GetString()+"teams/"+strconv.FormatInt(n.Team.ID, 10)+"/edit")
 }
 
 // ToDB returns the TeamMemberAddedNotification notification in a format which can be saved in the db
@@ -208,14 +376,14 @@ type UndoneTaskOverdueNotification struct {
 }
 
 // ToMail returns the mail notification for UndoneTaskOverdueNotification
-func (n *UndoneTaskOverdueNotification) ToMail() *notifications.Mail {
+func (n *UndoneTaskOverdueNotification) ToMail(lang string) *notifications.Mail {
 	until := time.Until(n.Task.DueDate).Round(1*time.Hour) * -1
 	return notifications.NewMail().
-		Subject(`Task "`+n.Task.Title+`" is overdue`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line(`This is a friendly reminder of the task "`+n.Task.Title+`" which is overdue since `+utils.HumanizeDuration(until)+` and not yet done.`).
-		Action("Open Task", config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.task.overdue.subject", n.Task.Title)).
+		Greeting(i18n.T(lang, "notifications.greeting", n.User.GetName())).
+		Line(i18n.T(lang, "notifications.task.overdue.message", n.Task.Title, utils.HumanizeDuration(until))).
+		Action(i18n.T(lang, "notifications.common.actions.open_task"), config.ServiceFrontendurl.GetString()+"tasks/"+strconv.FormatInt(n.Task.ID, 10)).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
 }
 
 // ToDB returns the UndoneTaskOverdueNotification notification in a format which can be saved in the db
@@ -235,7 +403,7 @@ type UndoneTasksOverdueNotification struct {
 }
 
 // ToMail returns the mail notification for UndoneTasksOverdueNotification
-func (n *UndoneTasksOverdueNotification) ToMail() *notifications.Mail {
+func (n *UndoneTasksOverdueNotification) ToMail(lang string) *notifications.Mail {
 
 	sortedTasks := make([]*Task, 0, len(n.Tasks))
 	for _, task := range n.Tasks {
@@ -249,16 +417,16 @@ func (n *UndoneTasksOverdueNotification) ToMail() *notifications.Mail {
 	overdueLine := ""
 	for _, task := range sortedTasks {
 		until := time.Until(task.DueDate).Round(1*time.Hour) * -1
-		overdueLine += `* [` + task.Title + `](` + config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(task.ID, 10) + `), overdue since ` + utils.HumanizeDuration(until) + "\n"
+		overdueLine += `* [` + task.Title + `](` + config.ServiceFrontendurl.GetString() + "tasks/" + strconv.FormatInt(task.ID, 10) + `), ` + i18n.T(lang, "notifications.task.overdue.overdue_since", utils.HumanizeDuration(until)) + "\n"
 	}
 
 	return notifications.NewMail().
-		Subject(`Your overdue tasks`).
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("You have the following overdue tasks:").
+		Subject(i18n.T(lang, "notifications.task.overdue.multiple_subject")).
+		Greeting(i18n.T(lang, "notifications.greeting", n.User.GetName())).
+		Line(i18n.T(lang, "notifications.task.overdue.multiple_message")).
 		Line(overdueLine).
-		Action("Open Vikunja", config.ServiceFrontendurl.GetString()).
-		Line("Have a nice day!")
+		Action(i18n.T(lang, "notifications.common.actions.open_vikunja"), config.ServiceFrontendurl.GetString()).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
 }
 
 // ToDB returns the UndoneTasksOverdueNotification notification in a format which can be saved in the db
@@ -283,16 +451,16 @@ func (n *UserMentionedInTaskNotification) SubjectID() int64 {
 }
 
 // ToMail returns the mail notification for UserMentionedInTaskNotification
-func (n *UserMentionedInTaskNotification) ToMail() *notifications.Mail {
-	subject := n.Doer.GetName() + ` mentioned you in a new task "` + n.Task.Title + `"`
+func (n *UserMentionedInTaskNotification) ToMail(lang string) *notifications.Mail {
+	subject := i18n.T(lang, "notifications.task.mentioned.subject", n.Doer.GetName(), n.Task.Title)
 	if n.IsNew {
-		subject = n.Doer.GetName() + ` mentioned you in a task "` + n.Task.Title + `"`
+		subject = i18n.T(lang, "notifications.task.mentioned.subject_new", n.Doer.GetName(), n.Task.Title)
 	}
 
 	mail := notifications.NewMail().
 		From(n.Doer.GetNameAndFromEmail()).
 		Subject(subject).
-		Line("**" + n.Doer.GetName() + "** mentioned you in a task:")
+		Line(i18n.T(lang, "notifications.task.mentioned.message", n.Doer.GetName()))
 
 	lines := bufio.NewScanner(strings.NewReader(n.Task.Description))
 	for lines.Scan() {
@@ -300,7 +468,7 @@ func (n *UserMentionedInTaskNotification) ToMail() *notifications.Mail {
 	}
 
 	return mail.
-		Action("View Task", n.Task.GetFrontendURL())
+		Action(i18n.T(lang, "notifications.common.actions.view_task"), n.Task.GetFrontendURL())
 }
 
 // ToDB returns the UserMentionedInTaskNotification notification in a format which can be saved in the db
@@ -319,14 +487,14 @@ type DataExportReadyNotification struct {
 }
 
 // ToMail returns the mail notification for DataExportReadyNotification
-func (n *DataExportReadyNotification) ToMail() *notifications.Mail {
+func (n *DataExportReadyNotification) ToMail(lang string) *notifications.Mail {
 	return notifications.NewMail().
-		Subject("Your Vikunja Data Export is ready").
-		Greeting("Hi "+n.User.GetName()+",").
-		Line("Your Vikunja Data Export is ready for you to download. Click the button below to download it:").
-		Action("Download", config.ServiceFrontendurl.GetString()+"user/export/download").
-		Line("The download will be available for the next 7 days.").
-		Line("Have a nice day!")
+		Subject(i18n.T(lang, "notifications.data_export.ready.subject")).
+		Greeting(i18n.T(lang, "notifications.greeting", n.User.GetName())).
+		Line(i18n.T(lang, "notifications.data_export.ready.message")).
+		Action(i18n.T(lang, "notifications.common.actions.download"), config.ServiceFrontendurl.GetString()+"user/export/download").
+		Line(i18n.T(lang, "notifications.data_export.ready.availability")).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
 }
 
 // ToDB returns the DataExportReadyNotification notification in a format which can be saved in the db
@@ -338,3 +506,46 @@ func (n *DataExportReadyNotification) ToDB() interface{} {
 func (n *DataExportReadyNotification) Name() string {
 	return "data.export.ready"
 }
+
+// ShareInvitationNotification represents a ShareInvitationNotification notification
+type ShareInvitationNotification struct {
+	Doer *user.User
+	// Either List or Team is set
+	List            *List
+	Team            *Team
+	InvitationToken string
+	Expires         time.Time
+}
+
+// ToMail returns the mail notification for ShareInvitationNotification
+func (n *ShareInvitationNotification) ToMail(lang string) *notifications.Mail {
+	subject := "notifications.invitation.team.subject"
+	message := "notifications.invitation.team.message"
+	var title string
+	if n.List != nil {
+		subject = "notifications.invitation.list.subject"
+		message = "notifications.invitation.list.message"
+		title = n.List.Title
+	} else {
+		title = n.Team.Name
+	}
+
+	return notifications.NewMail().
+		Subject(i18n.T(lang, subject, n.Doer.GetName(), title)).
+		From(n.Doer.GetNameAndFromEmail()).
+		Greeting(i18n.T(lang, "notifications.invitation.greeting")).
+		Line(i18n.T(lang, message, n.Doer.GetName(), title)).
+		Action(i18n.T(lang, "notifications.invitation.action"), config.ServiceFrontendurl.GetString()+"register?invitation="+n.InvitationToken).
+		Line(i18n.T(lang, "notifications.invitation.expires", n.Expires.Format("2006-01-02"))).
+		Line(i18n.T(lang, "notifications.common.have_nice_day"))
+}
+
+// ToDB returns the ShareInvitationNotification notification in a format which can be saved in the db
+func (n *ShareInvitationNotification) ToDB() interface{} {
+	return nil
+}
+
+// Name returns the name of the notification
+func (n *ShareInvitationNotification) Name() string {
+	return "share.invitation"
+}
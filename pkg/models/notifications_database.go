@@ -17,11 +17,27 @@
 package models
 
 import (
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
 	"xorm.io/xorm"
 )
 
+const (
+	// NotificationSnooze10Minutes snoozes a notification for ten minutes
+	NotificationSnooze10Minutes = "10m"
+	// NotificationSnooze1Hour snoozes a notification for one hour
+	NotificationSnooze1Hour = "1h"
+	// NotificationSnoozeTomorrow snoozes a notification until 9 am the next day in the user's time zone
+	NotificationSnoozeTomorrow = "tomorrow"
+	// NotificationSnoozeCustom snoozes a notification until the time provided in snooze_until
+	NotificationSnoozeCustom = "custom"
+)
+
 // DatabaseNotifications is a wrapper around the crud operations that come with a database notification.
 type DatabaseNotifications struct {
 	notifications.DatabaseNotification
@@ -30,6 +46,19 @@ type DatabaseNotifications struct {
 	// True is read, false is unread.
 	Read bool `xorm:"-" json:"read"`
 
+	// If set, the notification is snoozed instead of marked as (un-)read. Can be one of `10m`, `1h`, `tomorrow` or
+	// `custom`. If the notification is a task reminder, the reminder will be sent again at the snoozed time.
+	Snooze string `xorm:"-" json:"snooze"`
+	// The time until the notification should be snoozed. Only used when `snooze` is `custom`.
+	SnoozeUntil time.Time `xorm:"-" json:"snooze_until"`
+
+	// Only return notifications with this name when listing notifications.
+	FilterName string `xorm:"-" json:"-" query:"name"`
+	// Only return notifications about this subject when listing notifications.
+	FilterSubjectID int64 `xorm:"-" json:"-" query:"subject_id"`
+	// If set to `true` or `false`, only return read or unread notifications when listing notifications.
+	FilterRead string `xorm:"-" json:"-" query:"read"`
+
 	web.CRUDable `xorm:"-" json:"-"`
 	web.Rights   `xorm:"-" json:"-"`
 }
@@ -42,6 +71,9 @@ type DatabaseNotifications struct {
 // @Produce json
 // @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
 // @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param name query string false "Only return notifications with this name, for example `task.comment`."
+// @Param subject_id query int false "Only return notifications about this subject."
+// @Param read query bool false "If `true`, only return read notifications. If `false`, only return unread notifications."
 // @Security JWTKeyAuth
 // @Success 200 {array} notifications.DatabaseNotification "The notifications"
 // @Failure 403 {object} web.HTTPError "Link shares cannot have notifications."
@@ -52,8 +84,20 @@ func (d *DatabaseNotifications) ReadAll(s *xorm.Session, a web.Auth, search stri
 		return nil, 0, 0, ErrGenericForbidden{}
 	}
 
+	filter := &notifications.Filter{
+		Name:      d.FilterName,
+		SubjectID: d.FilterSubjectID,
+	}
+	if d.FilterRead != "" {
+		read, err := strconv.ParseBool(d.FilterRead)
+		if err != nil {
+			return nil, 0, 0, ErrInvalidData{Message: "read must be either true or false"}
+		}
+		filter.Read = &read
+	}
+
 	limit, start := getLimitFromPageIndex(page, perPage)
-	return notifications.GetNotificationsForUser(s, a.GetID(), limit, start)
+	return notifications.GetNotificationsForUser(s, a.GetID(), filter, limit, start)
 }
 
 // CanUpdate checks if a user can mark a notification as read.
@@ -65,9 +109,10 @@ func (d *DatabaseNotifications) CanUpdate(s *xorm.Session, a web.Auth) (bool, er
 	return notifications.CanMarkNotificationAsRead(s, &d.DatabaseNotification, a.GetID())
 }
 
-// Update marks a notification as read.
-// @Summary Mark a notification as (un-)read
+// Update marks a notification as read or snoozes it.
+// @Summary Mark a notification as (un-)read or snooze it
 // @Description Marks a notification as either read or unread. A user can only mark their own notifications as read.
+// @Description If `snooze` is set, the notification is hidden until the snooze time instead. Snoozing a task reminder reschedules the reminder for the current user.
 // @tags subscriptions
 // @Accept json
 // @Produce json
@@ -77,8 +122,91 @@ func (d *DatabaseNotifications) CanUpdate(s *xorm.Session, a web.Auth) (bool, er
 // @Failure 403 {object} web.HTTPError "The user does not have access to that notification."
 // @Failure 403 {object} web.HTTPError "Link shares cannot have notifications."
 // @Failure 404 {object} web.HTTPError "The notification does not exist."
+// @Failure 412 {object} web.HTTPError "The snooze option is invalid or the snooze time is in the past."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /notifications/{id} [post]
 func (d *DatabaseNotifications) Update(s *xorm.Session, a web.Auth) (err error) {
-	return notifications.MarkNotificationAsRead(s, &d.DatabaseNotification, d.Read)
+	if d.Snooze == "" {
+		return notifications.MarkNotificationAsRead(s, &d.DatabaseNotification, d.Read)
+	}
+
+	u, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return err
+	}
+
+	until, err := getNotificationSnoozeTime(d.Snooze, d.SnoozeUntil, u, time.Now())
+	if err != nil {
+		return err
+	}
+	if !until.After(time.Now()) {
+		return &ErrNotificationSnoozeTimeInPast{NotificationID: d.ID}
+	}
+
+	// Reminders are snoozed by rescheduling them, the reminder cron will then send a new notification.
+	if d.Name == (&ReminderDueNotification{}).Name() {
+		err = snoozeReminder(s, d.SubjectID, u.ID, until)
+		if err != nil {
+			return err
+		}
+		return notifications.MarkNotificationAsRead(s, &d.DatabaseNotification, true)
+	}
+
+	return notifications.SnoozeNotification(s, &d.DatabaseNotification, until)
+}
+
+func getNotificationSnoozeTime(snooze string, custom time.Time, u *user.User, now time.Time) (until time.Time, err error) {
+	switch snooze {
+	case NotificationSnooze10Minutes:
+		return now.Add(10 * time.Minute), nil
+	case NotificationSnooze1Hour:
+		return now.Add(time.Hour), nil
+	case NotificationSnoozeTomorrow:
+		tz := config.GetTimeZone()
+		if u.Timezone != "" {
+			tz, err = time.LoadLocation(u.Timezone)
+			if err != nil {
+				return
+			}
+		}
+		local := now.In(tz)
+		return time.Date(local.Year(), local.Month(), local.Day()+1, 9, 0, 0, 0, tz), nil
+	case NotificationSnoozeCustom:
+		return custom, nil
+	}
+
+	return until, &ErrInvalidNotificationSnooze{Snooze: snooze}
+}
+
+// DatabaseNotificationsBulk holds the options to mark multiple notifications as read or unread at once.
+type DatabaseNotificationsBulk struct {
+	// Whether to mark the notifications as read or unread.
+	Read bool `json:"read"`
+	// If set, only notifications with this name are changed.
+	Name string `json:"name"`
+	// If set, only notifications about this subject are changed.
+	SubjectID int64 `json:"subject_id"`
+}
+
+// MarkAllNotificationsAsRead marks all notifications of the current user matching the bulk options as read or unread.
+// It returns the number of notifications which were changed.
+func MarkAllNotificationsAsRead(s *xorm.Session, a web.Auth, bulk *DatabaseNotificationsBulk) (count int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return 0, ErrGenericForbidden{}
+	}
+
+	filter := &notifications.Filter{
+		Name:      bulk.Name,
+		SubjectID: bulk.SubjectID,
+	}
+	return notifications.MarkAllNotificationsAsRead(s, a.GetID(), filter, bulk.Read)
+}
+
+// GetUnreadNotificationCount returns the number of unread notifications of the current user.
+func GetUnreadNotificationCount(s *xorm.Session, a web.Auth) (count int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return 0, ErrGenericForbidden{}
+	}
+
+	return notifications.GetUnreadNotificationCount(s, a.GetID())
 }
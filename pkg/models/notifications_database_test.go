@@ -0,0 +1,57 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNotificationSnoozeTime(t *testing.T) {
+	now := time.Date(2021, 3, 4, 23, 30, 0, 0, time.UTC)
+	u := &user.User{ID: 1, Timezone: "Europe/Berlin"}
+
+	t.Run("10 minutes", func(t *testing.T) {
+		until, err := getNotificationSnoozeTime(NotificationSnooze10Minutes, time.Time{}, u, now)
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(10*time.Minute), until)
+	})
+	t.Run("1 hour", func(t *testing.T) {
+		until, err := getNotificationSnoozeTime(NotificationSnooze1Hour, time.Time{}, u, now)
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(time.Hour), until)
+	})
+	t.Run("tomorrow in the user's time zone", func(t *testing.T) {
+		until, err := getNotificationSnoozeTime(NotificationSnoozeTomorrow, time.Time{}, u, now)
+		assert.NoError(t, err)
+		// 23:30 UTC is already the 5th in Berlin
+		assert.Equal(t, time.Date(2021, 3, 6, 8, 0, 0, 0, time.UTC), until.UTC())
+	})
+	t.Run("custom", func(t *testing.T) {
+		custom := now.Add(72 * time.Hour)
+		until, err := getNotificationSnoozeTime(NotificationSnoozeCustom, custom, u, now)
+		assert.NoError(t, err)
+		assert.Equal(t, custom, until)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := getNotificationSnoozeTime("forever", time.Time{}, u, now)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidNotificationSnooze(err))
+	})
+}
@@ -0,0 +1,191 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"xorm.io/xorm"
+)
+
+// PublicListView is a read-only view of a list which can be shown to anyone who knows the hash of a public link share.
+// It only contains what is needed to display the list, no ids of users and never any email addresses.
+type PublicListView struct {
+	// The title of the list.
+	Title string `json:"title"`
+	// The description of the list.
+	Description string `json:"description"`
+	// The color of the list.
+	HexColor string `json:"hex_color"`
+	// All tasks of the list. Empty if the view is a kanban board, the tasks are in their buckets then.
+	Tasks []*PublicTask `json:"tasks"`
+	// All buckets of the list with their tasks. Only set if the view is a kanban board.
+	Buckets []*PublicBucket `json:"buckets,omitempty"`
+	// A timestamp when the list was last updated.
+	Updated time.Time `json:"updated"`
+
+	// Whether the link share can only be used from some ip addresses. Shared caches must not store such views.
+	IsIPRestricted bool `json:"-"`
+}
+
+// PublicBucket is a kanban bucket in a public list view.
+type PublicBucket struct {
+	ID    int64         `json:"id"`
+	Title string        `json:"title"`
+	Tasks []*PublicTask `json:"tasks"`
+}
+
+// PublicTask is a task in a public list view.
+type PublicTask struct {
+	ID          int64           `json:"id"`
+	Identifier  string          `json:"identifier"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Done        bool            `json:"done"`
+	DoneAt      time.Time       `json:"done_at"`
+	DueDate     time.Time       `json:"due_date"`
+	StartDate   time.Time       `json:"start_date"`
+	EndDate     time.Time       `json:"end_date"`
+	Priority    int64           `json:"priority"`
+	PercentDone float64         `json:"percent_done"`
+	HexColor    string          `json:"hex_color"`
+	Labels      []*PublicLabel  `json:"labels"`
+	Assignees   []*PublicPerson `json:"assignees"`
+
+	bucketID int64
+}
+
+// PublicLabel is a label of a task in a public list view.
+type PublicLabel struct {
+	Title    string `json:"title"`
+	HexColor string `json:"hex_color"`
+}
+
+// PublicPerson is an assignee of a task in a public list view.
+type PublicPerson struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+func newPublicTask(t *Task) *PublicTask {
+	pt := &PublicTask{
+		ID:          t.ID,
+		Identifier:  t.GetFullIdentifier(),
+		Title:       t.Title,
+		Description: t.Description,
+		Done:        t.Done,
+		DoneAt:      t.DoneAt,
+		DueDate:     t.DueDate,
+		StartDate:   t.StartDate,
+		EndDate:     t.EndDate,
+		Priority:    t.Priority,
+		PercentDone: t.PercentDone,
+		HexColor:    t.HexColor,
+		Labels:      make([]*PublicLabel, 0, len(t.Labels)),
+		Assignees:   make([]*PublicPerson, 0, len(t.Assignees)),
+		bucketID:    t.BucketID,
+	}
+
+	for _, l := range t.Labels {
+		pt.Labels = append(pt.Labels, &PublicLabel{Title: l.Title, HexColor: l.HexColor})
+	}
+	for _, a := range t.Assignees {
+		pt.Assignees = append(pt.Assignees, &PublicPerson{Username: a.Username, Name: a.GetName()})
+	}
+
+	return pt
+}
+
+// GetPublicListView returns the public view of the list a link share belongs to. The link share needs to be public,
+// must not be protected by a password and usable from the ip address, otherwise it is treated as if it would not exist.
+func GetPublicListView(s *xorm.Session, hash string, ip string, kanban bool) (view *PublicListView, err error) {
+	share, err := GetLinkShareByHash(s, hash)
+	if err != nil {
+		return nil, err
+	}
+	// The public view has no way to ask for the password
+	if !share.IsPublic || share.SharingType == SharingTypeWithPassword {
+		return nil, ErrListShareDoesNotExist{Hash: hash}
+	}
+
+	err = share.CheckAccess(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := GetListSimpleByID(s, share.ListID)
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy := taskPropertyPosition
+	if kanban {
+		sortBy = taskPropertyKanbanPosition
+	}
+	tasks, _, _, err := getTasksForLists(s, []*List{list}, share, &taskOptions{
+		sortby: []*sortParam{
+			{sortBy: sortBy, orderBy: orderAscending},
+			{sortBy: taskPropertyID, orderBy: orderAscending},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	view = &PublicListView{
+		Title:       list.Title,
+		Description: list.Description,
+		HexColor:    list.HexColor,
+		Tasks:       make([]*PublicTask, 0, len(tasks)),
+		Updated:     list.Updated,
+
+		IsIPRestricted: len(share.IPAllowlist) > 0,
+	}
+	for _, t := range tasks {
+		view.Tasks = append(view.Tasks, newPublicTask(t))
+	}
+
+	if !kanban {
+		return view, nil
+	}
+
+	buckets := []*Bucket{}
+	err = s.
+		Where("list_id = ?", list.ID).
+		OrderBy("position asc").
+		Find(&buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketMap := make(map[int64]*PublicBucket, len(buckets))
+	view.Buckets = make([]*PublicBucket, 0, len(buckets))
+	for _, b := range buckets {
+		pb := &PublicBucket{ID: b.ID, Title: b.Title, Tasks: []*PublicTask{}}
+		bucketMap[b.ID] = pb
+		view.Buckets = append(view.Buckets, pb)
+	}
+
+	for _, t := range view.Tasks {
+		if b, exists := bucketMap[t.bucketID]; exists {
+			b.Tasks = append(b.Tasks, t)
+		}
+	}
+	view.Tasks = []*PublicTask{}
+
+	return view, nil
+}
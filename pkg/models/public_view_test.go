@@ -0,0 +1,117 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPublicListView(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("is_public").Update(&LinkSharing{IsPublic: true})
+		assert.NoError(t, err)
+
+		view, err := GetPublicListView(s, "test", "127.0.0.1", false)
+		assert.NoError(t, err)
+		assert.Equal(t, "Test1", view.Title)
+		assert.Len(t, view.Tasks, 18)
+		assert.Empty(t, view.Buckets)
+		assert.False(t, view.IsIPRestricted)
+
+		var withAssignees *PublicTask
+		for _, task := range view.Tasks {
+			if task.ID == 30 {
+				withAssignees = task
+			}
+		}
+		assert.NotNil(t, withAssignees)
+		assert.Len(t, withAssignees.Assignees, 2)
+		assert.Equal(t, "user1", withAssignees.Assignees[0].Username)
+	})
+	t.Run("kanban", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("is_public").Update(&LinkSharing{IsPublic: true})
+		assert.NoError(t, err)
+
+		view, err := GetPublicListView(s, "test", "127.0.0.1", true)
+		assert.NoError(t, err)
+		assert.Empty(t, view.Tasks)
+		assert.Len(t, view.Buckets, 3)
+		for _, b := range view.Buckets {
+			if b.ID == 1 {
+				assert.Equal(t, "testbucket1", b.Title)
+				assert.NotEmpty(t, b.Tasks)
+			}
+		}
+	})
+	t.Run("restricted to some ip addresses", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("is_public", "ip_allowlist").Update(&LinkSharing{IsPublic: true, IPAllowlist: []string{"127.0.0.0/8"}})
+		assert.NoError(t, err)
+
+		view, err := GetPublicListView(s, "test", "127.0.0.1", false)
+		assert.NoError(t, err)
+		assert.True(t, view.IsIPRestricted)
+	})
+	t.Run("not public", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := GetPublicListView(s, "test", "127.0.0.1", false)
+		assert.Error(t, err)
+		assert.True(t, IsErrListShareDoesNotExist(err))
+	})
+	t.Run("protected by a password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 4).Cols("is_public").Update(&LinkSharing{IsPublic: true})
+		assert.NoError(t, err)
+
+		_, err = GetPublicListView(s, "testWithPassword", "127.0.0.1", false)
+		assert.Error(t, err)
+		assert.True(t, IsErrListShareDoesNotExist(err))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("is_public", "is_disabled").Update(&LinkSharing{IsPublic: true, IsDisabled: true})
+		assert.NoError(t, err)
+
+		_, err = GetPublicListView(s, "test", "127.0.0.1", false)
+		assert.Error(t, err)
+		assert.True(t, IsErrLinkShareDisabled(err))
+	})
+}
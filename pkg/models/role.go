@@ -0,0 +1,389 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// Permission is a single thing a role can allow someone to do on a list
+type Permission int
+
+// All permissions a role can grant
+const (
+	// Can comment on tasks
+	PermissionComment Permission = iota
+	// Can create new tasks
+	PermissionCreateTask
+	// Can edit existing tasks, including their assignees, labels, attachments and relations
+	PermissionEditTask
+	// Can delete tasks
+	PermissionDeleteTask
+	// Can create, edit and delete kanban buckets
+	PermissionManageBuckets
+	// Can share the list or namespace with others. Someone who can share because of their role cannot give anyone more rights or permissions than they have themselves.
+	PermissionShare
+)
+
+// column returns the column in the roles table which holds the permission
+func (p Permission) column() string {
+	switch p {
+	case PermissionComment:
+		return "can_comment"
+	case PermissionCreateTask:
+		return "can_create_task"
+	case PermissionEditTask:
+		return "can_edit_task"
+	case PermissionDeleteTask:
+		return "can_delete_task"
+	case PermissionManageBuckets:
+		return "can_manage_buckets"
+	case PermissionShare:
+		return "can_share"
+	}
+	return ""
+}
+
+// Role is a custom set of permissions which can be assigned to users, teams and link shares on lists and namespaces.
+// Someone who has access through a share with a role can read everything and do what the role allows.
+type Role struct {
+	// The unique, numeric id of this role.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"role"`
+	// The title of the role. You'll see this when sharing a list or namespace.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The description of the role.
+	Description string `xorm:"longtext null" json:"description"`
+
+	// Whether someone with this role can comment on tasks.
+	CanComment bool `xorm:"bool not null default false" json:"can_comment"`
+	// Whether someone with this role can create new tasks.
+	CanCreateTask bool `xorm:"bool not null default false" json:"can_create_task"`
+	// Whether someone with this role can edit tasks.
+	CanEditTask bool `xorm:"bool not null default false" json:"can_edit_task"`
+	// Whether someone with this role can delete tasks.
+	CanDeleteTask bool `xorm:"bool not null default false" json:"can_delete_task"`
+	// Whether someone with this role can create, edit and delete kanban buckets.
+	CanManageBuckets bool `xorm:"bool not null default false" json:"can_manage_buckets"`
+	// Whether someone with this role can share the list or namespace with others.
+	CanShare bool `xorm:"bool not null default false" json:"can_share"`
+
+	// The user who created this role.
+	Owner   *user.User `xorm:"-" json:"owner" valid:"-"`
+	OwnerID int64      `xorm:"bigint not null INDEX" json:"-"`
+
+	// A timestamp when this role was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this role was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for roles
+func (*Role) TableName() string {
+	return "roles"
+}
+
+func getRoleByID(s *xorm.Session, id int64) (role *Role, err error) {
+	role = &Role{}
+	exists, err := s.
+		Where("id = ?", id).
+		Get(role)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrRoleDoesNotExist{RoleID: id}
+	}
+	return
+}
+
+// rolesWithPermission returns a sub query for the ids of all roles which grant a permission
+func rolesWithPermission(p Permission) *builder.Builder {
+	return builder.
+		Select("id").
+		From("roles").
+		Where(builder.Eq{p.column(): true})
+}
+
+// permissions returns all permissions the role grants.
+func (r *Role) permissions() (permissions []Permission) {
+	granted := map[Permission]bool{
+		PermissionComment:       r.CanComment,
+		PermissionCreateTask:    r.CanCreateTask,
+		PermissionEditTask:      r.CanEditTask,
+		PermissionDeleteTask:    r.CanDeleteTask,
+		PermissionManageBuckets: r.CanManageBuckets,
+		PermissionShare:         r.CanShare,
+	}
+	for p := PermissionComment; p <= PermissionShare; p++ {
+		if granted[p] {
+			permissions = append(permissions, p)
+		}
+	}
+	return
+}
+
+// canGrantShare checks if someone whose own right is maxRight and who holds the permissions checked by has
+// can give others a share with the right and role. Shares with a role always come with read rights.
+func canGrantShare(s *xorm.Session, right Right, roleID int64, maxRight Right, has func(p Permission) (bool, error)) (bool, error) {
+	if roleID == 0 {
+		return right <= maxRight, nil
+	}
+
+	role, err := getRoleByID(s, roleID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range role.permissions() {
+		can, err := has(p)
+		if err != nil || !can {
+			return can, err
+		}
+	}
+
+	return true, nil
+}
+
+// All tables with shares which can have a role
+var roleShareTables = []string{"users_lists", "team_lists", "users_namespaces", "team_namespaces", "link_shares"}
+
+// isRoleInUse checks if any share was given with the role.
+func isRoleInUse(s *xorm.Session, roleID int64) (bool, error) {
+	for _, table := range roleShareTables {
+		exists, err := s.
+			Table(table).
+			Where("role_id = ?", roleID).
+			Exist()
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+
+	return false, nil
+}
+
+// checkShareRole makes sure the role of a share exists. Shares with a role only give read access on their own,
+// everything else is granted by the role.
+func checkShareRole(s *xorm.Session, roleID int64, right *Right) error {
+	if roleID == 0 {
+		return nil
+	}
+
+	_, err := getRoleByID(s, roleID)
+	if err != nil {
+		return err
+	}
+
+	*right = RightRead
+	return nil
+}
+
+// Create creates a new role
+// @Summary Create a role
+// @Description Creates a new role with a custom set of permissions. Roles can be assigned when sharing a list or namespace with users, teams or via link.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param role body models.Role true "The role you want to create."
+// @Success 201 {object} models.Role "The created role."
+// @Failure 400 {object} web.HTTPError "Invalid role object provided."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /roles [put]
+func (r *Role) Create(s *xorm.Session, a web.Auth) (err error) {
+	r.ID = 0
+	r.OwnerID = a.GetID()
+	_, err = s.Insert(r)
+	if err != nil {
+		return err
+	}
+
+	r.Owner, err = user.GetUserByID(s, r.OwnerID)
+	return err
+}
+
+// ReadOne returns a role
+// @Summary Get one role
+// @Description Returns one role by its ID.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} models.Role "The role."
+// @Failure 404 {object} web.HTTPError "The role does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /roles/{id} [get]
+func (r *Role) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	role, err := getRoleByID(s, r.ID)
+	if err != nil {
+		return err
+	}
+
+	*r = *role
+	r.Owner, err = user.GetUserByID(s, r.OwnerID)
+	return err
+}
+
+// ReadAll returns all roles
+// @Summary Get all roles
+// @Description Returns all roles on this instance. Roles are available to everyone to use when sharing.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search roles by their title."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.Role "The roles."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /roles [get]
+func (r *Role) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	roles := []*Role{}
+	query := s.
+		Where(db.ILIKE("title", search)).
+		OrderBy("id asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&roles)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	ownerIDs := make([]int64, 0, len(roles))
+	for _, role := range roles {
+		ownerIDs = append(ownerIDs, role.OwnerID)
+	}
+	owners, err := user.GetUsersByIDs(s, ownerIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, role := range roles {
+		role.Owner = owners[role.OwnerID]
+	}
+
+	numberOfTotalItems, err = s.
+		Where(db.ILIKE("title", search)).
+		Count(&Role{})
+	return roles, len(roles), numberOfTotalItems, err
+}
+
+// Update updates a role
+// @Summary Update a role
+// @Description Updates a role. The changes apply to everyone the role is assigned to. Only the creator of a role can update it. A role which is assigned to a share cannot grant more permissions than before, only fewer.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Role ID"
+// @Param role body models.Role true "The role with updated values."
+// @Success 200 {object} models.Role "The updated role."
+// @Failure 400 {object} web.HTTPError "Invalid role object provided."
+// @Failure 403 {object} web.HTTPError "The user is not the creator of the role."
+// @Failure 404 {object} web.HTTPError "The role does not exist."
+// @Failure 412 {object} web.HTTPError "The role is assigned to a share and would grant more permissions."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /roles/{id} [post]
+func (r *Role) Update(s *xorm.Session, a web.Auth) (err error) {
+	old, err := getRoleByID(s, r.ID)
+	if err != nil {
+		return err
+	}
+
+	// Whoever shared something with this role was only allowed to give its permissions at that time.
+	granted := make(map[Permission]bool)
+	for _, p := range old.permissions() {
+		granted[p] = true
+	}
+	for _, p := range r.permissions() {
+		if granted[p] {
+			continue
+		}
+		inUse, err := isRoleInUse(s, r.ID)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return &ErrRoleInUse{RoleID: r.ID}
+		}
+		break
+	}
+
+	_, err = s.
+		Where("id = ?", r.ID).
+		Cols(
+			"title",
+			"description",
+			"can_comment",
+			"can_create_task",
+			"can_edit_task",
+			"can_delete_task",
+			"can_manage_buckets",
+			"can_share",
+		).
+		Update(r)
+	if err != nil {
+		return err
+	}
+
+	return r.ReadOne(s, a)
+}
+
+// Delete removes a role
+// @Summary Delete a role
+// @Description Deletes a role. Everyone who had access through a share with this role only keeps read access. Only the creator of a role can delete it.
+// @tags sharing
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} models.Message "The role was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user is not the creator of the role."
+// @Failure 404 {object} web.HTTPError "The role does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /roles/{id} [delete]
+func (r *Role) Delete(s *xorm.Session, a web.Auth) (err error) {
+	for _, table := range roleShareTables {
+		_, err = s.
+			Table(table).
+			Where("role_id = ?", r.ID).
+			Update(map[string]interface{}{"role_id": 0})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.
+		Where("id = ?", r.ID).
+		Delete(&Role{})
+	return err
+}
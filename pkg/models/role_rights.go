@@ -0,0 +1,72 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if the user can create a new role
+func (r *Role) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// CanRead checks if the user can see a role. Roles are visible to everyone so they can be used when sharing.
+func (r *Role) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, 0, nil
+	}
+
+	role, err := getRoleByID(s, r.ID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if role.OwnerID == a.GetID() {
+		return true, int(RightAdmin), nil
+	}
+	return true, int(RightRead), nil
+}
+
+// CanUpdate checks if the user can update a role
+func (r *Role) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return r.isOwner(s, a)
+}
+
+// CanDelete checks if the user can delete a role
+func (r *Role) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return r.isOwner(s, a)
+}
+
+// Only the creator of a role can change it
+func (r *Role) isOwner(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	role, err := getRoleByID(s, r.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return role.OwnerID == a.GetID(), nil
+}
@@ -0,0 +1,434 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole_Create(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	r := &Role{
+		Title:         "Reviewer",
+		CanComment:    true,
+		CanDeleteTask: true,
+	}
+	u := &user.User{ID: 1}
+	can, err := r.CanCreate(s, u)
+	assert.NoError(t, err)
+	assert.True(t, can)
+	err = r.Create(s, u)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", r.Owner.Username)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertExists(t, "roles", map[string]interface{}{
+		"id":              r.ID,
+		"title":           "Reviewer",
+		"can_comment":     true,
+		"can_delete_task": true,
+		"can_edit_task":   false,
+		"owner_id":        1,
+	}, false)
+}
+
+func TestRole_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	r := &Role{}
+	result, _, total, err := r.ReadAll(s, &user.User{ID: 3}, "", 1, 50)
+	assert.NoError(t, err)
+	roles := result.([]*Role)
+	assert.Len(t, roles, 3)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, "user1", roles[0].Owner.Username)
+
+	result, _, _, err = r.ReadAll(s, &user.User{ID: 3}, "contri", 1, 50)
+	assert.NoError(t, err)
+	roles = result.([]*Role)
+	assert.Len(t, roles, 1)
+	assert.Equal(t, int64(2), roles[0].ID)
+
+	_, _, _, err = r.ReadAll(s, &LinkSharing{ID: 1}, "", 1, 50)
+	assert.Error(t, err)
+}
+
+func TestRole_Update(t *testing.T) {
+	t.Run("owner", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &Role{ID: 1, Title: "Commenter", CanComment: true, CanManageBuckets: true}
+		u := &user.User{ID: 1}
+		can, err := r.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = r.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "roles", map[string]interface{}{
+			"id":                 1,
+			"can_manage_buckets": true,
+		}, false)
+	})
+	t.Run("more permissions when in use", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&LinkSharing{ListID: 1, Hash: "rolelink", RoleID: 1, SharedByID: 1})
+		assert.NoError(t, err)
+
+		r := &Role{ID: 1, Title: "Commenter", CanComment: true, CanEditTask: true}
+		err = r.Update(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrRoleInUse(err))
+	})
+	t.Run("fewer permissions when in use", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&ListUser{UserID: 2, ListID: 1, RoleID: 2})
+		assert.NoError(t, err)
+
+		r := &Role{ID: 2, Title: "Editor", CanComment: true}
+		err = r.Update(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "roles", map[string]interface{}{
+			"id":            2,
+			"can_edit_task": false,
+		}, false)
+	})
+	t.Run("not the owner", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &Role{ID: 1}
+		can, err := r.CanUpdate(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		r := &Role{ID: 9999}
+		_, err := r.CanUpdate(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrRoleDoesNotExist(err))
+	})
+}
+
+func TestRole_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := s.Insert(&ListUser{UserID: 2, ListID: 1, RoleID: 1})
+	assert.NoError(t, err)
+
+	r := &Role{ID: 1}
+	err = r.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "roles", map[string]interface{}{
+		"id": 1,
+	})
+	db.AssertExists(t, "users_lists", map[string]interface{}{
+		"user_id": 2,
+		"list_id": 1,
+		"role_id": 0,
+		"right":   RightRead,
+	}, false)
+}
+
+func TestRolePermissions(t *testing.T) {
+	u := &user.User{ID: 2}
+
+	t.Run("commenter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&ListUser{UserID: 2, ListID: 1, RoleID: 1})
+		assert.NoError(t, err)
+
+		l := &List{ID: 1}
+		can, _, err := l.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		can, err = (&TaskComment{TaskID: 1}).CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		can, err = (&Task{ListID: 1}).CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		can, err = (&Task{ID: 1}).CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		can, err = (&Task{ID: 1}).CanDelete(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("contributor through a team", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&TeamList{TeamID: 1, ListID: 1, RoleID: 2})
+		assert.NoError(t, err)
+
+		can, err := (&Task{ListID: 1}).CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		can, err = (&Task{ID: 1}).CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		can, err = (&Task{ID: 1}).CanDelete(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+
+		can, err = (&Bucket{ListID: 1}).CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("contributor through a namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&NamespaceUser{UserID: 2, NamespaceID: 1, RoleID: 2})
+		assert.NoError(t, err)
+
+		can, err := (&Task{ListID: 1}).CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("role_id").Update(&LinkSharing{RoleID: 1})
+		assert.NoError(t, err)
+
+		share := &LinkSharing{ID: 1, ListID: 1, Right: RightRead}
+		can, err := (&TaskComment{TaskID: 1}).CanCreate(s, share)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		can, err = (&Task{ID: 1}).CanUpdate(s, share)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("sharer", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&ListUser{UserID: 2, ListID: 1, RoleID: 3})
+		assert.NoError(t, err)
+
+		can, err := (&ListUser{ListID: 1, Right: RightRead}).CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		can, err = (&Task{ID: 1}).CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("no role", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&ListUser{UserID: 2, ListID: 1, Right: RightRead})
+		assert.NoError(t, err)
+
+		can, err := (&TaskComment{TaskID: 1}).CanCreate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestShareWithRole(t *testing.T) {
+	t.Run("role overrides the right", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lu := &ListUser{Username: "user2", ListID: 1, Right: RightAdmin, RoleID: 2}
+		err := lu.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "users_lists", map[string]interface{}{
+			"user_id": 2,
+			"list_id": 1,
+			"right":   RightRead,
+			"role_id": 2,
+		}, false)
+	})
+	t.Run("nonexisting role", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		lu := &ListUser{Username: "user2", ListID: 1, RoleID: 9999}
+		err := lu.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrRoleDoesNotExist(err))
+	})
+}
+
+func TestShareWithRoleCannotEscalate(t *testing.T) {
+	u := &user.User{ID: 2}
+
+	t.Run("list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&ListUser{UserID: 2, ListID: 1, RoleID: 3})
+		assert.NoError(t, err)
+		_, err = s.Insert(&ListUser{UserID: 3, ListID: 1, Right: RightAdmin})
+		assert.NoError(t, err)
+		_, err = s.Insert(&TeamList{TeamID: 2, ListID: 1, Right: RightWrite})
+		assert.NoError(t, err)
+
+		t.Run("create with a higher right", func(t *testing.T) {
+			can, err := (&ListUser{ListID: 1, Username: "user4", Right: RightWrite}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+			can, err = (&TeamList{ListID: 1, TeamID: 3, Right: RightAdmin}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+		})
+		t.Run("create with a role granting more permissions", func(t *testing.T) {
+			can, err := (&ListUser{ListID: 1, Username: "user4", RoleID: 2}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+		})
+		t.Run("create with the same role", func(t *testing.T) {
+			can, err := (&ListUser{ListID: 1, Username: "user4", RoleID: 3}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.True(t, can)
+		})
+		t.Run("delete a share with a higher right", func(t *testing.T) {
+			can, err := (&ListUser{ListID: 1, Username: "user3"}).CanDelete(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+			can, err = (&TeamList{ListID: 1, TeamID: 2}).CanDelete(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+		})
+		t.Run("demote a share with a higher right", func(t *testing.T) {
+			can, err := (&ListUser{ListID: 1, Username: "user3", Right: RightRead}).CanUpdate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+			can, err = (&TeamList{ListID: 1, TeamID: 2, Right: RightRead}).CanUpdate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+		})
+		t.Run("link share", func(t *testing.T) {
+			can, err := (&LinkSharing{ListID: 1, Right: RightWrite}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+			can, err = (&LinkSharing{ListID: 1, RoleID: 2}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+			can, err = (&LinkSharing{ListID: 1, Right: RightRead}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.True(t, can)
+			// Link share 1 belongs to list 1 and has read rights
+			can, err = (&LinkSharing{ID: 1, ListID: 1, Right: RightWrite}).CanUpdate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+		})
+		t.Run("admin", func(t *testing.T) {
+			can, err := (&ListUser{ListID: 1, Username: "user3"}).CanDelete(s, &user.User{ID: 1})
+			assert.NoError(t, err)
+			assert.True(t, can)
+		})
+	})
+	t.Run("namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&NamespaceUser{UserID: 2, NamespaceID: 1, RoleID: 3})
+		assert.NoError(t, err)
+		_, err = s.Insert(&NamespaceUser{UserID: 3, NamespaceID: 1, Right: RightAdmin})
+		assert.NoError(t, err)
+		_, err = s.Insert(&TeamNamespace{TeamID: 2, NamespaceID: 1, Right: RightWrite})
+		assert.NoError(t, err)
+
+		t.Run("create", func(t *testing.T) {
+			can, err := (&NamespaceUser{NamespaceID: 1, Username: "user4", Right: RightRead}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.True(t, can)
+			can, err = (&NamespaceUser{NamespaceID: 1, Username: "user4", Right: RightWrite}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+			can, err = (&TeamNamespace{NamespaceID: 1, TeamID: 3, RoleID: 1}).CanCreate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+		})
+		t.Run("delete a share with a higher right", func(t *testing.T) {
+			can, err := (&NamespaceUser{NamespaceID: 1, Username: "user3"}).CanDelete(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+			can, err = (&TeamNamespace{NamespaceID: 1, TeamID: 2}).CanDelete(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+		})
+		t.Run("demote a share with a higher right", func(t *testing.T) {
+			can, err := (&NamespaceUser{NamespaceID: 1, Username: "user3", Right: RightRead}).CanUpdate(s, u)
+			assert.NoError(t, err)
+			assert.False(t, can)
+		})
+	})
+}
@@ -208,5 +208,9 @@ func (sf *SavedFilter) Delete(s *xorm.Session, a web.Auth) error {
 	_, err := s.
 		Where("id = ?", sf.ID).
 		Delete(sf)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return deleteBoardBuckets(s, &Bucket{SavedFilterID: sf.ID})
 }
@@ -0,0 +1,325 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+const shareInvitationValidity = 14 * 24 * time.Hour
+
+// ShareInvitation invites someone who does not have an account yet to a list or team. The list or team is shared
+// with them automatically once they registered and confirmed their email address.
+type ShareInvitation struct {
+	// The unique, numeric id of this invitation.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"invitation"`
+	// The email address of the invited person.
+	Email string `xorm:"varchar(250) not null index" json:"email" valid:"email,required,length(1|250)" maxLength:"250"`
+	// The list the person is invited to.
+	ListID int64 `xorm:"bigint null index" json:"-" param:"list"`
+	// The team the person is invited to.
+	TeamID int64 `xorm:"bigint null index" json:"-" param:"team"`
+	// The right the person will get on the list. 0 = Read only, 1 = Read & Write, 2 = Admin. Only used for lists.
+	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|2)" maximum:"2" default:"0"`
+	// Whether the person will be an admin of the team. Only used for teams.
+	Admin bool `xorm:"null" json:"admin"`
+
+	// The registration invitation sent to the person.
+	InvitationID int64 `xorm:"bigint not null" json:"-"`
+
+	CreatedByID int64 `xorm:"bigint not null" json:"-"`
+	// The user who sent the invitation.
+	CreatedBy *user.User `xorm:"-" json:"created_by"`
+
+	// A timestamp when this invitation was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for share invitations
+func (*ShareInvitation) TableName() string {
+	return "share_invitations"
+}
+
+// targetCond returns the condition to find all invitations to the same list or team.
+func (si *ShareInvitation) targetCond() builder.Cond {
+	if si.ListID != 0 {
+		return builder.Eq{"list_id": si.ListID}
+	}
+	return builder.Eq{"team_id": si.TeamID}
+}
+
+type emailInvitee struct {
+	email string
+}
+
+func (i *emailInvitee) RouteForMail() (string, error) {
+	return i.email, nil
+}
+
+func (i *emailInvitee) RouteForDB() int64 {
+	return 0
+}
+
+func (i *emailInvitee) Lang() string {
+	return ""
+}
+
+// Create invites someone to a list or team
+// @Summary Invite someone to a list
+// @Description Invites someone who does not have an account yet to a list. They get an email with a link to register, the list is shared with them once they registered and confirmed their email address.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "List ID"
+// @Param invitation body models.ShareInvitation true "The invitation."
+// @Success 201 {object} models.ShareInvitation "The created invitation."
+// @Failure 400 {object} web.HTTPError "Invalid invitation object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 409 {object} web.HTTPError "This email address was already invited."
+// @Failure 412 {object} web.HTTPError "A user with this email address already exists."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{id}/invitations [put]
+func (si *ShareInvitation) Create(s *xorm.Session, a web.Auth) (err error) {
+	si.ID = 0
+	si.Email = strings.ToLower(strings.TrimSpace(si.Email))
+
+	var list *List
+	var team *Team
+	if si.ListID != 0 {
+		if err := si.Right.isValid(); err != nil {
+			return err
+		}
+		list, err = GetListSimpleByID(s, si.ListID)
+		if err != nil {
+			return err
+		}
+		si.TeamID = 0
+		si.Admin = false
+	} else {
+		team, err = GetTeamByID(s, si.TeamID)
+		if err != nil {
+			return err
+		}
+		if team.isExternallyManaged() {
+			return ErrTeamIsExternallyManaged{TeamID: team.ID}
+		}
+		si.Right = RightRead
+	}
+
+	exists, err := s.Where("LOWER(email) = ?", si.Email).Exist(&user.User{})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &ErrShareInvitationUserExists{Email: si.Email}
+	}
+
+	exists, err = s.
+		Where("email = ?", si.Email).
+		And(si.targetCond()).
+		Exist(&ShareInvitation{})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &ErrShareInvitationAlreadyExists{Email: si.Email}
+	}
+
+	doer, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return err
+	}
+
+	invitation := &user.Invitation{
+		Email:   si.Email,
+		MaxUses: 1,
+		Expires: time.Now().Add(shareInvitationValidity),
+	}
+	err = user.CreateInvitation(s, doer, invitation)
+	if err != nil {
+		return err
+	}
+
+	si.InvitationID = invitation.ID
+	si.CreatedByID = doer.ID
+	si.CreatedBy = doer
+	_, err = s.Insert(si)
+	if err != nil {
+		return err
+	}
+
+	return notifications.Notify(&emailInvitee{email: si.Email}, &ShareInvitationNotification{
+		Doer:            doer,
+		List:            list,
+		Team:            team,
+		InvitationToken: invitation.ClearTextToken,
+		Expires:         invitation.Expires,
+	})
+}
+
+// ReadAll returns all pending invitations of a list or team
+// @Summary Get all pending invitations of a list
+// @Description Returns all people who were invited to a list but did not register yet.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Security JWTKeyAuth
+// @Success 200 {array} models.ShareInvitation "The invitations."
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{id}/invitations [get]
+func (si *ShareInvitation) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	can, err := si.canDoShareInvitation(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	invitations := []*ShareInvitation{}
+	err = s.
+		Where(si.targetCond()).
+		OrderBy("id asc").
+		Find(&invitations)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(invitations))
+	for _, i := range invitations {
+		userIDs = append(userIDs, i.CreatedByID)
+	}
+	users, err := user.GetUsersByIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, i := range invitations {
+		i.CreatedBy = users[i.CreatedByID]
+	}
+
+	return invitations, len(invitations), int64(len(invitations)), nil
+}
+
+// Delete withdraws an invitation
+// @Summary Withdraw an invitation to a list
+// @Description Withdraws an invitation to a list. The invitation link in the email cannot be used anymore.
+// @tags sharing
+// @Produce json
+// @Param id path int true "List ID"
+// @Param invitationID path int true "Invitation ID"
+// @Security JWTKeyAuth
+// @Success 200 {object} models.Message "The invitation was withdrawn successfully."
+// @Failure 403 {object} web.HTTPError "The user does not have admin access to the list."
+// @Failure 404 {object} web.HTTPError "The invitation does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{id}/invitations/{invitationID} [delete]
+func (si *ShareInvitation) Delete(s *xorm.Session, a web.Auth) (err error) {
+	invitation := &ShareInvitation{}
+	exists, err := s.
+		Where("id = ?", si.ID).
+		And(si.targetCond()).
+		Get(invitation)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &ErrShareInvitationDoesNotExist{ID: si.ID}
+	}
+
+	return deleteShareInvitations(s, &ShareInvitation{ID: invitation.ID})
+}
+
+// deleteShareInvitations removes all share invitations matching the condition and their registration invitations.
+func deleteShareInvitations(s *xorm.Session, cond *ShareInvitation) (err error) {
+	invitations := []*ShareInvitation{}
+	err = s.Find(&invitations, cond)
+	if err != nil || len(invitations) == 0 {
+		return err
+	}
+
+	ids := make([]int64, 0, len(invitations))
+	invitationIDs := make([]int64, 0, len(invitations))
+	for _, i := range invitations {
+		ids = append(ids, i.ID)
+		invitationIDs = append(invitationIDs, i.InvitationID)
+	}
+
+	_, err = s.In("id", invitationIDs).Delete(&user.Invitation{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.In("id", ids).Delete(&ShareInvitation{})
+	return err
+}
+
+// ApplyShareInvitations shares all lists and teams someone was invited to with them. Call this once the user's email
+// address is confirmed.
+func ApplyShareInvitations(s *xorm.Session, u *user.User) (err error) {
+	u, err = user.GetUserWithEmail(s, &user.User{ID: u.ID})
+	if err != nil {
+		return err
+	}
+
+	invitations := []*ShareInvitation{}
+	err = s.Where("email = ?", strings.ToLower(u.Email)).Find(&invitations)
+	if err != nil || len(invitations) == 0 {
+		return err
+	}
+
+	for _, i := range invitations {
+		if i.ListID != 0 {
+			hasAccess, err := s.Where("list_id = ? AND user_id = ?", i.ListID, u.ID).Exist(&ListUser{})
+			if err != nil {
+				return err
+			}
+			if !hasAccess {
+				_, err = s.Insert(&ListUser{UserID: u.ID, ListID: i.ListID, Right: i.Right})
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		isMember, err := s.Where("team_id = ? AND user_id = ?", i.TeamID, u.ID).Exist(&TeamMember{})
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			_, err = s.Insert(&TeamMember{UserID: u.ID, TeamID: i.TeamID, Admin: i.Admin})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return deleteShareInvitations(s, &ShareInvitation{Email: strings.ToLower(u.Email)})
+}
@@ -0,0 +1,148 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareInvitation_Create(t *testing.T) {
+	t.Run("existing user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		si := &ShareInvitation{ListID: 1, Email: "user2@example.com"}
+		err := si.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrShareInvitationUserExists(err))
+	})
+	t.Run("already invited", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		si := &ShareInvitation{ListID: 1, Email: "Shared@example.com"}
+		err := si.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrShareInvitationAlreadyExists(err))
+	})
+}
+
+func TestShareInvitation_ReadAll(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		si := &ShareInvitation{ListID: 1}
+		result, _, _, err := si.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		assert.NoError(t, err)
+		invitations := result.([]*ShareInvitation)
+		assert.Len(t, invitations, 1)
+		assert.Equal(t, int64(1), invitations[0].ID)
+		assert.Equal(t, "user1", invitations[0].CreatedBy.Username)
+	})
+	t.Run("team", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		si := &ShareInvitation{TeamID: 1}
+		result, _, _, err := si.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		assert.NoError(t, err)
+		invitations := result.([]*ShareInvitation)
+		assert.Len(t, invitations, 1)
+		assert.Equal(t, int64(2), invitations[0].ID)
+	})
+	t.Run("no admin access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		si := &ShareInvitation{ListID: 1}
+		_, _, _, err := si.ReadAll(s, &user.User{ID: 2}, "", 1, 50)
+		assert.Error(t, err)
+	})
+}
+
+func TestShareInvitation_Delete(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		si := &ShareInvitation{ID: 1, ListID: 1}
+		err := si.Delete(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+		db.AssertMissing(t, "share_invitations", map[string]interface{}{
+			"id": 1,
+		})
+		db.AssertMissing(t, "user_invitations", map[string]interface{}{
+			"id": 5,
+		})
+	})
+	t.Run("wrong list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		si := &ShareInvitation{ID: 1, ListID: 2}
+		err := si.Delete(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrShareInvitationDoesNotExist(err))
+	})
+}
+
+func TestApplyShareInvitations(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.CreateUser(s, &user.User{
+		Username: "shared",
+		Password: "12345678",
+		Email:    "shared@example.com",
+	})
+	assert.NoError(t, err)
+
+	err = ApplyShareInvitations(s, u)
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertExists(t, "users_lists", map[string]interface{}{
+		"user_id": u.ID,
+		"list_id": 1,
+		"right":   RightWrite,
+	}, false)
+	db.AssertExists(t, "team_members", map[string]interface{}{
+		"user_id": u.ID,
+		"team_id": 1,
+		"admin":   true,
+	}, false)
+	db.AssertMissing(t, "share_invitations", map[string]interface{}{
+		"email": "shared@example.com",
+	})
+}
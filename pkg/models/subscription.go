@@ -42,6 +42,91 @@ const (
 	entityTask      = `task`
 )
 
+// SubscriptionEvents is a bitmask of all events a subscriber wants to be notified about.
+// An empty mask means the subscriber gets notified about everything.
+type SubscriptionEvents int64
+
+const (
+	// SubscriptionEventComments notifies about new comments on a task
+	SubscriptionEventComments SubscriptionEvents = 1 << iota
+	// SubscriptionEventStatusChanges notifies when a task is marked as done or undone
+	SubscriptionEventStatusChanges
+	// SubscriptionEventAssigneeChanges notifies when a task gets assigned to someone
+	SubscriptionEventAssigneeChanges
+	// SubscriptionEventDueDateChanges notifies when the due date of a task changes
+	SubscriptionEventDueDateChanges
+	// SubscriptionEventAttachments notifies about attachments added to or removed from a task
+	SubscriptionEventAttachments
+	// SubscriptionEventTaskChanges notifies about all other changes of a task, like title or description
+	SubscriptionEventTaskChanges
+	// SubscriptionEventTaskDeleted notifies when a task was deleted
+	SubscriptionEventTaskDeleted
+	// SubscriptionEventListCreated notifies when a new list was created in a namespace
+	SubscriptionEventListCreated
+)
+
+// SubscriptionEventAll contains all events a subscriber can be notified about
+const SubscriptionEventAll = SubscriptionEventComments |
+	SubscriptionEventStatusChanges |
+	SubscriptionEventAssigneeChanges |
+	SubscriptionEventDueDateChanges |
+	SubscriptionEventAttachments |
+	SubscriptionEventTaskChanges |
+	SubscriptionEventTaskDeleted |
+	SubscriptionEventListCreated
+
+var subscriptionEventNames = []struct {
+	event SubscriptionEvents
+	name  string
+}{
+	{SubscriptionEventComments, "comments"},
+	{SubscriptionEventStatusChanges, "status"},
+	{SubscriptionEventAssigneeChanges, "assignees"},
+	{SubscriptionEventDueDateChanges, "due_date"},
+	{SubscriptionEventAttachments, "attachments"},
+	{SubscriptionEventTaskChanges, "task_changes"},
+	{SubscriptionEventTaskDeleted, "task_deleted"},
+	{SubscriptionEventListCreated, "list_created"},
+}
+
+// Has checks whether the mask contains an event. An empty mask contains all events.
+func (e SubscriptionEvents) Has(event SubscriptionEvents) bool {
+	return e == 0 || e&event != 0
+}
+
+// Names returns the names of all events in the mask
+func (e SubscriptionEvents) Names() (names []string) {
+	names = []string{}
+	for _, ev := range subscriptionEventNames {
+		if e.Has(ev.event) {
+			names = append(names, ev.name)
+		}
+	}
+	return
+}
+
+func getSubscriptionEventsFromNames(names []string) (events SubscriptionEvents, err error) {
+	for _, name := range names {
+		var found bool
+		for _, ev := range subscriptionEventNames {
+			if ev.name == name {
+				events |= ev.event
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, &ErrUnknownSubscriptionEvent{Event: name}
+		}
+	}
+
+	if events == SubscriptionEventAll {
+		return 0, nil
+	}
+
+	return
+}
+
 // Subscription represents a subscription for an entity
 type Subscription struct {
 	// The numeric ID of the subscription
@@ -52,6 +137,11 @@ type Subscription struct {
 	// The id of the entity to subscribe to.
 	EntityID int64 `xorm:"bigint index not null" json:"entity_id" param:"entityID"`
 
+	EventMask SubscriptionEvents `xorm:"bigint not null default 0" json:"-"`
+	// The events the user wants to be notified about. Can be any of `comments`, `status`, `assignees`, `due_date`,
+	// `attachments`, `task_changes`, `task_deleted` and `list_created`. If empty, the user is notified about all events.
+	Events []string `xorm:"-" json:"events"`
+
 	// The user who made this subscription
 	User   *user.User `xorm:"-" json:"user"`
 	UserID int64      `xorm:"bigint index not null" json:"-"`
@@ -125,11 +215,20 @@ func (sb *Subscription) Create(s *xorm.Session, auth web.Auth) (err error) {
 
 	sb.UserID = auth.GetID()
 
-	sub, err := GetSubscription(s, sb.EntityType, sb.EntityID, auth)
+	sb.EventMask, err = getSubscriptionEventsFromNames(sb.Events)
 	if err != nil {
 		return err
 	}
-	if sub != nil {
+
+	// Subscribing to an entity while already being subscribed to one of its parents is allowed, that way a user can
+	// choose different events for a single task than for the whole list.
+	exists, err := s.
+		Where("entity_id = ? AND entity_type = ? AND user_id = ?", sb.EntityID, sb.EntityType, sb.UserID).
+		Exist(&Subscription{})
+	if err != nil {
+		return err
+	}
+	if exists {
 		return &ErrSubscriptionAlreadyExists{
 			EntityID:   sb.EntityID,
 			EntityType: sb.EntityType,
@@ -142,6 +241,53 @@ func (sb *Subscription) Create(s *xorm.Session, auth web.Auth) (err error) {
 		return
 	}
 
+	sb.Events = sb.EventMask.Names()
+	sb.User, err = user.GetFromAuth(auth)
+	return
+}
+
+// Update changes the events of a subscription
+// @Summary Change the events of a subscription.
+// @Description Changes which events the current user wants to be notified about for a subscribed entity.
+// @tags subscriptions
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param entity path string true "The entity the user subscribed to. Can be either `namespace`, `list` or `task`."
+// @Param entityID path string true "The numeric id of the subscribed entity."
+// @Param subscription body models.Subscription true "The subscription with the new events."
+// @Success 200 {object} models.Subscription "The updated subscription"
+// @Failure 403 {object} web.HTTPError "The user does not have access to subscribe to this entity."
+// @Failure 404 {object} web.HTTPError "The subscription does not exist."
+// @Failure 412 {object} web.HTTPError "One of the subscription events is invalid."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /subscriptions/{entity}/{entityID} [post]
+func (sb *Subscription) Update(s *xorm.Session, auth web.Auth) (err error) {
+	sb.UserID = auth.GetID()
+
+	sb.EventMask, err = getSubscriptionEventsFromNames(sb.Events)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		Where("entity_id = ? AND entity_type = ? AND user_id = ?", sb.EntityID, sb.EntityType, sb.UserID).
+		Cols("event_mask").
+		NoAutoCondition().
+		Update(sb)
+	if err != nil {
+		return
+	}
+
+	_, err = s.
+		Where("entity_id = ? AND entity_type = ? AND user_id = ?", sb.EntityID, sb.EntityType, sb.UserID).
+		Get(sb)
+	if err != nil {
+		return
+	}
+
+	sb.Entity = sb.EntityType.String()
+	sb.Events = sb.EventMask.Names()
 	sb.User, err = user.GetFromAuth(auth)
 	return
 }
@@ -169,7 +315,9 @@ func (sb *Subscription) Delete(s *xorm.Session, auth web.Auth) (err error) {
 	return
 }
 
-func getSubscriberCondForEntity(entityType SubscriptionEntityType, entityID int64) (cond builder.Cond) {
+// getSubscriberCondForEntity returns the condition for all subscriptions of an entity and its parents.
+// Tasks in the trash have no parents unless withTrashed is set.
+func getSubscriberCondForEntity(entityType SubscriptionEntityType, entityID int64, withTrashed bool) (cond builder.Cond) {
 	if entityType == SubscriptionEntityNamespace {
 		cond = builder.And(
 			builder.Eq{"entity_id": entityID},
@@ -195,6 +343,10 @@ func getSubscriberCondForEntity(entityType SubscriptionEntityType, entityID int6
 	}
 
 	if entityType == SubscriptionEntityTask {
+		var taskCond builder.Cond = builder.Eq{"tasks.id": entityID}
+		if !withTrashed {
+			taskCond = taskCond.And(builder.IsNull{"tasks.deleted"})
+		}
 		cond = builder.Or(
 			builder.And(
 				builder.Eq{"entity_id": entityID},
@@ -205,7 +357,7 @@ func getSubscriberCondForEntity(entityType SubscriptionEntityType, entityID int6
 					Select("namespace_id").
 					From("lists").
 					Join("INNER", "tasks", "lists.id = tasks.list_id").
-					Where(builder.Eq{"tasks.id": entityID}),
+					Where(taskCond),
 				},
 				builder.Eq{"entity_type": SubscriptionEntityNamespace},
 			),
@@ -213,7 +365,7 @@ func getSubscriberCondForEntity(entityType SubscriptionEntityType, entityID int6
 				builder.Eq{"entity_id": builder.
 					Select("list_id").
 					From("tasks").
-					Where(builder.Eq{"id": entityID}),
+					Where(taskCond),
 				},
 				builder.Eq{"entity_type": SubscriptionEntityList},
 			),
@@ -254,10 +406,10 @@ func GetSubscriptions(s *xorm.Session, entityType SubscriptionEntityType, entity
 	var entitiesFilter builder.Cond
 	for _, eID := range entityIDs {
 		if entitiesFilter == nil {
-			entitiesFilter = getSubscriberCondForEntity(entityType, eID)
+			entitiesFilter = getSubscriberCondForEntity(entityType, eID, false)
 			continue
 		}
-		entitiesFilter = entitiesFilter.Or(getSubscriberCondForEntity(entityType, eID))
+		entitiesFilter = entitiesFilter.Or(getSubscriberCondForEntity(entityType, eID, false))
 	}
 
 	var subscriptions []*Subscription
@@ -272,26 +424,48 @@ func GetSubscriptions(s *xorm.Session, entityType SubscriptionEntityType, entity
 	listsToSubscriptions = make(map[int64]*Subscription)
 	for _, sub := range subscriptions {
 		sub.Entity = sub.EntityType.String()
+		sub.Events = sub.EventMask.Names()
 		listsToSubscriptions[sub.EntityID] = sub
 	}
 	return listsToSubscriptions, nil
 }
 
-func getSubscribersForEntity(s *xorm.Session, entityType SubscriptionEntityType, entityID int64) (subscriptions []*Subscription, err error) {
+// getSubscribersForEntity returns the subscriptions of all users who subscribed to an entity or one of its parents
+// and want to be notified about the event.
+// If a user subscribed to an entity and one of its parents, only the subscription closest to the entity is used.
+// That way a more specific subscription on a task can narrow down the events a user gets from a list subscription.
+func getSubscribersForEntity(s *xorm.Session, entityType SubscriptionEntityType, entityID int64, event SubscriptionEvents) (subscriptions []*Subscription, err error) {
 	if err := entityType.validate(); err != nil {
 		return nil, err
 	}
 
-	cond := getSubscriberCondForEntity(entityType, entityID)
+	var allSubscriptions []*Subscription
+	// Deleted tasks are already in the trash when their subscribers are notified
+	cond := getSubscriberCondForEntity(entityType, entityID, event == SubscriptionEventTaskDeleted)
 	err = s.
 		Where(cond).
-		Find(&subscriptions)
+		OrderBy("id ASC").
+		Find(&allSubscriptions)
 	if err != nil {
 		return
 	}
 
+	closestSubscriptions := make(map[int64]*Subscription, len(allSubscriptions))
+	for _, subscription := range allSubscriptions {
+		existing, has := closestSubscriptions[subscription.UserID]
+		// The entity types are ordered from namespace to task so a bigger one is closer to the entity
+		if !has || subscription.EntityType > existing.EntityType {
+			closestSubscriptions[subscription.UserID] = subscription
+		}
+	}
+
+	subscriptions = []*Subscription{}
 	userIDs := []int64{}
-	for _, subscription := range subscriptions {
+	for _, subscription := range allSubscriptions {
+		if closestSubscriptions[subscription.UserID] != subscription || !subscription.EventMask.Has(event) {
+			continue
+		}
+		subscriptions = append(subscriptions, subscription)
 		userIDs = append(userIDs, subscription.UserID)
 	}
 
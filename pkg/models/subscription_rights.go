@@ -46,6 +46,11 @@ func (sb *Subscription) CanCreate(s *xorm.Session, a web.Auth) (can bool, err er
 	return
 }
 
+// CanUpdate checks if a user can update a subscription
+func (sb *Subscription) CanUpdate(s *xorm.Session, a web.Auth) (can bool, err error) {
+	return sb.CanDelete(s, a)
+}
+
 // CanDelete checks if a user can delete a subscription
 func (sb *Subscription) CanDelete(s *xorm.Session, a web.Auth) (can bool, err error) {
 	if _, is := a.(*LinkSharing); is {
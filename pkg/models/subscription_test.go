@@ -344,3 +344,121 @@ func TestSubscriptionGet(t *testing.T) {
 		assert.True(t, IsErrUnknownSubscriptionEntityType(err))
 	})
 }
+
+func TestSubscriptionEvents(t *testing.T) {
+	t.Run("from names", func(t *testing.T) {
+		events, err := getSubscriptionEventsFromNames([]string{"comments", "due_date"})
+		assert.NoError(t, err)
+		assert.Equal(t, SubscriptionEventComments|SubscriptionEventDueDateChanges, events)
+		assert.True(t, events.Has(SubscriptionEventComments))
+		assert.False(t, events.Has(SubscriptionEventAttachments))
+		assert.Equal(t, []string{"comments", "due_date"}, events.Names())
+	})
+	t.Run("empty means all", func(t *testing.T) {
+		events, err := getSubscriptionEventsFromNames([]string{})
+		assert.NoError(t, err)
+		assert.Equal(t, SubscriptionEvents(0), events)
+		assert.True(t, events.Has(SubscriptionEventAttachments))
+		assert.Len(t, events.Names(), len(subscriptionEventNames))
+	})
+	t.Run("unknown event", func(t *testing.T) {
+		_, err := getSubscriptionEventsFromNames([]string{"comments", "lorem"})
+		assert.Error(t, err)
+		assert.True(t, IsErrUnknownSubscriptionEvent(err))
+	})
+}
+
+func TestSubscription_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sb := &Subscription{
+			Entity:   "task",
+			EntityID: 2,
+			Events:   []string{"comments", "attachments"},
+		}
+
+		can, err := sb.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		err = sb.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), sb.ID)
+		assert.Equal(t, []string{"comments", "attachments"}, sb.Events)
+
+		db.AssertExists(t, "subscriptions", map[string]interface{}{
+			"id":         1,
+			"event_mask": int64(SubscriptionEventComments | SubscriptionEventAttachments),
+		}, false)
+	})
+	t.Run("unknown event", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sb := &Subscription{
+			Entity:   "task",
+			EntityID: 2,
+			Events:   []string{"lorem"},
+		}
+
+		can, err := sb.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+
+		err = sb.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrUnknownSubscriptionEvent(err))
+	})
+}
+
+func TestGetSubscribersForEntity(t *testing.T) {
+	t.Run("closest subscription wins", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// User 6 subscribed to task 22 only for comments but to its list and namespace for everything
+		subs, err := getSubscribersForEntity(s, SubscriptionEntityTask, 22, SubscriptionEventComments)
+		assert.NoError(t, err)
+		assert.Len(t, subs, 1)
+		assert.Equal(t, int64(4), subs[0].ID)
+
+		subs, err = getSubscribersForEntity(s, SubscriptionEntityTask, 22, SubscriptionEventDueDateChanges)
+		assert.NoError(t, err)
+		assert.Len(t, subs, 0)
+	})
+	t.Run("inherited subscription with all events", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Task 21 belongs to list 12 which user 6 has subscribed to
+		subs, err := getSubscribersForEntity(s, SubscriptionEntityTask, 21, SubscriptionEventDueDateChanges)
+		assert.NoError(t, err)
+		assert.Len(t, subs, 1)
+		assert.Equal(t, int64(3), subs[0].ID)
+	})
+}
+
+func TestGetTaskChanges(t *testing.T) {
+	oldTask := &Task{Title: "Lorem", Done: false, Priority: 1}
+	newTask := &Task{Title: "Ipsum", Done: true, Priority: 1}
+
+	changes := getTaskChanges(oldTask, newTask)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "title", changes[0].Field)
+	assert.Equal(t, "Lorem", changes[0].Old)
+	assert.Equal(t, "Ipsum", changes[0].New)
+	assert.Equal(t, "done", changes[1].Field)
+
+	filtered := filterTaskChanges(changes, SubscriptionEventStatusChanges)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "done", filtered[0].Field)
+	assert.Len(t, filterTaskChanges(changes, 0), 2)
+}
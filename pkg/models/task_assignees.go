@@ -137,6 +137,11 @@ func (t *Task) updateTaskAssignees(s *xorm.Session, assignees []*user.User, doer
 			continue
 		}
 
+		err = checkAssigneeBucketLimits(s, t.ID, u.ID)
+		if err != nil {
+			return err
+		}
+
 		// Add the new assignee
 		err = t.addNewAssigneeByID(s, u.ID, list, doer)
 		if err != nil {
@@ -203,6 +208,11 @@ func (la *TaskAssginee) Create(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	err = checkAssigneeBucketLimits(s, la.TaskID, la.UserID)
+	if err != nil {
+		return
+	}
+
 	task := &Task{ID: la.TaskID}
 	return task.addNewAssigneeByID(s, la.UserID, list, a)
 }
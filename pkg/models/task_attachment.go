@@ -20,6 +20,7 @@ import (
 	"io"
 	"time"
 
+	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/files"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
@@ -84,7 +85,16 @@ func (ta *TaskAttachment) NewAttachment(s *xorm.Session, f io.ReadCloser, realna
 		return err
 	}
 
-	return nil
+	task, err := GetTaskByIDSimple(s, ta.TaskID)
+	if err != nil {
+		return err
+	}
+
+	return events.Dispatch(&TaskAttachmentCreatedEvent{
+		Task:       &task,
+		Attachment: ta,
+		Doer:       ta.CreatedBy,
+	})
 }
 
 // ReadOne returns a task attachment
@@ -203,6 +213,21 @@ func (ta *TaskAttachment) Delete(s *xorm.Session, a web.Auth) error {
 		return err
 	}
 
+	task, err := GetTaskByIDSimple(s, ta.TaskID)
+	if err != nil {
+		return err
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	err = events.Dispatch(&TaskAttachmentDeletedEvent{
+		Task:       &task,
+		Attachment: ta,
+		Doer:       doer,
+	})
+	if err != nil {
+		return err
+	}
+
 	// Delete the underlying file
 	err = ta.File.Delete()
 	// If the file does not exist, we don't want to error out
@@ -74,7 +74,8 @@ func validateTaskField(fieldName string) error {
 		taskPropertyUpdated,
 		taskPropertyPosition,
 		taskPropertyKanbanPosition,
-		taskPropertyBucketID:
+		taskPropertyBucketID,
+		taskPropertyStatusID:
 		return nil
 	}
 	return ErrInvalidTaskField{TaskField: fieldName}
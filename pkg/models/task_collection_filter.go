@@ -214,6 +214,11 @@ func getNativeValueForTaskField(fieldName string, comparator taskFilterComparato
 		return
 	}
 
+	// The status is filtered by its id
+	if realFieldName == "Status" {
+		realFieldName = "StatusID"
+	}
+
 	if realFieldName == "Assignees" {
 		vals := strings.Split(value, ",")
 		valueSlice := append([]string{}, vals...)
@@ -29,11 +29,11 @@ func (tc *TaskComment) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
 
 func (tc *TaskComment) canUserModifyTaskComment(s *xorm.Session, a web.Auth) (bool, error) {
 	t := Task{ID: tc.TaskID}
-	canWriteTask, err := t.CanWrite(s, a)
+	canComment, err := t.canDoTask(s, a, PermissionComment)
 	if err != nil {
 		return false, err
 	}
-	if !canWriteTask {
+	if !canComment {
 		return false, nil
 	}
 
@@ -62,5 +62,5 @@ func (tc *TaskComment) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
 // CanCreate checks if a user can create a new comment
 func (tc *TaskComment) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
 	t := Task{ID: tc.TaskID}
-	return t.CanWrite(s, a)
+	return t.canDoTask(s, a, PermissionComment)
 }
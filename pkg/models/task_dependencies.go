@@ -0,0 +1,203 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// dependencyKinds are the relation kinds which make a task depend on another. Relations of these kinds point from the
+// task which has to be done first to the task which depends on it.
+var dependencyKinds = []RelationKind{RelationKindBlocking, RelationKindPreceeds}
+
+// dependency returns the task which has to be done first and the task which depends on it. ok is false if the relation
+// does not make one task depend on the other.
+func (rel *TaskRelation) dependency() (before, after int64, ok bool) {
+	switch rel.RelationKind {
+	case RelationKindBlocking, RelationKindPreceeds:
+		return rel.TaskID, rel.OtherTaskID, true
+	case RelationKindBlocked, RelationKindFollows:
+		return rel.OtherTaskID, rel.TaskID, true
+	}
+	return 0, 0, false
+}
+
+// getDependentTaskIDs returns the ids of all tasks which directly depend on one of the given tasks, keyed by the task
+// they depend on.
+func getDependentTaskIDs(s *xorm.Session, taskIDs []int64) (dependents map[int64][]int64, err error) {
+	relations := []*TaskRelation{}
+	err = s.
+		In("task_id", taskIDs).
+		In("relation_kind", dependencyKinds).
+		Find(&relations)
+	if err != nil {
+		return nil, err
+	}
+
+	dependents = make(map[int64][]int64)
+	for _, rel := range relations {
+		dependents[rel.TaskID] = append(dependents[rel.TaskID], rel.OtherTaskID)
+	}
+	return
+}
+
+// checkDependencyCycle returns an error if a new relation would make a task depend on itself, directly or through other
+// tasks.
+func checkDependencyCycle(s *xorm.Session, rel *TaskRelation) error {
+	before, after, ok := rel.dependency()
+	if !ok {
+		return nil
+	}
+
+	// The relation creates a cycle if the task which has to be done first already depends on the other one.
+	visited := map[int64]bool{after: true}
+	current := []int64{after}
+	for len(current) > 0 {
+		dependents, err := getDependentTaskIDs(s, current)
+		if err != nil {
+			return err
+		}
+
+		next := []int64{}
+		for _, ids := range dependents {
+			for _, id := range ids {
+				if id == before {
+					return &ErrRelationCreatesCycle{
+						TaskID:      rel.TaskID,
+						OtherTaskID: rel.OtherTaskID,
+						Kind:        rel.RelationKind,
+					}
+				}
+				if !visited[id] {
+					visited[id] = true
+					next = append(next, id)
+				}
+			}
+		}
+		current = next
+	}
+
+	return nil
+}
+
+// checkTaskIsNotBlocked returns an error if completing blocked tasks is disabled and one of the tasks blocking a task
+// is not done yet.
+func checkTaskIsNotBlocked(s *xorm.Session, taskID int64) error {
+	if !config.ServicePreventCompletingBlockedTasks.GetBool() {
+		return nil
+	}
+
+	blockingTaskIDs := []int64{}
+	err := s.
+		Table("task_relations").
+		Join("INNER", "tasks", "tasks.id = task_relations.other_task_id").
+		Where("task_relations.task_id = ? AND task_relations.relation_kind = ? AND tasks.done = ? AND tasks.deleted IS NULL", taskID, RelationKindBlocked, false).
+		OrderBy("tasks.id asc").
+		Select("tasks.id").
+		Find(&blockingTaskIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(blockingTaskIDs) > 0 {
+		return &ErrTaskIsBlocked{TaskID: taskID, BlockingTaskIDs: blockingTaskIDs}
+	}
+	return nil
+}
+
+// getDependencyShift returns by how much the dates of the tasks depending on a task need to be moved after it was
+// updated. The end date is used if the task has one, the start date otherwise.
+func getDependencyShift(oldTask, newTask *Task) time.Duration {
+	if !oldTask.EndDate.IsZero() && !newTask.EndDate.IsZero() {
+		return newTask.EndDate.Sub(oldTask.EndDate)
+	}
+	if oldTask.EndDate.IsZero() && newTask.EndDate.IsZero() &&
+		!oldTask.StartDate.IsZero() && !newTask.StartDate.IsZero() {
+		return newTask.StartDate.Sub(oldTask.StartDate)
+	}
+	return 0
+}
+
+// shiftDependentTasks moves the start and end dates of all tasks which depend on a task by the same duration the task
+// was moved. Tasks without dates or which the user cannot edit are left as they are, and so are the tasks depending on
+// them.
+func shiftDependentTasks(s *xorm.Session, a web.Auth, taskID int64, shift time.Duration) error {
+	if shift == 0 {
+		return nil
+	}
+
+	visited := map[int64]bool{taskID: true}
+	current := []int64{taskID}
+	for len(current) > 0 {
+		dependents, err := getDependentTaskIDs(s, current)
+		if err != nil {
+			return err
+		}
+
+		ids := []int64{}
+		for _, dependentIDs := range dependents {
+			for _, id := range dependentIDs {
+				if !visited[id] {
+					visited[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		tasks := []*Task{}
+		err = s.In("id", ids).Find(&tasks)
+		if err != nil {
+			return err
+		}
+
+		current = []int64{}
+		for _, t := range tasks {
+			if t.StartDate.IsZero() && t.EndDate.IsZero() {
+				continue
+			}
+
+			can, err := t.CanUpdate(s, a)
+			if err != nil {
+				return err
+			}
+			if !can {
+				continue
+			}
+
+			if !t.StartDate.IsZero() {
+				t.StartDate = t.StartDate.Add(shift)
+			}
+			if !t.EndDate.IsZero() {
+				t.EndDate = t.EndDate.Add(shift)
+			}
+			_, err = s.ID(t.ID).Cols("start_date", "end_date").Update(t)
+			if err != nil {
+				return err
+			}
+			current = append(current, t.ID)
+		}
+	}
+
+	return nil
+}
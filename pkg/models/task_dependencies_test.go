@@ -0,0 +1,210 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+)
+
+func createRelations(t *testing.T, s *xorm.Session, relations ...*TaskRelation) {
+	for _, rel := range relations {
+		err := rel.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+	}
+}
+
+func TestTaskRelation_Create_Cycle(t *testing.T) {
+	t.Run("direct", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		createRelations(t, s, &TaskRelation{TaskID: 1, OtherTaskID: 2, RelationKind: RelationKindBlocking})
+
+		rel := &TaskRelation{TaskID: 2, OtherTaskID: 1, RelationKind: RelationKindPreceeds}
+		err := rel.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrRelationCreatesCycle(err))
+	})
+	t.Run("through other tasks", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		createRelations(t, s,
+			&TaskRelation{TaskID: 1, OtherTaskID: 2, RelationKind: RelationKindBlocking},
+			&TaskRelation{TaskID: 3, OtherTaskID: 2, RelationKind: RelationKindFollows},
+		)
+
+		// 3 blocked by ... 1 would mean 1 -> 2 -> 3 -> 1
+		rel := &TaskRelation{TaskID: 1, OtherTaskID: 3, RelationKind: RelationKindBlocked}
+		err := rel.Create(s, &user.User{ID: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrRelationCreatesCycle(err))
+
+		// Same direction is fine
+		rel = &TaskRelation{TaskID: 1, OtherTaskID: 3, RelationKind: RelationKindPreceeds}
+		err = rel.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+	})
+	t.Run("other relation kinds", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		createRelations(t, s, &TaskRelation{TaskID: 1, OtherTaskID: 2, RelationKind: RelationKindBlocking})
+
+		rel := &TaskRelation{TaskID: 2, OtherTaskID: 1, RelationKind: RelationKindRelated}
+		err := rel.Create(s, &user.User{ID: 1})
+		assert.NoError(t, err)
+	})
+}
+
+func TestTask_Update_Blocked(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("allowed by default", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		createRelations(t, s, &TaskRelation{TaskID: 3, OtherTaskID: 1, RelationKind: RelationKindBlocking})
+
+		task := &Task{ID: 1, Title: "task #1", ListID: 1, Done: true}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+	})
+	t.Run("prevented", func(t *testing.T) {
+		config.ServicePreventCompletingBlockedTasks.Set(true)
+		defer config.ServicePreventCompletingBlockedTasks.Set(false)
+
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		createRelations(t, s,
+			&TaskRelation{TaskID: 3, OtherTaskID: 1, RelationKind: RelationKindBlocking},
+			// Task 2 is done already
+			&TaskRelation{TaskID: 2, OtherTaskID: 1, RelationKind: RelationKindBlocking},
+		)
+
+		task := &Task{ID: 1, Title: "task #1", ListID: 1, Done: true}
+		err := task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskIsBlocked(err))
+		assert.Equal(t, []int64{3}, err.(*ErrTaskIsBlocked).BlockingTaskIDs)
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: 3}
+		err = m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskIsBlocked(err))
+
+		bt := &BulkTask{IDs: []int64{1}, Task: Task{Done: true}}
+		can, err := bt.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = bt.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskIsBlocked(err))
+	})
+}
+
+func TestTask_Update_ShiftDependents(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	end := time.Date(2018, 12, 10, 12, 0, 0, 0, config.GetTimeZone())
+	_, err := s.ID(1).Cols("end_date").Update(&Task{EndDate: end})
+	assert.NoError(t, err)
+
+	createRelations(t, s,
+		// Task 7 has a start date, task 8 an end date, task 10 has no dates
+		&TaskRelation{TaskID: 1, OtherTaskID: 7, RelationKind: RelationKindPreceeds},
+		&TaskRelation{TaskID: 7, OtherTaskID: 8, RelationKind: RelationKindBlocking},
+		&TaskRelation{TaskID: 1, OtherTaskID: 10, RelationKind: RelationKindPreceeds},
+	)
+
+	before7, err := GetTaskByIDSimple(s, 7)
+	assert.NoError(t, err)
+	before8, err := GetTaskByIDSimple(s, 8)
+	assert.NoError(t, err)
+
+	task := &Task{ID: 1, Title: "task #1", ListID: 1, EndDate: end.Add(48 * time.Hour)}
+	err = task.Update(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+
+	after7, err := GetTaskByIDSimple(s, 7)
+	assert.NoError(t, err)
+	after8, err := GetTaskByIDSimple(s, 8)
+	assert.NoError(t, err)
+	after10, err := GetTaskByIDSimple(s, 10)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 48*time.Hour, after7.StartDate.Sub(before7.StartDate))
+	assert.Equal(t, 48*time.Hour, after8.EndDate.Sub(before8.EndDate))
+	assert.True(t, after10.StartDate.IsZero())
+	assert.True(t, after10.EndDate.IsZero())
+}
+
+func TestCriticalPath_ReadOne(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	start := time.Date(2018, 12, 1, 0, 0, 0, 0, config.GetTimeZone())
+	day := 24 * time.Hour
+	for id, days := range map[int64]time.Duration{3: 2, 4: 1, 5: 3} {
+		_, err := s.ID(id).Cols("start_date", "end_date").Update(&Task{StartDate: start, EndDate: start.Add(days * day)})
+		assert.NoError(t, err)
+	}
+	createRelations(t, s,
+		&TaskRelation{TaskID: 3, OtherTaskID: 4, RelationKind: RelationKindPreceeds},
+		&TaskRelation{TaskID: 3, OtherTaskID: 5, RelationKind: RelationKindBlocking},
+	)
+
+	cp := &CriticalPath{ListID: 1}
+	err := cp.ReadOne(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 5}, cp.TaskIDs)
+	assert.Equal(t, int64(5*day.Seconds()), cp.Duration)
+
+	tasks := make(map[int64]*CriticalPathTask, len(cp.Tasks))
+	for _, task := range cp.Tasks {
+		tasks[task.TaskID] = task
+	}
+	assert.NotContains(t, tasks, int64(2)) // Done
+	assert.True(t, tasks[3].Critical)
+	assert.True(t, tasks[5].Critical)
+	assert.False(t, tasks[4].Critical)
+	assert.Equal(t, int64(2*day.Seconds()), tasks[4].EarliestStart)
+	assert.Equal(t, int64(2*day.Seconds()), tasks[4].Slack)
+
+	t.Run("no access", func(t *testing.T) {
+		cp := &CriticalPath{ListID: 1}
+		can, _, err := cp.CanRead(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
@@ -0,0 +1,139 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// TaskHistoryKind describes what happened to a task in a history entry
+type TaskHistoryKind string
+
+// All kinds of task history entries
+const (
+	// TaskHistoryKindBucketRule is recorded when a bucket rule changed a task.
+	TaskHistoryKindBucketRule TaskHistoryKind = `bucket_rule`
+	// TaskHistoryKindMoved is recorded when a task was moved to another list.
+	TaskHistoryKindMoved TaskHistoryKind = `moved`
+)
+
+// TaskHistory is an entry in the history of a task. It records changes which were made automatically and moves of
+// the task to other lists.
+type TaskHistory struct {
+	// The unique, numeric id of this history entry.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The task which was changed.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id" param:"listtask"`
+	// What happened to the task.
+	Kind TaskHistoryKind `xorm:"varchar(50) not null" json:"kind"`
+	// The bucket which caused the change.
+	BucketID int64 `xorm:"bigint null" json:"bucket_id"`
+	// The bucket rule which changed the task.
+	BucketRuleID int64 `xorm:"bigint null" json:"bucket_rule_id"`
+	// The trigger of the bucket rule.
+	Trigger BucketRuleTrigger `xorm:"varchar(50) null" json:"trigger"`
+	// The action of the bucket rule.
+	Action BucketRuleAction `xorm:"varchar(50) null" json:"action"`
+	// The value of the bucket rule at the time it was executed.
+	Value int64 `xorm:"bigint null" json:"value"`
+	// The list the task was moved from.
+	FromListID int64 `xorm:"bigint null" json:"from_list_id"`
+	// The list the task was moved to.
+	ToListID int64 `xorm:"bigint null" json:"to_list_id"`
+	// The identifier the task had before it was moved.
+	OldIdentifier string `xorm:"varchar(250) null" json:"old_identifier"`
+
+	CreatedByID int64 `xorm:"bigint not null" json:"-"`
+	// The user whose action led to the change.
+	CreatedBy *user.User `xorm:"-" json:"created_by"`
+
+	// A timestamp when this entry was created.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for task history entries
+func (*TaskHistory) TableName() string {
+	return "task_history"
+}
+
+// CanRead checks if a user can see the history of a task
+func (th *TaskHistory) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	t := &Task{ID: th.TaskID}
+	return t.CanRead(s, a)
+}
+
+// ReadAll returns the history of a task
+// @Summary Get the history of a task
+// @Description Returns all changes which were made automatically to a task and all moves of the task to other lists, newest first.
+// @tags task
+// @Accept json
+// @Produce json
+// @Param taskID path int true "Task ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.TaskHistory "The history entries."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{taskID}/history [get]
+func (th *TaskHistory) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	can, _, err := th.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	entries := []*TaskHistory{}
+	query := s.
+		Where("task_id = ?", th.TaskID).
+		OrderBy("id desc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&entries)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		userIDs = append(userIDs, e.CreatedByID)
+	}
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, e := range entries {
+		e.CreatedBy = users[e.CreatedByID]
+	}
+
+	numberOfTotalItems, err = s.
+		Where("task_id = ?", th.TaskID).
+		Count(&TaskHistory{})
+	return entries, len(entries), numberOfTotalItems, err
+}
@@ -0,0 +1,410 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// TaskIdentifierRedirect keeps the old index of a task which was moved to another list around. This way, old
+// identifiers of the task still lead to it.
+type TaskIdentifierRedirect struct {
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"-"`
+	// The task the identifier redirects to.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id"`
+	// The list the task was in.
+	ListID int64 `xorm:"bigint not null INDEX" json:"list_id"`
+	// The index the task had in that list.
+	Index int64 `xorm:"bigint not null" json:"index"`
+	// A timestamp when the task was moved.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for task identifier redirects
+func (*TaskIdentifierRedirect) TableName() string {
+	return "task_identifier_redirects"
+}
+
+// getNextTaskIndex returns the next free index in a list. Indexes of tasks which were moved away from the list are
+// not reused to keep redirects from their old identifiers working. Tasks in the trash keep their index as well.
+func getNextTaskIndex(s *xorm.Session, listID int64) (index int64, err error) {
+	latestTask := &Task{}
+	_, err = s.
+		Unscoped().
+		Where("list_id = ?", listID).
+		OrderBy("`index` desc").
+		Get(latestTask)
+	if err != nil {
+		return 0, err
+	}
+
+	latestRedirect := &TaskIdentifierRedirect{}
+	_, err = s.
+		Where("list_id = ?", listID).
+		OrderBy("`index` desc").
+		Get(latestRedirect)
+	if err != nil {
+		return 0, err
+	}
+
+	if latestRedirect.Index > latestTask.Index {
+		return latestRedirect.Index + 1, nil
+	}
+	return latestTask.Index + 1, nil
+}
+
+// getMatchingBucketID returns the id of the bucket in the list which matches the bucket a task was in before it was
+// moved to that list. The done bucket matches the done bucket, all other buckets the bucket with the same title.
+// Returns 0 if there is no matching bucket.
+func getMatchingBucketID(s *xorm.Session, bucketID int64, listID int64) (int64, error) {
+	if bucketID == 0 {
+		return 0, nil
+	}
+
+	bucket, err := getBucketByID(s, bucketID)
+	if err != nil {
+		if IsErrBucketDoesNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if bucket.IsDoneBucket {
+		done, err := getDoneBucketForList(s, listID)
+		if err != nil || done == nil {
+			return 0, err
+		}
+		return done.ID, nil
+	}
+
+	buckets := []*Bucket{}
+	err = s.
+		Where("list_id = ?", listID).
+		OrderBy("position asc").
+		Find(&buckets)
+	if err != nil {
+		return 0, err
+	}
+	for _, b := range buckets {
+		if strings.EqualFold(b.Title, bucket.Title) {
+			return b.ID, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// moveTaskToList gives a task which is moved to another list a new index in that list. It keeps a redirect from the
+// old identifier of the task and records the move in the history of the task.
+func moveTaskToList(s *xorm.Session, a web.Auth, task *Task, originalTask *Task) (err error) {
+	task.Index, err = getNextTaskIndex(s, task.ListID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Insert(&TaskIdentifierRedirect{
+		TaskID: task.ID,
+		ListID: originalTask.ListID,
+		Index:  originalTask.Index,
+	})
+	if err != nil {
+		return err
+	}
+
+	lists, err := GetListsByIDs(s, []int64{originalTask.ListID, task.ListID})
+	if err != nil {
+		return err
+	}
+	oldList, newList := lists[originalTask.ListID], lists[task.ListID]
+
+	old := *originalTask
+	if oldList != nil {
+		old.setIdentifier(oldList)
+	}
+	if newList != nil {
+		task.setIdentifier(newList)
+	}
+
+	// The task is not part of the board of the namespace of its old list anymore
+	if oldList != nil && newList != nil && oldList.NamespaceID != newList.NamespaceID {
+		_, err = s.
+			Where("task_id = ?", task.ID).
+			In("bucket_id", (&Bucket{NamespaceID: oldList.NamespaceID}).boardBucketIDs()).
+			Delete(&TaskBucket{})
+		if err != nil {
+			return err
+		}
+	}
+
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Insert(&TaskHistory{
+		TaskID:        task.ID,
+		Kind:          TaskHistoryKindMoved,
+		FromListID:    originalTask.ListID,
+		ToListID:      task.ListID,
+		OldIdentifier: old.GetFullIdentifier(),
+		CreatedByID:   doer.ID,
+	})
+	return err
+}
+
+// TaskMove represents a move of a task to another list.
+type TaskMove struct {
+	// The id of the task to move.
+	TaskID int64 `json:"-" param:"listtask"`
+	// The list the task should be moved to.
+	ListID int64 `json:"list_id"`
+	// The bucket in the new list the task should be put in. If not provided, the task will be put in the bucket
+	// matching the one it was in before, or the default bucket of the list if there is none.
+	BucketID int64 `json:"bucket_id"`
+
+	// The task after it was moved.
+	Task *Task `json:"task"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// CanUpdate checks if a user can move a task. The user needs to be able to edit the task and create tasks in the
+// new list.
+func (m *TaskMove) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	t := &Task{ID: m.TaskID, ListID: m.ListID}
+	return t.CanUpdate(s, a)
+}
+
+// Update moves a task to another list
+// @Summary Move a task to another list
+// @Description Moves a task to another list. The task gets a new index in the new list, its old identifier will still lead to it. If no bucket is provided, the task is put in the bucket with the same title as the one it was in, the done bucket if it was in the done bucket or the default bucket otherwise. The move is recorded in the history of the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Task ID"
+// @Param move body models.TaskMove true "Where to move the task to"
+// @Success 200 {object} models.TaskMove "The move including the updated task."
+// @Failure 400 {object} web.HTTPError "The bucket does not belong to the list."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task or the list."
+// @Failure 412 {object} web.HTTPError "The bucket limit is exceeded."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{id}/move [post]
+func (m *TaskMove) Update(s *xorm.Session, a web.Auth) (err error) {
+	ot, err := GetTaskByIDSimple(s, m.TaskID)
+	if err != nil {
+		return err
+	}
+	oldTask := ot
+
+	task := ot
+	if m.ListID != 0 {
+		task.ListID = m.ListID
+	}
+	task.BucketID = m.BucketID
+
+	list := &List{ID: task.ListID}
+	if err := list.CheckIsArchived(s); err != nil {
+		return err
+	}
+
+	if m.BucketID != 0 {
+		bucket, err := getBucketByID(s, m.BucketID)
+		if err != nil {
+			return err
+		}
+		if err := checkBucketAndTaskBelongToSameList(&task, bucket); err != nil {
+			return err
+		}
+	}
+
+	if task.ListID != ot.ListID && task.BucketID == 0 {
+		task.BucketID, err = getMatchingBucketID(s, ot.BucketID, task.ListID)
+		if err != nil {
+			return err
+		}
+	}
+	if task.ListID == ot.ListID && task.BucketID == 0 {
+		task.BucketID = ot.BucketID
+	}
+
+	if err := setTaskStatus(s, &task, &ot); err != nil {
+		return err
+	}
+
+	if err := setTaskBucket(s, &task, &ot, task.BucketID != ot.BucketID); err != nil {
+		return err
+	}
+
+	if err := setTaskStatusFromBucket(s, &task, &ot); err != nil {
+		return err
+	}
+
+	colsToUpdate := []string{"list_id", "bucket_id", "status_id"}
+	doneCols, err := updateTaskDoneAfterMove(s, &task, &ot)
+	if err != nil {
+		return err
+	}
+	colsToUpdate = append(colsToUpdate, doneCols...)
+
+	if task.ListID != ot.ListID {
+		if err := moveTaskToList(s, a, &task, &ot); err != nil {
+			return err
+		}
+		colsToUpdate = append(colsToUpdate, "index")
+	}
+
+	_, err = s.ID(task.ID).Cols(colsToUpdate...).Update(&task)
+	if err != nil {
+		return err
+	}
+
+	err = recordBucketTransition(s, task.ID, ot.BucketID, task.BucketID)
+	if err != nil {
+		return err
+	}
+
+	err = applyBucketRules(s, a, &task, ot.BucketID, task.BucketID)
+	if err != nil {
+		return err
+	}
+
+	updated, err := GetTaskByIDSimple(s, task.ID)
+	if err != nil {
+		return err
+	}
+	m.Task = &updated
+	m.Task.Identifier = task.Identifier
+
+	doer, _ := user.GetFromAuth(a)
+	return events.Dispatch(&TaskUpdatedEvent{
+		Task:    m.Task,
+		OldTask: &oldTask,
+		Doer:    doer,
+	})
+}
+
+// TaskByIdentifier resolves a task identifier like PROJ-12 to its task.
+type TaskByIdentifier struct {
+	// The identifier of the task, made of the identifier of its list and the index of the task.
+	Identifier string `json:"identifier" param:"identifier"`
+	// True if the identifier is an old identifier of a task which was moved to another list.
+	Redirected bool `json:"redirected"`
+	// The task the identifier belongs to.
+	Task *Task `json:"task"`
+
+	taskID int64
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// resolve finds the task the identifier belongs to, either by the index of a task in the list or by the redirects
+// of tasks which were moved away from the list.
+func (ti *TaskByIdentifier) resolve(s *xorm.Session) (err error) {
+	notFound := &ErrTaskIdentifierDoesNotExist{Identifier: ti.Identifier}
+
+	sep := strings.LastIndex(ti.Identifier, "-")
+	if sep <= 0 {
+		return notFound
+	}
+	index, err := strconv.ParseInt(ti.Identifier[sep+1:], 10, 64)
+	if err != nil {
+		return notFound
+	}
+
+	list := &List{}
+	exists, err := s.
+		Where("identifier = ?", ti.Identifier[:sep]).
+		Get(list)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return notFound
+	}
+
+	task := &Task{}
+	exists, err = s.
+		Where("list_id = ? AND `index` = ?", list.ID, index).
+		Get(task)
+	if err != nil {
+		return err
+	}
+	if exists {
+		ti.taskID = task.ID
+		return nil
+	}
+
+	redirect := &TaskIdentifierRedirect{}
+	exists, err = s.
+		Where("list_id = ? AND `index` = ?", list.ID, index).
+		OrderBy("id desc").
+		Get(redirect)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return notFound
+	}
+
+	ti.taskID = redirect.TaskID
+	ti.Redirected = true
+	return nil
+}
+
+// CanRead checks if a user can read the task the identifier belongs to
+func (ti *TaskByIdentifier) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if err := ti.resolve(s); err != nil {
+		return false, 0, err
+	}
+
+	t := &Task{ID: ti.taskID}
+	return t.CanRead(s, a)
+}
+
+// ReadOne returns the task an identifier belongs to
+// @Summary Get a task by its identifier
+// @Description Returns the task with the identifier. Old identifiers of tasks which were moved to another list still lead to the task, `redirected` is true in that case.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param identifier path string true "The task identifier, for example PROJ-12"
+// @Success 200 {object} models.TaskByIdentifier "The task"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 404 {object} web.HTTPError "There is no task with this identifier."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/by-identifier/{identifier} [get]
+func (ti *TaskByIdentifier) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	if ti.taskID == 0 {
+		if err := ti.resolve(s); err != nil {
+			return err
+		}
+	}
+
+	ti.Task = &Task{ID: ti.taskID}
+	return ti.Task.ReadOne(s, a)
+}
@@ -0,0 +1,235 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskMove_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("move to another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &TaskMove{TaskID: 1, ListID: 2}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		// List 2 has tasks with index 1 and 2 and a redirect from index 3
+		assert.Equal(t, int64(4), m.Task.Index)
+		assert.Equal(t, "test2-4", m.Task.Identifier)
+		// Bucket 4 is the default and done bucket of list 2
+		assert.Equal(t, int64(4), m.Task.BucketID)
+		assert.True(t, m.Task.Done)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":        1,
+			"list_id":   2,
+			"index":     4,
+			"bucket_id": 4,
+		}, false)
+		db.AssertExists(t, "task_identifier_redirects", map[string]interface{}{
+			"task_id": 1,
+			"list_id": 1,
+			"index":   1,
+		}, false)
+		db.AssertExists(t, "task_history", map[string]interface{}{
+			"task_id":        1,
+			"kind":           TaskHistoryKindMoved,
+			"from_list_id":   1,
+			"to_list_id":     2,
+			"old_identifier": "test1-1",
+			"created_by_id":  1,
+		}, false)
+		// List 2 is in the same namespace, task 1 stays on the board of namespace 1
+		db.AssertExists(t, "task_buckets", map[string]interface{}{
+			"task_id":   1,
+			"bucket_id": 37,
+		}, false)
+	})
+	t.Run("moved by a link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task, err := GetTaskByIDSimple(s, 1)
+		assert.NoError(t, err)
+		original := task
+		task.ListID = 2
+
+		err = moveTaskToList(s, &LinkSharing{ID: 2}, &task, &original)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		// Link shares show up with their negative id, the user with id 2 did not move the task
+		db.AssertExists(t, "task_history", map[string]interface{}{
+			"task_id":       1,
+			"kind":          TaskHistoryKindMoved,
+			"created_by_id": -2,
+		}, false)
+	})
+	t.Run("matching bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{Title: "TestBucket1", ListID: 2, CreatedByID: 1}
+		_, err := s.Insert(b)
+		assert.NoError(t, err)
+
+		m := &TaskMove{TaskID: 1, ListID: 2}
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, b.ID, m.Task.BucketID)
+		assert.False(t, m.Task.Done)
+	})
+	t.Run("bucket of another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &TaskMove{TaskID: 1, ListID: 2, BucketID: 1}
+		err := m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrBucketDoesNotBelongToList(err))
+	})
+	t.Run("list in another namespace", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// List 16 is in namespace 11, which is shared with user 1 with write access
+		m := &TaskMove{TaskID: 1, ListID: 16}
+		can, err := m.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = m.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertMissing(t, "task_buckets", map[string]interface{}{
+			"task_id":   1,
+			"bucket_id": 37,
+		})
+	})
+	t.Run("no access to the new list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &TaskMove{TaskID: 1, ListID: 24}
+		can, err := m.CanUpdate(s, u)
+		assert.Error(t, err)
+		assert.False(t, can)
+	})
+	t.Run("index is not reused", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Task 13 has index 1 and task 37 index 2 in list 2, index 3 is used by a redirect
+		m := &TaskMove{TaskID: 37, ListID: 1}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+
+		task := &Task{Title: "new", ListID: 2}
+		err = task.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), task.Index)
+	})
+}
+
+func TestTaskByIdentifier_ReadOne(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("current identifier", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TaskByIdentifier{Identifier: "test1-1"}
+		can, _, err := ti.CanRead(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = ti.ReadOne(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), ti.Task.ID)
+		assert.False(t, ti.Redirected)
+	})
+	t.Run("old identifier", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TaskByIdentifier{Identifier: "test2-3"}
+		err := ti.ReadOne(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), ti.Task.ID)
+		assert.True(t, ti.Redirected)
+	})
+	t.Run("after moving a task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &TaskMove{TaskID: 2, ListID: 2}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+
+		ti := &TaskByIdentifier{Identifier: "test1-2"}
+		err = ti.ReadOne(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), ti.Task.ID)
+		assert.True(t, ti.Redirected)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		for _, identifier := range []string{"test1-9999", "nope-1", "test1", "test1-abc"} {
+			ti := &TaskByIdentifier{Identifier: identifier}
+			_, _, err := ti.CanRead(s, u)
+			assert.Error(t, err)
+			assert.True(t, IsErrTaskIdentifierDoesNotExist(err), identifier)
+		}
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TaskByIdentifier{Identifier: "test1-1"}
+		can, _, err := ti.CanRead(s, &user.User{ID: 2})
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
@@ -145,6 +145,11 @@ func (rel *TaskRelation) Create(s *xorm.Session, a web.Auth) error {
 		}
 	}
 
+	err = checkDependencyCycle(s, rel)
+	if err != nil {
+		return err
+	}
+
 	rel.CreatedBy, err = GetUserOrLinkShareUser(s, a)
 	if err != nil {
 		return err
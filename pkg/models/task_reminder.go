@@ -38,7 +38,9 @@ type TaskReminder struct {
 	ID       int64     `xorm:"bigint autoincr not null unique pk"`
 	TaskID   int64     `xorm:"bigint not null INDEX"`
 	Reminder time.Time `xorm:"DATETIME not null INDEX 'reminder'"`
-	Created  time.Time `xorm:"created not null"`
+	// If set, this is a snoozed reminder which only notifies this user and is removed once it was sent.
+	UserID  int64     `xorm:"bigint not null default 0 INDEX"`
+	Created time.Time `xorm:"created not null"`
 }
 
 // TableName returns a pretty table name
@@ -124,6 +126,7 @@ func getTasksWithRemindersDueAndTheirUsers(s *xorm.Session, now time.Time) (remi
 		// All reminders from -12h to +14h to include all time zones
 		Where("reminder >= ? and reminder < ?", now.Add(time.Hour*-12).Format(dbTimeFormat), nextMinute.Add(time.Hour*14).Format(dbTimeFormat)).
 		And("tasks.done = false").
+		And("tasks.deleted IS NULL").
 		Find(&reminders)
 	if err != nil {
 		return
@@ -175,12 +178,21 @@ func getTasksWithRemindersDueAndTheirUsers(s *xorm.Session, now time.Time) (remi
 				tzs[u.User.Timezone] = tz
 			}
 
+			// Snoozed reminders only notify the user who snoozed them
+			if r.UserID != 0 && r.UserID != u.User.ID {
+				continue
+			}
+
 			actualReminder := r.Reminder.In(tz)
 			if (actualReminder.After(now) && actualReminder.Before(now.Add(time.Minute))) || actualReminder.Equal(now) {
-				reminderNotifications = append(reminderNotifications, &ReminderDueNotification{
+				n := &ReminderDueNotification{
 					User: u.User,
 					Task: u.Task,
-				})
+				}
+				if r.UserID != 0 {
+					n.snoozedReminderID = r.ID
+				}
+				reminderNotifications = append(reminderNotifications, n)
 			}
 		}
 	}
@@ -229,9 +241,50 @@ func RegisterReminderCron() {
 			}
 
 			log.Debugf("[Task Reminder Cron] Sent reminder email for task %d to user %d", n.Task.ID, n.User.ID)
+
+			if n.snoozedReminderID != 0 {
+				_, err = s.Where("id = ?", n.snoozedReminderID).Delete(&TaskReminder{})
+				if err != nil {
+					log.Errorf("[Task Reminder Cron] Could not remove snoozed reminder %d: %s", n.snoozedReminderID, err)
+					return
+				}
+			}
 		}
+
 	})
 	if err != nil {
 		log.Fatalf("Could not register reminder cron: %s", err)
 	}
 }
+
+// getSnoozedRemindersForUser returns all snoozed reminders of a user for the given tasks, keyed by task id.
+func getSnoozedRemindersForUser(s *xorm.Session, taskIDs []int64, userID int64) (reminders map[int64][]time.Time, err error) {
+	reminders = make(map[int64][]time.Time)
+
+	snoozed := []*TaskReminder{}
+	err = s.
+		In("task_id", taskIDs).
+		And("user_id = ?", userID).
+		OrderBy("reminder ASC").
+		Find(&snoozed)
+	if err != nil {
+		return
+	}
+
+	for _, r := range snoozed {
+		reminders[r.TaskID] = append(reminders[r.TaskID], r.Reminder)
+	}
+
+	return
+}
+
+// snoozeReminder reschedules a reminder of a task for a single user. The reminder cron will pick it up
+// like every other reminder and remove it once it was sent.
+func snoozeReminder(s *xorm.Session, taskID, userID int64, until time.Time) (err error) {
+	_, err = s.Insert(&TaskReminder{
+		TaskID:   taskID,
+		UserID:   userID,
+		Reminder: until,
+	})
+	return
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,4 +49,74 @@ func TestReminderGetTasksInTheNextMinute(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, taskIDs, 0)
 	})
+	t.Run("Snoozed reminder", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		now, err := time.Parse(time.RFC3339Nano, "2018-12-02T01:13:00Z")
+		assert.NoError(t, err)
+		err = snoozeReminder(s, 27, 1, now.Add(30*time.Second))
+		assert.NoError(t, err)
+		// Snoozed reminders of other users should not notify the creator
+		err = snoozeReminder(s, 27, 2, now.Add(30*time.Second))
+		assert.NoError(t, err)
+
+		notifications, err := getTasksWithRemindersDueAndTheirUsers(s, now)
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+		assert.Equal(t, int64(27), notifications[0].Task.ID)
+		assert.Equal(t, int64(1), notifications[0].User.ID)
+		assert.NotZero(t, notifications[0].snoozedReminderID)
+	})
+	t.Run("Snoozed reminders are not regular reminders", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		until := time.Date(2018, 12, 2, 1, 13, 0, 0, time.UTC)
+		err := snoozeReminder(s, 27, 1, until)
+		assert.NoError(t, err)
+
+		reminders, err := getRemindersForTasks(s, []int64{27})
+		assert.NoError(t, err)
+		assert.Len(t, reminders, 2)
+
+		snoozed, err := getSnoozedRemindersForUser(s, []int64{27}, 1)
+		assert.NoError(t, err)
+		assert.Len(t, snoozed[27], 1)
+		assert.True(t, until.Equal(snoozed[27][0]))
+	})
+}
+
+func TestReminderFilterIgnoresSnoozedRemindersOfOtherUsers(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	until := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+	err := snoozeReminder(s, 1, 2, until)
+	assert.NoError(t, err)
+
+	readTaskIDs := func() []int64 {
+		tc := &TaskCollection{
+			ListID:           1,
+			FilterBy:         []string{"reminders", "reminders"},
+			FilterValue:      []string{"2018-11-01T00:00:00+00:00", "2018-11-30T00:00:00+00:00"},
+			FilterComparator: []string{"greater", "less"},
+		}
+		res, _, _, err := tc.ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		assert.NoError(t, err)
+		ids := []int64{}
+		for _, task := range res.([]*Task) {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	assert.NotContains(t, readTaskIDs(), int64(1))
+
+	err = snoozeReminder(s, 1, 1, until)
+	assert.NoError(t, err)
+	assert.Contains(t, readTaskIDs(), int64(1))
 }
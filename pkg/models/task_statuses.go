@@ -0,0 +1,542 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskCaldavStatus is the status a task has in caldav, see https://tools.ietf.org/html/rfc5545#section-3.8.1.11
+type TaskCaldavStatus string
+
+// All caldav statuses of a VTODO
+const (
+	TaskCaldavStatusNeedsAction TaskCaldavStatus = `NEEDS-ACTION`
+	TaskCaldavStatusInProcess   TaskCaldavStatus = `IN-PROCESS`
+	TaskCaldavStatusCompleted   TaskCaldavStatus = `COMPLETED`
+	TaskCaldavStatusCancelled   TaskCaldavStatus = `CANCELLED`
+)
+
+// TaskStatus is a workflow status like "in review" or "blocked" which can be configured per list.
+type TaskStatus struct {
+	// The unique, numeric id of this status.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"status"`
+	// The list this status belongs to.
+	ListID int64 `xorm:"bigint not null INDEX" json:"list_id" param:"list"`
+	// The title of this status.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// The status tasks with this status have in caldav. Can be one of `NEEDS-ACTION`, `IN-PROCESS`, `COMPLETED` or `CANCELLED`. Defaults to `NEEDS-ACTION`.
+	CaldavStatus TaskCaldavStatus `xorm:"varchar(20) not null" json:"caldav_status"`
+	// The kanban bucket this status is mapped to. Tasks with this status are moved into that bucket and tasks moved into that bucket get this status.
+	BucketID int64 `xorm:"bigint null" json:"bucket_id"`
+	// The position this status has when querying all statuses of a list.
+	Position float64 `xorm:"double null" json:"position"`
+	// The ids of all statuses a task with this status can be moved to. If empty, tasks can be moved to any status of the list
+	// or have their status removed. Otherwise, the status of a task can't be removed.
+	AllowedTransitions []int64 `xorm:"-" json:"allowed_transitions"`
+
+	// The user who initially created the status.
+	CreatedBy   *user.User `xorm:"-" json:"created_by" valid:"-"`
+	CreatedByID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this status was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this status was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for task statuses
+func (*TaskStatus) TableName() string {
+	return "task_statuses"
+}
+
+// TaskStatusTransition allows moving a task from one status to another.
+type TaskStatusTransition struct {
+	ID           int64 `xorm:"bigint autoincr not null unique pk"`
+	FromStatusID int64 `xorm:"bigint not null INDEX"`
+	ToStatusID   int64 `xorm:"bigint not null INDEX"`
+}
+
+// TableName returns the table name for task status transitions
+func (*TaskStatusTransition) TableName() string {
+	return "task_status_transitions"
+}
+
+func getTaskStatusByID(s *xorm.Session, id int64) (status *TaskStatus, err error) {
+	status = &TaskStatus{}
+	exists, err := s.Where("id = ?", id).Get(status)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrTaskStatusDoesNotExist{StatusID: id}
+	}
+	return
+}
+
+func addTransitionsToStatuses(s *xorm.Session, statuses []*TaskStatus) (err error) {
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	statusMap := make(map[int64]*TaskStatus, len(statuses))
+	statusIDs := make([]int64, 0, len(statuses))
+	for _, status := range statuses {
+		status.AllowedTransitions = []int64{}
+		statusMap[status.ID] = status
+		statusIDs = append(statusIDs, status.ID)
+	}
+
+	transitions := []*TaskStatusTransition{}
+	err = s.In("from_status_id", statusIDs).OrderBy("id asc").Find(&transitions)
+	if err != nil {
+		return err
+	}
+
+	for _, transition := range transitions {
+		status := statusMap[transition.FromStatusID]
+		status.AllowedTransitions = append(status.AllowedTransitions, transition.ToStatusID)
+	}
+	return nil
+}
+
+func (ts *TaskStatus) validate(s *xorm.Session) (err error) {
+	invalid := &ErrInvalidTaskStatus{StatusID: ts.ID, CaldavStatus: ts.CaldavStatus}
+
+	switch ts.CaldavStatus {
+	case "":
+		ts.CaldavStatus = TaskCaldavStatusNeedsAction
+	case TaskCaldavStatusNeedsAction, TaskCaldavStatusInProcess, TaskCaldavStatusCompleted, TaskCaldavStatusCancelled:
+	default:
+		return invalid
+	}
+
+	if ts.BucketID != 0 {
+		bucket, err := getBucketByID(s, ts.BucketID)
+		if err != nil {
+			return err
+		}
+		if bucket.ListID != ts.ListID {
+			return invalid
+		}
+	}
+
+	if len(ts.AllowedTransitions) == 0 {
+		return nil
+	}
+
+	unique := make(map[int64]bool, len(ts.AllowedTransitions))
+	transitions := make([]int64, 0, len(ts.AllowedTransitions))
+	for _, id := range ts.AllowedTransitions {
+		if !unique[id] {
+			unique[id] = true
+			transitions = append(transitions, id)
+		}
+	}
+	ts.AllowedTransitions = transitions
+
+	count, err := s.
+		Where("list_id = ?", ts.ListID).
+		In("id", transitions).
+		Count(&TaskStatus{})
+	if err != nil {
+		return err
+	}
+	if count != int64(len(transitions)) {
+		return invalid
+	}
+
+	return nil
+}
+
+func (ts *TaskStatus) saveTransitions(s *xorm.Session) (err error) {
+	_, err = s.Where("from_status_id = ?", ts.ID).Delete(&TaskStatusTransition{})
+	if err != nil {
+		return err
+	}
+
+	if len(ts.AllowedTransitions) == 0 {
+		return nil
+	}
+
+	transitions := make([]*TaskStatusTransition, 0, len(ts.AllowedTransitions))
+	for _, id := range ts.AllowedTransitions {
+		transitions = append(transitions, &TaskStatusTransition{FromStatusID: ts.ID, ToStatusID: id})
+	}
+	_, err = s.Insert(&transitions)
+	return err
+}
+
+// Create adds a new status to a list
+// @Summary Create a new task status
+// @Description Creates a new workflow status on a list.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Param status body models.TaskStatus true "The status object"
+// @Success 201 {object} models.TaskStatus "The created status."
+// @Failure 400 {object} web.HTTPError "Invalid status object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/statuses [put]
+func (ts *TaskStatus) Create(s *xorm.Session, a web.Auth) (err error) {
+	ts.ID = 0
+	if err := ts.validate(s); err != nil {
+		return err
+	}
+
+	ts.CreatedBy, err = GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return
+	}
+	ts.CreatedByID = ts.CreatedBy.ID
+
+	_, err = s.Insert(ts)
+	if err != nil {
+		return
+	}
+
+	ts.Position = calculateDefaultPosition(ts.ID, ts.Position)
+	_, err = s.Where("id = ?", ts.ID).Cols("position").Update(ts)
+	if err != nil {
+		return
+	}
+
+	return ts.saveTransitions(s)
+}
+
+// ReadOne returns a single status
+// @Summary Get one task status
+// @Description Returns a single workflow status of a list.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Param statusID path int true "Status Id"
+// @Success 200 {object} models.TaskStatus "The status."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 404 {object} web.HTTPError "The status does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/statuses/{statusID} [get]
+func (ts *TaskStatus) ReadOne(s *xorm.Session, a web.Auth) (err error) {
+	status, err := getTaskStatusByID(s, ts.ID)
+	if err != nil {
+		return err
+	}
+	*ts = *status
+
+	err = addTransitionsToStatuses(s, []*TaskStatus{ts})
+	if err != nil {
+		return err
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, []int64{ts.CreatedByID})
+	if err != nil {
+		return err
+	}
+	ts.CreatedBy = users[ts.CreatedByID]
+	return nil
+}
+
+// ReadAll returns all statuses of a list
+// @Summary Get all task statuses of a list
+// @Description Returns all workflow statuses of a list, sorted by their position.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Success 200 {array} models.TaskStatus "The statuses."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the list."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/statuses [get]
+func (ts *TaskStatus) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	l := &List{ID: ts.ListID}
+	can, _, err := l.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	statuses := []*TaskStatus{}
+	err = s.
+		Where("list_id = ?", ts.ListID).
+		OrderBy("position asc, id asc").
+		Find(&statuses)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	err = addTransitionsToStatuses(s, statuses)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	userIDs := make([]int64, 0, len(statuses))
+	for _, status := range statuses {
+		userIDs = append(userIDs, status.CreatedByID)
+	}
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, status := range statuses {
+		status.CreatedBy = users[status.CreatedByID]
+	}
+
+	return statuses, len(statuses), int64(len(statuses)), nil
+}
+
+// Update changes an existing status
+// @Summary Update a task status
+// @Description Updates the title, caldav status, bucket, position and allowed transitions of a workflow status. Tasks which already have the status keep it.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Param statusID path int true "Status Id"
+// @Param status body models.TaskStatus true "The status object"
+// @Success 200 {object} models.TaskStatus "The updated status."
+// @Failure 400 {object} web.HTTPError "Invalid status object provided."
+// @Failure 404 {object} web.HTTPError "The status does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/statuses/{statusID} [post]
+func (ts *TaskStatus) Update(s *xorm.Session, a web.Auth) (err error) {
+	if err := ts.validate(s); err != nil {
+		return err
+	}
+
+	_, err = s.
+		Where("id = ?", ts.ID).
+		Cols("title", "caldav_status", "bucket_id", "position").
+		Update(ts)
+	if err != nil {
+		return
+	}
+
+	return ts.saveTransitions(s)
+}
+
+// Delete removes a status
+// @Summary Delete a task status
+// @Description Deletes a workflow status. All tasks which had it will have no status afterwards.
+// @tags task
+// @Produce json
+// @Security JWTKeyAuth
+// @Param listID path int true "List Id"
+// @Param statusID path int true "Status Id"
+// @Success 200 {object} models.Message "Successfully deleted."
+// @Failure 404 {object} web.HTTPError "The status does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /lists/{listID}/statuses/{statusID} [delete]
+func (ts *TaskStatus) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.Where("id = ?", ts.ID).Delete(&TaskStatus{})
+	if err != nil {
+		return
+	}
+
+	_, err = s.
+		Where(builder.Or(builder.Eq{"from_status_id": ts.ID}, builder.Eq{"to_status_id": ts.ID})).
+		Delete(&TaskStatusTransition{})
+	if err != nil {
+		return
+	}
+
+	_, err = s.
+		Where("status_id = ?", ts.ID).
+		Cols("status_id").
+		Update(&Task{StatusID: 0})
+	return
+}
+
+// deleteTaskStatusesForList removes all statuses of a list.
+func deleteTaskStatusesForList(s *xorm.Session, listID int64) (err error) {
+	statusIDs := builder.Select("id").From("task_statuses").Where(builder.Eq{"list_id": listID})
+	_, err = s.In("from_status_id", statusIDs).Delete(&TaskStatusTransition{})
+	if err != nil {
+		return
+	}
+
+	_, err = s.Where("list_id = ?", listID).Delete(&TaskStatus{})
+	return
+}
+
+// getTaskStatusForBucket returns the status which is mapped to a bucket or nil if there is none.
+func getTaskStatusForBucket(s *xorm.Session, bucketID int64) (status *TaskStatus, err error) {
+	status = &TaskStatus{}
+	exists, err := s.
+		Where("bucket_id = ?", bucketID).
+		OrderBy("position asc, id asc").
+		Get(status)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return status, nil
+}
+
+// getTaskStatusIDForCaldavStatus returns the status of a list which matches a caldav status. If the current status of
+// the task already matches it, the task keeps it.
+func getTaskStatusIDForCaldavStatus(s *xorm.Session, listID, currentStatusID int64, caldavStatus TaskCaldavStatus) (int64, error) {
+	statuses := []*TaskStatus{}
+	err := s.
+		Where("list_id = ? AND caldav_status = ?", listID, caldavStatus).
+		OrderBy("position asc, id asc").
+		Find(&statuses)
+	if err != nil || len(statuses) == 0 {
+		return 0, err
+	}
+
+	for _, status := range statuses {
+		if status.ID == currentStatusID {
+			return currentStatusID, nil
+		}
+	}
+	return statuses[0].ID, nil
+}
+
+// checkTaskStatusTransition checks if a task can be moved from one status to another. A toStatusID of 0 means
+// the status is removed, which is only possible if the status allows all transitions. Otherwise, a task could be
+// moved to any status by removing its status first.
+func checkTaskStatusTransition(s *xorm.Session, taskID, fromStatusID, toStatusID int64) error {
+	if fromStatusID == 0 || fromStatusID == toStatusID {
+		return nil
+	}
+
+	transitions := []*TaskStatusTransition{}
+	err := s.Where("from_status_id = ?", fromStatusID).Find(&transitions)
+	if err != nil || len(transitions) == 0 {
+		return err
+	}
+
+	for _, transition := range transitions {
+		if transition.ToStatusID == toStatusID {
+			return nil
+		}
+	}
+
+	return &ErrTaskStatusTransitionNotAllowed{
+		TaskID:       taskID,
+		FromStatusID: fromStatusID,
+		ToStatusID:   toStatusID,
+	}
+}
+
+// setTaskStatus checks the status a task should get and moves the task to the bucket mapped to it.
+// originalTask is nil for new tasks.
+func setTaskStatus(s *xorm.Session, task *Task, originalTask *Task) (err error) {
+	var fromStatusID int64
+	if originalTask != nil {
+		fromStatusID = originalTask.StatusID
+	}
+
+	if task.StatusID == 0 && task.CaldavStatus != "" {
+		task.StatusID, err = getTaskStatusIDForCaldavStatus(s, task.ListID, fromStatusID, task.CaldavStatus)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Statuses belong to a list, a task moved to another list can't keep its status
+	if originalTask != nil && task.ListID != originalTask.ListID && task.StatusID == fromStatusID {
+		task.StatusID = 0
+		return nil
+	}
+
+	if task.StatusID == 0 {
+		return checkTaskStatusTransition(s, task.ID, fromStatusID, 0)
+	}
+
+	status, err := getTaskStatusByID(s, task.StatusID)
+	if err != nil {
+		return err
+	}
+	if status.ListID != task.ListID {
+		return &ErrTaskStatusDoesNotBelongToList{StatusID: status.ID, ListID: task.ListID}
+	}
+
+	err = checkTaskStatusTransition(s, task.ID, fromStatusID, status.ID)
+	if err != nil {
+		return err
+	}
+
+	if status.BucketID != 0 && (status.ID != fromStatusID || task.BucketID == 0) {
+		task.BucketID = status.BucketID
+	}
+
+	return nil
+}
+
+// setTaskStatusFromBucket gives a task the status which is mapped to the bucket it was moved to, unless its status
+// was changed explicitly.
+func setTaskStatusFromBucket(s *xorm.Session, task *Task, originalTask *Task) (err error) {
+	if originalTask != nil && (task.StatusID != originalTask.StatusID || task.BucketID == originalTask.BucketID) {
+		return nil
+	}
+	if originalTask == nil && task.StatusID != 0 {
+		return nil
+	}
+
+	status, err := getTaskStatusForBucket(s, task.BucketID)
+	if err != nil || status == nil || status.ID == task.StatusID {
+		return err
+	}
+
+	err = checkTaskStatusTransition(s, task.ID, task.StatusID, status.ID)
+	if err != nil {
+		return err
+	}
+
+	task.StatusID = status.ID
+	return nil
+}
+
+func addStatusesToTasks(s *xorm.Session, taskMap map[int64]*Task) (err error) {
+	statusIDs := []int64{}
+	for _, t := range taskMap {
+		if t.StatusID != 0 {
+			statusIDs = append(statusIDs, t.StatusID)
+		}
+	}
+	if len(statusIDs) == 0 {
+		return nil
+	}
+
+	statuses := make(map[int64]*TaskStatus, len(statusIDs))
+	err = s.In("id", statusIDs).Find(&statuses)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range taskMap {
+		t.Status = statuses[t.StatusID]
+	}
+	return nil
+}
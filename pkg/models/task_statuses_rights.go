@@ -0,0 +1,63 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if a user can add a status to a list
+func (ts *TaskStatus) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	l := &List{ID: ts.ListID}
+	return l.CanUpdate(s, a)
+}
+
+// CanRead checks if a user can see a status
+func (ts *TaskStatus) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if err := ts.checkList(s); err != nil {
+		return false, 0, err
+	}
+	l := &List{ID: ts.ListID}
+	return l.CanRead(s, a)
+}
+
+// CanUpdate checks if a user can change a status
+func (ts *TaskStatus) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	if err := ts.checkList(s); err != nil {
+		return false, err
+	}
+	l := &List{ID: ts.ListID}
+	return l.CanUpdate(s, a)
+}
+
+// CanDelete checks if a user can delete a status
+func (ts *TaskStatus) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return ts.CanUpdate(s, a)
+}
+
+// checkList makes sure the status belongs to the list from the path
+func (ts *TaskStatus) checkList(s *xorm.Session) error {
+	status, err := getTaskStatusByID(s, ts.ID)
+	if err != nil {
+		return err
+	}
+	if status.ListID != ts.ListID {
+		return &ErrTaskStatusDoesNotExist{StatusID: ts.ID}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskStatus_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ts := &TaskStatus{
+			ListID:             1,
+			Title:              "Waiting",
+			AllowedTransitions: []int64{1, 4, 1},
+		}
+		err := ts.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, TaskCaldavStatusNeedsAction, ts.CaldavStatus)
+		assert.Equal(t, []int64{1, 4}, ts.AllowedTransitions)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "task_statuses", map[string]interface{}{
+			"id":            ts.ID,
+			"list_id":       1,
+			"title":         "Waiting",
+			"caldav_status": "NEEDS-ACTION",
+		}, false)
+		db.AssertExists(t, "task_status_transitions", map[string]interface{}{
+			"from_status_id": ts.ID,
+			"to_status_id":   4,
+		}, false)
+	})
+	t.Run("invalid caldav status", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ts := &TaskStatus{ListID: 1, Title: "Waiting", CaldavStatus: "SOMEDAY"}
+		err := ts.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskStatus(err))
+	})
+	t.Run("bucket of another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ts := &TaskStatus{ListID: 1, Title: "Waiting", BucketID: 4}
+		err := ts.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskStatus(err))
+	})
+	t.Run("transition to a status of another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ts := &TaskStatus{ListID: 1, Title: "Waiting", AllowedTransitions: []int64{5}}
+		err := ts.Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTaskStatus(err))
+	})
+}
+
+func TestTaskStatus_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	ts := &TaskStatus{ListID: 1}
+	result, _, _, err := ts.ReadAll(s, &user.User{ID: 1}, "", 0, 0)
+	assert.NoError(t, err)
+	statuses := result.([]*TaskStatus)
+	assert.Len(t, statuses, 4)
+	assert.Equal(t, "Backlog", statuses[0].Title)
+	assert.Equal(t, []int64{2, 4}, statuses[0].AllowedTransitions)
+	assert.Equal(t, []int64{}, statuses[2].AllowedTransitions)
+	assert.Equal(t, int64(1), statuses[0].CreatedBy.ID)
+
+	t.Run("no access", func(t *testing.T) {
+		ts := &TaskStatus{ListID: 1}
+		_, _, _, err := ts.ReadAll(s, &user.User{ID: 2}, "", 0, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestTaskStatus_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := s.ID(1).Cols("status_id").Update(&Task{StatusID: 4})
+	assert.NoError(t, err)
+
+	ts := &TaskStatus{ID: 4, ListID: 1}
+	can, err := ts.CanDelete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, can)
+	err = ts.Delete(s, &user.User{ID: 1})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "task_statuses", map[string]interface{}{"id": 4})
+	db.AssertMissing(t, "task_status_transitions", map[string]interface{}{"to_status_id": 4})
+	db.AssertMissing(t, "task_status_transitions", map[string]interface{}{"from_status_id": 4})
+	db.AssertExists(t, "tasks", map[string]interface{}{"id": 1, "status_id": 0}, false)
+}
+
+func TestTaskStatus_Rights(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	// Status 5 belongs to list 2
+	ts := &TaskStatus{ID: 5, ListID: 1}
+	_, err := ts.CanUpdate(s, &user.User{ID: 1})
+	assert.Error(t, err)
+	assert.True(t, IsErrTaskStatusDoesNotExist(err))
+}
+
+func TestTask_Status(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("set status without bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1, Title: "task #1", ListID: 1, StatusID: 4}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), task.BucketID)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{"id": 1, "status_id": 4, "bucket_id": 1}, false)
+	})
+	t.Run("status moves the task to its bucket", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1, Title: "task #1", ListID: 1, StatusID: 3}
+		err := task.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), task.BucketID)
+		assert.True(t, task.Done)
+	})
+	t.Run("transition not allowed", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(1).Cols("status_id").Update(&Task{StatusID: 4})
+		assert.NoError(t, err)
+
+		// Blocked tasks can only go back to the backlog
+		task := &Task{ID: 1, Title: "task #1", ListID: 1, StatusID: 3}
+		err = task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskStatusTransitionNotAllowed(err))
+
+		task = &Task{ID: 1, Title: "task #1", ListID: 1, StatusID: 1}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+	})
+	t.Run("removing the status", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(1).Cols("status_id").Update(&Task{StatusID: 4})
+		assert.NoError(t, err)
+
+		// Otherwise it would be possible to go from blocked to done by removing the status in between
+		task := &Task{ID: 1, Title: "task #1", ListID: 1}
+		err = task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskStatusTransitionNotAllowed(err))
+
+		// Done allows all transitions
+		_, err = s.ID(1).Cols("status_id").Update(&Task{StatusID: 3})
+		assert.NoError(t, err)
+		task = &Task{ID: 1, Title: "task #1", ListID: 1}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), task.StatusID)
+	})
+	t.Run("status of another list", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{ID: 1, Title: "task #1", ListID: 1, StatusID: 5}
+		err := task.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskStatusDoesNotBelongToList(err))
+	})
+	t.Run("moving on kanban sets the status", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: 3}
+		err := m.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), m.Task.StatusID)
+	})
+	t.Run("moving on kanban checks the transition", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.ID(1).Cols("status_id").Update(&Task{StatusID: 4})
+		assert.NoError(t, err)
+
+		m := &KanbanTaskMove{TaskID: 1, BucketID: 3}
+		err = m.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTaskStatusTransitionNotAllowed(err))
+	})
+	t.Run("caldav status", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		task := &Task{Title: "Lorem", ListID: 1, CaldavStatus: TaskCaldavStatusNeedsAction}
+		err := task.Create(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), task.StatusID)
+
+		// A task which is blocked keeps its status since it matches the caldav status
+		_, err = s.ID(task.ID).Cols("status_id").Update(&Task{StatusID: 4})
+		assert.NoError(t, err)
+		task = &Task{ID: task.ID, Title: "Lorem", ListID: 1, CaldavStatus: TaskCaldavStatusInProcess}
+		err = task.Update(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), task.StatusID)
+	})
+	t.Run("filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.In("id", []int64{1, 2}).Cols("status_id").Update(&Task{StatusID: 4})
+		assert.NoError(t, err)
+
+		tc := &TaskCollection{
+			ListID:      1,
+			FilterBy:    []string{"status"},
+			FilterValue: []string{"4"},
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		assert.NoError(t, err)
+		tasks := result.([]*Task)
+		assert.Len(t, tasks, 2)
+		for _, task := range tasks {
+			assert.Equal(t, int64(4), task.Status.ID)
+		}
+	})
+}
@@ -27,6 +27,7 @@ import (
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/files"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
@@ -63,6 +64,8 @@ type Task struct {
 	DueDate time.Time `xorm:"DATETIME INDEX null 'due_date'" json:"due_date"`
 	// An array of datetimes when the user wants to be reminded of the task.
 	Reminders []time.Time `xorm:"-" json:"reminder_dates"`
+	// The reminders the current user snoozed for this task. These are only visible to the user who snoozed them.
+	SnoozedReminders []time.Time `xorm:"-" json:"snoozed_reminder_dates"`
 	// The list this task belongs to.
 	ListID int64 `xorm:"bigint INDEX not null" json:"list_id" param:"list"`
 	// An amount in seconds this task repeats itself. If this is set, when marking the task as done, it will mark itself as "undone" and then increase all remindes and the due date by its amount.
@@ -113,8 +116,21 @@ type Task struct {
 	// A timestamp when this task was last updated. You cannot change this value.
 	Updated time.Time `xorm:"updated not null" json:"updated"`
 
+	// A timestamp when this task was moved to the trash. Zero if it is not in the trash.
+	Deleted time.Time `xorm:"deleted null INDEX" json:"-"`
+	// The user who moved this task to the trash.
+	DeletedByID int64 `xorm:"bigint null" json:"-"`
+	// Whether this task was moved to the trash together with its list.
+	DeletedWithParent bool `xorm:"null" json:"-"`
+
 	// BucketID is the ID of the kanban bucket this task belongs to.
 	BucketID int64 `xorm:"bigint null" json:"bucket_id"`
+	// The id of the workflow status of this task. Statuses are configured per list.
+	StatusID int64 `xorm:"bigint null INDEX" json:"status_id"`
+	// The workflow status of this task.
+	Status *TaskStatus `xorm:"-" json:"status"`
+	// The status of the task in caldav. Only used to find the matching workflow status when a task is changed via caldav.
+	CaldavStatus TaskCaldavStatus `xorm:"-" json:"-"`
 
 	// The position of the task - any task list can be sorted as usual by this parameter.
 	// When accessing tasks via kanban buckets, this is primarily used to sort them based on a range
@@ -328,6 +344,12 @@ func getRawTasksForLists(s *xorm.Session, lists []*List, a web.Auth, opts *taskO
 	labelFilters := []builder.Cond{}
 	namespaceFilters := []builder.Cond{}
 
+	// Snoozed reminders belong to the user who snoozed them and must not be matched for anyone else
+	var reminderUserCond builder.Cond = builder.Eq{"user_id": 0}
+	if _, is := a.(*LinkSharing); !is {
+		reminderUserCond = builder.In("user_id", 0, a.GetID())
+	}
+
 	var filters = make([]builder.Cond, 0, len(opts.filters))
 	// To still find tasks with nil values, we exclude 0s when comparing with >/< values.
 	for _, f := range opts.filters {
@@ -337,7 +359,7 @@ func getRawTasksForLists(s *xorm.Session, lists []*List, a web.Auth, opts *taskO
 			if err != nil {
 				return nil, 0, 0, err
 			}
-			reminderFilters = append(reminderFilters, filter)
+			reminderFilters = append(reminderFilters, builder.And(filter, reminderUserCond))
 			continue
 		}
 
@@ -364,6 +386,10 @@ func getRawTasksForLists(s *xorm.Session, lists []*List, a web.Auth, opts *taskO
 			continue
 		}
 
+		if f.field == "status" {
+			f.field = taskPropertyStatusID
+		}
+
 		if f.field == "namespace" || f.field == "namespace_id" {
 			f.field = "namespace_id"
 			filter, err := getFilterCond(f, opts.filterIncludeNulls)
@@ -580,7 +606,9 @@ func GetTasksByUIDs(s *xorm.Session, uids []string, a web.Auth) (tasks []*Task,
 
 func getRemindersForTasks(s *xorm.Session, taskIDs []int64) (reminders []*TaskReminder, err error) {
 	reminders = []*TaskReminder{}
-	err = s.In("task_id", taskIDs).Find(&reminders)
+	err = s.In("task_id", taskIDs).
+		And("user_id = 0").
+		Find(&reminders)
 	return
 }
 
@@ -740,6 +768,11 @@ func addMoreInfoToTasks(s *xorm.Session, taskMap map[int64]*Task, a web.Auth) (e
 		return
 	}
 
+	err = addStatusesToTasks(s, taskMap)
+	if err != nil {
+		return
+	}
+
 	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
 	if err != nil {
 		return
@@ -755,6 +788,14 @@ func addMoreInfoToTasks(s *xorm.Session, taskMap map[int64]*Task, a web.Auth) (e
 		return err
 	}
 
+	snoozedReminders := make(map[int64][]time.Time)
+	if _, is := a.(*user.User); is {
+		snoozedReminders, err = getSnoozedRemindersForUser(s, taskIDs, a.GetID())
+		if err != nil {
+			return err
+		}
+	}
+
 	// Get all identifiers
 	lists, err := GetListsByIDs(s, listIDs)
 	if err != nil {
@@ -769,6 +810,7 @@ func addMoreInfoToTasks(s *xorm.Session, taskMap map[int64]*Task, a web.Auth) (e
 
 		// Add the reminders
 		task.Reminders = taskReminders[task.ID]
+		task.SnoozedReminders = snoozedReminders[task.ID]
 
 		// Prepare the subtasks
 		task.RelatedTasks = make(RelatedTaskMap)
@@ -795,22 +837,6 @@ func checkBucketAndTaskBelongToSameList(fullTask *Task, bucket *Bucket) (err err
 	return
 }
 
-// Checks if adding a new task would exceed the bucket limit
-func checkBucketLimit(s *xorm.Session, t *Task, bucket *Bucket) (err error) {
-	if bucket.Limit > 0 {
-		taskCount, err := s.
-			Where("bucket_id = ?", bucket.ID).
-			Count(&Task{})
-		if err != nil {
-			return err
-		}
-		if taskCount >= bucket.Limit {
-			return ErrBucketLimitExceeded{TaskID: t.ID, BucketID: bucket.ID, Limit: bucket.Limit}
-		}
-	}
-	return nil
-}
-
 // Contains all the task logic to figure out what bucket to use for this task.
 func setTaskBucket(s *xorm.Session, task *Task, originalTask *Task, doCheckBucketLimit bool) (err error) {
 	// Make sure we have a bucket
@@ -825,7 +851,7 @@ func setTaskBucket(s *xorm.Session, task *Task, originalTask *Task, doCheckBucke
 		}
 	}
 
-	if task.BucketID == 0 || (originalTask != nil && task.ListID != 0 && originalTask.ListID != task.ListID) {
+	if task.BucketID == 0 || (originalTask != nil && task.ListID != 0 && originalTask.ListID != task.ListID && task.BucketID == originalTask.BucketID) {
 		bucket, err = getDefaultBucket(s, task.ListID)
 		if err != nil {
 			return err
@@ -913,12 +939,22 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err
 		t.UID = uuid.NewString()
 	}
 
+	err = setTaskStatus(s, t, nil)
+	if err != nil {
+		return
+	}
+
 	// Get the default bucket and move the task there
 	err = setTaskBucket(s, t, nil, true)
 	if err != nil {
 		return
 	}
 
+	err = setTaskStatusFromBucket(s, t, nil)
+	if err != nil {
+		return
+	}
+
 	// Get the index for this task
 	latestTask := &Task{}
 	_, err = s.Where("list_id = ?", t.ListID).OrderBy("id desc").Get(latestTask)
@@ -926,7 +962,10 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err
 		return err
 	}
 
-	t.Index = latestTask.Index + 1
+	t.Index, err = getNextTaskIndex(s, t.ListID)
+	if err != nil {
+		return err
+	}
 	// If no position was supplied, set a default one
 	t.Position = calculateDefaultPosition(latestTask.ID+1, t.Position)
 	t.KanbanPosition = calculateDefaultPosition(latestTask.ID+1, t.KanbanPosition)
@@ -934,6 +973,11 @@ func createTask(s *xorm.Session, t *Task, a web.Auth, updateAssignees bool) (err
 		return err
 	}
 
+	err = recordBucketTransition(s, t.ID, 0, t.BucketID)
+	if err != nil {
+		return err
+	}
+
 	t.CreatedBy = createdBy
 
 	// Update the assignees
@@ -991,6 +1035,8 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if err != nil {
 		return
 	}
+	// Keep a copy of the old values around to tell subscribers what changed
+	oldTask := ot
 
 	if t.ListID == 0 {
 		t.ListID = ot.ListID
@@ -1010,10 +1056,24 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	// When a repeating task is marked as done, we update all deadlines and reminders and set it as undone
 	updateDone(&ot, t)
 
+	if err := setTaskStatus(s, t, &ot); err != nil {
+		return err
+	}
+
 	if err := setTaskBucket(s, t, &ot, t.BucketID != ot.BucketID); err != nil {
 		return err
 	}
 
+	if err := setTaskStatusFromBucket(s, t, &ot); err != nil {
+		return err
+	}
+
+	if t.Done && !ot.Done {
+		if err := checkTaskIsNotBlocked(s, t.ID); err != nil {
+			return err
+		}
+	}
+
 	// Update the assignees
 	if err := ot.updateTaskAssignees(s, t.Assignees, a); err != nil {
 		return err
@@ -1039,6 +1099,7 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 		"percent_done",
 		"list_id",
 		"bucket_id",
+		"status_id",
 		"position",
 		"repeat_mode",
 		"kanban_position",
@@ -1047,13 +1108,9 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 
 	// If the task is being moved between lists, make sure to move the bucket + index as well
 	if t.ListID != 0 && ot.ListID != t.ListID {
-		latestTask := &Task{}
-		_, err = s.Where("list_id = ?", t.ListID).OrderBy("id desc").Get(latestTask)
-		if err != nil {
+		if err := moveTaskToList(s, a, t, &ot); err != nil {
 			return err
 		}
-
-		t.Index = latestTask.Index + 1
 		colsToUpdate = append(colsToUpdate, "index")
 	}
 
@@ -1161,6 +1218,10 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if t.KanbanPosition == 0 {
 		ot.KanbanPosition = 0
 	}
+	// Status
+	if t.StatusID == 0 {
+		ot.StatusID = 0
+	}
 	// Repeat from current date
 	if t.RepeatMode == TaskRepeatModeDefault {
 		ot.RepeatMode = TaskRepeatModeDefault
@@ -1181,6 +1242,21 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 	if err != nil {
 		return err
 	}
+
+	err = recordBucketTransition(s, t.ID, oldTask.BucketID, t.BucketID)
+	if err != nil {
+		return err
+	}
+
+	err = shiftDependentTasks(s, a, t.ID, getDependencyShift(&oldTask, t))
+	if err != nil {
+		return err
+	}
+
+	err = applyBucketRules(s, a, t, oldTask.BucketID, t.BucketID)
+	if err != nil {
+		return err
+	}
 	// Get the task updated timestamp in a new struct - if we'd just try to put it into t which we already have, it
 	// would still contain the old updated date.
 	nt := &Task{}
@@ -1192,8 +1268,9 @@ func (t *Task) Update(s *xorm.Session, a web.Auth) (err error) {
 
 	doer, _ := user.GetFromAuth(a)
 	err = events.Dispatch(&TaskUpdatedEvent{
-		Task: t,
-		Doer: doer,
+		Task:    t,
+		OldTask: &oldTask,
+		Doer:    doer,
 	})
 	if err != nil {
 		return err
@@ -1386,7 +1463,7 @@ func updateDone(oldTask *Task, newTask *Task) {
 func (t *Task) updateReminders(s *xorm.Session, reminders []time.Time) (err error) {
 
 	_, err = s.
-		Where("task_id = ?", t.ID).
+		Where("task_id = ? AND user_id = 0", t.ID).
 		Delete(&TaskReminder{})
 	if err != nil {
 		return
@@ -1411,7 +1488,7 @@ func (t *Task) updateReminders(s *xorm.Session, reminders []time.Time) (err erro
 
 // Delete implements the delete method for listTask
 // @Summary Delete a task
-// @Description Deletes a task from a list. This does not mean "mark it done".
+// @Description Moves a task to the trash. This does not mean "mark it done". The task can be restored from the trash until it is purged after the configured retention period.
 // @tags task
 // @Produce json
 // @Security JWTKeyAuth
@@ -1423,7 +1500,32 @@ func (t *Task) updateReminders(s *xorm.Session, reminders []time.Time) (err erro
 // @Router /tasks/{id} [delete]
 func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 
-	if _, err = s.ID(t.ID).Delete(Task{}); err != nil {
+	doer, err := GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+
+	err = trashTasks(s, builder.Eq{"id": t.ID}, doer.ID, false)
+	if err != nil {
+		return err
+	}
+
+	err = events.Dispatch(&TaskDeletedEvent{
+		Task: t,
+		Doer: doer,
+	})
+	if err != nil {
+		return
+	}
+
+	err = updateListLastUpdated(s, &List{ID: t.ListID})
+	return
+}
+
+// purge permanently removes a task and everything associated with it, no matter if it is in the trash or not.
+func (t *Task) purge(s *xorm.Session) (err error) {
+
+	if _, err = s.Unscoped().ID(t.ID).Delete(&Task{}); err != nil {
 		return err
 	}
 
@@ -1433,7 +1535,7 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 	}
 
 	// Delete Favorites
-	err = removeFromFavorite(s, t.ID, a, FavoriteKindTask)
+	_, err = s.Where("entity_id = ? AND kind = ?", t.ID, FavoriteKindTask).Delete(&Favorite{})
 	if err != nil {
 		return
 	}
@@ -1450,9 +1552,14 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return err
 	}
 	for _, attachment := range attachments {
-		// Using the attachment delete method here because that takes care of removing all files properly
-		err = attachment.Delete(s, a)
-		if err != nil && !IsErrTaskAttachmentDoesNotExist(err) {
+		// The attachment delete method can't be used here because it needs the task, which is gone at this point.
+		_, err = s.Where("id = ?", attachment.ID).Delete(&TaskAttachment{})
+		if err != nil {
+			return err
+		}
+		f := &files.File{ID: attachment.FileID}
+		err = f.Delete()
+		if err != nil && !files.IsErrFileDoesNotExist(err) {
 			return err
 		}
 	}
@@ -1475,16 +1582,25 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
-	doer, _ := user.GetFromAuth(a)
-	err = events.Dispatch(&TaskDeletedEvent{
-		Task: t,
-		Doer: doer,
-	})
+	// Delete all buckets on namespace and saved filter boards
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskBucket{})
 	if err != nil {
 		return
 	}
 
-	err = updateListLastUpdated(s, &List{ID: t.ListID})
+	// Delete the history
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskHistory{})
+	if err != nil {
+		return
+	}
+
+	// Delete the redirects from old identifiers
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskIdentifierRedirect{})
+	if err != nil {
+		return
+	}
+
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskBucketEntry{})
 	return
 }
 
@@ -23,19 +23,19 @@ import (
 
 // CanDelete checks if the user can delete an task
 func (t *Task) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
-	return t.canDoTask(s, a)
+	return t.canDoTask(s, a, PermissionDeleteTask)
 }
 
 // CanUpdate determines if a user has the right to update a list task
 func (t *Task) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
-	return t.canDoTask(s, a)
+	return t.canDoTask(s, a, PermissionEditTask)
 }
 
 // CanCreate determines if a user has the right to create a list task
 func (t *Task) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
-	// A user can do a task if he has write acces to its list
+	// A user can create a task if they have write access to its list or a role which allows it
 	l := &List{ID: t.ListID}
-	return l.CanWrite(s, a)
+	return l.checkPermission(s, a, PermissionCreateTask)
 }
 
 // CanRead determines if a user can read a task
@@ -53,11 +53,11 @@ func (t *Task) CanRead(s *xorm.Session, a web.Auth) (canRead bool, maxRight int,
 
 // CanWrite checks if a user has write access to a task
 func (t *Task) CanWrite(s *xorm.Session, a web.Auth) (canWrite bool, err error) {
-	return t.canDoTask(s, a)
+	return t.canDoTask(s, a, PermissionEditTask)
 }
 
 // Helper function to check if a user can do stuff on a list task
-func (t *Task) canDoTask(s *xorm.Session, a web.Auth) (bool, error) {
+func (t *Task) canDoTask(s *xorm.Session, a web.Auth, p Permission) (bool, error) {
 	// Get the task
 	ot, err := GetTaskByIDSimple(s, t.ID)
 	if err != nil {
@@ -67,7 +67,7 @@ func (t *Task) canDoTask(s *xorm.Session, a web.Auth) (bool, error) {
 	// Check if we're moving the task into a different list to check if the user has sufficient rights for that on the new list
 	if t.ListID != 0 && t.ListID != ot.ListID {
 		newList := &List{ID: t.ListID}
-		can, err := newList.CanWrite(s, a)
+		can, err := newList.checkPermission(s, a, PermissionCreateTask)
 		if err != nil {
 			return false, err
 		}
@@ -76,7 +76,7 @@ func (t *Task) canDoTask(s *xorm.Session, a web.Auth) (bool, error) {
 		}
 	}
 
-	// A user can do a task if it has write acces to its list
+	// A user can do a task if it has write acces to its list or a role which allows it
 	l := &List{ID: ot.ListID}
-	return l.CanWrite(s, a)
+	return l.checkPermission(s, a, p)
 }
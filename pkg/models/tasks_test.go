@@ -340,9 +340,12 @@ func TestTask_Delete(t *testing.T) {
 		err = s.Commit()
 		assert.NoError(t, err)
 
-		db.AssertMissing(t, "tasks", map[string]interface{}{
-			"id": 1,
-		})
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":                  1,
+			"deleted_by_id":       1,
+			"deleted_with_parent": false,
+		}, false)
+		assertTrashed(t, "tasks", map[string]interface{}{"id": 1}, true)
 	})
 }
 
@@ -44,6 +44,9 @@ func (tm *TeamMember) Create(s *xorm.Session, a web.Auth) (err error) {
 	if err != nil {
 		return err
 	}
+	if team.isExternallyManaged() {
+		return ErrTeamIsExternallyManaged{TeamID: tm.TeamID}
+	}
 
 	// Check if the user exists
 	member, err := user2.GetUserByUsername(s, tm.Username)
@@ -90,6 +93,14 @@ func (tm *TeamMember) Create(s *xorm.Session, a web.Auth) (err error) {
 // @Router /teams/{id}/members/{userID} [delete]
 func (tm *TeamMember) Delete(s *xorm.Session, a web.Auth) (err error) {
 
+	team, err := GetTeamByID(s, tm.TeamID)
+	if err != nil {
+		return err
+	}
+	if team.isExternallyManaged() {
+		return ErrTeamIsExternallyManaged{TeamID: tm.TeamID}
+	}
+
 	total, err := s.Where("team_id = ?", tm.TeamID).Count(&TeamMember{})
 	if err != nil {
 		return
@@ -121,6 +132,14 @@ func (tm *TeamMember) Delete(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /teams/{id}/members/{userID}/admin [post]
 func (tm *TeamMember) Update(s *xorm.Session, a web.Auth) (err error) {
+	team, err := GetTeamByID(s, tm.TeamID)
+	if err != nil {
+		return err
+	}
+	if team.isExternallyManaged() {
+		return ErrTeamIsExternallyManaged{TeamID: tm.TeamID}
+	}
+
 	// Find the numeric user id
 	user, err := user2.GetUserByUsername(s, tm.Username)
 	if err != nil {
@@ -39,6 +39,12 @@ type Team struct {
 	Description string `xorm:"longtext null" json:"description"`
 	CreatedByID int64  `xorm:"bigint not null INDEX" json:"-"`
 
+	// The id of the group at an external identity provider this team is synced with. Teams with an external id are
+	// managed by that provider and cannot be changed in Vikunja.
+	ExternalID string `xorm:"varchar(250) null INDEX" json:"external_id"`
+	// The issuer of the identity provider which manages this team.
+	Issuer string `xorm:"text null" json:"-"`
+
 	// The user who created this team.
 	CreatedBy *user.User `xorm:"-" json:"created_by"`
 	// An array of all members in this team.
@@ -91,6 +97,10 @@ type TeamUser struct {
 	TeamID int64 `json:"-"`
 }
 
+func (t *Team) isExternallyManaged() bool {
+	return t.ExternalID != ""
+}
+
 // GetTeamByID gets a team by its ID
 func GetTeamByID(s *xorm.Session, id int64) (team *Team, err error) {
 	if id < 1 {
@@ -264,6 +274,9 @@ func (t *Team) Create(s *xorm.Session, a web.Auth) (err error) {
 
 	t.CreatedByID = doer.ID
 	t.CreatedBy = doer
+	// Only teams synced from an identity provider can be externally managed
+	t.ExternalID = ""
+	t.Issuer = ""
 
 	_, err = s.Insert(t)
 	if err != nil {
@@ -295,6 +308,14 @@ func (t *Team) Create(s *xorm.Session, a web.Auth) (err error) {
 // @Router /teams/{id} [delete]
 func (t *Team) Delete(s *xorm.Session, a web.Auth) (err error) {
 
+	team, err := GetTeamByID(s, t.ID)
+	if err != nil {
+		return
+	}
+	if team.isExternallyManaged() {
+		return ErrTeamIsExternallyManaged{TeamID: t.ID}
+	}
+
 	// Delete the team
 	_, err = s.ID(t.ID).Delete(&Team{})
 	if err != nil {
@@ -319,6 +340,12 @@ func (t *Team) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	// Delete pending invitations to the team
+	err = deleteShareInvitations(s, &ShareInvitation{TeamID: t.ID})
+	if err != nil {
+		return
+	}
+
 	return events.Dispatch(&TeamDeletedEvent{
 		Team: t,
 		Doer: a,
@@ -345,11 +372,16 @@ func (t *Team) Update(s *xorm.Session, a web.Auth) (err error) {
 	}
 
 	// Check if the team exists
-	_, err = GetTeamByID(s, t.ID)
+	existing, err := GetTeamByID(s, t.ID)
 	if err != nil {
 		return
 	}
+	if existing.isExternallyManaged() {
+		return ErrTeamIsExternallyManaged{TeamID: t.ID}
+	}
 
+	t.ExternalID = ""
+	t.Issuer = ""
 	_, err = s.ID(t.ID).Update(t)
 	if err != nil {
 		return
@@ -363,3 +395,71 @@ func (t *Team) Update(s *xorm.Session, a web.Auth) (err error) {
 
 	return
 }
+
+// SyncExternalTeams makes the user a member of exactly the teams which belong to the given external groups of an
+// identity provider. Teams which do not exist yet are created, memberships in teams of groups the user is no longer
+// part of are removed. Teams of other identity providers and local teams are not touched.
+func SyncExternalTeams(s *xorm.Session, u *user.User, issuer string, groups []string) (err error) {
+	teamIDs := make([]int64, 0, len(groups))
+
+	for _, group := range groups {
+		if group == "" {
+			continue
+		}
+
+		team := &Team{}
+		exists, err := s.
+			Where("issuer = ? AND external_id = ?", issuer, group).
+			Get(team)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			team = &Team{
+				Name:        group,
+				CreatedByID: u.ID,
+				ExternalID:  group,
+				Issuer:      issuer,
+			}
+			if _, err := s.Insert(team); err != nil {
+				return err
+			}
+
+			if err := events.Dispatch(&TeamCreatedEvent{Team: team, Doer: u}); err != nil {
+				return err
+			}
+		}
+
+		teamIDs = append(teamIDs, team.ID)
+
+		isMember, err := s.
+			Where("team_id = ? AND user_id = ?", team.ID, u.ID).
+			Exist(&TeamMember{})
+		if err != nil {
+			return err
+		}
+		if isMember {
+			continue
+		}
+
+		if _, err := s.Insert(&TeamMember{TeamID: team.ID, UserID: u.ID}); err != nil {
+			return err
+		}
+	}
+
+	// Remove the user from all teams of this provider they are not part of anymore
+	cond := builder.And(
+		builder.Eq{"user_id": u.ID},
+		builder.In("team_id", builder.
+			Select("id").
+			From("teams").
+			Where(builder.Eq{"issuer": issuer}.And(builder.Neq{"external_id": ""}))),
+	)
+	if len(teamIDs) > 0 {
+		cond = cond.And(builder.NotIn("team_id", teamIDs))
+	}
+
+	_, err = s.Where(cond).Delete(&TeamMember{})
+	return
+}
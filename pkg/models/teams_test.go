@@ -207,3 +207,83 @@ func TestIsErrInvalidRight(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, IsErrInvalidRight(err))
 }
+
+func TestSyncExternalTeams(t *testing.T) {
+	u := &user.User{ID: 1}
+	const issuer = "https://some.issuer"
+
+	t.Run("creates teams and memberships", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SyncExternalTeams(s, u, issuer, []string{"developers", "admins"})
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"name":        "developers",
+			"external_id": "developers",
+			"issuer":      issuer,
+		}, false)
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"name":        "admins",
+			"external_id": "admins",
+			"issuer":      issuer,
+		}, false)
+
+		count, err := s.
+			Join("INNER", "teams", "teams.id = team_members.team_id").
+			Where("team_members.user_id = ? AND teams.issuer = ?", u.ID, issuer).
+			Count(&TeamMember{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+	t.Run("removes memberships of groups the user left", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SyncExternalTeams(s, u, issuer, []string{"developers", "admins"})
+		assert.NoError(t, err)
+		err = SyncExternalTeams(s, u, issuer, []string{"developers"})
+		assert.NoError(t, err)
+
+		admins := &Team{}
+		_, err = s.Where("issuer = ? AND external_id = ?", issuer, "admins").Get(admins)
+		assert.NoError(t, err)
+		exists, err := s.Where("team_id = ? AND user_id = ?", admins.ID, u.ID).Exist(&TeamMember{})
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		// Local team memberships are not touched
+		exists, err = s.Where("team_id = ? AND user_id = ?", 1, u.ID).Exist(&TeamMember{})
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+	t.Run("external teams cannot be changed locally", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SyncExternalTeams(s, u, issuer, []string{"developers"})
+		assert.NoError(t, err)
+
+		team := &Team{}
+		_, err = s.Where("issuer = ? AND external_id = ?", issuer, "developers").Get(team)
+		assert.NoError(t, err)
+
+		err = (&Team{ID: team.ID, Name: "Renamed"}).Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTeamIsExternallyManaged(err))
+
+		err = (&Team{ID: team.ID}).Delete(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTeamIsExternallyManaged(err))
+
+		err = (&TeamMember{TeamID: team.ID, Username: "user2"}).Create(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTeamIsExternallyManaged(err))
+	})
+}
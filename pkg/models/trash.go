@@ -0,0 +1,732 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TrashItemKind is the kind of an item in the trash
+type TrashItemKind string
+
+// All kinds of items which can be in the trash
+const (
+	TrashItemKindTask      TrashItemKind = `task`
+	TrashItemKindList      TrashItemKind = `list`
+	TrashItemKindNamespace TrashItemKind = `namespace`
+)
+
+// TrashItem is a task, list or namespace which was moved to the trash. Items which were moved to the trash together
+// with their list or namespace are not trash items on their own, they are restored and purged with it.
+type TrashItem struct {
+	// The kind of the item. One of task, list or namespace.
+	Kind TrashItemKind `json:"kind" param:"kind"`
+	// The id of the task, list or namespace.
+	ID int64 `json:"id" param:"item"`
+	// The title of the task, list or namespace.
+	Title string `json:"title"`
+	// The id of the list of a task, the namespace of a list or the parent namespace of a namespace.
+	ParentID int64 `json:"parent_id"`
+
+	// A timestamp when the item was moved to the trash.
+	Deleted time.Time `json:"deleted"`
+	// The user who moved the item to the trash.
+	DeletedBy *user.User `json:"deleted_by"`
+	// A timestamp when the item will be removed from the trash for good. Zero if it is kept forever.
+	PurgeAt time.Time `json:"purge_at"`
+
+	// The restored task. Only returned after restoring a task.
+	Task *Task `json:"task,omitempty"`
+	// The restored list. Only returned after restoring a list.
+	List *List `json:"list,omitempty"`
+	// The restored namespace. Only returned after restoring a namespace.
+	Namespace *Namespace `json:"namespace,omitempty"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+// The columns changed when moving something to the trash or restoring it from there.
+var trashCols = []string{"deleted", "deleted_by_id", "deleted_with_parent"}
+
+// Marks all rows matching the condition which are not in the trash yet with the values of the bean.
+func trashRows(s *xorm.Session, cond builder.Cond, bean interface{}) (err error) {
+	_, err = s.
+		Unscoped().
+		Where(cond).
+		And("deleted IS NULL").
+		Cols(trashCols...).
+		NoAutoTime().
+		Update(bean)
+	return
+}
+
+// Resets the trash columns of all rows matching the condition. The deleted column is set to null explicitly since
+// xorm would store a zero time instead.
+func restoreRows(s *xorm.Session, cond builder.Cond, bean interface{}) (err error) {
+	_, err = s.
+		Unscoped().
+		Where(cond).
+		Cols("deleted_by_id", "deleted_with_parent").
+		SetExpr("deleted", "NULL").
+		NoAutoTime().
+		Update(bean)
+	return
+}
+
+func trashTasks(s *xorm.Session, cond builder.Cond, doerID int64, withParent bool) error {
+	return trashRows(s, cond, &Task{
+		Deleted:           time.Now(),
+		DeletedByID:       doerID,
+		DeletedWithParent: withParent,
+	})
+}
+
+// Moves a list to the trash together with all of its tasks. Tasks which are already in the trash stay separate
+// trash items.
+func trashList(s *xorm.Session, l *List, doerID int64, withParent bool) (err error) {
+	err = trashRows(s, builder.Eq{"id": l.ID}, &List{
+		Deleted:           time.Now(),
+		DeletedByID:       doerID,
+		DeletedWithParent: withParent,
+	})
+	if err != nil {
+		return
+	}
+
+	return trashTasks(s, builder.Eq{"list_id": l.ID}, doerID, true)
+}
+
+// Moves a namespace to the trash together with all of its sub namespaces and lists.
+func trashNamespace(s *xorm.Session, n *Namespace, doerID int64, withParent bool) (err error) {
+	children := []*Namespace{}
+	err = s.Where("parent_id = ?", n.ID).Find(&children)
+	if err != nil {
+		return
+	}
+
+	lists := []*List{}
+	err = s.Where("namespace_id = ?", n.ID).Find(&lists)
+	if err != nil {
+		return
+	}
+
+	err = trashRows(s, builder.Eq{"id": n.ID}, &Namespace{
+		Deleted:           time.Now(),
+		DeletedByID:       doerID,
+		DeletedWithParent: withParent,
+	})
+	if err != nil {
+		return
+	}
+
+	for _, child := range children {
+		err = trashNamespace(s, child, doerID, true)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, l := range lists {
+		err = trashList(s, l, doerID, true)
+		if err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+func restoreTask(s *xorm.Session, t *Task) (err error) {
+	err = restoreRows(s, builder.Eq{"id": t.ID}, &Task{})
+	if err != nil {
+		return
+	}
+
+	// The bucket of the task might have been deleted while the task was in the trash
+	_, err = getBucketByID(s, t.BucketID)
+	if err == nil || !IsErrBucketDoesNotExist(err) {
+		return
+	}
+
+	bucket, err := getDefaultBucket(s, t.ListID)
+	if err != nil {
+		return
+	}
+	t.BucketID = bucket.ID
+	_, err = s.
+		Where("id = ?", t.ID).
+		Cols("bucket_id").
+		NoAutoTime().
+		Update(t)
+	return
+}
+
+func restoreList(s *xorm.Session, l *List) (err error) {
+	err = restoreRows(s, builder.Eq{"id": l.ID}, &List{})
+	if err != nil {
+		return
+	}
+
+	// Another list might have taken the identifier while the list was in the trash
+	if l.Identifier != "" {
+		taken, err := s.
+			Where("identifier = ? AND id != ?", l.Identifier, l.ID).
+			Exist(&List{})
+		if err != nil {
+			return err
+		}
+		if taken {
+			l.Identifier = ""
+			_, err = s.
+				Where("id = ?", l.ID).
+				Cols("identifier").
+				NoAutoTime().
+				Update(l)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return restoreRows(s, builder.And(
+		builder.Eq{"list_id": l.ID},
+		builder.Eq{"deleted_with_parent": true},
+	), &Task{})
+}
+
+func restoreNamespace(s *xorm.Session, n *Namespace) (err error) {
+	err = restoreRows(s, builder.Eq{"id": n.ID}, &Namespace{})
+	if err != nil {
+		return
+	}
+
+	children := []*Namespace{}
+	err = s.
+		Unscoped().
+		Where("parent_id = ? AND deleted IS NOT NULL AND deleted_with_parent = ?", n.ID, true).
+		Find(&children)
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		err = restoreNamespace(s, child)
+		if err != nil {
+			return
+		}
+	}
+
+	lists := []*List{}
+	err = s.
+		Unscoped().
+		Where("namespace_id = ? AND deleted IS NOT NULL AND deleted_with_parent = ?", n.ID, true).
+		Find(&lists)
+	if err != nil {
+		return
+	}
+	for _, l := range lists {
+		err = restoreList(s, l)
+		if err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+// The condition for all items which were moved to the trash on their own.
+var trashItemCond = builder.And(
+	builder.NotNull{"deleted"},
+	builder.Eq{"deleted_with_parent": false},
+)
+
+// Loads the task, list or namespace of the trash item.
+func (ti *TrashItem) load(s *xorm.Session) (err error) {
+	var bean interface{}
+	switch ti.Kind {
+	case TrashItemKindTask:
+		ti.Task = &Task{}
+		bean = ti.Task
+	case TrashItemKindList:
+		ti.List = &List{}
+		bean = ti.List
+	case TrashItemKindNamespace:
+		ti.Namespace = &Namespace{}
+		bean = ti.Namespace
+	default:
+		return &ErrInvalidTrashItemKind{Kind: ti.Kind}
+	}
+
+	exists, err := s.
+		Unscoped().
+		Where(builder.And(trashItemCond, builder.Eq{"id": ti.ID})).
+		Get(bean)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &ErrTrashItemDoesNotExist{Kind: ti.Kind, ID: ti.ID}
+	}
+
+	ti.setFields()
+	return nil
+}
+
+// Sets the fields of the trash item from its loaded task, list or namespace.
+func (ti *TrashItem) setFields() {
+	switch ti.Kind {
+	case TrashItemKindTask:
+		ti.ID, ti.Title, ti.ParentID, ti.Deleted = ti.Task.ID, ti.Task.Title, ti.Task.ListID, ti.Task.Deleted
+	case TrashItemKindList:
+		ti.ID, ti.Title, ti.ParentID, ti.Deleted = ti.List.ID, ti.List.Title, ti.List.NamespaceID, ti.List.Deleted
+	case TrashItemKindNamespace:
+		ti.ID, ti.Title, ti.ParentID, ti.Deleted = ti.Namespace.ID, ti.Namespace.Title, ti.Namespace.ParentID, ti.Namespace.Deleted
+	}
+}
+
+// Checks if the user has the right to restore or purge a trash item. These are the same rights needed to delete the
+// item in the first place.
+func (ti *TrashItem) canManage(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	err := ti.load(s)
+	if err != nil {
+		return false, err
+	}
+
+	return ti.checkManageRights(s, a)
+}
+
+// Checks the rights of canManage for a trash item which was already loaded.
+func (ti *TrashItem) checkManageRights(s *xorm.Session, a web.Auth) (bool, error) {
+	switch ti.Kind {
+	case TrashItemKindTask:
+		l := &List{ID: ti.Task.ListID}
+		return l.CanWrite(s, a)
+	case TrashItemKindList:
+		if ti.List.isOwner(&user.User{ID: a.GetID()}) {
+			return true, nil
+		}
+		is, _, err := ti.List.checkRight(s, a, RightAdmin)
+		return is, err
+	case TrashItemKindNamespace:
+		if ti.Namespace.OwnerID == a.GetID() {
+			return true, nil
+		}
+		// Shares of the namespace itself or its parent namespaces still apply
+		ancestorIDs, err := getNamespaceAncestorIDs(s, ti.Namespace.ID)
+		if err != nil {
+			return false, err
+		}
+		is, _, err := checkInheritedNamespaceRight(s, a, append([]int64{ti.Namespace.ID}, ancestorIDs...), RightAdmin)
+		return is, err
+	}
+
+	return false, nil
+}
+
+// CanUpdate checks if the user can restore an item from the trash
+func (ti *TrashItem) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return ti.canManage(s, a)
+}
+
+// CanDelete checks if the user can remove an item from the trash for good
+func (ti *TrashItem) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return ti.canManage(s, a)
+}
+
+func getTrashPurgeTime(deleted time.Time) time.Time {
+	retention := config.ServiceTrashRetention.GetInt()
+	if retention <= 0 {
+		return time.Time{}
+	}
+	return deleted.Add(time.Hour * 24 * time.Duration(retention))
+}
+
+// Returns all candidates for the trash of the user with their task, list or namespace loaded. They still need to be
+// checked with checkManageRights.
+func getTrashItemCandidates(s *xorm.Session, u *user.User, search string) (items []*TrashItem, err error) {
+	items = []*TrashItem{}
+
+	cond := trashItemCond
+	if search != "" {
+		cond = builder.And(cond, db.ILIKE("title", search))
+	}
+
+	namespaceIDs, subNamespaceIDs, err := getNamespaceIDsForUser(s, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	namespaceIDs = append(namespaceIDs, subNamespaceIDs...)
+
+	namespaces := []*Namespace{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			cond,
+			builder.Or(
+				builder.Eq{"owner_id": u.ID},
+				builder.In("id", namespaceIDs),
+				builder.In("parent_id", namespaceIDs),
+			),
+		)).
+		Find(&namespaces)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range namespaces {
+		items = append(items, &TrashItem{Kind: TrashItemKindNamespace, Namespace: n})
+	}
+
+	lists := []*List{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			cond,
+			builder.Or(
+				builder.Eq{"owner_id": u.ID},
+				builder.In("namespace_id", namespaceIDs),
+				builder.In("id", builder.Select("list_id").From("users_lists").Where(builder.Eq{"user_id": u.ID})),
+				builder.In("id", builder.
+					Select("team_lists.list_id").
+					From("team_lists").
+					Join("INNER", "team_members", "team_members.team_id = team_lists.team_id").
+					Where(builder.Eq{"team_members.user_id": u.ID}),
+				),
+			),
+		)).
+		Find(&lists)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lists {
+		items = append(items, &TrashItem{Kind: TrashItemKindList, List: l})
+	}
+
+	userLists, err := getUserListsStatement(s, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []*Task{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			cond,
+			builder.In("list_id", userLists.Select("l.id")),
+		)).
+		Find(&tasks)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		items = append(items, &TrashItem{Kind: TrashItemKindTask, Task: t})
+	}
+
+	for _, item := range items {
+		item.setFields()
+	}
+
+	return items, nil
+}
+
+// ReadAll returns all items in the trash the user can restore
+// @Summary Get the trash
+// @Description Returns all tasks, lists and namespaces in the trash the user can restore, the most recently deleted first. Tasks, lists and sub namespaces which were deleted together with their list or namespace are not returned on their own.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search items by title."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.TrashItem "The items in the trash."
+// @Failure 403 {object} web.HTTPError "Link shares cannot access the trash."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash [get]
+func (ti *TrashItem) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	candidates, err := getTrashItemCandidates(s, &user.User{ID: a.GetID()}, search)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	items := make([]*TrashItem, 0, len(candidates))
+	for _, item := range candidates {
+		can, err := item.checkManageRights(s, a)
+		if err != nil {
+			// The list or namespace of the item is in the trash itself, the item can't be restored on its own
+			if IsErrListDoesNotExist(err) || IsErrNamespaceDoesNotExist(err) {
+				continue
+			}
+			return nil, 0, 0, err
+		}
+		if !can {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Deleted.After(items[j].Deleted)
+	})
+
+	totalItems := int64(len(items))
+	limit, start := getLimitFromPageIndex(page, perPage)
+	if limit > 0 {
+		if start > len(items) {
+			start = len(items)
+		}
+		end := start + limit
+		if end > len(items) {
+			end = len(items)
+		}
+		items = items[start:end]
+	}
+
+	userIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		var deletedByID int64
+		switch item.Kind {
+		case TrashItemKindTask:
+			deletedByID = item.Task.DeletedByID
+		case TrashItemKindList:
+			deletedByID = item.List.DeletedByID
+		case TrashItemKindNamespace:
+			deletedByID = item.Namespace.DeletedByID
+		}
+		userIDs = append(userIDs, deletedByID)
+		item.DeletedBy = &user.User{ID: deletedByID}
+		item.PurgeAt = getTrashPurgeTime(item.Deleted)
+		item.Task, item.List, item.Namespace = nil, nil, nil
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, item := range items {
+		item.DeletedBy = users[item.DeletedBy.ID]
+	}
+
+	return items, len(items), totalItems, nil
+}
+
+// Update restores an item from the trash
+// @Summary Restore an item from the trash
+// @Description Restores a task, list or namespace from the trash together with everything which was deleted with it. The list of a task, the namespace of a list and the parent namespace of a namespace must not be in the trash.
+// @tags trash
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param kind path string true "The kind of the item. One of task, list or namespace."
+// @Param id path int true "The id of the item."
+// @Success 200 {object} models.TrashItem "The restored item."
+// @Failure 400 {object} web.HTTPError "Invalid trash item kind."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the item."
+// @Failure 404 {object} web.HTTPError "The item is not in the trash or its parent is in the trash as well."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash/{kind}/{id} [post]
+func (ti *TrashItem) Update(s *xorm.Session, a web.Auth) (err error) {
+	err = ti.load(s)
+	if err != nil {
+		return err
+	}
+
+	switch ti.Kind {
+	case TrashItemKindTask:
+		err = restoreTask(s, ti.Task)
+		if err != nil {
+			return err
+		}
+		task, err := GetTaskByIDSimple(s, ti.Task.ID)
+		if err != nil {
+			return err
+		}
+		ti.Task = &task
+		doer, _ := user.GetFromAuth(a)
+		return events.Dispatch(&TaskRestoredEvent{
+			Task: ti.Task,
+			Doer: doer,
+		})
+	case TrashItemKindList:
+		_, err = getNamespaceSimpleByID(s, ti.List.NamespaceID)
+		if err != nil {
+			return err
+		}
+		err = restoreList(s, ti.List)
+		if err != nil {
+			return err
+		}
+		ti.List, err = GetListSimpleByID(s, ti.List.ID)
+		if err != nil {
+			return err
+		}
+		return events.Dispatch(&ListRestoredEvent{
+			List: ti.List,
+			Doer: a,
+		})
+	case TrashItemKindNamespace:
+		if ti.Namespace.ParentID > 0 {
+			_, err = getNamespaceSimpleByID(s, ti.Namespace.ParentID)
+			if err != nil {
+				return err
+			}
+		}
+		err = restoreNamespace(s, ti.Namespace)
+		if err != nil {
+			return err
+		}
+		ti.Namespace, err = getNamespaceSimpleByID(s, ti.Namespace.ID)
+		if err != nil {
+			return err
+		}
+		return events.Dispatch(&NamespaceRestoredEvent{
+			Namespace: ti.Namespace,
+			Doer:      a,
+		})
+	}
+
+	return nil
+}
+
+// Delete removes an item from the trash for good
+// @Summary Remove an item from the trash
+// @Description Removes a task, list or namespace from the trash for good together with everything which was deleted with it. This cannot be undone.
+// @tags trash
+// @Produce json
+// @Security JWTKeyAuth
+// @Param kind path string true "The kind of the item. One of task, list or namespace."
+// @Param id path int true "The id of the item."
+// @Success 200 {object} models.Message "The item was removed for good."
+// @Failure 400 {object} web.HTTPError "Invalid trash item kind."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the item."
+// @Failure 404 {object} web.HTTPError "The item is not in the trash."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /trash/{kind}/{id} [delete]
+func (ti *TrashItem) Delete(s *xorm.Session, a web.Auth) (err error) {
+	err = ti.load(s)
+	if err != nil {
+		return err
+	}
+
+	switch ti.Kind {
+	case TrashItemKindTask:
+		return ti.Task.purge(s)
+	case TrashItemKindList:
+		return ti.List.purge(s)
+	case TrashItemKindNamespace:
+		return purgeNamespace(s, ti.Namespace, true)
+	}
+
+	return nil
+}
+
+// Removes all items from the trash for good which were moved there before the given time.
+func purgeTrash(s *xorm.Session, olderThan time.Time) (purged int, err error) {
+	cond := builder.And(trashItemCond, builder.Lt{"deleted": olderThan})
+
+	// Namespaces come first since purging them purges their lists as well
+	namespaces := []*Namespace{}
+	err = s.Unscoped().Where(cond).Find(&namespaces)
+	if err != nil {
+		return
+	}
+	for _, n := range namespaces {
+		err = purgeNamespace(s, n, true)
+		if err != nil {
+			return
+		}
+	}
+
+	lists := []*List{}
+	err = s.Unscoped().Where(cond).Find(&lists)
+	if err != nil {
+		return
+	}
+	for _, l := range lists {
+		err = l.purge(s)
+		if err != nil {
+			return
+		}
+	}
+
+	tasks := []*Task{}
+	err = s.Unscoped().Where(cond).Find(&tasks)
+	if err != nil {
+		return
+	}
+	for _, t := range tasks {
+		err = t.purge(s)
+		if err != nil {
+			return
+		}
+	}
+
+	return len(namespaces) + len(lists) + len(tasks), nil
+}
+
+// RegisterTrashPurgeCron registers a cron function to remove all items from the trash for good which are older than
+// the configured retention period.
+func RegisterTrashPurgeCron() {
+	const logPrefix = "[Trash Purge Cron] "
+
+	retention := config.ServiceTrashRetention.GetInt()
+	if retention <= 0 {
+		return
+	}
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		purged, err := purgeTrash(s, time.Now().Add(time.Hour*24*time.Duration(-retention)))
+		if err != nil {
+			log.Errorf(logPrefix+"Error purging the trash: %s", err)
+			_ = s.Rollback()
+			return
+		}
+
+		err = s.Commit()
+		if err != nil {
+			log.Errorf(logPrefix+"Error purging the trash: %s", err)
+			return
+		}
+
+		if purged > 0 {
+			log.Debugf(logPrefix+"Removed %d items from the trash", purged)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register trash purge cron: %s", err)
+	}
+}
@@ -0,0 +1,317 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+)
+
+func TestTrashItem_ReadAll(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 3}).Delete(s, u)
+		assert.NoError(t, err)
+		err = (&List{ID: 2}).Delete(s, u)
+		assert.NoError(t, err)
+
+		res, _, _, err := (&TrashItem{}).ReadAll(s, u, "", 1, 50)
+		assert.NoError(t, err)
+		items := res.([]*TrashItem)
+		assert.Len(t, items, 2)
+		for _, item := range items {
+			assert.Equal(t, int64(1), item.DeletedBy.ID)
+			assert.False(t, item.PurgeAt.IsZero())
+			switch item.Kind {
+			case TrashItemKindTask:
+				assert.Equal(t, int64(3), item.ID)
+			case TrashItemKindList:
+				assert.Equal(t, int64(2), item.ID)
+			default:
+				t.Errorf("unexpected trash item kind %s", item.Kind)
+			}
+		}
+	})
+	t.Run("search", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 3}).Delete(s, u)
+		assert.NoError(t, err)
+		err = (&List{ID: 2}).Delete(s, u)
+		assert.NoError(t, err)
+
+		res, _, total, err := (&TrashItem{}).ReadAll(s, u, "test2", 1, 50)
+		assert.NoError(t, err)
+		items := res.([]*TrashItem)
+		assert.Len(t, items, 1)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, TrashItemKindList, items[0].Kind)
+		assert.Equal(t, int64(2), items[0].ID)
+	})
+	t.Run("pagination", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 3}).Delete(s, u)
+		assert.NoError(t, err)
+		err = (&List{ID: 2}).Delete(s, u)
+		assert.NoError(t, err)
+
+		res, count, total, err := (&TrashItem{}).ReadAll(s, u, "", 1, 1)
+		assert.NoError(t, err)
+		first := res.([]*TrashItem)
+		assert.Len(t, first, 1)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, int64(2), total)
+
+		res, _, _, err = (&TrashItem{}).ReadAll(s, u, "", 2, 1)
+		assert.NoError(t, err)
+		second := res.([]*TrashItem)
+		assert.Len(t, second, 1)
+		assert.NotEqual(t, first[0].Kind, second[0].Kind)
+
+		res, _, _, err = (&TrashItem{}).ReadAll(s, u, "", 3, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+	t.Run("items deleted with their parent", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Namespace{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		res, _, _, err := (&TrashItem{}).ReadAll(s, u, "", 1, 50)
+		assert.NoError(t, err)
+		items := res.([]*TrashItem)
+		assert.Len(t, items, 1)
+		assert.Equal(t, TrashItemKindNamespace, items[0].Kind)
+		assert.Equal(t, int64(1), items[0].ID)
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&List{ID: 24}).Delete(s, &user.User{ID: 6})
+		assert.NoError(t, err)
+
+		res, _, _, err := (&TrashItem{}).ReadAll(s, u, "", 1, 50)
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, _, err := (&TrashItem{}).ReadAll(s, &LinkSharing{ID: 1}, "", 1, 50)
+		assert.Error(t, err)
+	})
+}
+
+func TestTrashItem_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		ti := &TrashItem{Kind: TrashItemKindTask, ID: 1}
+		can, err := ti.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.True(t, can)
+		err = ti.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "task #1", ti.Task.Title)
+		assertTrashed(t, "tasks", map[string]interface{}{"id": 1}, false)
+	})
+	t.Run("list with its tasks", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+		err = (&List{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		ti := &TrashItem{Kind: TrashItemKindList, ID: 1}
+		err = ti.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assertTrashed(t, "lists", map[string]interface{}{"id": 1}, false)
+		assertTrashed(t, "tasks", map[string]interface{}{"id": 2}, false)
+		// Task 1 was deleted on its own and stays in the trash
+		assertTrashed(t, "tasks", map[string]interface{}{"id": 1}, true)
+	})
+	t.Run("namespace with its lists", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Namespace{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		ti := &TrashItem{Kind: TrashItemKindNamespace, ID: 1}
+		err = ti.Update(s, u)
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assertTrashed(t, "namespaces", map[string]interface{}{"id": 1}, false)
+		assertTrashed(t, "lists", map[string]interface{}{"id": 2}, false)
+		assertTrashed(t, "tasks", map[string]interface{}{"id": 1}, false)
+	})
+	t.Run("list in a namespace in the trash", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&List{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+		err = (&Namespace{ID: 1}).Delete(s, u)
+		assert.NoError(t, err)
+
+		ti := &TrashItem{Kind: TrashItemKindList, ID: 1}
+		err = ti.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrNamespaceDoesNotExist(err))
+	})
+	t.Run("not in the trash", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TrashItem{Kind: TrashItemKindTask, ID: 1}
+		err := ti.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrTrashItemDoesNotExist(err))
+	})
+	t.Run("invalid kind", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		ti := &TrashItem{Kind: "label", ID: 1}
+		err := ti.Update(s, u)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTrashItemKind(err))
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&List{ID: 24}).Delete(s, &user.User{ID: 6})
+		assert.NoError(t, err)
+
+		ti := &TrashItem{Kind: TrashItemKindList, ID: 24}
+		can, err := ti.CanUpdate(s, u)
+		assert.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestTrashItem_Delete(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	err := (&List{ID: 1}).Delete(s, u)
+	assert.NoError(t, err)
+
+	ti := &TrashItem{Kind: TrashItemKindList, ID: 1}
+	can, err := ti.CanDelete(s, u)
+	assert.NoError(t, err)
+	assert.True(t, can)
+	err = ti.Delete(s, u)
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "lists", map[string]interface{}{"id": 1})
+	db.AssertMissing(t, "tasks", map[string]interface{}{"list_id": 1})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	err := (&Task{ID: 3}).Delete(s, u)
+	assert.NoError(t, err)
+	err = (&List{ID: 2}).Delete(s, u)
+	assert.NoError(t, err)
+
+	purged, err := purgeTrash(s, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	purged, err = purgeTrash(s, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "tasks", map[string]interface{}{"id": 3})
+	db.AssertMissing(t, "lists", map[string]interface{}{"id": 2})
+	db.AssertExists(t, "lists", map[string]interface{}{"id": 1}, false)
+}
+
+// assertTrashed asserts that all rows matching the values are in the trash or that there is an active row matching
+// the values.
+func assertTrashed(t *testing.T, table string, values map[string]interface{}, trashed bool) {
+	s := db.NewSession()
+	defer s.Close()
+
+	exists, err := s.Table(table).Where(builder.Eq(values)).Exist()
+	assert.NoError(t, err)
+	assert.True(t, exists, fmt.Sprintf("Entries %v do not exist in table %s", values, table))
+
+	active, err := s.Table(table).Where(builder.Eq(values)).And("deleted IS NULL").Exist()
+	assert.NoError(t, err)
+	assert.Equal(t, !trashed, active, fmt.Sprintf("Entries %v in table %s are not in the expected trash state", values, table))
+}
@@ -67,6 +67,19 @@ func SetupTests() {
 		"saved_filters",
 		"subscriptions",
 		"favorites",
+		"admin_audit_log",
+		"user_invitations",
+		"share_invitations",
+		"roles",
+		"link_share_access_log",
+		"task_buckets",
+		"bucket_rules",
+		"task_history",
+		"task_bucket_entries",
+		"task_statuses",
+		"task_status_transitions",
+		"task_identifier_redirects",
+		"list_templates",
 	)
 	if err != nil {
 		log.Fatal(err)
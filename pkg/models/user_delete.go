@@ -95,24 +95,37 @@ func getNamespacesToDelete(s *xorm.Session, u *user.User) (namespacesToDelete []
 		return nil, err
 	}
 
-	if res == nil {
-		return nil, nil
+	namespaces := []*Namespace{}
+	if res != nil {
+		for _, n := range res.([]*NamespaceWithLists) {
+			namespaces = append(namespaces, &n.Namespace)
+		}
+	}
+
+	// Namespaces in the trash are not returned by ReadAll but need to be removed as well
+	trashed := []*Namespace{}
+	err = s.
+		Unscoped().
+		Where("owner_id = ? AND deleted IS NOT NULL", u.ID).
+		Find(&trashed)
+	if err != nil {
+		return nil, err
 	}
+	namespaces = append(namespaces, trashed...)
 
-	namespaces := res.([]*NamespaceWithLists)
 	for _, n := range namespaces {
 		if n.ID < 0 {
 			continue
 		}
 
-		hadUsers, err := ensureNamespaceAdminUser(s, &n.Namespace)
+		hadUsers, err := ensureNamespaceAdminUser(s, n)
 		if err != nil {
 			return nil, err
 		}
 		if hadUsers {
 			continue
 		}
-		hadTeams, err := ensureNamespaceAdminTeam(s, &n.Namespace)
+		hadTeams, err := ensureNamespaceAdminTeam(s, n)
 		if err != nil {
 			return nil, err
 		}
@@ -120,7 +133,7 @@ func getNamespacesToDelete(s *xorm.Session, u *user.User) (namespacesToDelete []
 			continue
 		}
 
-		namespacesToDelete = append(namespacesToDelete, &n.Namespace)
+		namespacesToDelete = append(namespacesToDelete, n)
 	}
 
 	return
@@ -134,11 +147,28 @@ func getListsToDelete(s *xorm.Session, u *user.User) (listsToDelete []*List, err
 		return nil, err
 	}
 
-	if res == nil {
-		return nil, nil
+	lists := []*List{}
+	if res != nil {
+		lists = res.([]*List)
 	}
 
-	lists := res.([]*List)
+	// Lists in the trash are not returned by ReadAll but need to be removed as well
+	trashed := []*List{}
+	err = s.
+		Unscoped().
+		Where(builder.And(
+			builder.NotNull{"deleted"},
+			builder.Or(
+				builder.Eq{"owner_id": u.ID},
+				builder.In("namespace_id", builder.Select("id").From("namespaces").Where(builder.Eq{"owner_id": u.ID})),
+			),
+		)).
+		Find(&trashed)
+	if err != nil {
+		return nil, err
+	}
+	lists = append(lists, trashed...)
+
 	for _, l := range lists {
 		if l.ID < 0 {
 			continue
@@ -182,19 +212,44 @@ func DeleteUser(s *xorm.Session, u *user.User) (err error) {
 
 	// Delete everything not shared with anybody else
 	for _, n := range namespacesToDelete {
-		err = deleteNamespace(s, n, u, false)
+		err = purgeNamespace(s, n, false)
 		if err != nil {
 			return err
 		}
 	}
 
 	for _, l := range listsToDelete {
-		err = l.Delete(s, u)
+		err = l.purge(s)
 		if err != nil {
 			return err
 		}
 	}
 
+	err = user.DeleteAllSessionsForUser(s, u.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("user_id = ?", u.ID).Delete(&user.WebAuthnCredential{})
+	if err != nil {
+		return err
+	}
+
+	err = user.DeleteRecoveryCodes(s, u)
+	if err != nil {
+		return err
+	}
+
+	err = deleteShareInvitations(s, &ShareInvitation{CreatedByID: u.ID})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("created_by_id = ?", u.ID).Delete(&user.Invitation{})
+	if err != nil {
+		return err
+	}
+
 	_, err = s.Where("id = ?", u.ID).Delete(&user.User{})
 	if err != nil {
 		return err
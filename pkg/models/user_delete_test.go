@@ -46,6 +46,44 @@ func TestDeleteUser(t *testing.T) {
 		db.AssertExists(t, "lists", map[string]interface{}{"id": 10}, false)
 		db.AssertExists(t, "lists", map[string]interface{}{"id": 11}, false)
 	})
+	t.Run("with items in the trash", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+
+		u := &user.User{ID: 6}
+		err := (&List{ID: 24}).Delete(s, u)
+		assert.NoError(t, err)
+		err = (&Namespace{ID: 6}).Delete(s, u)
+		assert.NoError(t, err)
+
+		err = DeleteUser(s, u)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "lists", map[string]interface{}{"id": 24})
+		db.AssertMissing(t, "namespaces", map[string]interface{}{"id": 6})
+	})
+	t.Run("keeps sub namespaces in the trash", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+
+		u := &user.User{ID: 6}
+		// Namespace 1 belongs to user 1
+		setNamespaceParent(t, s, 1, 6)
+		err := (&Namespace{ID: 6}).Delete(s, u)
+		assert.NoError(t, err)
+
+		err = DeleteUser(s, u)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "namespaces", map[string]interface{}{"id": 6})
+		assertTrashed(t, "namespaces", map[string]interface{}{
+			"id":                  1,
+			"parent_id":           0,
+			"deleted_with_parent": false,
+		}, true)
+	})
 	t.Run("user with no namespaces", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 		s := db.NewSession()
@@ -59,3 +97,26 @@ func TestDeleteUser(t *testing.T) {
 		// No assertions for deleted lists and namespaces since that user doesn't have any
 	})
 }
+
+func TestAdminUser_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+	notifications.Fake()
+
+	au := &AdminUser{ID: 6}
+	err := au.Delete(s, &user.User{ID: 14})
+	assert.NoError(t, err)
+	err = s.Commit()
+	assert.NoError(t, err)
+
+	db.AssertMissing(t, "users", map[string]interface{}{
+		"id": 6,
+	})
+	db.AssertExists(t, "admin_audit_log", map[string]interface{}{
+		"admin_id":       14,
+		"action":         AdminActionUserDeleted,
+		"target_user_id": 6,
+		"details":        "user6",
+	}, false)
+}
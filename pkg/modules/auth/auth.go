@@ -17,10 +17,12 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
@@ -39,24 +41,49 @@ const (
 // Token represents an authentification token
 type Token struct {
 	Token string `json:"token"`
+	// The refresh token can be used to obtain a new token once the current one expired. Only returned for user tokens.
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// If true, the token can only be used to set up a second factor. Log in again once it is set up.
+	SecondFactorEnrollment bool `json:"second_factor_enrollment,omitempty"`
 }
 
-// NewUserAuthTokenResponse creates a new user auth token response from a user object.
+// How long a token which can only be used to set up a second factor is valid
+const secondFactorEnrollmentTTL = 15 * time.Minute
+
+// NewUserAuthTokenResponse creates a new session for a user and responds with a token for it.
 func NewUserAuthTokenResponse(u *user.User, c echo.Context, long bool) error {
-	t, err := NewUserJWTAuthtoken(u, long)
+	s := db.NewSession()
+	defer s.Close()
+
+	session, err := user.CreateSession(s, u, c.Request().UserAgent(), c.RealIP(), long)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	if err := s.Commit(); err != nil {
+		return err
+	}
+
+	return NewUserAuthTokenResponseForSession(u, session, c)
+}
+
+// NewUserAuthTokenResponseForSession responds with a token for an existing session.
+func NewUserAuthTokenResponseForSession(u *user.User, session *user.Session, c echo.Context) error {
+	t, err := NewUserJWTAuthtoken(u, session)
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, Token{Token: t})
+	return c.JSON(http.StatusOK, Token{Token: t, RefreshToken: session.ClearTextRefreshToken})
 }
 
-// NewUserJWTAuthtoken generates and signes a new jwt token for a user. This is a global function to be able to call it from integration tests.
-func NewUserJWTAuthtoken(u *user.User, long bool) (token string, err error) {
+// NewUserJWTAuthtoken generates and signes a new jwt token for a user session. This is a global function to be able to call it from integration tests.
+func NewUserJWTAuthtoken(u *user.User, session *user.Session) (token string, err error) {
 	t := jwt.New(jwt.SigningMethodHS256)
 
 	var ttl = time.Duration(config.ServiceJWTTTL.GetInt64())
-	if long {
+	if session.IsLongSession {
 		ttl = time.Duration(config.ServiceJWTTTLLong.GetInt64())
 	}
 	var exp = time.Now().Add(time.Second * ttl).Unix()
@@ -65,18 +92,47 @@ func NewUserJWTAuthtoken(u *user.User, long bool) (token string, err error) {
 	claims := t.Claims.(jwt.MapClaims)
 	claims["type"] = AuthTypeUser
 	claims["id"] = u.ID
+	claims["sid"] = session.ID
 	claims["username"] = u.Username
 	claims["email"] = u.Email
 	claims["exp"] = exp
 	claims["name"] = u.Name
 	claims["emailRemindersEnabled"] = u.EmailRemindersEnabled
 	claims["isLocalUser"] = u.Issuer == user.IssuerLocal
-	claims["long"] = long
+	claims["long"] = session.IsLongSession
+
+	// Generate encoded token and send it as response.
+	return t.SignedString([]byte(config.ServiceJWTSecret.GetString()))
+}
+
+// NewSecondFactorEnrollmentJWTAuthtoken creates a token for a user who has to set up a second factor before they can
+// log in. The token is not bound to a session and only valid for the endpoints to set up totp or webauthn.
+func NewSecondFactorEnrollmentJWTAuthtoken(u *user.User) (token string, err error) {
+	t := jwt.New(jwt.SigningMethodHS256)
+
+	// Set claims
+	claims := t.Claims.(jwt.MapClaims)
+	claims["type"] = AuthTypeUser
+	claims["id"] = u.ID
+	claims["username"] = u.Username
+	claims["email"] = u.Email
+	claims["exp"] = time.Now().Add(secondFactorEnrollmentTTL).Unix()
+	claims["name"] = u.Name
+	claims["emailRemindersEnabled"] = u.EmailRemindersEnabled
+	claims["isLocalUser"] = u.Issuer == user.IssuerLocal
+	claims["secondFactorEnrollment"] = true
 
 	// Generate encoded token and send it as response.
 	return t.SignedString([]byte(config.ServiceJWTSecret.GetString()))
 }
 
+// IsSecondFactorEnrollmentToken checks if a token can only be used to set up a second factor.
+func IsSecondFactorEnrollmentToken(token *jwt.Token) bool {
+	claims := token.Claims.(jwt.MapClaims)
+	enrollment, _ := claims["secondFactorEnrollment"].(bool)
+	return enrollment
+}
+
 // NewLinkShareJWTAuthtoken creates a new jwt token from a link share
 func NewLinkShareJWTAuthtoken(share *models.LinkSharing) (token string, err error) {
 	t := jwt.New(jwt.SigningMethodHS256)
@@ -112,3 +168,60 @@ func GetAuthFromClaims(c echo.Context) (a web.Auth, err error) {
 	}
 	return nil, echo.NewHTTPError(http.StatusBadRequest, models.Message{Message: "Invalid JWT token."})
 }
+
+// GetSessionIDFromClaims returns the id of the session a user token was issued for.
+func GetSessionIDFromClaims(c echo.Context) int64 {
+	jwtinf := c.Get("user").(*jwt.Token)
+	claims := jwtinf.Claims.(jwt.MapClaims)
+	sid, _ := claims["sid"].(float64)
+	return int64(sid)
+}
+
+// ValidateUserSession checks if the session a user token was issued for still exists.
+// Tokens of other types and tokens to set up a second factor are always valid.
+func ValidateUserSession(token *jwt.Token) error {
+	claims := token.Claims.(jwt.MapClaims)
+	typ, _ := claims["type"].(float64)
+	if int(typ) != AuthTypeUser || IsSecondFactorEnrollmentToken(token) {
+		return nil
+	}
+
+	sid, hasSession := claims["sid"].(float64)
+	uid, hasUser := claims["id"].(float64)
+	if !hasSession || !hasUser {
+		return errors.New("token is not bound to a session")
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := user.ValidateSession(s, int64(sid), int64(uid))
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	return s.Commit()
+}
+
+// ValidateLinkShare checks if the link share a token was issued for can still be used from an ip address.
+// Tokens of other types are always valid.
+func ValidateLinkShare(token *jwt.Token, ip string) error {
+	claims := token.Claims.(jwt.MapClaims)
+	typ, _ := claims["type"].(float64)
+	if int(typ) != AuthTypeLinkShare {
+		return nil
+	}
+
+	id, _ := claims["id"].(float64)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	share, err := models.GetLinkShareByID(s, int64(id))
+	if err != nil {
+		return err
+	}
+
+	return share.CheckAccess(ip)
+}
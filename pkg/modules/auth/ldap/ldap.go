@@ -0,0 +1,241 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	"github.com/go-ldap/ldap/v3"
+	"xorm.io/xorm"
+)
+
+// connection is the part of an ldap connection Vikunja needs to authenticate users.
+type connection interface {
+	Bind(username, password string) error
+	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close()
+}
+
+// dial connects to the configured ldap server. It is a variable so tests can replace it with an in-process directory.
+var dial = func() (connection, error) {
+	scheme := "ldap"
+	if config.AuthLDAPUseTLS.GetBool() {
+		scheme = "ldaps"
+	}
+	url := scheme + "://" + config.AuthLDAPHost.GetString() + ":" + strconv.Itoa(config.AuthLDAPPort.GetInt())
+
+	return ldap.DialURL(url, ldap.DialWithTLSConfig(&tls.Config{
+		ServerName:         config.AuthLDAPHost.GetString(),
+		InsecureSkipVerify: !config.AuthLDAPVerifyTLS.GetBool(),
+	}))
+}
+
+// AuthenticateUserInLDAP checks the credentials of a user against the configured ldap server.
+// If they are valid, the user is created in Vikunja or their details are updated if they logged in before.
+// If group sync is enabled, the teams of the user are synced with their ldap groups.
+func AuthenticateUserInLDAP(s *xorm.Session, username, password string) (u *user.User, err error) {
+	// An empty password would result in an unauthenticated bind which most servers accept
+	if username == "" || password == "" {
+		return nil, user.ErrNoUsernamePassword{}
+	}
+
+	l, err := dial()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to ldap server: %w", err)
+	}
+	defer l.Close()
+
+	err = bindServiceUser(l)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := searchUser(l, username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.Bind(entry.DN, password)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, user.ErrWrongUsernameOrPassword{}
+		}
+		return nil, fmt.Errorf("could not bind as ldap user: %w", err)
+	}
+
+	u, err = getOrCreateUser(s, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.AuthLDAPGroupSyncEnabled.GetBool() {
+		return u, nil
+	}
+
+	// Group membership is looked up with the service user since regular users are often not allowed to see groups
+	err = bindServiceUser(l)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := getGroupsForUser(l, entry.DN)
+	if err != nil {
+		return nil, err
+	}
+
+	err = models.SyncExternalTeams(s, u, user.IssuerLDAP, groups)
+	return u, err
+}
+
+func bindServiceUser(l connection) error {
+	if config.AuthLDAPBindDN.GetString() == "" {
+		return nil
+	}
+
+	err := l.Bind(config.AuthLDAPBindDN.GetString(), config.AuthLDAPBindPassword.GetString())
+	if err != nil {
+		return fmt.Errorf("could not bind as ldap service user: %w", err)
+	}
+	return nil
+}
+
+func searchUser(l connection, username string) (entry *ldap.Entry, err error) {
+	request := ldap.NewSearchRequest(
+		config.AuthLDAPBaseDN.GetString(),
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf(config.AuthLDAPUserFilter.GetString(), ldap.EscapeFilter(username)),
+		[]string{
+			"dn",
+			config.AuthLDAPAttributeUsername.GetString(),
+			config.AuthLDAPAttributeEmail.GetString(),
+			config.AuthLDAPAttributeDisplayname.GetString(),
+		},
+		nil,
+	)
+
+	result, err := l.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not search for ldap user: %w", err)
+	}
+
+	if len(result.Entries) != 1 {
+		if len(result.Entries) > 1 {
+			log.Warningf("LDAP user filter matched %d entries for username %s, refusing to log in", len(result.Entries), username)
+		}
+		return nil, user.ErrWrongUsernameOrPassword{}
+	}
+
+	return result.Entries[0], nil
+}
+
+func getGroupsForUser(l connection, userDN string) (groups []string, err error) {
+	request := ldap.NewSearchRequest(
+		config.AuthLDAPBaseDN.GetString(),
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf(config.AuthLDAPGroupFilter.GetString(), ldap.EscapeFilter(userDN)),
+		[]string{config.AuthLDAPAttributeGroupName.GetString()},
+		nil,
+	)
+
+	result, err := l.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not search for ldap groups: %w", err)
+	}
+
+	groups = make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		name := entry.GetAttributeValue(config.AuthLDAPAttributeGroupName.GetString())
+		if name != "" {
+			groups = append(groups, name)
+		}
+	}
+
+	return groups, nil
+}
+
+func getOrCreateUser(s *xorm.Session, entry *ldap.Entry) (u *user.User, err error) {
+	email := entry.GetAttributeValue(config.AuthLDAPAttributeEmail.GetString())
+	name := entry.GetAttributeValue(config.AuthLDAPAttributeDisplayname.GetString())
+
+	// Users are identified by their DN since the username may not be unique across the whole directory
+	u, err = user.GetUserWithEmail(s, &user.User{
+		Issuer:  user.IssuerLDAP,
+		Subject: entry.DN,
+	})
+	if err != nil && !user.IsErrUserDoesNotExist(err) {
+		return nil, err
+	}
+
+	if user.IsErrUserDoesNotExist(err) {
+		uu := &user.User{
+			Username: entry.GetAttributeValue(config.AuthLDAPAttributeUsername.GetString()),
+			Email:    email,
+			Name:     name,
+			Status:   user.StatusActive,
+			Issuer:   user.IssuerLDAP,
+			Subject:  entry.DN,
+		}
+
+		u, err = user.CreateUser(s, uu)
+		if err != nil && !user.IsErrUsernameExists(err) {
+			return nil, err
+		}
+
+		// If the username is already taken by another user, create a random one
+		if user.IsErrUsernameExists(err) {
+			uu.Username = petname.Generate(3, "-")
+			u, err = user.CreateUser(s, uu)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = models.CreateNewNamespaceForUser(s, u)
+		return u, err
+	}
+
+	if email != u.Email || name != u.Name {
+		u, err = user.UpdateUser(s, &user.User{
+			ID:      u.ID,
+			Email:   email,
+			Name:    name,
+			Issuer:  user.IssuerLDAP,
+			Subject: entry.DN,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
@@ -0,0 +1,217 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"errors"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDirectory is an in-process stand-in for an ldap server. Searches are answered by looking up the exact filter.
+type fakeDirectory struct {
+	passwords map[string]string
+	results   map[string][]*ldap.Entry
+}
+
+func (d *fakeDirectory) Bind(username, password string) error {
+	if pw, has := d.passwords[username]; has && pw == password {
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+}
+
+func (d *fakeDirectory) Search(r *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	return &ldap.SearchResult{Entries: d.results[r.Filter]}, nil
+}
+
+func (d *fakeDirectory) Close() {}
+
+const (
+	testBaseDN  = "dc=example,dc=org"
+	testBindDN  = "cn=admin,dc=example,dc=org"
+	testAliceDN = "uid=alice,ou=people,dc=example,dc=org"
+)
+
+func setupFakeDirectory(t *testing.T) {
+	config.AuthLDAPBaseDN.Set(testBaseDN)
+	config.AuthLDAPBindDN.Set(testBindDN)
+	config.AuthLDAPBindPassword.Set("admin")
+	config.AuthLDAPGroupSyncEnabled.Set(false)
+
+	directory := &fakeDirectory{
+		passwords: map[string]string{
+			testBindDN:                              "admin",
+			testAliceDN:                             "secret",
+			"uid=user1,ou=people,dc=example,dc=org": "secret",
+		},
+		results: map[string][]*ldap.Entry{
+			"(&(objectClass=inetOrgPerson)(uid=alice))": {
+				ldap.NewEntry(testAliceDN, map[string][]string{
+					"uid":         {"alice"},
+					"mail":        {"alice@example.org"},
+					"displayName": {"Alice"},
+				}),
+			},
+			"(&(objectClass=inetOrgPerson)(uid=user1))": {
+				ldap.NewEntry("uid=user1,ou=people,dc=example,dc=org", map[string][]string{
+					"uid":  {"user1"},
+					"mail": {"user1@example.org"},
+				}),
+			},
+			"(&(objectClass=groupOfNames)(member=" + testAliceDN + "))": {
+				ldap.NewEntry("cn=developers,ou=groups,dc=example,dc=org", map[string][]string{
+					"cn": {"developers"},
+				}),
+			},
+		},
+	}
+
+	originalDial := dial
+	dial = func() (connection, error) {
+		return directory, nil
+	}
+	t.Cleanup(func() {
+		dial = originalDial
+		config.AuthLDAPGroupSyncEnabled.Set(false)
+	})
+}
+
+func TestAuthenticateUserInLDAP(t *testing.T) {
+	t.Run("new user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupFakeDirectory(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := AuthenticateUserInLDAP(s, "alice", "secret")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "alice", u.Username)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":       u.ID,
+			"username": "alice",
+			"email":    "alice@example.org",
+			"name":     "Alice",
+			"issuer":   user.IssuerLDAP,
+			"subject":  testAliceDN,
+		}, false)
+		db.AssertExists(t, "namespaces", map[string]interface{}{
+			"owner_id": u.ID,
+		}, false)
+	})
+	t.Run("existing user with changed email", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupFakeDirectory(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := AuthenticateUserInLDAP(s, "alice", "secret")
+		assert.NoError(t, err)
+
+		directory, _ := dial()
+		directory.(*fakeDirectory).results["(&(objectClass=inetOrgPerson)(uid=alice))"][0] = ldap.NewEntry(testAliceDN, map[string][]string{
+			"uid":         {"alice"},
+			"mail":        {"alice@example.com"},
+			"displayName": {"Alice"},
+		})
+
+		u2, err := AuthenticateUserInLDAP(s, "alice", "secret")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		assert.Equal(t, u.ID, u2.ID)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":    u.ID,
+			"email": "alice@example.com",
+		}, false)
+	})
+	t.Run("username taken by a local user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupFakeDirectory(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := AuthenticateUserInLDAP(s, "user1", "secret")
+		assert.NoError(t, err)
+		assert.NotEqual(t, int64(1), u.ID)
+		assert.NotEqual(t, "user1", u.Username)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupFakeDirectory(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := AuthenticateUserInLDAP(s, "alice", "wrong")
+		assert.Error(t, err)
+		assert.True(t, user.IsErrWrongUsernameOrPassword(err))
+		db.AssertMissing(t, "users", map[string]interface{}{
+			"username": "alice",
+		})
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupFakeDirectory(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := AuthenticateUserInLDAP(s, "bob", "secret")
+		assert.Error(t, err)
+		assert.True(t, user.IsErrWrongUsernameOrPassword(err))
+	})
+	t.Run("empty password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupFakeDirectory(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := AuthenticateUserInLDAP(s, "alice", "")
+		assert.Error(t, err)
+		assert.True(t, user.IsErrNoUsernamePassword(err))
+	})
+	t.Run("group sync", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupFakeDirectory(t)
+		config.AuthLDAPGroupSyncEnabled.Set(true)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, err := AuthenticateUserInLDAP(s, "alice", "secret")
+		assert.NoError(t, err)
+		err = s.Commit()
+		assert.NoError(t, err)
+
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"name":        "developers",
+			"external_id": "developers",
+			"issuer":      user.IssuerLDAP,
+		}, false)
+		db.AssertExists(t, "team_members", map[string]interface{}{
+			"user_id": u.ID,
+		}, false)
+	})
+}
@@ -53,8 +53,11 @@ type Provider struct {
 	AuthURL         string `json:"auth_url"`
 	ClientID        string `json:"client_id"`
 	ClientSecret    string `json:"-"`
-	openIDProvider  *oidc.Provider
-	Oauth2Config    *oauth2.Config `json:"-"`
+	// The claim which contains the groups of a user. If set, Vikunja creates a team for each group and keeps its
+	// members in sync with the identity provider.
+	GroupsClaim    string `json:"-"`
+	openIDProvider *oidc.Provider
+	Oauth2Config   *oauth2.Config `json:"-"`
 }
 
 type claims struct {
@@ -194,6 +197,22 @@ func HandleCallback(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	if provider.GroupsClaim != "" {
+		groups, err := getGroupsForUser(provider, idToken, oauth2Token)
+		if err != nil {
+			_ = s.Rollback()
+			log.Errorf("Error getting groups for provider %s: %v", provider.Name, err)
+			return handler.HandleHTTPError(err, c)
+		}
+
+		err = models.SyncExternalTeams(s, u, idToken.Issuer, groups)
+		if err != nil {
+			_ = s.Rollback()
+			log.Errorf("Error syncing teams for provider %s: %v", provider.Name, err)
+			return handler.HandleHTTPError(err, c)
+		}
+	}
+
 	err = s.Commit()
 	if err != nil {
 		return handler.HandleHTTPError(err, c)
@@ -203,6 +222,55 @@ func HandleCallback(c echo.Context) error {
 	return auth.NewUserAuthTokenResponse(u, c, false)
 }
 
+// getGroupsForUser returns the groups of the user from the configured groups claim. The claim is looked up in the
+// id token first and in the userinfo if the id token does not contain it.
+func getGroupsForUser(provider *Provider, idToken *oidc.IDToken, oauth2Token *oauth2.Token) (groups []string, err error) {
+	rawClaims := make(map[string]interface{})
+	err = idToken.Claims(&rawClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, has := rawClaims[provider.GroupsClaim]; !has {
+		info, err := provider.openIDProvider.UserInfo(context.Background(), provider.Oauth2Config.TokenSource(context.Background(), oauth2Token))
+		if err != nil {
+			return nil, err
+		}
+
+		err = info.Claims(&rawClaims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return getGroupsFromClaims(rawClaims, provider.GroupsClaim), nil
+}
+
+func getGroupsFromClaims(rawClaims map[string]interface{}, claim string) (groups []string) {
+	groups = []string{}
+
+	switch v := rawClaims[claim].(type) {
+	case string:
+		if v != "" {
+			groups = append(groups, v)
+		}
+	case []interface{}:
+		for _, g := range v {
+			if group, is := g.(string); is && group != "" {
+				groups = append(groups, group)
+			}
+		}
+	case []string:
+		for _, group := range v {
+			if group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	return
+}
+
 func getOrCreateUser(s *xorm.Session, cl *claims, issuer, subject string) (u *user.User, err error) {
 	// Check if the user exists for that issuer and subject
 	u, err = user.GetUserWithEmail(s, &user.User{
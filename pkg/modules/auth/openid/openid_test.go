@@ -94,3 +94,22 @@ func TestGetOrCreateUser(t *testing.T) {
 		}, false)
 	})
 }
+
+func TestGetGroupsFromClaims(t *testing.T) {
+	t.Run("list of groups", func(t *testing.T) {
+		groups := getGroupsFromClaims(map[string]interface{}{
+			"groups": []interface{}{"developers", "", "admins", 42},
+		}, "groups")
+		assert.Equal(t, []string{"developers", "admins"}, groups)
+	})
+	t.Run("single group", func(t *testing.T) {
+		groups := getGroupsFromClaims(map[string]interface{}{
+			"roles": "developers",
+		}, "roles")
+		assert.Equal(t, []string{"developers"}, groups)
+	})
+	t.Run("missing claim", func(t *testing.T) {
+		groups := getGroupsFromClaims(map[string]interface{}{}, "groups")
+		assert.Empty(t, groups)
+	})
+}
@@ -127,6 +127,10 @@ func getProviderFromMap(pi map[string]interface{}) (provider *Provider, err erro
 		ClientSecret:    pi["clientsecret"].(string),
 	}
 
+	if groupsClaim, is := pi["groupsclaim"].(string); is {
+		provider.GroupsClaim = groupsClaim
+	}
+
 	cl, is := pi["clientid"].(int)
 	if is {
 		provider.ClientID = strconv.Itoa(cl)
@@ -19,6 +19,11 @@ package notifications
 import (
 	"time"
 
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -38,6 +43,8 @@ type DatabaseNotification struct {
 
 	// When this notification is marked as read, this will be updated with the current timestamp.
 	ReadAt time.Time `xorm:"datetime null" json:"read_at"`
+	// If the notification was snoozed, it is hidden until this time.
+	SnoozedUntil time.Time `xorm:"datetime null index" json:"snoozed_until"`
 
 	// A timestamp when this notification was created. You cannot change this value.
 	Created time.Time `xorm:"created not null" json:"created"`
@@ -48,12 +55,56 @@ func (d *DatabaseNotification) TableName() string {
 	return "notifications"
 }
 
+// Filter narrows down the notifications returned or changed by the functions in this package.
+// Empty fields are ignored.
+type Filter struct {
+	// Only notifications with this name
+	Name string
+	// Only notifications about this subject
+	SubjectID int64
+	// If not nil, only read (true) or unread (false) notifications
+	Read *bool
+}
+
+func (f *Filter) toCond(notifiableID int64) builder.Cond {
+	cond := builder.And(
+		builder.Eq{"notifiable_id": notifiableID},
+		builder.Or(
+			builder.IsNull{"snoozed_until"},
+			builder.Lte{"snoozed_until": time.Now()},
+		),
+	)
+
+	if f == nil {
+		return cond
+	}
+
+	if f.Name != "" {
+		cond = cond.And(builder.Eq{"name": f.Name})
+	}
+	if f.SubjectID != 0 {
+		cond = cond.And(builder.Eq{"subject_id": f.SubjectID})
+	}
+	if f.Read != nil {
+		if *f.Read {
+			cond = cond.And(builder.NotNull{"read_at"})
+		} else {
+			cond = cond.And(builder.IsNull{"read_at"})
+		}
+	}
+
+	return cond
+}
+
 // GetNotificationsForUser returns all notifications for a user. It is possible to limit the amount of notifications
 // to return with the limit and start parameters.
 // We're not passing a user object in directly because every other package imports this one so we'd get import cycles.
-func GetNotificationsForUser(s *xorm.Session, notifiableID int64, limit, start int) (notifications []*DatabaseNotification, resultCount int, total int64, err error) {
+// Snoozed notifications are only returned once their snooze time has passed.
+func GetNotificationsForUser(s *xorm.Session, notifiableID int64, filter *Filter, limit, start int) (notifications []*DatabaseNotification, resultCount int, total int64, err error) {
+	cond := filter.toCond(notifiableID)
+
 	err = s.
-		Where("notifiable_id = ?", notifiableID).
+		Where(cond).
 		Limit(limit, start).
 		OrderBy("id DESC").
 		Find(&notifications)
@@ -62,11 +113,34 @@ func GetNotificationsForUser(s *xorm.Session, notifiableID int64, limit, start i
 	}
 
 	total, err = s.
-		Where("notifiable_id = ?", notifiableID).
+		Where(cond).
 		Count(&DatabaseNotification{})
 	return notifications, len(notifications), total, err
 }
 
+// GetUnreadNotificationCount returns the number of unread notifications of a user.
+func GetUnreadNotificationCount(s *xorm.Session, notifiableID int64) (count int64, err error) {
+	read := false
+	return s.
+		Where((&Filter{Read: &read}).toCond(notifiableID)).
+		Count(&DatabaseNotification{})
+}
+
+// MarkAllNotificationsAsRead marks all notifications of a user matching the filter as read or unread.
+// It returns the number of changed notifications.
+func MarkAllNotificationsAsRead(s *xorm.Session, notifiableID int64, filter *Filter, read bool) (count int64, err error) {
+	n := &DatabaseNotification{}
+	if read {
+		n.ReadAt = time.Now()
+	}
+
+	return s.
+		Where(filter.toCond(notifiableID)).
+		Cols("read_at").
+		NoAutoCondition().
+		Update(n)
+}
+
 func GetNotificationsForNameAndUser(s *xorm.Session, notifiableID int64, event string, subjectID int64) (notifications []*DatabaseNotification, err error) {
 	notifications = []*DatabaseNotification{}
 	err = s.Where("notifiable_id = ? AND name = ? AND subject_id = ?", notifiableID, event, subjectID).
@@ -97,3 +171,50 @@ func MarkNotificationAsRead(s *xorm.Session, notification *DatabaseNotification,
 		Update(notification)
 	return
 }
+
+// SnoozeNotification hides a notification until the given time. Once that time has passed, the notification shows up
+// again as unread. It should be called only after CanMarkNotificationAsRead has been called.
+func SnoozeNotification(s *xorm.Session, notification *DatabaseNotification, until time.Time) (err error) {
+	notification.SnoozedUntil = until
+	notification.ReadAt = time.Time{}
+
+	_, err = s.
+		Where("id = ?", notification.ID).
+		Cols("snoozed_until", "read_at").
+		Update(notification)
+	return
+}
+
+func deleteOldReadNotifications(s *xorm.Session, olderThan time.Time) (deleted int64, err error) {
+	return s.
+		Where("read_at IS NOT NULL AND read_at < ?", olderThan).
+		Delete(&DatabaseNotification{})
+}
+
+// RegisterOldNotificationCleanupCron registers a cron function to delete all read notifications older than the
+// configured retention period.
+func RegisterOldNotificationCleanupCron() {
+	const logPrefix = "[Notification Cleanup Cron] "
+
+	retention := config.ServiceNotificationRetention.GetInt()
+	if retention <= 0 {
+		return
+	}
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		deleted, err := deleteOldReadNotifications(s, time.Now().Add(time.Hour*24*time.Duration(-retention)))
+		if err != nil {
+			log.Errorf(logPrefix+"Error removing old read notifications: %s", err)
+			return
+		}
+		if deleted > 0 {
+			log.Debugf(logPrefix+"Deleted %d old read notifications", deleted)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register notification cleanup cron: %s", err)
+	}
+}
@@ -78,14 +78,12 @@ func (m *Mail) Line(line string) *Mail {
 	return m
 }
 
-// SendMail passes the notification to the mailing queue for sending
-func SendMail(m *Mail) error {
-	opts, err := RenderMail(m)
+// SendMail renders the mail in the given language and passes it to the mailing queue for sending
+func SendMail(m *Mail, lang string) error {
+	opts, err := RenderMail(m, lang)
 	if err != nil {
 		return err
 	}
 
-	mail.SendMail(opts)
-
-	return nil
+	return mail.SendMail(opts)
 }
@@ -21,9 +21,13 @@ import (
 	"embed"
 	_ "embed"
 	templatehtml "html/template"
+	"os"
+	"path/filepath"
 	templatetext "text/template"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/i18n"
+	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/mail"
 	"code.vikunja.io/api/pkg/utils"
 
@@ -75,7 +79,7 @@ const mailTemplateHTML = `
 
 {{ if .ActionURL }}
 	<p style="color: #9CA3AF;font-size:12px;border-top: 1px solid #dbdbdb;margin-top:20px;padding-top:20px;">
-		If the button above doesn't work, copy the url below and paste it in your browsers address bar:<br/>
+		{{ .CopyURLText }}<br/>
 		{{ .ActionURL }}
 	</p>
 {{ end }}
@@ -89,18 +93,51 @@ const mailTemplateHTML = `
 //go:embed logo.png
 var logo embed.FS
 
-// RenderMail takes a precomposed mail message and renders it into a ready to send mail.Opts object
-func RenderMail(m *Mail) (mailOpts *mail.Opts, err error) {
+const (
+	mailTemplatePlainFile = "mail.txt"
+	mailTemplateHTMLFile  = "mail.html"
+)
+
+// getMailTemplate returns the operator-provided template with the given file name from the configured templates
+// path or the built-in fallback if there is none. A template in a subdirectory named after the language
+// (e.g. <templatespath>/de-DE/mail.html) takes precedence over one in the templates path itself.
+func getMailTemplate(lang, fileName, fallback string) string {
+	templatesPath := config.MailerTemplatesPath.GetString()
+	if templatesPath == "" {
+		return fallback
+	}
+
+	candidates := []string{filepath.Join(templatesPath, fileName)}
+	if lang != "" {
+		candidates = append([]string{filepath.Join(templatesPath, filepath.Base(lang), fileName)}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		content, err := os.ReadFile(candidate)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Errorf("Could not read mail template %s, using the built-in one instead: %s", candidate, err)
+			}
+			continue
+		}
+		return string(content)
+	}
+
+	return fallback
+}
+
+// RenderMail takes a precomposed mail message and renders it in the given language into a ready to send mail.Opts object
+func RenderMail(m *Mail, lang string) (mailOpts *mail.Opts, err error) {
 
 	var htmlContent bytes.Buffer
 	var plainContent bytes.Buffer
 
-	plain, err := templatetext.New("mail-plain").Parse(mailTemplatePlain)
+	plain, err := templatetext.New("mail-plain").Parse(getMailTemplate(lang, mailTemplatePlainFile, mailTemplatePlain))
 	if err != nil {
 		return nil, err
 	}
 
-	html, err := templatehtml.New("mail-plain").Parse(mailTemplateHTML)
+	html, err := templatehtml.New("mail-plain").Parse(getMailTemplate(lang, mailTemplateHTMLFile, mailTemplateHTML))
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +153,9 @@ func RenderMail(m *Mail) (mailOpts *mail.Opts, err error) {
 	data["ActionURL"] = m.actionURL
 	data["Boundary"] = boundary
 	data["FrontendURL"] = config.ServiceFrontendurl.GetString()
+	data["Lang"] = lang
+	//#nosec - the translations are embedded into the binary and therefore trusted
+	data["CopyURLText"] = templatehtml.HTML(i18n.T(lang, "notifications.common.copy_url"))
 
 	var introLinesHTML []templatehtml.HTML
 	for _, line := range m.introLines {
@@ -17,8 +17,12 @@
 package notifications
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"code.vikunja.io/api/pkg/config"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -96,7 +100,7 @@ func TestRenderMail(t *testing.T) {
 		Line("This should be an outro line").
 		Line("And one more, because why not?")
 
-	mailopts, err := RenderMail(mail)
+	mailopts, err := RenderMail(mail, "en")
 	assert.NoError(t, err)
 	assert.Equal(t, mail.from, mailopts.From)
 	assert.Equal(t, mail.to, mailopts.To)
@@ -173,3 +177,34 @@ And one more, because why not?
 </html>
 `, mailopts.HTMLMessage)
 }
+
+func TestRenderMailWithCustomTemplates(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "mail.txt"), []byte("{{ .Greeting }} - custom"), 0600)
+	assert.NoError(t, err)
+	err = os.MkdirAll(filepath.Join(dir, "de-DE"), 0700)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "de-DE", "mail.txt"), []byte("{{ .Greeting }} - angepasst"), 0600)
+	assert.NoError(t, err)
+
+	config.MailerTemplatesPath.Set(dir)
+	defer config.MailerTemplatesPath.Set("")
+
+	mail := NewMail().
+		Subject("Testmail").
+		Greeting("Hi there,").
+		Line("This is a line")
+
+	t.Run("default", func(t *testing.T) {
+		mailopts, err := RenderMail(mail, "en")
+		assert.NoError(t, err)
+		assert.Equal(t, "Hi there, - custom", mailopts.Message)
+		// No custom html template, falls back to the built-in one
+		assert.Contains(t, mailopts.HTMLMessage, "<!doctype html>")
+	})
+	t.Run("language specific", func(t *testing.T) {
+		mailopts, err := RenderMail(mail, "de-DE")
+		assert.NoError(t, err)
+		assert.Equal(t, "Hi there, - angepasst", mailopts.Message)
+	})
+}
@@ -24,7 +24,9 @@ import (
 
 // Notification is a notification which can be sent via mail or db.
 type Notification interface {
-	ToMail() *Mail
+	// ToMail should return the mail message in the given language or nil if the notification should not be sent
+	// via mail.
+	ToMail(lang string) *Mail
 	ToDB() interface{}
 	Name() string
 }
@@ -44,6 +46,9 @@ type Notifiable interface {
 	RouteForMail() (string, error)
 	// Should return the id of the notifiable entity
 	RouteForDB() int64
+	// Should return the language the notifiable wants to receive notifications in.
+	// An empty string means the configured default language is used.
+	Lang() string
 }
 
 // Notify notifies a notifiable of a notification
@@ -62,7 +67,8 @@ func Notify(notifiable Notifiable, notification Notification) (err error) {
 }
 
 func notifyMail(notifiable Notifiable, notification Notification) error {
-	mail := notification.ToMail()
+	lang := notifiable.Lang()
+	mail := notification.ToMail(lang)
 	if mail == nil {
 		return nil
 	}
@@ -73,7 +79,7 @@ func notifyMail(notifiable Notifiable, notification Notification) error {
 	}
 	mail.To(to)
 
-	return SendMail(mail)
+	return SendMail(mail, lang)
 }
 
 func notifyDB(notifiable Notifiable, notification Notification) (err error) {
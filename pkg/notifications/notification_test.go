@@ -18,6 +18,7 @@ package notifications
 
 import (
 	"testing"
+	"time"
 
 	"code.vikunja.io/api/pkg/db"
 	"github.com/stretchr/testify/assert"
@@ -30,7 +31,7 @@ type testNotification struct {
 }
 
 // ToMail returns the mail notification for testNotification
-func (n *testNotification) ToMail() *Mail {
+func (n *testNotification) ToMail(lang string) *Mail {
 	return NewMail().
 		Subject("Test Notification").
 		Line(n.Test)
@@ -62,6 +63,11 @@ func (t *testNotifiable) RouteForDB() int64 {
 	return 42
 }
 
+// Lang returns the language of the test notifiable
+func (t *testNotifiable) Lang() string {
+	return "en"
+}
+
 func TestNotify(t *testing.T) {
 	tn := &testNotification{
 		Test:       "somethingsomething",
@@ -89,3 +95,103 @@ func TestNotify(t *testing.T) {
 
 	db.AssertExists(t, "notifications", vals, true)
 }
+
+func TestSnoozeNotification(t *testing.T) {
+	s := db.NewSession()
+	defer s.Close()
+
+	n := &DatabaseNotification{
+		NotifiableID: 43,
+		Notification: []byte(`{"test":"snooze"}`),
+		Name:         "test.notification",
+	}
+	_, err := s.Insert(n)
+	assert.NoError(t, err)
+
+	notifications, _, total, err := GetNotificationsForUser(s, 43, nil, 50, 0)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, int64(1), total)
+
+	err = SnoozeNotification(s, n, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	notifications, _, total, err = GetNotificationsForUser(s, 43, nil, 50, 0)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 0)
+	assert.Equal(t, int64(0), total)
+
+	err = SnoozeNotification(s, n, time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+
+	notifications, _, _, err = GetNotificationsForUser(s, 43, nil, 50, 0)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 1)
+}
+
+func TestNotificationFilters(t *testing.T) {
+	s := db.NewSession()
+	defer s.Close()
+
+	for _, n := range []*DatabaseNotification{
+		{NotifiableID: 44, Notification: []byte(`{}`), Name: "task.comment", SubjectID: 1},
+		{NotifiableID: 44, Notification: []byte(`{}`), Name: "task.comment", SubjectID: 2},
+		{NotifiableID: 44, Notification: []byte(`{}`), Name: "task.assigned", SubjectID: 1, ReadAt: time.Now().Add(-time.Hour * 24 * 100)},
+	} {
+		_, err := s.Insert(n)
+		assert.NoError(t, err)
+	}
+
+	t.Run("by name", func(t *testing.T) {
+		notifications, _, total, err := GetNotificationsForUser(s, 44, &Filter{Name: "task.comment"}, 50, 0)
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 2)
+		assert.Equal(t, int64(2), total)
+	})
+	t.Run("by subject", func(t *testing.T) {
+		notifications, _, _, err := GetNotificationsForUser(s, 44, &Filter{Name: "task.comment", SubjectID: 2}, 50, 0)
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+	})
+	t.Run("by read state", func(t *testing.T) {
+		read := true
+		notifications, _, _, err := GetNotificationsForUser(s, 44, &Filter{Read: &read}, 50, 0)
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+		assert.Equal(t, "task.assigned", notifications[0].Name)
+
+		count, err := GetUnreadNotificationCount(s, 44)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+	t.Run("mark all as read", func(t *testing.T) {
+		changed, err := MarkAllNotificationsAsRead(s, 44, &Filter{SubjectID: 1}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), changed)
+
+		count, err := GetUnreadNotificationCount(s, 44)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		_, err = MarkAllNotificationsAsRead(s, 44, nil, false)
+		assert.NoError(t, err)
+
+		count, err = GetUnreadNotificationCount(s, 44)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+	t.Run("delete old read notifications", func(t *testing.T) {
+		_, err := s.Where("notifiable_id = ? AND name = ?", 44, "task.assigned").
+			Cols("read_at").
+			Update(&DatabaseNotification{ReadAt: time.Now().Add(-time.Hour * 24 * 100)})
+		assert.NoError(t, err)
+
+		deleted, err := deleteOldReadNotifications(s, time.Now().Add(-time.Hour*24*90))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		_, _, total, err := GetNotificationsForUser(s, 44, nil, 50, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+	})
+}
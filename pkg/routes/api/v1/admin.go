@@ -0,0 +1,161 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// AdminPasswordReset holds the new password of a user when an admin resets it.
+type AdminPasswordReset struct {
+	// The new password of the user. If empty, the user gets an email with a link to reset their password instead.
+	NewPassword string `json:"new_password"`
+}
+
+// AdminRegistrationSetting holds whether registration is enabled.
+type AdminRegistrationSetting struct {
+	Enabled bool `json:"enabled"`
+}
+
+// AdminResetUserPassword is the handler to reset the password of a user as an admin
+// @Summary Reset the password of a user
+// @Description Sets a new password for a local user. If no new password is provided, the user gets an email with a link to reset their password instead. Changing the password revokes all sessions of the user. Only instance admins can do this, the reset is recorded in the audit log.
+// @tags admin
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "User ID"
+// @Param reset body v1.AdminPasswordReset true "The new password."
+// @Success 200 {object} models.Message
+// @Failure 403 {object} web.HTTPError "Only instance admins can do this."
+// @Failure 404 {object} web.HTTPError "The user does not exist."
+// @Failure 412 {object} web.HTTPError "The user is not a local user."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /admin/users/{id}/password [post]
+func AdminResetUserPassword(c echo.Context) error {
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	userID, err := strconv.ParseInt(c.Param("user"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid user id."})
+	}
+
+	reset := &AdminPasswordReset{}
+	if err := c.Bind(reset); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "No or invalid password model provided."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = models.ResetUserPassword(s, a, userID, reset.NewPassword)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if reset.NewPassword == "" {
+		return c.JSON(http.StatusOK, models.Message{Message: "The user got an email to reset their password."})
+	}
+	return c.JSON(http.StatusOK, models.Message{Message: "The password was updated successfully."})
+}
+
+// AdminInstanceStats is the handler to get statistics about this instance
+// @Summary Get instance statistics
+// @Description Returns how many users, lists, namespaces, tasks, teams and files this instance has. If metrics are enabled, the numbers come from the metrics counters. Only instance admins can see this.
+// @tags admin
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} models.InstanceStats
+// @Failure 403 {object} web.HTTPError "Only instance admins can see this."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /admin/stats [get]
+func AdminInstanceStats(c echo.Context) error {
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	stats, err := models.GetInstanceStats(s, a)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// AdminSetRegistration is the handler to enable or disable registration
+// @Summary Enable or disable registration
+// @Description Enables or disables registration of new users at runtime, overriding the `service.enableregistration` config option. Only instance admins can do this, the change is recorded in the audit log.
+// @tags admin
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param registration body v1.AdminRegistrationSetting true "Whether registration should be enabled."
+// @Success 200 {object} v1.AdminRegistrationSetting
+// @Failure 403 {object} web.HTTPError "Only instance admins can do this."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /admin/registration [post]
+func AdminSetRegistration(c echo.Context) error {
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	setting := &AdminRegistrationSetting{}
+	if err := c.Bind(setting); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "No or invalid registration setting provided."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = models.SetRegistrationEnabled(s, a, setting.Enabled)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, setting)
+}
@@ -20,6 +20,7 @@ import (
 	"net/http"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/modules/auth/openid"
 	microsofttodo "code.vikunja.io/api/pkg/modules/migration/microsoft-todo"
@@ -28,6 +29,7 @@ import (
 	"code.vikunja.io/api/pkg/modules/migration/trello"
 	vikunja_file "code.vikunja.io/api/pkg/modules/migration/vikunja-file"
 	"code.vikunja.io/api/pkg/modules/migration/wunderlist"
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/version"
 
 	"github.com/labstack/echo/v4"
@@ -44,6 +46,7 @@ type vikunjaInfos struct {
 	TaskAttachmentsEnabled     bool      `json:"task_attachments_enabled"`
 	EnabledBackgroundProviders []string  `json:"enabled_background_providers"`
 	TotpEnabled                bool      `json:"totp_enabled"`
+	SecondFactorRequired       bool      `json:"second_factor_required"`
 	Legal                      legalInfo `json:"legal"`
 	CaldavEnabled              bool      `json:"caldav_enabled"`
 	AuthInfo                   authInfo  `json:"auth"`
@@ -55,6 +58,17 @@ type vikunjaInfos struct {
 type authInfo struct {
 	Local         localAuthInfo  `json:"local"`
 	OpenIDConnect openIDAuthInfo `json:"openid_connect"`
+	LDAP          ldapAuthInfo   `json:"ldap"`
+	WebAuthn      webAuthnInfo   `json:"webauthn"`
+}
+
+type webAuthnInfo struct {
+	Enabled      bool `json:"enabled"`
+	Passwordless bool `json:"passwordless"`
+}
+
+type ldapAuthInfo struct {
+	Enabled bool `json:"enabled"`
 }
 
 type localAuthInfo struct {
@@ -89,6 +103,7 @@ func Info(c echo.Context) error {
 		RegistrationEnabled:    config.ServiceEnableRegistration.GetBool(),
 		TaskAttachmentsEnabled: config.ServiceEnableTaskAttachments.GetBool(),
 		TotpEnabled:            config.ServiceEnableTotp.GetBool(),
+		SecondFactorRequired:   config.ServiceRequireSecondFactor.GetBool(),
 		CaldavEnabled:          config.ServiceEnableCaldav.GetBool(),
 		EmailRemindersEnabled:  config.ServiceEnableEmailReminders.GetBool(),
 		UserDeletionEnabled:    config.ServiceEnableUserDeletion.GetBool(),
@@ -109,6 +124,13 @@ func Info(c echo.Context) error {
 				Enabled:     config.AuthOpenIDEnabled.GetBool(),
 				RedirectURL: config.AuthOpenIDRedirectURL.GetString(),
 			},
+			LDAP: ldapAuthInfo{
+				Enabled: config.AuthLDAPEnabled.GetBool(),
+			},
+			WebAuthn: webAuthnInfo{
+				Enabled:      config.AuthWebAuthnEnabled.GetBool(),
+				Passwordless: config.AuthWebAuthnEnabled.GetBool() && config.AuthWebAuthnPasswordless.GetBool(),
+			},
 		},
 	}
 
@@ -120,6 +142,14 @@ func Info(c echo.Context) error {
 
 	info.AuthInfo.OpenIDConnect.Providers = providers
 
+	s := db.NewSession()
+	defer s.Close()
+	info.RegistrationEnabled, err = user.IsRegistrationEnabled(s)
+	if err != nil {
+		log.Errorf("Error while getting the registration setting for /info: %s", err)
+		info.RegistrationEnabled = config.ServiceEnableRegistration.GetBool()
+	}
+
 	// Migrators
 	if config.MigrationWunderlistEnable.GetBool() {
 		m := &wunderlist.Migration{}
@@ -50,6 +50,7 @@ type LinkShareAuth struct {
 // @Param share path string true "The share hash"
 // @Success 200 {object} auth.Token "The valid jwt auth token."
 // @Failure 400 {object} web.HTTPError "Invalid link share object provided."
+// @Failure 403 {object} web.HTTPError "The link share is disabled, expired, used up or cannot be used from this ip address."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /shares/{share}/auth [post]
 func AuthenticateLinkShare(c echo.Context) error {
@@ -67,11 +68,22 @@ func AuthenticateLinkShare(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
-	if share.SharingType == models.SharingTypeWithPassword {
-		err := models.VerifyLinkSharePassword(share, sh.Password)
-		if err != nil {
-			return handler.HandleHTTPError(err, c)
-		}
+	useErr := models.UseLinkShare(s, share, sh.Password, c.RealIP())
+
+	// Every attempt ends up in the access log, failed ones too
+	err = models.LogLinkShareAccess(s, share, c.RealIP(), c.Request().UserAgent(), useErr == nil)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if useErr != nil {
+		return handler.HandleHTTPError(useErr, c)
 	}
 
 	t, err := auth.NewLinkShareJWTAuthtoken(share)
@@ -17,30 +17,38 @@
 package v1
 
 import (
+	"bytes"
 	"net/http"
 
 	"code.vikunja.io/api/pkg/modules/keyvalue"
 
+	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/modules/auth/ldap"
 	user2 "code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web/handler"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+	"xorm.io/xorm"
 )
 
 // Login is the login handler
 // @Summary Login
 // @Description Logs a user in. Returns a JWT-Token to authenticate further requests.
+// @Description If ldap authentication is enabled, the credentials are checked against the ldap server first.
+// @Description If the user registered a webauthn credential, the response to a challenge from /login/webauthn needs to be provided. If only the webauthn response is provided without a username and password, the user is logged in with a passkey.
+// @Description If the instance requires a second factor and the user has not set one up yet, the returned token has `second_factor_enrollment` set and can only be used to set up totp or a webauthn credential.
 // @tags user
 // @Accept json
 // @Produce json
 // @Param credentials body user.Login true "The login credentials"
 // @Success 200 {object} auth.Token
 // @Failure 400 {object} models.Message "Invalid user password model."
-// @Failure 412 {object} models.Message "Invalid totp passcode."
+// @Failure 412 {object} models.Message "Invalid totp passcode, webauthn response or recovery code."
 // @Failure 403 {object} models.Message "Invalid username or password."
 // @Router /login [post]
 func Login(c echo.Context) error {
@@ -52,8 +60,16 @@ func Login(c echo.Context) error {
 	s := db.NewSession()
 	defer s.Close()
 
-	// Check user
-	user, err := user2.CheckUserCredentials(s, &u)
+	var user *user2.User
+	var err error
+	passwordless := u.Username == "" && u.Password == "" && len(u.WebAuthn) > 0 &&
+		config.AuthWebAuthnEnabled.GetBool() && config.AuthWebAuthnPasswordless.GetBool()
+	if passwordless {
+		// A passkey is both possession and knowledge or inherence, it does not need a second factor
+		user, err = user2.FinishPasswordlessWebAuthnLogin(s, bytes.NewReader(u.WebAuthn))
+	} else {
+		user, err = checkLoginCredentials(s, &u)
+	}
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -64,26 +80,10 @@ func Login(c echo.Context) error {
 		return handler.HandleHTTPError(&user2.ErrAccountDisabled{UserID: user.ID}, c)
 	}
 
-	totpEnabled, err := user2.TOTPEnabledForUser(s, user)
-	if err != nil {
-		_ = s.Rollback()
-		return handler.HandleHTTPError(err, c)
-	}
-
-	if totpEnabled {
-		if u.TOTPPasscode == "" {
-			_ = s.Rollback()
-			return handler.HandleHTTPError(user2.ErrInvalidTOTPPasscode{}, c)
-		}
-
-		_, err = user2.ValidateTOTPPasscode(s, &user2.TOTPPasscode{
-			User:     user,
-			Passcode: u.TOTPPasscode,
-		})
+	var needsSecondFactor bool
+	if !passwordless {
+		needsSecondFactor, err = checkSecondFactor(s, user, &u)
 		if err != nil {
-			if user2.IsErrInvalidTOTPPasscode(err) {
-				user2.HandleFailedTOTPAuth(s, user)
-			}
 			_ = s.Rollback()
 			return handler.HandleHTTPError(err, c)
 		}
@@ -101,10 +101,127 @@ func Login(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	if needsSecondFactor {
+		t, err := auth.NewSecondFactorEnrollmentJWTAuthtoken(user)
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+		return c.JSON(http.StatusOK, auth.Token{Token: t, SecondFactorEnrollment: true})
+	}
+
 	// Create token
 	return auth.NewUserAuthTokenResponse(user, c, u.LongToken)
 }
 
+// checkLoginCredentials checks the username and password of a user against all enabled auth providers.
+func checkLoginCredentials(s *xorm.Session, u *user2.Login) (user *user2.User, err error) {
+	if config.AuthLDAPEnabled.GetBool() {
+		user, err = ldap.AuthenticateUserInLDAP(s, u.Username, u.Password)
+		if err != nil && !user2.IsErrWrongUsernameOrPassword(err) {
+			log.Errorf("Error authenticating user %s against ldap: %s", u.Username, err)
+		}
+	}
+	if user == nil && config.AuthLocalEnabled.GetBool() {
+		user, err = user2.CheckUserCredentials(s, u)
+	}
+	return
+}
+
+// checkSecondFactor makes sure a user provided a valid second factor if they enabled one. If the instance requires
+// a second factor and the user has none yet, needsSecondFactor is true.
+func checkSecondFactor(s *xorm.Session, user *user2.User, u *user2.Login) (needsSecondFactor bool, err error) {
+	totpEnabled, err := user2.TOTPEnabledForUser(s, user)
+	if err != nil {
+		return false, err
+	}
+	webAuthnEnabled, err := user2.WebAuthnEnabledForUser(s, user)
+	if err != nil {
+		return false, err
+	}
+
+	if !totpEnabled && !webAuthnEnabled {
+		return config.ServiceRequireSecondFactor.GetBool(), nil
+	}
+
+	if u.RecoveryCode != "" {
+		return false, user2.UseRecoveryCode(s, user, u.RecoveryCode)
+	}
+
+	if webAuthnEnabled && len(u.WebAuthn) > 0 {
+		return false, user2.ValidateWebAuthnLogin(s, user, bytes.NewReader(u.WebAuthn))
+	}
+
+	if !totpEnabled {
+		return false, &user2.ErrWebAuthnRequired{}
+	}
+
+	if u.TOTPPasscode == "" {
+		return false, user2.ErrInvalidTOTPPasscode{}
+	}
+
+	err = user2.ValidateTOTPPasscodeOrRecoveryCode(s, &user2.TOTPPasscode{
+		User:     user,
+		Passcode: u.TOTPPasscode,
+	})
+	if err != nil && user2.IsErrInvalidTOTPPasscode(err) {
+		user2.HandleFailedTOTPAuth(s, user)
+	}
+	return false, err
+}
+
+// WebAuthnLoginOptions returns a webauthn challenge to log in with
+// @Summary Request a webauthn login challenge
+// @Description Returns the options which need to be passed to `navigator.credentials.get()` to log in with a webauthn credential.
+// @Description If a username and password are provided, the challenge is for the webauthn credentials of that user as a second factor. Otherwise, the challenge can be answered with any passkey to log in without a password.
+// @tags user
+// @Accept json
+// @Produce json
+// @Param credentials body user.Login false "The login credentials. Leave empty to log in with a passkey."
+// @Success 200 {object} protocol.CredentialAssertion
+// @Failure 400 {object} models.Message "Invalid user password model."
+// @Failure 403 {object} models.Message "Invalid username or password."
+// @Failure 412 {object} web.HTTPError "The user has not registered any webauthn credentials."
+// @Router /login/webauthn [post]
+func WebAuthnLoginOptions(c echo.Context) error {
+	u := user2.Login{}
+	if err := c.Bind(&u); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Please provide a username and password."})
+	}
+
+	if u.Username == "" && u.Password == "" {
+		if !config.AuthWebAuthnPasswordless.GetBool() {
+			return handler.HandleHTTPError(user2.ErrNoUsernamePassword{}, c)
+		}
+
+		options, err := user2.BeginPasswordlessWebAuthnLogin()
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+		return c.JSON(http.StatusOK, options)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	user, err := checkLoginCredentials(s, &u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	options, err := user2.BeginWebAuthnLogin(s, user)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, options)
+}
+
 // RenewToken gives a new token to every user with a valid token
 // If the token is valid is checked in the middleware.
 // @Summary Renew user token
@@ -151,17 +268,67 @@ func RenewToken(c echo.Context) (err error) {
 		return handler.HandleHTTPError(err, c)
 	}
 
-	if err := s.Commit(); err != nil {
+	session, err := user2.ValidateSession(s, auth.GetSessionIDFromClaims(c), user.ID)
+	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
 	}
 
-	var long bool
-	lng, has := claims["long"]
-	if has {
-		long = lng.(bool)
+	err = user2.ExtendSession(s, session)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
 	}
 
 	// Create token
-	return auth.NewUserAuthTokenResponse(user, c, long)
+	t, err := auth.NewUserJWTAuthtoken(user, session)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+	return c.JSON(http.StatusOK, auth.Token{Token: t})
+}
+
+// RefreshToken exchanges a refresh token for a new token
+// @Summary Refresh user token
+// @Description Returns a new jwt user token and a new refresh token for the session the provided refresh token belongs to. The old refresh token becomes invalid. Use this to keep a user logged in after their token expired.
+// @tags user
+// @Accept json
+// @Produce json
+// @Param token body v1.RefreshTokenRequest true "The refresh token"
+// @Success 200 {object} auth.Token
+// @Failure 400 {object} models.Message "No refresh token provided."
+// @Failure 401 {object} web.HTTPError "The refresh token is invalid or expired."
+// @Failure 412 {object} web.HTTPError "The user account is disabled."
+// @Router /user/token/refresh [post]
+func RefreshToken(c echo.Context) (err error) {
+	r := &RefreshTokenRequest{}
+	if err := c.Bind(r); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Please provide a refresh token."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	session, u, err := user2.RefreshSession(s, r.RefreshToken)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return auth.NewUserAuthTokenResponseForSession(u, session, c)
+}
+
+// RefreshTokenRequest holds the refresh token to exchange for a new token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
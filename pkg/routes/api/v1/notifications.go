@@ -0,0 +1,106 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// NotificationCount holds the number of unread notifications
+type NotificationCount struct {
+	Unread int64 `json:"unread"`
+}
+
+// MarkAllNotificationsAsRead is the handler to mark multiple notifications as read or unread at once
+// @Summary Mark all notifications of the current user as (un-)read
+// @Description Marks all notifications of the current user as either read or unread. If a name or subject is provided, only matching notifications are changed.
+// @tags subscriptions
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param bulk body models.DatabaseNotificationsBulk true "Which notifications to mark as read or unread."
+// @Success 200 {object} models.Message "The notifications were updated successfully."
+// @Failure 400 {object} web.HTTPError "Invalid bulk options provided."
+// @Failure 403 {object} web.HTTPError "Link shares cannot have notifications."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /notifications [post]
+func MarkAllNotificationsAsRead(c echo.Context) error {
+	bulk := &models.DatabaseNotificationsBulk{}
+	if err := c.Bind(bulk); err != nil {
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid model provided. Error was: %s", he.Message))
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid model provided.")
+	}
+
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	count, err := models.MarkAllNotificationsAsRead(s, a, bulk)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, &models.Message{Message: fmt.Sprintf("%d notifications were updated successfully.", count)})
+}
+
+// GetUnreadNotificationCount is the handler to return the number of unread notifications
+// @Summary Get the number of unread notifications
+// @Description Returns the number of unread notifications of the current user.
+// @tags subscriptions
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} v1.NotificationCount "The number of unread notifications."
+// @Failure 403 {object} web.HTTPError "Link shares cannot have notifications."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /notifications/unread [get]
+func GetUnreadNotificationCount(c echo.Context) error {
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	count, err := models.GetUnreadNotificationCount(s, a)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, &NotificationCount{Unread: count})
+}
@@ -0,0 +1,161 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+const publicViewTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; margin: 1rem; color: #222; }
+h1 { font-size: 1.5rem; }
+.board { display: flex; gap: 1rem; align-items: flex-start; overflow-x: auto; }
+.bucket { background: #f4f5f7; border-radius: 4px; padding: .5rem; min-width: 16rem; }
+.bucket h2 { font-size: 1rem; margin: .25rem 0 .5rem; }
+ul { list-style: none; padding: 0; margin: 0; }
+li { background: #fff; border: 1px solid #ddd; border-radius: 4px; padding: .5rem; margin-bottom: .5rem; }
+.done { text-decoration: line-through; color: #888; }
+.identifier { color: #888; margin-right: .25rem; }
+.label { display: inline-block; border-radius: 3px; padding: 0 .25rem; font-size: .75rem; margin-right: .25rem; background: #e2e2e2; }
+.meta { font-size: .75rem; color: #666; margin-top: .25rem; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+{{ define "tasks" }}<ul>{{ range . }}
+<li><span class="identifier">{{ .Identifier }}</span><span{{ if .Done }} class="done"{{ end }}>{{ .Title }}</span>
+{{ if .Labels }}<div>{{ range .Labels }}<span class="label">{{ .Title }}</span>{{ end }}</div>{{ end }}
+{{ if or .Assignees (not .DueDate.IsZero) }}<div class="meta">{{ range .Assignees }}{{ .Name }} {{ end }}{{ if not .DueDate.IsZero }}Due {{ .DueDate.Format "2006-01-02" }}{{ end }}</div>{{ end }}
+</li>{{ end }}
+</ul>{{ end }}
+{{ if .Buckets }}<div class="board">{{ range .Buckets }}
+<div class="bucket"><h2>{{ .Title }}</h2>{{ template "tasks" .Tasks }}</div>{{ end }}
+</div>{{ else }}{{ template "tasks" .Tasks }}{{ end }}
+</body>
+</html>
+`
+
+var publicViewHTML = template.Must(template.New("public").Parse(publicViewTemplate))
+
+func getPublicListView(c echo.Context) (*models.PublicListView, error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	view, err := models.GetPublicListView(s, c.Param("share"), c.RealIP(), c.QueryParam("view") == "kanban")
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	if err := s.Commit(); err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// writeCacheable sends a response which clients and proxies can cache. Views of link shares with an ip allowlist
+// may only be cached by the client since a proxy would serve them to everyone. If the client already has the current
+// version, only a 304 is sent.
+func writeCacheable(c echo.Context, view *models.PublicListView, contentType string, body []byte) error {
+	cacheability := "public"
+	if view.IsIPRestricted {
+		cacheability = "private"
+	}
+
+	etag := `"` + utils.Sha256(string(body)) + `"`
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Set("Cache-Control", cacheability+", max-age="+strconv.FormatInt(config.ServicePublicViewCacheTTL.GetInt64(), 10))
+
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.Blob(http.StatusOK, contentType, body)
+}
+
+// PublicListView returns the public read-only view of a list
+// @Summary Get the public view of a list
+// @Description Returns a read-only view of the list of a public link share. No authentication is needed. The link share needs to be public, enabled, not expired and allow the ip address of the client. Assignees only contain their username and name. The response may be cached for the configured public view cache ttl, so changes to the list or share can take that long to show up everywhere. Views of link shares with an ip allowlist are only cached by the client.
+// @tags sharing
+// @Produce json
+// @Param share path string true "The share hash"
+// @Param view query string false "Set to `kanban` to get the tasks grouped by their kanban buckets."
+// @Success 200 {object} models.PublicListView "The public list view."
+// @Success 304 "The client already has the current version."
+// @Failure 403 {object} web.HTTPError "The link share is disabled, expired or cannot be used from this ip address."
+// @Failure 404 {object} web.HTTPError "The link share does not exist or is not public."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /shares/{share}/public [get]
+func PublicListView(c echo.Context) error {
+	view, err := getPublicListView(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	body, err := json.Marshal(view)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return writeCacheable(c, view, echo.MIMEApplicationJSONCharsetUTF8, body)
+}
+
+// PublicListViewHTML returns the public read-only view of a list as html
+// @Summary Get the public view of a list as html
+// @Description Returns a server-rendered, read-only view of the list of a public link share which can be embedded in other pages. No authentication is needed. The same restrictions and caching rules as for the json view apply.
+// @tags sharing
+// @Produce html
+// @Param share path string true "The share hash"
+// @Param view query string false "Set to `kanban` to render a kanban board."
+// @Success 200 {string} string "The rendered list."
+// @Success 304 "The client already has the current version."
+// @Failure 403 {object} web.HTTPError "The link share is disabled, expired or cannot be used from this ip address."
+// @Failure 404 {object} web.HTTPError "The link share does not exist or is not public."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /shares/{share}/public/html [get]
+func PublicListViewHTML(c echo.Context) error {
+	view, err := getPublicListView(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	var body bytes.Buffer
+	err = publicViewHTML.Execute(&body, view)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return writeCacheable(c, view, echo.MIMETextHTMLCharsetUTF8, body.Bytes())
+}
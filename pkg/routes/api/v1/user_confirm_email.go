@@ -48,7 +48,14 @@ func UserConfirmEmail(c echo.Context) error {
 	s := db.NewSession()
 	defer s.Close()
 
-	err := user.ConfirmEmail(s, &emailConfirm)
+	u, err := user.ConfirmEmail(s, &emailConfirm)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	// Now that we know the email address belongs to the user, share everything they were invited to
+	err = models.ApplyShareInvitations(s, u)
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
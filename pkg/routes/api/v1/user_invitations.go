@@ -0,0 +1,143 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// GetUserInvitations is the handler to list all invitations of the current user
+// @Summary Get all invitations
+// @Description Returns all invitations the current user created. The invitation tokens are only shown once after creating an invitation.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} user.Invitation
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/invitations [get]
+func GetUserInvitations(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	invitations, err := user.GetInvitationsForUser(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, invitations)
+}
+
+// CreateUserInvitation is the handler to create a new invitation
+// @Summary Create an invitation
+// @Description Creates a new invitation which allows people to register, even if registration is disabled. Invitations are single use by default, set `max_uses` to 0 for unlimited uses. If user invitations are disabled, only instance admins can create invitations. The token is only returned once.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param invitation body user.Invitation true "The invitation."
+// @Success 201 {object} user.Invitation
+// @Failure 400 {object} web.HTTPError "Invalid invitation object provided."
+// @Failure 403 {object} web.HTTPError "Only instance admins can create invitations."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/invitations [put]
+func CreateUserInvitation(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	invitation := &user.Invitation{MaxUses: 1}
+	if err := c.Bind(invitation); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "No or invalid invitation model provided."})
+	}
+	if err := c.Validate(invitation); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = user.CreateInvitation(s, u, invitation)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusCreated, invitation)
+}
+
+// DeleteUserInvitation is the handler to delete an invitation
+// @Summary Delete an invitation
+// @Description Deletes an invitation of the current user. The invitation cannot be used anymore. Instance admins can delete all invitations.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} models.Message
+// @Failure 404 {object} web.HTTPError "The invitation does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/invitations/{id} [delete]
+func DeleteUserInvitation(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid invitation id."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = user.DeleteInvitation(s, u, id)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, &models.Message{Message: "The invitation was deleted successfully."})
+}
@@ -0,0 +1,96 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// RecoveryCodeCount holds how many recovery codes a user has left.
+type RecoveryCodeCount struct {
+	Remaining int64 `json:"remaining"`
+}
+
+// UserRecoveryCodeCount returns how many unused recovery codes the current user has left.
+// @Summary Get the number of remaining recovery codes
+// @Description Returns how many unused recovery codes the current user has left. The codes themselves are only shown once after generating them.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} v1.RecoveryCodeCount
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/recoverycodes [get]
+func UserRecoveryCodeCount(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	count, err := user.GetRecoveryCodeCount(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, &RecoveryCodeCount{Remaining: count})
+}
+
+// UserRecoveryCodesGenerate generates new recovery codes for the current user.
+// @Summary Generate new recovery codes
+// @Description Replaces all recovery codes of the current user with new ones. The old codes cannot be used anymore. The new codes are only shown once.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} user.RecoveryCodes
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/recoverycodes [post]
+func UserRecoveryCodesGenerate(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	codes, err := user.GenerateRecoveryCodes(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, codes)
+}
@@ -21,7 +21,6 @@ import (
 
 	"code.vikunja.io/api/pkg/db"
 
-	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web/handler"
@@ -30,19 +29,17 @@ import (
 
 // RegisterUser is the register handler
 // @Summary Register
-// @Description Creates a new user account.
+// @Description Creates a new user account. If registration is disabled, an invitation token is required.
 // @tags user
 // @Accept json
 // @Produce json
 // @Param credentials body user.APIUserPassword true "The user credentials"
 // @Success 200 {object} user.User
 // @Failure 400 {object} web.HTTPError "No or invalid user register object provided / User already exists."
+// @Failure 412 {object} web.HTTPError "The invitation is invalid or the email domain is not allowed."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /register [post]
 func RegisterUser(c echo.Context) error {
-	if !config.ServiceEnableRegistration.GetBool() {
-		return echo.ErrNotFound
-	}
 	// Check for Request Content
 	var datUser *user.APIUserPassword
 	if err := c.Bind(&datUser); err != nil {
@@ -55,8 +52,28 @@ func RegisterUser(c echo.Context) error {
 	s := db.NewSession()
 	defer s.Close()
 
+	// Invited users can always register
+	if datUser.InvitationToken == "" {
+		registrationEnabled, err := user.IsRegistrationEnabled(s)
+		if err != nil {
+			_ = s.Rollback()
+			return handler.HandleHTTPError(err, c)
+		}
+		if !registrationEnabled {
+			_ = s.Rollback()
+			return echo.ErrNotFound
+		}
+	}
+
 	// Insert the user
-	newUser, err := user.CreateUser(s, datUser.APIFormat())
+	var newUser *user.User
+	var emailConfirmed bool
+	var err error
+	if datUser.InvitationToken != "" {
+		newUser, emailConfirmed, err = user.CreateUserWithInvitation(s, datUser.APIFormat(), datUser.InvitationToken)
+	} else {
+		newUser, err = user.RegisterUser(s, datUser.APIFormat())
+	}
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -69,6 +86,16 @@ func RegisterUser(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	// Only an invitation sent to the user's email address proves it belongs to them. Everyone else
+	// gets the shares they were invited to once they confirmed their email address.
+	if emailConfirmed {
+		err = models.ApplyShareInvitations(s, newUser)
+		if err != nil {
+			_ = s.Rollback()
+			return handler.HandleHTTPError(err, c)
+		}
+	}
+
 	if err := s.Commit(); err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
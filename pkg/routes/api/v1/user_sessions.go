@@ -0,0 +1,120 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+func getUserForSessions(c echo.Context) (*user.User, error) {
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return nil, err
+	}
+
+	u, is := a.(*user.User)
+	if !is {
+		return nil, echo.NewHTTPError(http.StatusForbidden, models.Message{Message: "Only users can manage sessions."})
+	}
+	return u, nil
+}
+
+// GetUserSessions is the handler to return all active sessions of the current user
+// @Summary Returns the sessions of the current user
+// @Description Returns all active sessions of the current user with the device, ip address and the last time they were used. The session used to make the request is marked with `is_current`.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} user.Session
+// @Failure 403 {object} web.HTTPError "Link shares cannot have sessions."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/sessions [get]
+func GetUserSessions(c echo.Context) error {
+	u, err := getUserForSessions(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	sessions, err := user.GetSessionsForUser(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	currentSessionID := auth.GetSessionIDFromClaims(c)
+	for _, session := range sessions {
+		session.IsCurrent = session.ID == currentSessionID
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// DeleteUserSession is the handler to revoke a session of the current user
+// @Summary Revoke a session
+// @Description Revokes a session of the current user. All tokens issued for this session become invalid immediately. Revoking the current session logs the user out.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} models.Message
+// @Failure 403 {object} web.HTTPError "Link shares cannot have sessions."
+// @Failure 404 {object} web.HTTPError "The session does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/sessions/{id} [delete]
+func DeleteUserSession(c echo.Context) error {
+	u, err := getUserForSessions(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid session id."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = user.DeleteSession(s, u, id)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, &models.Message{Message: "The session was revoked successfully."})
+}
@@ -69,13 +69,13 @@ func UserTOTPEnroll(c echo.Context) error {
 
 // UserTOTPEnable is the handler to enable totp for a user
 // @Summary Enable a previously enrolled totp setting.
-// @Description Enables a previously enrolled totp setting by providing a totp passcode.
+// @Description Enables a previously enrolled totp setting by providing a totp passcode. If the user does not have recovery codes yet, the response contains new ones which are only shown once.
 // @tags user
 // @Accept json
 // @Produce json
 // @Param totp body user.TOTPPasscode true "The totp passcode."
 // @Security JWTKeyAuth
-// @Success 200 {object} models.Message "Successfully enabled"
+// @Success 200 {object} v1.TOTPEnabled "Successfully enabled"
 // @Failure 400 {object} web.HTTPError "Something's invalid."
 // @Failure 404 {object} web.HTTPError "User does not exist."
 // @Failure 412 {object} web.HTTPError "TOTP is not enrolled."
@@ -102,7 +102,7 @@ func UserTOTPEnable(c echo.Context) error {
 	s := db.NewSession()
 	defer s.Close()
 
-	err = user.EnableTOTP(s, passcode)
+	codes, err := user.EnableTOTP(s, passcode)
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -113,7 +113,18 @@ func UserTOTPEnable(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
-	return c.JSON(http.StatusOK, models.Message{Message: "TOTP was enabled successfully."})
+	enabled := &TOTPEnabled{Message: models.Message{Message: "TOTP was enabled successfully."}}
+	if codes != nil {
+		enabled.RecoveryCodes = codes.Codes
+	}
+	return c.JSON(http.StatusOK, enabled)
+}
+
+// TOTPEnabled is the response after enabling totp.
+type TOTPEnabled struct {
+	models.Message
+	// Only set if the user did not have any recovery codes yet. The codes are only shown once.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 // UserTOTPDisable disables totp settings for the current user.
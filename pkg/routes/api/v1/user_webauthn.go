@@ -0,0 +1,195 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// WebAuthnCredentialRegistration holds the response of an authenticator to a registration challenge.
+type WebAuthnCredentialRegistration struct {
+	// A name to recognize the credential later, for example "Yubikey" or "Phone".
+	Name string `json:"name"`
+	// The credential as returned by `navigator.credentials.create()`.
+	Credential json.RawMessage `json:"credential"`
+}
+
+// UserWebAuthnCredentials returns all webauthn credentials of the current user.
+// @Summary Get all webauthn credentials
+// @Description Returns all security keys and passkeys the current user registered.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} user.WebAuthnCredential
+// @Failure 404 {object} web.HTTPError "User does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn [get]
+func UserWebAuthnCredentials(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	credentials, err := user.GetWebAuthnCredentials(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, credentials)
+}
+
+// UserWebAuthnEnroll starts registering a new webauthn credential.
+// @Summary Start registering a webauthn credential
+// @Description Returns the options which need to be passed to `navigator.credentials.create()` to register a new security key or passkey. The response of the authenticator then needs to be sent to the "enable webauthn" endpoint.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} protocol.CredentialCreation
+// @Failure 404 {object} web.HTTPError "User does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/enroll [post]
+func UserWebAuthnEnroll(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	options, err := user.BeginWebAuthnRegistration(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, options)
+}
+
+// UserWebAuthnEnable finishes registering a new webauthn credential.
+// @Summary Finish registering a webauthn credential
+// @Description Verifies the response of the authenticator to a challenge from the "enroll webauthn" endpoint and saves the credential. If this is the first second factor of the user, the response contains recovery codes which are only shown once.
+// @tags user
+// @Accept json
+// @Produce json
+// @Param credential body v1.WebAuthnCredentialRegistration true "The name and the response of the authenticator."
+// @Security JWTKeyAuth
+// @Success 200 {object} user.WebAuthnRegistration
+// @Failure 400 {object} web.HTTPError "Something's invalid."
+// @Failure 412 {object} web.HTTPError "The webauthn response is invalid or the name is empty."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/enable [post]
+func UserWebAuthnEnable(c echo.Context) error {
+	registration := &WebAuthnCredentialRegistration{}
+	if err := c.Bind(registration); err != nil {
+		log.Debugf("Invalid model error. Internal error was: %s", err.Error())
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid model provided. Error was: %s", he.Message))
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid model provided.")
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	result, err := user.FinishWebAuthnRegistration(s, u, registration.Name, bytes.NewReader(registration.Credential))
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// UserWebAuthnDelete removes a webauthn credential of the current user.
+// @Summary Delete a webauthn credential
+// @Description Removes a security key or passkey of the current user. If it was their last second factor, their recovery codes are removed as well.
+// @tags user
+// @Accept json
+// @Produce json
+// @Param id path int true "Credential ID"
+// @Security JWTKeyAuth
+// @Success 200 {object} models.Message "Successfully deleted"
+// @Failure 404 {object} web.HTTPError "The credential does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/{id} [delete]
+func UserWebAuthnDelete(c echo.Context) error {
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid credential id."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = user.DeleteWebAuthnCredential(s, u, id)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "The webauthn credential was deleted successfully."})
+}
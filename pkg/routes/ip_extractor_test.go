@@ -0,0 +1,42 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package routes
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIPExtractor(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+
+	t.Run("no trusted proxies", func(t *testing.T) {
+		assert.Equal(t, "10.0.0.5", getIPExtractor(nil)(req))
+	})
+	t.Run("request from a trusted proxy", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.7", getIPExtractor([]string{"10.0.0.0/24"})(req))
+		assert.Equal(t, "203.0.113.7", getIPExtractor([]string{"10.0.0.5"})(req))
+	})
+	t.Run("request from an untrusted address", func(t *testing.T) {
+		assert.Equal(t, "10.0.0.5", getIPExtractor([]string{"192.168.1.1"})(req))
+	})
+}
@@ -147,6 +147,8 @@ func NewEcho() *echo.Echo {
 		}
 	}
 
+	e.IPExtractor = getIPExtractor(config.ServiceTrustedProxies.GetStringSlice())
+
 	// Validation
 	e.Validator = &CustomValidator{}
 
@@ -244,9 +246,18 @@ func registerAPIRoutes(a *echo.Group) {
 	rateLimiter := createRateLimiter(rate)
 	ur.Use(RateLimit(rateLimiter, "ip"))
 
+	if config.AuthLocalEnabled.GetBool() || config.AuthLDAPEnabled.GetBool() {
+		ur.POST("/login", apiv1.Login)
+	}
+
+	ur.POST("/user/token/refresh", apiv1.RefreshToken)
+
+	if config.AuthWebAuthnEnabled.GetBool() && (config.AuthLocalEnabled.GetBool() || config.AuthLDAPEnabled.GetBool()) {
+		ur.POST("/login/webauthn", apiv1.WebAuthnLoginOptions)
+	}
+
 	if config.AuthLocalEnabled.GetBool() {
 		// User stuff
-		ur.POST("/login", apiv1.Login)
 		ur.POST("/register", apiv1.RegisterUser)
 		ur.POST("/user/password/token", apiv1.UserRequestResetPasswordToken)
 		ur.POST("/user/password/reset", apiv1.UserResetPassword)
@@ -268,9 +279,11 @@ func registerAPIRoutes(a *echo.Group) {
 	// Avatar endpoint
 	n.GET("/avatar/:username", apiv1.GetAvatar)
 
-	// Link share auth
+	// Link share auth and public views
 	if config.ServiceEnableLinkSharing.GetBool() {
 		ur.POST("/shares/:share/auth", apiv1.AuthenticateLinkShare)
+		ur.GET("/shares/:share/public", apiv1.PublicListView)
+		ur.GET("/shares/:share/public/html", apiv1.PublicListViewHTML)
 	}
 
 	// ===== Routes with Authetication =====
@@ -278,7 +291,7 @@ func registerAPIRoutes(a *echo.Group) {
 	a.Use(middleware.JWTWithConfig(middleware.JWTConfig{
 		// Custom parse function to make the middleware work with the github.com/golang-jwt/jwt/v4 package.
 		// See https://github.com/labstack/echo/pull/1916#issuecomment-878046299
-		ParseTokenFunc: func(auth string, c echo.Context) (interface{}, error) {
+		ParseTokenFunc: func(rawToken string, c echo.Context) (interface{}, error) {
 			keyFunc := func(t *jwt.Token) (interface{}, error) {
 				if t.Method.Alg() != "HS256" {
 					return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
@@ -286,16 +299,23 @@ func registerAPIRoutes(a *echo.Group) {
 				return []byte(config.ServiceJWTSecret.GetString()), nil
 			}
 
-			token, err := jwt.Parse(auth, keyFunc)
+			token, err := jwt.Parse(rawToken, keyFunc)
 			if err != nil {
 				return nil, err
 			}
 			if !token.Valid {
 				return nil, errors.New("invalid token")
 			}
+			if err := auth.ValidateUserSession(token); err != nil {
+				return nil, err
+			}
+			if err := auth.ValidateLinkShare(token, c.RealIP()); err != nil {
+				return nil, err
+			}
 			return token, nil
 		},
 	}))
+	a.Use(restrictSecondFactorEnrollment)
 
 	// Rate limit
 	setupRateLimit(a, config.RateLimitKind.GetString())
@@ -312,6 +332,8 @@ func registerAPIRoutes(a *echo.Group) {
 	u.POST("/password", apiv1.UserChangePassword)
 	u.GET("s", apiv1.UserList)
 	u.POST("/token", apiv1.RenewToken)
+	u.GET("/sessions", apiv1.GetUserSessions)
+	u.DELETE("/sessions/:id", apiv1.DeleteUserSession)
 	u.POST("/settings/email", apiv1.UpdateUserEmail)
 	u.GET("/settings/avatar", apiv1.GetUserAvatarProvider)
 	u.POST("/settings/avatar", apiv1.ChangeUserAvatarProvider)
@@ -332,6 +354,20 @@ func registerAPIRoutes(a *echo.Group) {
 		u.GET("/settings/totp/qrcode", apiv1.UserTOTPQrCode)
 	}
 
+	if config.AuthWebAuthnEnabled.GetBool() {
+		u.GET("/settings/webauthn", apiv1.UserWebAuthnCredentials)
+		u.POST("/settings/webauthn/enroll", apiv1.UserWebAuthnEnroll)
+		u.POST("/settings/webauthn/enable", apiv1.UserWebAuthnEnable)
+		u.DELETE("/settings/webauthn/:id", apiv1.UserWebAuthnDelete)
+	}
+
+	u.GET("/settings/recoverycodes", apiv1.UserRecoveryCodeCount)
+	u.POST("/settings/recoverycodes", apiv1.UserRecoveryCodesGenerate)
+
+	u.GET("/invitations", apiv1.GetUserInvitations)
+	u.PUT("/invitations", apiv1.CreateUserInvitation)
+	u.DELETE("/invitations/:id", apiv1.DeleteUserInvitation)
+
 	// User deletion
 	if config.ServiceEnableUserDeletion.GetBool() {
 		u.POST("/deletion/request", apiv1.UserRequestDeletion)
@@ -351,6 +387,13 @@ func registerAPIRoutes(a *echo.Group) {
 	a.PUT("/namespaces/:namespace/lists", listHandler.CreateWeb)
 	a.GET("/lists/:list/listusers", apiv1.ListUsersForList)
 
+	listMoveHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ListMove{}
+		},
+	}
+	a.POST("/lists/:list/move", listMoveHandler.UpdateWeb)
+
 	if config.ServiceEnableLinkSharing.GetBool() {
 		listSharingHandler := &handler.WebHandler{
 			EmptyStruct: func() handler.CObject {
@@ -360,7 +403,15 @@ func registerAPIRoutes(a *echo.Group) {
 		a.PUT("/lists/:list/shares", listSharingHandler.CreateWeb)
 		a.GET("/lists/:list/shares", listSharingHandler.ReadAllWeb)
 		a.GET("/lists/:list/shares/:share", listSharingHandler.ReadOneWeb)
+		a.POST("/lists/:list/shares/:share", listSharingHandler.UpdateWeb)
 		a.DELETE("/lists/:list/shares/:share", listSharingHandler.DeleteWeb)
+
+		linkShareAccessLogHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.LinkShareAccessLog{}
+			},
+		}
+		a.GET("/lists/:list/shares/:share/log", linkShareAccessLogHandler.ReadAllWeb)
 	}
 
 	taskCollectionHandler := &handler.WebHandler{
@@ -379,6 +430,49 @@ func registerAPIRoutes(a *echo.Group) {
 	a.PUT("/lists/:list/buckets", kanbanBucketHandler.CreateWeb)
 	a.POST("/lists/:list/buckets/:bucket", kanbanBucketHandler.UpdateWeb)
 	a.DELETE("/lists/:list/buckets/:bucket", kanbanBucketHandler.DeleteWeb)
+	a.GET("/namespaces/:namespace/buckets", kanbanBucketHandler.ReadAllWeb)
+	a.PUT("/namespaces/:namespace/buckets", kanbanBucketHandler.CreateWeb)
+	a.POST("/namespaces/:namespace/buckets/:bucket", kanbanBucketHandler.UpdateWeb)
+	a.DELETE("/namespaces/:namespace/buckets/:bucket", kanbanBucketHandler.DeleteWeb)
+
+	bucketRuleHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.BucketRule{}
+		},
+	}
+	a.GET("/lists/:list/buckets/:bucket/rules", bucketRuleHandler.ReadAllWeb)
+	a.PUT("/lists/:list/buckets/:bucket/rules", bucketRuleHandler.CreateWeb)
+	a.POST("/lists/:list/buckets/:bucket/rules/:rule", bucketRuleHandler.UpdateWeb)
+	a.DELETE("/lists/:list/buckets/:bucket/rules/:rule", bucketRuleHandler.DeleteWeb)
+	a.GET("/namespaces/:namespace/buckets/:bucket/rules", bucketRuleHandler.ReadAllWeb)
+	a.PUT("/namespaces/:namespace/buckets/:bucket/rules", bucketRuleHandler.CreateWeb)
+	a.POST("/namespaces/:namespace/buckets/:bucket/rules/:rule", bucketRuleHandler.UpdateWeb)
+	a.DELETE("/namespaces/:namespace/buckets/:bucket/rules/:rule", bucketRuleHandler.DeleteWeb)
+
+	kanbanReportHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.KanbanReport{}
+		},
+	}
+	a.GET("/lists/:list/kanban/report", kanbanReportHandler.ReadOneWeb)
+
+	criticalPathHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.CriticalPath{}
+		},
+	}
+	a.GET("/lists/:list/critical-path", criticalPathHandler.ReadOneWeb)
+
+	taskStatusHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskStatus{}
+		},
+	}
+	a.GET("/lists/:list/statuses", taskStatusHandler.ReadAllWeb)
+	a.PUT("/lists/:list/statuses", taskStatusHandler.CreateWeb)
+	a.GET("/lists/:list/statuses/:status", taskStatusHandler.ReadOneWeb)
+	a.POST("/lists/:list/statuses/:status", taskStatusHandler.UpdateWeb)
+	a.DELETE("/lists/:list/statuses/:status", taskStatusHandler.DeleteWeb)
 
 	listDuplicateHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
@@ -405,6 +499,34 @@ func registerAPIRoutes(a *echo.Group) {
 	}
 	a.POST("/tasks/bulk", bulkTaskHandler.UpdateWeb)
 
+	kanbanTaskMoveHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.KanbanTaskMove{}
+		},
+	}
+	a.POST("/tasks/:listtask/kanban", kanbanTaskMoveHandler.UpdateWeb)
+
+	taskMoveHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskMove{}
+		},
+	}
+	a.POST("/tasks/:listtask/move", taskMoveHandler.UpdateWeb)
+
+	taskByIdentifierHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskByIdentifier{}
+		},
+	}
+	a.GET("/tasks/by-identifier/:identifier", taskByIdentifierHandler.ReadOneWeb)
+
+	taskHistoryHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskHistory{}
+		},
+	}
+	a.GET("/tasks/:listtask/history", taskHistoryHandler.ReadAllWeb)
+
 	assigneeTaskHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.TaskAssginee{}
@@ -511,6 +633,44 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/filters/:filter", savedFiltersHandler.DeleteWeb)
 	a.POST("/filters/:filter", savedFiltersHandler.UpdateWeb)
 
+	listTemplateHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ListTemplate{}
+		},
+	}
+	a.GET("/templates", listTemplateHandler.ReadAllWeb)
+	a.PUT("/templates", listTemplateHandler.CreateWeb)
+	a.GET("/templates/:template", listTemplateHandler.ReadOneWeb)
+	a.POST("/templates/:template", listTemplateHandler.UpdateWeb)
+	a.DELETE("/templates/:template", listTemplateHandler.DeleteWeb)
+
+	listTemplateInstanceHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ListTemplateInstance{}
+		},
+	}
+	a.PUT("/templates/:template/instantiate", listTemplateInstanceHandler.CreateWeb)
+
+	trashHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TrashItem{}
+		},
+	}
+	a.GET("/trash", trashHandler.ReadAllWeb)
+	a.POST("/trash/:kind/:item", trashHandler.UpdateWeb)
+	a.DELETE("/trash/:kind/:item", trashHandler.DeleteWeb)
+
+	roleHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.Role{}
+		},
+	}
+	a.GET("/roles", roleHandler.ReadAllWeb)
+	a.PUT("/roles", roleHandler.CreateWeb)
+	a.GET("/roles/:role", roleHandler.ReadOneWeb)
+	a.POST("/roles/:role", roleHandler.UpdateWeb)
+	a.DELETE("/roles/:role", roleHandler.DeleteWeb)
+
 	namespaceHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.Namespace{}
@@ -523,6 +683,13 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/namespaces/:namespace", namespaceHandler.DeleteWeb)
 	a.GET("/namespaces/:namespace/lists", apiv1.GetListsByNamespaceID)
 
+	namespaceMoveHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.NamespaceMove{}
+		},
+	}
+	a.POST("/namespaces/:namespace/move", namespaceMoveHandler.UpdateWeb)
+
 	namespaceTeamHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.TeamNamespace{}
@@ -563,6 +730,18 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/teams/:team/members/:user", teamMemberHandler.DeleteWeb)
 	a.POST("/teams/:team/members/:user/admin", teamMemberHandler.UpdateWeb)
 
+	shareInvitationHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ShareInvitation{}
+		},
+	}
+	a.GET("/lists/:list/invitations", shareInvitationHandler.ReadAllWeb)
+	a.PUT("/lists/:list/invitations", shareInvitationHandler.CreateWeb)
+	a.DELETE("/lists/:list/invitations/:invitation", shareInvitationHandler.DeleteWeb)
+	a.GET("/teams/:team/invitations", shareInvitationHandler.ReadAllWeb)
+	a.PUT("/teams/:team/invitations", shareInvitationHandler.CreateWeb)
+	a.DELETE("/teams/:team/invitations/:invitation", shareInvitationHandler.DeleteWeb)
+
 	// Subscriptions
 	subscriptionHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
@@ -570,6 +749,7 @@ func registerAPIRoutes(a *echo.Group) {
 		},
 	}
 	a.PUT("/subscriptions/:entity/:entityID", subscriptionHandler.CreateWeb)
+	a.POST("/subscriptions/:entity/:entityID", subscriptionHandler.UpdateWeb)
 	a.DELETE("/subscriptions/:entity/:entityID", subscriptionHandler.DeleteWeb)
 
 	// Notifications
@@ -579,8 +759,38 @@ func registerAPIRoutes(a *echo.Group) {
 		},
 	}
 	a.GET("/notifications", notificationHandler.ReadAllWeb)
+	a.POST("/notifications", apiv1.MarkAllNotificationsAsRead)
+	a.GET("/notifications/unread", apiv1.GetUnreadNotificationCount)
 	a.POST("/notifications/:notificationid", notificationHandler.UpdateWeb)
 
+	// Instance administration
+	adminUserHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.AdminUser{}
+		},
+	}
+	a.GET("/admin/users", adminUserHandler.ReadAllWeb)
+	a.GET("/admin/users/:user", adminUserHandler.ReadOneWeb)
+	a.POST("/admin/users/:user", adminUserHandler.UpdateWeb)
+	a.DELETE("/admin/users/:user", adminUserHandler.DeleteWeb)
+	a.POST("/admin/users/:user/password", apiv1.AdminResetUserPassword)
+
+	adminStorageHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.AdminStorageUsage{}
+		},
+	}
+	a.GET("/admin/storage", adminStorageHandler.ReadAllWeb)
+
+	adminAuditHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.AdminAuditLog{}
+		},
+	}
+	a.GET("/admin/audit", adminAuditHandler.ReadAllWeb)
+	a.GET("/admin/stats", apiv1.AdminInstanceStats)
+	a.POST("/admin/registration", apiv1.AdminSetRegistration)
+
 	// Migrations
 	m := a.Group("/migration")
 	registerMigrations(m)
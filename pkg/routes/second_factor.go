@@ -0,0 +1,64 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package routes
+
+import (
+	"strings"
+
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// All endpoints a user needs to set up a second factor
+var secondFactorEnrollmentPaths = []string{
+	"/api/v1/user/settings/totp",
+	"/api/v1/user/settings/webauthn",
+	"/api/v1/user/settings/recoverycodes",
+}
+
+func isSecondFactorEnrollmentPath(path string) bool {
+	if path == "/api/v1/user" {
+		return true
+	}
+	for _, p := range secondFactorEnrollmentPaths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// restrictSecondFactorEnrollment makes sure tokens which were only issued to set up a second factor can't be used
+// for anything else.
+func restrictSecondFactorEnrollment(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token, is := c.Get("user").(*jwt.Token)
+		if !is || !auth.IsSecondFactorEnrollmentToken(token) || isSecondFactorEnrollmentPath(c.Path()) {
+			return next(c)
+		}
+
+		u, err := user.GetUserFromClaims(token.Claims.(jwt.MapClaims))
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+		return handler.HandleHTTPError(&user.ErrSecondFactorRequired{UserID: u.ID}, c)
+	}
+}
@@ -0,0 +1,83 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package routes
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestrictSecondFactorEnrollment(t *testing.T) {
+	e := echo.New()
+	call := func(path string, claims jwt.MapClaims) (called bool) {
+		c := e.NewContext(httptest.NewRequest("POST", path, nil), httptest.NewRecorder())
+		c.SetPath(path)
+		c.Set("user", &jwt.Token{Claims: claims})
+		_ = restrictSecondFactorEnrollment(func(c echo.Context) error {
+			called = true
+			return nil
+		})(c)
+		return
+	}
+	enrollmentClaims := jwt.MapClaims{
+		"type":                   float64(1),
+		"id":                     float64(1),
+		"username":               "user1",
+		"email":                  "user1@example.com",
+		"name":                   "",
+		"secondFactorEnrollment": true,
+	}
+
+	t.Run("setting up a second factor", func(t *testing.T) {
+		assert.True(t, call("/api/v1/user/settings/totp/enroll", enrollmentClaims))
+		assert.True(t, call("/api/v1/user/settings/totp/enable", enrollmentClaims))
+		assert.True(t, call("/api/v1/user/settings/webauthn/enroll", enrollmentClaims))
+		assert.True(t, call("/api/v1/user", enrollmentClaims))
+	})
+	t.Run("anything else", func(t *testing.T) {
+		assert.False(t, call("/api/v1/lists", enrollmentClaims))
+		assert.False(t, call("/api/v1/users", enrollmentClaims))
+		assert.False(t, call("/api/v1/user/token", enrollmentClaims))
+		assert.False(t, call("/api/v1/user/settings/totpx", enrollmentClaims))
+	})
+	t.Run("token from the login", func(t *testing.T) {
+		config.InitDefaultConfig()
+		raw, err := auth.NewSecondFactorEnrollmentJWTAuthtoken(&user.User{ID: 1, Username: "user1"})
+		assert.NoError(t, err)
+		token, err := jwt.Parse(raw, func(t *jwt.Token) (interface{}, error) {
+			return []byte(config.ServiceJWTSecret.GetString()), nil
+		})
+		assert.NoError(t, err)
+
+		// The token is not bound to a session
+		assert.NoError(t, auth.ValidateUserSession(token))
+		assert.True(t, auth.IsSecondFactorEnrollmentToken(token))
+		assert.True(t, call("/api/v1/user/settings/totp/enroll", token.Claims.(jwt.MapClaims)))
+		assert.False(t, call("/api/v1/lists", token.Claims.(jwt.MapClaims)))
+	})
+	t.Run("normal token", func(t *testing.T) {
+		assert.True(t, call("/api/v1/lists", jwt.MapClaims{"type": float64(1), "id": float64(1)}))
+	})
+}
@@ -37,5 +37,10 @@ func GetTables() []interface{} {
 		&User{},
 		&TOTP{},
 		&Token{},
+		&Session{},
+		&WebAuthnCredential{},
+		&RecoveryCode{},
+		&Invitation{},
+		&InstanceSetting{},
 	}
 }
@@ -479,3 +479,367 @@ func (err *ErrAccountIsNotLocal) HTTPError() web.HTTPError {
 		Message:  "This account is managed by a third-party authentication provider.",
 	}
 }
+
+// ErrSessionDoesNotExist represents a "SessionDoesNotExist" kind of error.
+type ErrSessionDoesNotExist struct {
+	SessionID int64
+}
+
+// IsErrSessionDoesNotExist checks if an error is a ErrSessionDoesNotExist.
+func IsErrSessionDoesNotExist(err error) bool {
+	_, ok := err.(*ErrSessionDoesNotExist)
+	return ok
+}
+
+func (err *ErrSessionDoesNotExist) Error() string {
+	return "Session does not exist"
+}
+
+// ErrCodeSessionDoesNotExist holds the unique world-error code of this error
+const ErrCodeSessionDoesNotExist = 1022
+
+// HTTPError holds the http error description
+func (err *ErrSessionDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeSessionDoesNotExist,
+		Message:  "This session does not exist.",
+	}
+}
+
+// ErrInvalidRefreshToken represents a "InvalidRefreshToken" kind of error.
+type ErrInvalidRefreshToken struct{}
+
+// IsErrInvalidRefreshToken checks if an error is a ErrInvalidRefreshToken.
+func IsErrInvalidRefreshToken(err error) bool {
+	_, ok := err.(*ErrInvalidRefreshToken)
+	return ok
+}
+
+func (err *ErrInvalidRefreshToken) Error() string {
+	return "Invalid refresh token"
+}
+
+// ErrCodeInvalidRefreshToken holds the unique world-error code of this error
+const ErrCodeInvalidRefreshToken = 1023
+
+// HTTPError holds the http error description
+func (err *ErrInvalidRefreshToken) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusUnauthorized,
+		Code:     ErrCodeInvalidRefreshToken,
+		Message:  "The refresh token is invalid or expired. Please log in again.",
+	}
+}
+
+// ErrWebAuthnRequired represents a "WebAuthnRequired" kind of error.
+type ErrWebAuthnRequired struct{}
+
+// IsErrWebAuthnRequired checks if an error is a ErrWebAuthnRequired.
+func IsErrWebAuthnRequired(err error) bool {
+	_, ok := err.(*ErrWebAuthnRequired)
+	return ok
+}
+
+func (err *ErrWebAuthnRequired) Error() string {
+	return "Webauthn required"
+}
+
+// ErrCodeWebAuthnRequired holds the unique world-error code of this error
+const ErrCodeWebAuthnRequired = 1024
+
+// HTTPError holds the http error description
+func (err *ErrWebAuthnRequired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeWebAuthnRequired,
+		Message:  "A webauthn credential is required to log in. Request a challenge at /login/webauthn first.",
+	}
+}
+
+// ErrInvalidWebAuthnResponse represents a "InvalidWebAuthnResponse" kind of error.
+type ErrInvalidWebAuthnResponse struct{}
+
+// IsErrInvalidWebAuthnResponse checks if an error is a ErrInvalidWebAuthnResponse.
+func IsErrInvalidWebAuthnResponse(err error) bool {
+	_, ok := err.(*ErrInvalidWebAuthnResponse)
+	return ok
+}
+
+func (err *ErrInvalidWebAuthnResponse) Error() string {
+	return "Invalid webauthn response"
+}
+
+// ErrCodeInvalidWebAuthnResponse holds the unique world-error code of this error
+const ErrCodeInvalidWebAuthnResponse = 1025
+
+// HTTPError holds the http error description
+func (err *ErrInvalidWebAuthnResponse) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidWebAuthnResponse,
+		Message:  "The webauthn response is invalid or expired.",
+	}
+}
+
+// ErrWebAuthnCredentialDoesNotExist represents a "WebAuthnCredentialDoesNotExist" kind of error.
+type ErrWebAuthnCredentialDoesNotExist struct {
+	ID int64
+}
+
+// IsErrWebAuthnCredentialDoesNotExist checks if an error is a ErrWebAuthnCredentialDoesNotExist.
+func IsErrWebAuthnCredentialDoesNotExist(err error) bool {
+	_, ok := err.(*ErrWebAuthnCredentialDoesNotExist)
+	return ok
+}
+
+func (err *ErrWebAuthnCredentialDoesNotExist) Error() string {
+	return "Webauthn credential does not exist"
+}
+
+// ErrCodeWebAuthnCredentialDoesNotExist holds the unique world-error code of this error
+const ErrCodeWebAuthnCredentialDoesNotExist = 1026
+
+// HTTPError holds the http error description
+func (err *ErrWebAuthnCredentialDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeWebAuthnCredentialDoesNotExist,
+		Message:  "This webauthn credential does not exist.",
+	}
+}
+
+// ErrWebAuthnCredentialNameEmpty represents a "WebAuthnCredentialNameEmpty" kind of error.
+type ErrWebAuthnCredentialNameEmpty struct{}
+
+// IsErrWebAuthnCredentialNameEmpty checks if an error is a ErrWebAuthnCredentialNameEmpty.
+func IsErrWebAuthnCredentialNameEmpty(err error) bool {
+	_, ok := err.(*ErrWebAuthnCredentialNameEmpty)
+	return ok
+}
+
+func (err *ErrWebAuthnCredentialNameEmpty) Error() string {
+	return "Webauthn credential name is empty"
+}
+
+// ErrCodeWebAuthnCredentialNameEmpty holds the unique world-error code of this error
+const ErrCodeWebAuthnCredentialNameEmpty = 1027
+
+// HTTPError holds the http error description
+func (err *ErrWebAuthnCredentialNameEmpty) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeWebAuthnCredentialNameEmpty,
+		Message:  "Please provide a name for the webauthn credential.",
+	}
+}
+
+// ErrWebAuthnNotEnabled represents a "WebAuthnNotEnabled" kind of error.
+type ErrWebAuthnNotEnabled struct{}
+
+// IsErrWebAuthnNotEnabled checks if an error is a ErrWebAuthnNotEnabled.
+func IsErrWebAuthnNotEnabled(err error) bool {
+	_, ok := err.(*ErrWebAuthnNotEnabled)
+	return ok
+}
+
+func (err *ErrWebAuthnNotEnabled) Error() string {
+	return "Webauthn is not enabled"
+}
+
+// ErrCodeWebAuthnNotEnabled holds the unique world-error code of this error
+const ErrCodeWebAuthnNotEnabled = 1028
+
+// HTTPError holds the http error description
+func (err *ErrWebAuthnNotEnabled) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeWebAuthnNotEnabled,
+		Message:  "The user has not registered any webauthn credentials.",
+	}
+}
+
+// ErrInvalidRecoveryCode represents a "InvalidRecoveryCode" kind of error.
+type ErrInvalidRecoveryCode struct{}
+
+// IsErrInvalidRecoveryCode checks if an error is a ErrInvalidRecoveryCode.
+func IsErrInvalidRecoveryCode(err error) bool {
+	_, ok := err.(*ErrInvalidRecoveryCode)
+	return ok
+}
+
+func (err *ErrInvalidRecoveryCode) Error() string {
+	return "Invalid recovery code"
+}
+
+// ErrCodeInvalidRecoveryCode holds the unique world-error code of this error
+const ErrCodeInvalidRecoveryCode = 1029
+
+// HTTPError holds the http error description
+func (err *ErrInvalidRecoveryCode) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidRecoveryCode,
+		Message:  "The recovery code is invalid or was already used.",
+	}
+}
+
+// ErrUserIsNotAdmin represents a "UserIsNotAdmin" kind of error.
+type ErrUserIsNotAdmin struct {
+	UserID int64
+}
+
+// IsErrUserIsNotAdmin checks if an error is a ErrUserIsNotAdmin.
+func IsErrUserIsNotAdmin(err error) bool {
+	_, ok := err.(*ErrUserIsNotAdmin)
+	return ok
+}
+
+func (err *ErrUserIsNotAdmin) Error() string {
+	return fmt.Sprintf("User is not an instance admin [UserID: %d]", err.UserID)
+}
+
+// ErrCodeUserIsNotAdmin holds the unique world-error code of this error
+const ErrCodeUserIsNotAdmin = 1030
+
+// HTTPError holds the http error description
+func (err *ErrUserIsNotAdmin) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeUserIsNotAdmin,
+		Message:  "Only instance admins can do this.",
+	}
+}
+
+// ErrInvalidUserStatus represents a "InvalidUserStatus" kind of error.
+type ErrInvalidUserStatus struct {
+	Status Status
+}
+
+// IsErrInvalidUserStatus checks if an error is a ErrInvalidUserStatus.
+func IsErrInvalidUserStatus(err error) bool {
+	_, ok := err.(*ErrInvalidUserStatus)
+	return ok
+}
+
+func (err *ErrInvalidUserStatus) Error() string {
+	return fmt.Sprintf("Invalid user status [Status: %d]", err.Status)
+}
+
+// ErrCodeInvalidUserStatus holds the unique world-error code of this error
+const ErrCodeInvalidUserStatus = 1031
+
+// HTTPError holds the http error description
+func (err *ErrInvalidUserStatus) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidUserStatus,
+		Message:  "The user status is invalid.",
+	}
+}
+
+// ErrEmailDomainNotAllowed represents a "EmailDomainNotAllowed" kind of error.
+type ErrEmailDomainNotAllowed struct {
+	Email string
+}
+
+// IsErrEmailDomainNotAllowed checks if an error is a ErrEmailDomainNotAllowed.
+func IsErrEmailDomainNotAllowed(err error) bool {
+	_, ok := err.(*ErrEmailDomainNotAllowed)
+	return ok
+}
+
+func (err *ErrEmailDomainNotAllowed) Error() string {
+	return fmt.Sprintf("Email domain is not allowed [Email: %s]", err.Email)
+}
+
+// ErrCodeEmailDomainNotAllowed holds the unique world-error code of this error
+const ErrCodeEmailDomainNotAllowed = 1032
+
+// HTTPError holds the http error description
+func (err *ErrEmailDomainNotAllowed) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeEmailDomainNotAllowed,
+		Message:  "Accounts with an email address of this domain are not allowed on this instance.",
+	}
+}
+
+// ErrInvalidInvitation represents a "InvalidInvitation" kind of error.
+type ErrInvalidInvitation struct{}
+
+// IsErrInvalidInvitation checks if an error is a ErrInvalidInvitation.
+func IsErrInvalidInvitation(err error) bool {
+	_, ok := err.(*ErrInvalidInvitation)
+	return ok
+}
+
+func (err *ErrInvalidInvitation) Error() string {
+	return "Invalid invitation"
+}
+
+// ErrCodeInvalidInvitation holds the unique world-error code of this error
+const ErrCodeInvalidInvitation = 1033
+
+// HTTPError holds the http error description
+func (err *ErrInvalidInvitation) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidInvitation,
+		Message:  "The invitation is invalid, expired or was already used.",
+	}
+}
+
+// ErrInvitationDoesNotExist represents a "InvitationDoesNotExist" kind of error.
+type ErrInvitationDoesNotExist struct {
+	InvitationID int64
+}
+
+// IsErrInvitationDoesNotExist checks if an error is a ErrInvitationDoesNotExist.
+func IsErrInvitationDoesNotExist(err error) bool {
+	_, ok := err.(*ErrInvitationDoesNotExist)
+	return ok
+}
+
+func (err *ErrInvitationDoesNotExist) Error() string {
+	return fmt.Sprintf("Invitation does not exist [InvitationID: %d]", err.InvitationID)
+}
+
+// ErrCodeInvitationDoesNotExist holds the unique world-error code of this error
+const ErrCodeInvitationDoesNotExist = 1034
+
+// HTTPError holds the http error description
+func (err *ErrInvitationDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeInvitationDoesNotExist,
+		Message:  "The invitation does not exist.",
+	}
+}
+
+// ErrSecondFactorRequired represents a "SecondFactorRequired" kind of error.
+type ErrSecondFactorRequired struct {
+	UserID int64
+}
+
+// IsErrSecondFactorRequired checks if an error is a ErrSecondFactorRequired.
+func IsErrSecondFactorRequired(err error) bool {
+	_, ok := err.(*ErrSecondFactorRequired)
+	return ok
+}
+
+func (err *ErrSecondFactorRequired) Error() string {
+	return fmt.Sprintf("Second factor required but not set up [UserID: %d]", err.UserID)
+}
+
+// ErrCodeSecondFactorRequired holds the unique world-error code of this error
+const ErrCodeSecondFactorRequired = 1035
+
+// HTTPError holds the http error description
+func (err *ErrSecondFactorRequired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeSecondFactorRequired,
+		Message:  "This instance requires a second factor. Please set up totp or a webauthn credential and log in again.",
+	}
+}
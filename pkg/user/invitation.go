@@ -0,0 +1,181 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/utils"
+	"xorm.io/xorm"
+)
+
+// Invitation allows people to register on this instance, even if registration is disabled.
+type Invitation struct {
+	// The unique, numeric id of this invitation.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// A hash of the token, the clear text token is only shown once after creating the invitation.
+	Token string `xorm:"varchar(450) not null unique index" json:"-"`
+	// The invitation token. Only returned right after creating the invitation.
+	ClearTextToken string `xorm:"-" json:"token,omitempty"`
+	// If set, only someone with this email address can use the invitation.
+	Email string `xorm:"varchar(250) null" json:"email" valid:"email,length(0|250)" maxLength:"250"`
+	// How often the invitation can be used. 0 means unlimited.
+	MaxUses int64 `xorm:"bigint not null default 1" json:"max_uses"`
+	// How often the invitation was used already.
+	Uses int64 `xorm:"bigint not null default 0" json:"uses"`
+	// If set, the invitation cannot be used after this time.
+	Expires time.Time `xorm:"datetime null" json:"expires"`
+
+	CreatedByID int64 `xorm:"bigint not null index" json:"-"`
+
+	// A timestamp when this invitation was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the real table name for invitations
+func (*Invitation) TableName() string {
+	return "user_invitations"
+}
+
+// CreateInvitation creates a new invitation. If user invitations are disabled, only instance admins can create them.
+func CreateInvitation(s *xorm.Session, creator *User, invitation *Invitation) (err error) {
+	if !config.ServiceEnableUserInvitations.GetBool() {
+		u, err := GetUserByID(s, creator.ID)
+		if err != nil {
+			return err
+		}
+		if !u.IsAdmin {
+			return &ErrUserIsNotAdmin{UserID: u.ID}
+		}
+	}
+
+	if invitation.MaxUses < 0 {
+		invitation.MaxUses = 0
+	}
+
+	invitation.ID = 0
+	invitation.Uses = 0
+	invitation.Email = strings.ToLower(strings.TrimSpace(invitation.Email))
+	invitation.CreatedByID = creator.ID
+	invitation.ClearTextToken = utils.MakeRandomString(40)
+	invitation.Token = utils.Sha256(invitation.ClearTextToken)
+
+	_, err = s.Insert(invitation)
+	return
+}
+
+// GetInvitationsForUser returns all invitations a user created.
+func GetInvitationsForUser(s *xorm.Session, u *User) (invitations []*Invitation, err error) {
+	invitations = []*Invitation{}
+	err = s.
+		Where("created_by_id = ?", u.ID).
+		OrderBy("id desc").
+		Find(&invitations)
+	return
+}
+
+// DeleteInvitation removes an invitation. Only the user who created it or an instance admin can delete it.
+func DeleteInvitation(s *xorm.Session, u *User, invitationID int64) (err error) {
+	invitation := &Invitation{}
+	exists, err := s.Where("id = ?", invitationID).Get(invitation)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &ErrInvitationDoesNotExist{InvitationID: invitationID}
+	}
+
+	if invitation.CreatedByID != u.ID {
+		doer, err := GetUserByID(s, u.ID)
+		if err != nil {
+			return err
+		}
+		if !doer.IsAdmin {
+			return &ErrInvitationDoesNotExist{InvitationID: invitationID}
+		}
+	}
+
+	_, err = s.Where("id = ?", invitationID).Delete(&Invitation{})
+	return
+}
+
+// UseInvitation checks if an invitation token is valid for an email address and counts it as used.
+func UseInvitation(s *xorm.Session, token string, email string) (invitation *Invitation, err error) {
+	if token == "" {
+		return nil, &ErrInvalidInvitation{}
+	}
+
+	invitation = &Invitation{}
+	exists, err := s.Where("token = ?", utils.Sha256(token)).Get(invitation)
+	if err != nil {
+		return nil, err
+	}
+	if !exists ||
+		(!invitation.Expires.IsZero() && invitation.Expires.Before(time.Now())) ||
+		(invitation.Email != "" && !strings.EqualFold(invitation.Email, strings.TrimSpace(email))) {
+		return nil, &ErrInvalidInvitation{}
+	}
+
+	// Checking the uses and counting them up in one statement makes sure concurrent registrations
+	// can't use an invitation more often than allowed.
+	affected, err := s.
+		Where("id = ?", invitation.ID).
+		And("max_uses = 0 OR uses < max_uses").
+		Incr("uses").
+		Update(&Invitation{})
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, &ErrInvalidInvitation{}
+	}
+	invitation.Uses++
+
+	return invitation, nil
+}
+
+func domainMatches(domain string, domains []string) bool {
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d == "" {
+			continue
+		}
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEmailDomainAllowed checks an email address against the configured allowed and denied email domains.
+func checkEmailDomainAllowed(email string) error {
+	at := strings.LastIndex(email, "@")
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+
+	allowed := config.ServiceAllowedEmailDomains.GetStringSlice()
+	if len(allowed) > 0 && !domainMatches(domain, allowed) {
+		return &ErrEmailDomainNotAllowed{Email: email}
+	}
+
+	if domainMatches(domain, config.ServiceDeniedEmailDomains.GetStringSlice()) {
+		return &ErrEmailDomainNotAllowed{Email: email}
+	}
+
+	return nil
+}
@@ -0,0 +1,295 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateInvitation(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		invitation := &Invitation{MaxUses: 5, Email: " Someone@Example.com "}
+		err := CreateInvitation(s, &User{ID: 1}, invitation)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, invitation.ClearTextToken)
+		db.AssertExists(t, "user_invitations", map[string]interface{}{
+			"id":            invitation.ID,
+			"token":         invitation.Token,
+			"email":         "someone@example.com",
+			"max_uses":      5,
+			"created_by_id": 1,
+		}, false)
+	})
+	t.Run("invitations disabled, not an admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		config.ServiceEnableUserInvitations.Set(false)
+		defer config.ServiceEnableUserInvitations.Set(true)
+
+		err := CreateInvitation(s, &User{ID: 1}, &Invitation{MaxUses: 1})
+		assert.Error(t, err)
+		assert.True(t, IsErrUserIsNotAdmin(err))
+	})
+	t.Run("invitations disabled, admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		config.ServiceEnableUserInvitations.Set(false)
+		defer config.ServiceEnableUserInvitations.Set(true)
+
+		err := CreateInvitation(s, &User{ID: 14}, &Invitation{MaxUses: 1})
+		assert.NoError(t, err)
+	})
+}
+
+func TestDeleteInvitation(t *testing.T) {
+	t.Run("own invitation", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteInvitation(s, &User{ID: 1}, 1)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "user_invitations", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("invitation of someone else", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteInvitation(s, &User{ID: 1}, 4)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvitationDoesNotExist(err))
+	})
+	t.Run("invitation of someone else as admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteInvitation(s, &User{ID: 14}, 4)
+		assert.NoError(t, err)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteInvitation(s, &User{ID: 1}, 9999)
+		assert.Error(t, err)
+		assert.True(t, IsErrInvitationDoesNotExist(err))
+	})
+}
+
+func TestUseInvitation(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		invitation, err := UseInvitation(s, "invitationtoken1", "new@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), invitation.Uses)
+		db.AssertExists(t, "user_invitations", map[string]interface{}{
+			"id":   1,
+			"uses": 4,
+		}, false)
+	})
+	t.Run("bound to email", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UseInvitation(s, "invitationtoken2", "invited@example.com")
+		assert.NoError(t, err)
+	})
+	t.Run("wrong email", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UseInvitation(s, "invitationtoken2", "someone@example.com")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidInvitation(err))
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UseInvitation(s, "invitationtoken3", "new@example.com")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidInvitation(err))
+	})
+	t.Run("used up", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UseInvitation(s, "invitationtoken4", "new@example.com")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidInvitation(err))
+	})
+	t.Run("only as often as allowed", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UseInvitation(s, "invitationtoken2", "invited@example.com")
+		assert.NoError(t, err)
+		_, err = UseInvitation(s, "invitationtoken2", "invited@example.com")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidInvitation(err))
+		db.AssertExists(t, "user_invitations", map[string]interface{}{
+			"id":   2,
+			"uses": 1,
+		}, false)
+	})
+	t.Run("nonexisting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := UseInvitation(s, "doesnotexist", "new@example.com")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidInvitation(err))
+	})
+}
+
+func TestCreateUserWithInvitation(t *testing.T) {
+	t.Run("confirms the email when bound to it", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		config.MailerEnabled.Set(true)
+		defer config.MailerEnabled.Set(false)
+
+		u, emailConfirmed, err := CreateUserWithInvitation(s, &User{
+			Username: "invited",
+			Password: "12345678",
+			Email:    "invited@example.com",
+		}, "invitationtoken2")
+		assert.NoError(t, err)
+		assert.True(t, emailConfirmed)
+		assert.Equal(t, Status(StatusActive), u.Status)
+	})
+	t.Run("does not confirm the email when not bound to it", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u, emailConfirmed, err := CreateUserWithInvitation(s, &User{
+			Username: "invited",
+			Password: "12345678",
+			Email:    "shared@example.com",
+		}, "invitationtoken1")
+		assert.NoError(t, err)
+		assert.False(t, emailConfirmed)
+		assert.Equal(t, Status(StatusActive), u.Status)
+	})
+	t.Run("invalid invitation", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := CreateUserWithInvitation(s, &User{
+			Username: "invited",
+			Password: "12345678",
+			Email:    "invited@example.com",
+		}, "invitationtoken3")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidInvitation(err))
+		db.AssertMissing(t, "users", map[string]interface{}{
+			"username": "invited",
+		})
+	})
+}
+
+func TestCheckEmailDomainAllowed(t *testing.T) {
+	defer config.ServiceAllowedEmailDomains.Set([]string{})
+	defer config.ServiceDeniedEmailDomains.Set([]string{})
+
+	assert.NoError(t, checkEmailDomainAllowed("user@example.com"))
+
+	config.ServiceAllowedEmailDomains.Set([]string{"example.com"})
+	assert.NoError(t, checkEmailDomainAllowed("user@example.com"))
+	assert.NoError(t, checkEmailDomainAllowed("user@mail.example.com"))
+	assert.True(t, IsErrEmailDomainNotAllowed(checkEmailDomainAllowed("user@example.org")))
+	assert.True(t, IsErrEmailDomainNotAllowed(checkEmailDomainAllowed("user@notexample.com")))
+
+	config.ServiceAllowedEmailDomains.Set([]string{})
+	config.ServiceDeniedEmailDomains.Set([]string{"@spam.com"})
+	assert.True(t, IsErrEmailDomainNotAllowed(checkEmailDomainAllowed("user@spam.com")))
+	assert.NoError(t, checkEmailDomainAllowed("user@example.com"))
+}
+
+func TestRegisterUserEmailDomains(t *testing.T) {
+	config.ServiceDeniedEmailDomains.Set([]string{"example.com"})
+	defer config.ServiceDeniedEmailDomains.Set([]string{})
+
+	t.Run("self registration", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := RegisterUser(s, &User{
+			Username: "newuser",
+			Password: "12345678",
+			Email:    "newuser@example.com",
+		})
+		assert.Error(t, err)
+		assert.True(t, IsErrEmailDomainNotAllowed(err))
+	})
+	t.Run("created by an admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := CreateUser(s, &User{
+			Username: "newuser",
+			Password: "12345678",
+			Email:    "newuser@example.com",
+		})
+		assert.NoError(t, err)
+	})
+	t.Run("invited", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := CreateUserWithInvitation(s, &User{
+			Username: "invited",
+			Password: "12345678",
+			Email:    "invited@example.com",
+		}, "invitationtoken2")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,102 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/utils"
+	"xorm.io/xorm"
+)
+
+// RecoveryCode is a single use code a user can log in with in place of their second factor.
+type RecoveryCode struct {
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"-"`
+	UserID int64 `xorm:"bigint not null index" json:"-"`
+	// A hash of the code, the clear text code is only shown to the user once after generating it.
+	Code    string    `xorm:"varchar(450) not null index" json:"-"`
+	Created time.Time `xorm:"created not null" json:"-"`
+}
+
+// TableName returns the real table name for recovery codes
+func (*RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// RecoveryCodes holds the clear text recovery codes of a user right after they were generated.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+const recoveryCodeCount = 10
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// GenerateRecoveryCodes replaces all recovery codes of a user with new ones.
+// The codes are only returned here, only their hashes are saved.
+func GenerateRecoveryCodes(s *xorm.Session, u *User) (codes *RecoveryCodes, err error) {
+	err = DeleteRecoveryCodes(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	codes = &RecoveryCodes{Codes: make([]string, 0, recoveryCodeCount)}
+	for i := 0; i < recoveryCodeCount; i++ {
+		code := strings.ToLower(utils.MakeRandomString(10))
+		_, err = s.Insert(&RecoveryCode{
+			UserID: u.ID,
+			Code:   utils.Sha256(code),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		codes.Codes = append(codes.Codes, code[:5]+"-"+code[5:])
+	}
+
+	return codes, nil
+}
+
+// GetRecoveryCodeCount returns how many unused recovery codes a user has left.
+func GetRecoveryCodeCount(s *xorm.Session, u *User) (count int64, err error) {
+	return s.Where("user_id = ?", u.ID).Count(&RecoveryCode{})
+}
+
+// UseRecoveryCode checks if a recovery code is valid for a user and invalidates it so it can't be used again.
+func UseRecoveryCode(s *xorm.Session, u *User, code string) (err error) {
+	deleted, err := s.
+		Where("user_id = ? AND code = ?", u.ID, utils.Sha256(normalizeRecoveryCode(code))).
+		Delete(&RecoveryCode{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrInvalidRecoveryCode{}
+	}
+	return nil
+}
+
+// DeleteRecoveryCodes removes all recovery codes of a user.
+func DeleteRecoveryCodes(s *xorm.Session, u *User) (err error) {
+	_, err = s.
+		Where("user_id = ?", u.ID).
+		Delete(&RecoveryCode{})
+	return
+}
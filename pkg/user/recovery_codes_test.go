@@ -0,0 +1,90 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	u := &User{ID: 1}
+	codes, err := GenerateRecoveryCodes(s, u)
+	assert.NoError(t, err)
+	assert.Len(t, codes.Codes, recoveryCodeCount)
+
+	count, err := GetRecoveryCodeCount(s, u)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(recoveryCodeCount), count)
+
+	// The old codes are replaced
+	err = UseRecoveryCode(s, u, "abcde-fghij")
+	assert.Error(t, err)
+	assert.True(t, IsErrInvalidRecoveryCode(err))
+
+	err = UseRecoveryCode(s, u, codes.Codes[0])
+	assert.NoError(t, err)
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 1}, "abcde-fghij")
+		assert.NoError(t, err)
+		db.AssertMissing(t, "user_recovery_codes", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("without dash and different case", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 1}, " KLMNOpqrst ")
+		assert.NoError(t, err)
+	})
+	t.Run("already used", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 1}, "abcde-fghij")
+		assert.NoError(t, err)
+		err = UseRecoveryCode(s, &User{ID: 1}, "abcde-fghij")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRecoveryCode(err))
+	})
+	t.Run("code of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 1}, "uvwxy-zabcd")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRecoveryCode(err))
+	})
+}
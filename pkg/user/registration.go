@@ -0,0 +1,79 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+
+	"xorm.io/xorm"
+)
+
+// InstanceSetting holds a setting of the instance which instance admins can change at runtime.
+// It overrides the value from the config file and is kept across restarts.
+type InstanceSetting struct {
+	Key     string    `xorm:"varchar(250) not null pk"`
+	Value   string    `xorm:"text null"`
+	Updated time.Time `xorm:"updated not null"`
+}
+
+// TableName returns the table name for instance settings
+func (*InstanceSetting) TableName() string {
+	return "instance_settings"
+}
+
+const registrationEnabledKey = `registration_enabled`
+
+// IsRegistrationEnabled returns whether new users can register themselves. Instance admins can override the
+// configured value at runtime.
+func IsRegistrationEnabled(s *xorm.Session) (enabled bool, err error) {
+	setting := &InstanceSetting{}
+	exists, err := s.Where("`key` = ?", registrationEnabledKey).Get(setting)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return config.ServiceEnableRegistration.GetBool(), nil
+	}
+
+	return strconv.ParseBool(setting.Value)
+}
+
+// SetRegistrationEnabled overrides the configured registration setting.
+func SetRegistrationEnabled(s *xorm.Session, enabled bool) (err error) {
+	setting := &InstanceSetting{
+		Key:   registrationEnabledKey,
+		Value: strconv.FormatBool(enabled),
+	}
+
+	exists, err := s.Where("`key` = ?", registrationEnabledKey).Exist(&InstanceSetting{})
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = s.Insert(setting)
+		return
+	}
+
+	_, err = s.
+		Where("`key` = ?", registrationEnabledKey).
+		Cols("value", "updated").
+		Update(setting)
+	return
+}
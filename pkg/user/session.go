@@ -0,0 +1,230 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/utils"
+	"xorm.io/xorm"
+)
+
+// Session is a server side session of a user. Every jwt token issued to a user belongs to a session, revoking the
+// session invalidates all tokens issued for it.
+type Session struct {
+	// The unique, numeric id of this session.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	UserID int64 `xorm:"bigint not null index" json:"-"`
+	// The name of the device this session was created on. This is the user agent of the client used to log in.
+	DeviceName string `xorm:"text null" json:"device_name"`
+	// The ip address from which the session was created.
+	IPAddress string `xorm:"varchar(250) null" json:"ip_address"`
+	// Whether this is a long session, created with the "stay logged in" option.
+	IsLongSession bool `xorm:"bool not null default false" json:"is_long_session"`
+	// Whether this is the session which was used to make the request.
+	IsCurrent bool `xorm:"-" json:"is_current"`
+
+	// A hash of the refresh token of this session.
+	RefreshToken string `xorm:"varchar(450) not null index" json:"-"`
+	// The refresh token in clear text. Only available right after the session was created or refreshed.
+	ClearTextRefreshToken string `xorm:"-" json:"-"`
+
+	// The last time a token of this session was used.
+	LastSeen time.Time `xorm:"datetime not null" json:"last_seen"`
+	// The session can be refreshed until this date.
+	Expires time.Time `xorm:"datetime not null index" json:"expires"`
+	// A timestamp when this session was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the real table name for user sessions
+func (*Session) TableName() string {
+	return "user_sessions"
+}
+
+// Only update the last seen date of a session once per minute to avoid writing to the db on every request
+const sessionLastSeenInterval = time.Minute
+
+// The session must outlive the tokens issued for it, otherwise it could not be refreshed once they expired.
+func getSessionTTL(long bool) time.Duration {
+	sessionTTL, jwtTTL := config.ServiceSessionTTL.GetInt64(), config.ServiceJWTTTL.GetInt64()
+	if long {
+		sessionTTL, jwtTTL = config.ServiceSessionTTLLong.GetInt64(), config.ServiceJWTTTLLong.GetInt64()
+	}
+	if sessionTTL < jwtTTL {
+		sessionTTL = jwtTTL
+	}
+	return time.Duration(sessionTTL) * time.Second
+}
+
+// extend pushes the end of the lifetime of a session back. It never shortens it.
+func (session *Session) extend() {
+	session.LastSeen = time.Now()
+	expires := session.LastSeen.Add(getSessionTTL(session.IsLongSession))
+	if expires.After(session.Expires) {
+		session.Expires = expires
+	}
+}
+
+// newRefreshToken sets a new refresh token for a session and extends its lifetime.
+func (session *Session) newRefreshToken() {
+	session.ClearTextRefreshToken = utils.MakeRandomString(tokenSize)
+	session.RefreshToken = utils.Sha256(session.ClearTextRefreshToken)
+	session.extend()
+}
+
+// CreateSession creates a new session for a user.
+func CreateSession(s *xorm.Session, u *User, deviceName, ipAddress string, long bool) (session *Session, err error) {
+	session = &Session{
+		UserID:        u.ID,
+		DeviceName:    deviceName,
+		IPAddress:     ipAddress,
+		IsLongSession: long,
+	}
+	session.newRefreshToken()
+
+	_, err = s.Insert(session)
+	return
+}
+
+// ValidateSession checks if a session exists, belongs to the user and is not expired.
+func ValidateSession(s *xorm.Session, sessionID, userID int64) (session *Session, err error) {
+	session = &Session{}
+	exists, err := s.
+		Where("id = ? AND user_id = ? AND expires > ?", sessionID, userID, time.Now()).
+		Get(session)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrSessionDoesNotExist{SessionID: sessionID}
+	}
+
+	if time.Since(session.LastSeen) > sessionLastSeenInterval {
+		session.LastSeen = time.Now()
+		_, err = s.
+			Where("id = ?", session.ID).
+			Cols("last_seen").
+			Update(session)
+	}
+
+	return
+}
+
+// ExtendSession extends the lifetime of a session when a new token is issued for it. The refresh token stays the same.
+func ExtendSession(s *xorm.Session, session *Session) (err error) {
+	session.extend()
+	_, err = s.
+		Where("id = ?", session.ID).
+		Cols("last_seen", "expires").
+		Update(session)
+	return
+}
+
+// RefreshSession exchanges a refresh token for a new one and extends the lifetime of the session it belongs to.
+// The old refresh token cannot be used again.
+func RefreshSession(s *xorm.Session, refreshToken string) (session *Session, u *User, err error) {
+	if refreshToken == "" {
+		return nil, nil, &ErrInvalidRefreshToken{}
+	}
+
+	session = &Session{}
+	exists, err := s.
+		Where("refresh_token = ? AND expires > ?", utils.Sha256(refreshToken), time.Now()).
+		Get(session)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, &ErrInvalidRefreshToken{}
+	}
+
+	u, err = GetUserByID(s, session.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.Status == StatusDisabled {
+		return nil, nil, &ErrAccountDisabled{UserID: u.ID}
+	}
+
+	session.newRefreshToken()
+	_, err = s.
+		Where("id = ?", session.ID).
+		Cols("refresh_token", "last_seen", "expires").
+		Update(session)
+	return
+}
+
+// GetSessionsForUser returns all active sessions of a user, the most recently used first.
+func GetSessionsForUser(s *xorm.Session, u *User) (sessions []*Session, err error) {
+	sessions = []*Session{}
+	err = s.
+		Where("user_id = ? AND expires > ?", u.ID, time.Now()).
+		OrderBy("last_seen desc").
+		Find(&sessions)
+	return
+}
+
+// DeleteSession revokes a session of a user.
+func DeleteSession(s *xorm.Session, u *User, sessionID int64) (err error) {
+	deleted, err := s.
+		Where("id = ? AND user_id = ?", sessionID, u.ID).
+		Delete(&Session{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrSessionDoesNotExist{SessionID: sessionID}
+	}
+	return nil
+}
+
+// DeleteAllSessionsForUser revokes all sessions of a user.
+func DeleteAllSessionsForUser(s *xorm.Session, userID int64) (err error) {
+	_, err = s.
+		Where("user_id = ?", userID).
+		Delete(&Session{})
+	return
+}
+
+// RegisterSessionCleanupCron registers a cron function to clean up all expired sessions.
+func RegisterSessionCleanupCron() {
+	const logPrefix = "[User Session Cleanup Cron] "
+
+	err := cron.Schedule("0 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		deleted, err := s.
+			Where("expires < ?", time.Now()).
+			Delete(&Session{})
+		if err != nil {
+			log.Errorf(logPrefix+"Error removing expired sessions: %s", err)
+			return
+		}
+		if deleted > 0 {
+			log.Debugf(logPrefix+"Deleted %d expired sessions", deleted)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register session cleanup cron: %s", err)
+	}
+}
@@ -0,0 +1,217 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSession(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	session, err := CreateSession(s, &User{ID: 1}, "curl/7.85.0", "192.0.2.1", true)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, session.ClearTextRefreshToken)
+	assert.NotEqual(t, session.ClearTextRefreshToken, session.RefreshToken)
+	assert.True(t, session.Expires.After(session.LastSeen))
+	// The session must outlive the token so it can still be refreshed once the token expired
+	jwtTTL := time.Duration(config.ServiceJWTTTLLong.GetInt64()) * time.Second
+	assert.True(t, session.Expires.After(session.LastSeen.Add(jwtTTL)))
+
+	db.AssertExists(t, "user_sessions", map[string]interface{}{
+		"id":              session.ID,
+		"user_id":         1,
+		"ip_address":      "192.0.2.1",
+		"is_long_session": true,
+	}, false)
+}
+
+func TestValidateSession(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		session, err := ValidateSession(s, 1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), session.ID)
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := ValidateSession(s, 2, 1)
+		assert.Error(t, err)
+		assert.True(t, IsErrSessionDoesNotExist(err))
+	})
+	t.Run("other user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := ValidateSession(s, 3, 1)
+		assert.Error(t, err)
+		assert.True(t, IsErrSessionDoesNotExist(err))
+	})
+}
+
+func TestExtendSession(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	session, err := CreateSession(s, &User{ID: 1}, "curl/7.85.0", "192.0.2.1", false)
+	assert.NoError(t, err)
+	session.Expires = time.Now().Add(time.Minute)
+	_, err = s.ID(session.ID).Cols("expires").Update(session)
+	assert.NoError(t, err)
+
+	err = ExtendSession(s, session)
+	assert.NoError(t, err)
+	assert.True(t, session.Expires.After(time.Now().Add(time.Hour)))
+
+	extended, err := ValidateSession(s, session.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, session.Expires.Unix(), extended.Expires.Unix())
+}
+
+func TestRefreshSession(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		session, u, err := RefreshSession(s, "refreshtoken1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), session.ID)
+		assert.Equal(t, int64(1), u.ID)
+		assert.NotEqual(t, "refreshtoken1", session.ClearTextRefreshToken)
+
+		// The old refresh token cannot be used again
+		_, _, err = RefreshSession(s, "refreshtoken1")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+
+		_, _, err = RefreshSession(s, session.ClearTextRefreshToken)
+		assert.NoError(t, err)
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := RefreshSession(s, "expiredrefreshtoken")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+	})
+	t.Run("empty", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := RefreshSession(s, "")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+	})
+}
+
+func TestGetSessionsForUser(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	sessions, err := GetSessionsForUser(s, &User{ID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, int64(1), sessions[0].ID)
+}
+
+func TestDeleteSession(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteSession(s, &User{ID: 1}, 1)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "user_sessions", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("session of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteSession(s, &User{ID: 1}, 3)
+		assert.Error(t, err)
+		assert.True(t, IsErrSessionDoesNotExist(err))
+		db.AssertExists(t, "user_sessions", map[string]interface{}{
+			"id": 3,
+		}, false)
+	})
+}
+
+func TestSessionRevocation(t *testing.T) {
+	t.Run("password change", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UpdateUserPassword(s, &User{ID: 1}, "12345678")
+		assert.NoError(t, err)
+		db.AssertMissing(t, "user_sessions", map[string]interface{}{
+			"user_id": 1,
+		})
+		db.AssertExists(t, "user_sessions", map[string]interface{}{
+			"user_id": 2,
+		}, false)
+	})
+	t.Run("disable account", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &User{ID: 1}
+		err := u.SetStatus(s, StatusDisabled)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "user_sessions", map[string]interface{}{
+			"user_id": 1,
+		})
+	})
+	t.Run("enable account", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &User{ID: 1}
+		err := u.SetStatus(s, StatusActive)
+		assert.NoError(t, err)
+		db.AssertExists(t, "user_sessions", map[string]interface{}{
+			"user_id": 1,
+		}, false)
+	})
+}
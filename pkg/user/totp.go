@@ -104,7 +104,8 @@ func EnrollTOTP(s *xorm.Session, user *User) (t *TOTP, err error) {
 }
 
 // EnableTOTP enables totp for a user. The provided passcode is used to verify the user has a working totp setup.
-func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (err error) {
+// If the user does not have any recovery codes yet, new ones are generated and returned.
+func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (codes *RecoveryCodes, err error) {
 	t, err := ValidateTOTPPasscode(s, passcode)
 	if err != nil {
 		return
@@ -114,15 +115,54 @@ func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (err error) {
 		Where("id = ?", t.ID).
 		Cols("enabled").
 		Update(&TOTP{Enabled: true})
-	return
+	if err != nil {
+		return
+	}
+
+	count, err := GetRecoveryCodeCount(s, passcode.User)
+	if err != nil || count > 0 {
+		return nil, err
+	}
+
+	return GenerateRecoveryCodes(s, passcode.User)
 }
 
-// DisableTOTP removes all totp settings for a user.
+// DisableTOTP removes all totp settings for a user. If totp was their last second factor, their recovery codes
+// are removed as well.
 func DisableTOTP(s *xorm.Session, user *User) (err error) {
 	_, err = s.
 		Where("user_id = ?", user.ID).
 		Delete(&TOTP{})
-	return
+	if err != nil {
+		return
+	}
+
+	hasWebAuthn, err := s.Where("user_id = ?", user.ID).Exist(&WebAuthnCredential{})
+	if err != nil || hasWebAuthn {
+		return
+	}
+
+	return DeleteRecoveryCodes(s, user)
+}
+
+// ValidateTOTPPasscodeOrRecoveryCode validates a totp passcode. If the passcode is not a valid totp code, it is
+// checked as a recovery code instead so users who lost their authenticator can use the same login form.
+func ValidateTOTPPasscodeOrRecoveryCode(s *xorm.Session, passcode *TOTPPasscode) (err error) {
+	_, err = ValidateTOTPPasscode(s, passcode)
+	if err == nil || !IsErrInvalidTOTPPasscode(err) {
+		return
+	}
+
+	// Totp passcodes only consist of six digits, everything longer can only be a recovery code
+	if len(normalizeRecoveryCode(passcode.Passcode)) <= 6 {
+		return
+	}
+
+	if UseRecoveryCode(s, passcode.User, passcode.Passcode) != nil {
+		return ErrInvalidTOTPPasscode{Passcode: passcode.Passcode}
+	}
+
+	return nil
 }
 
 // ValidateTOTPPasscode validated totp codes of users.
@@ -0,0 +1,154 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableTOTP(t *testing.T) {
+	t.Run("generates recovery codes", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &User{ID: 3, Username: "user3"}
+		enrolled, err := EnrollTOTP(s, u)
+		assert.NoError(t, err)
+		passcode, err := totp.GenerateCode(enrolled.Secret, time.Now())
+		assert.NoError(t, err)
+
+		codes, err := EnableTOTP(s, &TOTPPasscode{User: u, Passcode: passcode})
+		assert.NoError(t, err)
+		assert.NotNil(t, codes)
+		assert.Len(t, codes.Codes, recoveryCodeCount)
+		db.AssertExists(t, "totp", map[string]interface{}{
+			"user_id": 3,
+			"enabled": true,
+		}, false)
+	})
+	t.Run("keeps existing recovery codes", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &User{ID: 1, Username: "user1"}
+		enrolled, err := EnrollTOTP(s, u)
+		assert.NoError(t, err)
+		passcode, err := totp.GenerateCode(enrolled.Secret, time.Now())
+		assert.NoError(t, err)
+
+		codes, err := EnableTOTP(s, &TOTPPasscode{User: u, Passcode: passcode})
+		assert.NoError(t, err)
+		assert.Nil(t, codes)
+		db.AssertExists(t, "user_recovery_codes", map[string]interface{}{
+			"id":      1,
+			"user_id": 1,
+		}, false)
+	})
+	t.Run("invalid passcode", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &User{ID: 3, Username: "user3"}
+		_, err := EnrollTOTP(s, u)
+		assert.NoError(t, err)
+
+		_, err = EnableTOTP(s, &TOTPPasscode{User: u, Passcode: "000000"})
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTOTPPasscode(err))
+	})
+}
+
+func TestDisableTOTP(t *testing.T) {
+	t.Run("removes recovery codes with totp", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &User{ID: 10}
+		_, err := GenerateRecoveryCodes(s, u)
+		assert.NoError(t, err)
+
+		err = DisableTOTP(s, u)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "totp", map[string]interface{}{
+			"user_id": 10,
+		})
+		count, err := GetRecoveryCodeCount(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+	t.Run("keeps recovery codes with webauthn", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &User{ID: 1}
+		err := DisableTOTP(s, u)
+		assert.NoError(t, err)
+		count, err := GetRecoveryCodeCount(s, u)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+}
+
+func TestValidateTOTPPasscodeOrRecoveryCode(t *testing.T) {
+	t.Run("totp passcode", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		passcode, err := totp.GenerateCode("JBSWY3DPEHPK3PXP", time.Now())
+		assert.NoError(t, err)
+		err = ValidateTOTPPasscodeOrRecoveryCode(s, &TOTPPasscode{User: &User{ID: 10}, Passcode: passcode})
+		assert.NoError(t, err)
+	})
+	t.Run("recovery code", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &User{ID: 10}
+		codes, err := GenerateRecoveryCodes(s, u)
+		assert.NoError(t, err)
+
+		err = ValidateTOTPPasscodeOrRecoveryCode(s, &TOTPPasscode{User: u, Passcode: codes.Codes[0]})
+		assert.NoError(t, err)
+
+		// Recovery codes can only be used once
+		err = ValidateTOTPPasscodeOrRecoveryCode(s, &TOTPPasscode{User: u, Passcode: codes.Codes[0]})
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTOTPPasscode(err))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ValidateTOTPPasscodeOrRecoveryCode(s, &TOTPPasscode{User: &User{ID: 10}, Passcode: "000000"})
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidTOTPPasscode(err))
+	})
+}
@@ -17,6 +17,7 @@
 package user
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -42,8 +43,13 @@ type Login struct {
 	Username string `json:"username"`
 	// The password for the user.
 	Password string `json:"password"`
-	// The totp passcode of a user. Only needs to be provided when enabled.
+	// The totp passcode of a user. Only needs to be provided when enabled. A recovery code can be provided here as well.
 	TOTPPasscode string `json:"totp_passcode"`
+	// The response of the authenticator to a webauthn login challenge. Needs to be provided if the user registered
+	// a webauthn credential. If provided without a username and password, the user is logged in with a passkey.
+	WebAuthn json.RawMessage `json:"webauthn"`
+	// A recovery code which can be used in place of a totp passcode or webauthn credential.
+	RecoveryCode string `json:"recovery_code"`
 	// If true, the token returned will be valid a lot longer than default. Useful for "remember me" style logins.
 	LongToken bool `json:"long_token"`
 }
@@ -82,6 +88,8 @@ type User struct {
 	Email string `xorm:"varchar(250) null" json:"email,omitempty" valid:"email,length(0|250)" maxLength:"250"`
 
 	Status Status `xorm:"default 0" json:"-"`
+	// Instance admins can manage all users of this instance through the admin api.
+	IsAdmin bool `xorm:"bool default false" json:"-"`
 
 	AvatarProvider string `xorm:"varchar(255) null" json:"-"`
 	AvatarFileID   int64  `xorm:"null" json:"-"`
@@ -134,6 +142,11 @@ func (u *User) RouteForDB() int64 {
 	return u.ID
 }
 
+// Lang returns the language a user wants to receive notifications in
+func (u *User) Lang() string {
+	return u.Language
+}
+
 // GetID implements the Auth interface
 func (u *User) GetID() int64 {
 	return u.ID
@@ -186,6 +199,8 @@ type APIUserPassword struct {
 	Password string `json:"password" valid:"length(8|250)" minLength:"8" maxLength:"250"`
 	// The user's email address
 	Email string `json:"email" valid:"email,length(0|250)" maxLength:"250"`
+	// An invitation token. Only needed if registration is disabled on this instance.
+	InvitationToken string `json:"invitation_token"`
 }
 
 // APIFormat formats an API User into a normal user struct
@@ -544,15 +559,30 @@ func UpdateUserPassword(s *xorm.Session, user *User, newPassword string) (err er
 		return err
 	}
 
-	return err
+	// Everyone who knew the old password should not be able to use their existing sessions anymore
+	return DeleteAllSessionsForUser(s, user.ID)
 }
 
-// SetStatus sets a users status in the database
+// SetStatus sets a users status in the database. Disabling a user revokes all their sessions.
 func (u *User) SetStatus(s *xorm.Session, status Status) (err error) {
 	u.Status = status
 	_, err = s.
 		Where("id = ?", u.ID).
 		Cols("status").
 		Update(u)
+	if err != nil || status != StatusDisabled {
+		return
+	}
+
+	return DeleteAllSessionsForUser(s, u.ID)
+}
+
+// SetAdmin grants or revokes instance admin rights for a user.
+func (u *User) SetAdmin(s *xorm.Session, isAdmin bool) (err error) {
+	u.IsAdmin = isAdmin
+	_, err = s.
+		Where("id = ?", u.ID).
+		Cols("is_admin").
+		Update(u)
 	return
 }
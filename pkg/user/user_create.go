@@ -26,8 +26,43 @@ import (
 
 const IssuerLocal = `local`
 
+// IssuerLDAP is the issuer of users who authenticate through ldap
+const IssuerLDAP = `ldap`
+
 // CreateUser creates a new user and inserts it into the database
 func CreateUser(s *xorm.Session, user *User) (newUser *User, err error) {
+	return createUser(s, user, false)
+}
+
+// RegisterUser creates a new user who signed up on their own. Unlike CreateUser, it enforces the configured
+// allowed and denied email domains.
+func RegisterUser(s *xorm.Session, user *User) (newUser *User, err error) {
+	err = checkEmailDomainAllowed(user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return createUser(s, user, false)
+}
+
+// CreateUserWithInvitation creates a new user who was invited. If the invitation was sent to the user's email
+// address, they don't need to confirm it again. emailConfirmed reports whether that was the case.
+func CreateUserWithInvitation(s *xorm.Session, user *User, invitationToken string) (newUser *User, emailConfirmed bool, err error) {
+	invitation, err := UseInvitation(s, invitationToken, user.Email)
+	if err != nil {
+		return nil, false, err
+	}
+
+	emailConfirmed = invitation.Email != ""
+	newUser, err = createUser(s, user, emailConfirmed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return newUser, emailConfirmed, nil
+}
+
+func createUser(s *xorm.Session, user *User, emailConfirmed bool) (newUser *User, err error) {
 
 	if user.Issuer == "" {
 		user.Issuer = IssuerLocal
@@ -86,7 +121,7 @@ func CreateUser(s *xorm.Session, user *User) (newUser *User, err error) {
 	}
 
 	// Dont send a mail if no mailer is configured
-	if !config.MailerEnabled.GetBool() || user.Issuer != IssuerLocal {
+	if !config.MailerEnabled.GetBool() || user.Issuer != IssuerLocal || emailConfirmed {
 		return newUserOut, err
 	}
 
@@ -98,11 +133,12 @@ func CreateUser(s *xorm.Session, user *User) (newUser *User, err error) {
 
 	_, err = s.
 		Where("id = ?", user.ID).
-		Cols("email", "is_active").
+		Cols("status").
 		Update(user)
 	if err != nil {
 		return
 	}
+	newUserOut.Status = user.Status
 
 	n := &EmailConfirmNotification{
 		User:         user,
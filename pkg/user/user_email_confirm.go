@@ -26,12 +26,12 @@ type EmailConfirm struct {
 	Token string `json:"token"`
 }
 
-// ConfirmEmail handles the confirmation of an email address
-func ConfirmEmail(s *xorm.Session, c *EmailConfirm) (err error) {
+// ConfirmEmail handles the confirmation of an email address and returns the user whose address was confirmed.
+func ConfirmEmail(s *xorm.Session, c *EmailConfirm) (user *User, err error) {
 
 	// Check if we have an email confirm token
 	if c.Token == "" {
-		return ErrInvalidEmailConfirmToken{}
+		return nil, ErrInvalidEmailConfirmToken{}
 	}
 
 	token, err := getToken(s, c.Token, TokenEmailConfirm)
@@ -39,10 +39,10 @@ func ConfirmEmail(s *xorm.Session, c *EmailConfirm) (err error) {
 		return
 	}
 	if token == nil {
-		return ErrInvalidEmailConfirmToken{Token: c.Token}
+		return nil, ErrInvalidEmailConfirmToken{Token: c.Token}
 	}
 
-	user, err := GetUserByID(s, token.UserID)
+	user, err = GetUserByID(s, token.UserID)
 	if err != nil {
 		return
 	}
@@ -54,7 +54,7 @@ func ConfirmEmail(s *xorm.Session, c *EmailConfirm) (err error) {
 	}
 	_, err = s.
 		Where("id = ?", user.ID).
-		Cols("is_active").
+		Cols("status").
 		Update(user)
 	return
 }
@@ -67,7 +67,7 @@ func TestUserEmailConfirm(t *testing.T) {
 			s := db.NewSession()
 			defer s.Close()
 
-			if err := ConfirmEmail(s, tt.args.c); (err != nil) != tt.wantErr {
+			if _, err := ConfirmEmail(s, tt.args.c); (err != nil) != tt.wantErr {
 				t.Errorf("ConfirmEmail() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -0,0 +1,449 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"encoding/base64"
+	"io"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/modules/keyvalue"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"xorm.io/xorm"
+)
+
+// WebAuthnCredential is a security key or passkey a user registered to log in with.
+type WebAuthnCredential struct {
+	// The unique, numeric id of this credential.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	UserID int64 `xorm:"bigint not null index" json:"-"`
+	// A name the user gave this credential to recognize it, for example "Yubikey" or "Phone".
+	Name string `xorm:"varchar(250) not null" json:"name"`
+
+	// The credential id assigned by the authenticator, base64url encoded.
+	CredentialID    string `xorm:"varchar(450) not null unique" json:"-"`
+	PublicKey       []byte `xorm:"blob not null" json:"-"`
+	AttestationType string `xorm:"varchar(250) null" json:"-"`
+	AAGUID          []byte `xorm:"blob null" json:"-"`
+	SignCount       int64  `xorm:"bigint not null default 0" json:"-"`
+
+	// The last time this credential was used to log in.
+	LastUsed time.Time `xorm:"datetime null" json:"last_used"`
+	// A timestamp when this credential was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the real table name for webauthn credentials
+func (*WebAuthnCredential) TableName() string {
+	return "user_webauthn_credentials"
+}
+
+func (c *WebAuthnCredential) toWebAuthn() webauthn.Credential {
+	id, _ := base64.RawURLEncoding.DecodeString(c.CredentialID)
+	return webauthn.Credential{
+		ID:              id,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    c.AAGUID,
+			SignCount: uint32(c.SignCount),
+		},
+	}
+}
+
+// WebAuthnRegistration holds the result of registering a new webauthn credential.
+type WebAuthnRegistration struct {
+	Credential *WebAuthnCredential `json:"credential"`
+	// Only set when this is the first second factor of the user. The codes are only shown once.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// webAuthnUser wraps a user and their credentials to implement webauthn.User.
+type webAuthnUser struct {
+	user        *User
+	credentials []*WebAuthnCredential
+}
+
+func (w *webAuthnUser) WebAuthnID() []byte {
+	return []byte(strconv.FormatInt(w.user.ID, 10))
+}
+
+func (w *webAuthnUser) WebAuthnName() string {
+	return w.user.Username
+}
+
+func (w *webAuthnUser) WebAuthnDisplayName() string {
+	return w.user.GetName()
+}
+
+func (w *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (w *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(w.credentials))
+	for _, c := range w.credentials {
+		credentials = append(credentials, c.toWebAuthn())
+	}
+	return credentials
+}
+
+// webAuthnSession is the state of a registration or login ceremony between the two requests it consists of.
+type webAuthnSession struct {
+	Data    webauthn.SessionData `json:"data"`
+	Created time.Time            `json:"created"`
+}
+
+// A ceremony has to be completed within this time
+const webAuthnSessionTimeout = 5 * time.Minute
+
+func getWebAuthnRegistrationKey(u *User) string {
+	return "webauthn_registration_" + strconv.FormatInt(u.ID, 10)
+}
+
+func getWebAuthnLoginKey(u *User) string {
+	return "webauthn_login_" + strconv.FormatInt(u.ID, 10)
+}
+
+func getWebAuthnPasswordlessLoginKey(challenge string) string {
+	return "webauthn_passwordless_login_" + challenge
+}
+
+func putWebAuthnSession(key string, data *webauthn.SessionData) error {
+	return keyvalue.Put(key, &webAuthnSession{
+		Data:    *data,
+		Created: time.Now(),
+	})
+}
+
+// popWebAuthnSession returns a ceremony and removes it so it can only be completed once.
+func popWebAuthnSession(key string) (data *webauthn.SessionData, err error) {
+	session := &webAuthnSession{}
+	exists, err := keyvalue.GetWithValue(key, session)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrInvalidWebAuthnResponse{}
+	}
+
+	err = keyvalue.Del(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Since(session.Created) > webAuthnSessionTimeout {
+		return nil, &ErrInvalidWebAuthnResponse{}
+	}
+
+	return &session.Data, nil
+}
+
+func getWebAuthn() (*webauthn.WebAuthn, error) {
+	return webauthn.New(&webauthn.Config{
+		RPDisplayName: config.AuthWebAuthnRPDisplayName.GetString(),
+		RPID:          config.AuthWebAuthnRPID.GetString(),
+		RPOrigin:      config.AuthWebAuthnOrigin.GetString(),
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			UserVerification: getWebAuthnUserVerification(),
+		},
+	})
+}
+
+func getWebAuthnUserVerification() protocol.UserVerificationRequirement {
+	switch config.AuthWebAuthnUserVerification.GetString() {
+	case string(protocol.VerificationRequired):
+		return protocol.VerificationRequired
+	case string(protocol.VerificationDiscouraged):
+		return protocol.VerificationDiscouraged
+	}
+	return protocol.VerificationPreferred
+}
+
+func getWebAuthnUser(s *xorm.Session, u *User) (w *webAuthnUser, err error) {
+	credentials, err := GetWebAuthnCredentials(s, u)
+	if err != nil {
+		return nil, err
+	}
+	return &webAuthnUser{user: u, credentials: credentials}, nil
+}
+
+// GetWebAuthnCredentials returns all webauthn credentials of a user.
+func GetWebAuthnCredentials(s *xorm.Session, u *User) (credentials []*WebAuthnCredential, err error) {
+	credentials = []*WebAuthnCredential{}
+	err = s.
+		Where("user_id = ?", u.ID).
+		OrderBy("id asc").
+		Find(&credentials)
+	return
+}
+
+// WebAuthnEnabledForUser checks if a user registered at least one webauthn credential.
+func WebAuthnEnabledForUser(s *xorm.Session, u *User) (bool, error) {
+	if !config.AuthWebAuthnEnabled.GetBool() {
+		return false, nil
+	}
+	return s.Where("user_id = ?", u.ID).Exist(&WebAuthnCredential{})
+}
+
+// BeginWebAuthnRegistration starts registering a new webauthn credential for a user.
+// The returned options need to be passed to the authenticator by the client.
+func BeginWebAuthnRegistration(s *xorm.Session, u *User) (options *protocol.CredentialCreation, err error) {
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(w.credentials))
+	for _, c := range w.WebAuthnCredentials() {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+
+	// Asking for a resident key allows using the credential as a passkey for passwordless login
+	options, data, err := wa.BeginRegistration(
+		w,
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementPreferred,
+			UserVerification: getWebAuthnUserVerification(),
+		}),
+		webauthn.WithExclusions(exclusions),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return options, putWebAuthnSession(getWebAuthnRegistrationKey(u), data)
+}
+
+// FinishWebAuthnRegistration verifies the response of the authenticator and saves the new credential.
+// If this is the first second factor of the user, recovery codes are generated as well.
+func FinishWebAuthnRegistration(s *xorm.Session, u *User, name string, response io.Reader) (registration *WebAuthnRegistration, err error) {
+	if name == "" {
+		return nil, &ErrWebAuthnCredentialNameEmpty{}
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(response)
+	if err != nil {
+		return nil, &ErrInvalidWebAuthnResponse{}
+	}
+
+	data, err := popWebAuthnSession(getWebAuthnRegistrationKey(u))
+	if err != nil {
+		return nil, err
+	}
+
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := wa.CreateCredential(w, *data, parsed)
+	if err != nil {
+		return nil, &ErrInvalidWebAuthnResponse{}
+	}
+
+	registration = &WebAuthnRegistration{
+		Credential: &WebAuthnCredential{
+			UserID:          u.ID,
+			Name:            name,
+			CredentialID:    base64.RawURLEncoding.EncodeToString(credential.ID),
+			PublicKey:       credential.PublicKey,
+			AttestationType: credential.AttestationType,
+			AAGUID:          credential.Authenticator.AAGUID,
+			SignCount:       int64(credential.Authenticator.SignCount),
+		},
+	}
+	_, err = s.Insert(registration.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodeCount, err := GetRecoveryCodeCount(s, u)
+	if err != nil {
+		return nil, err
+	}
+	if recoveryCodeCount == 0 {
+		codes, err := GenerateRecoveryCodes(s, u)
+		if err != nil {
+			return nil, err
+		}
+		registration.RecoveryCodes = codes.Codes
+	}
+
+	return registration, nil
+}
+
+// DeleteWebAuthnCredential removes a webauthn credential of a user. If it was their last second factor, their
+// recovery codes are removed as well.
+func DeleteWebAuthnCredential(s *xorm.Session, u *User, id int64) (err error) {
+	deleted, err := s.
+		Where("id = ? AND user_id = ?", id, u.ID).
+		Delete(&WebAuthnCredential{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrWebAuthnCredentialDoesNotExist{ID: id}
+	}
+
+	hasCredentials, err := s.Where("user_id = ?", u.ID).Exist(&WebAuthnCredential{})
+	if err != nil || hasCredentials {
+		return err
+	}
+
+	totpEnabled, err := TOTPEnabledForUser(s, u)
+	if err != nil || totpEnabled {
+		return err
+	}
+
+	return DeleteRecoveryCodes(s, u)
+}
+
+// BeginWebAuthnLogin starts a login with a webauthn credential as second factor of a user.
+func BeginWebAuthnLogin(s *xorm.Session, u *User) (options *protocol.CredentialAssertion, err error) {
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+	if len(w.credentials) == 0 {
+		return nil, &ErrWebAuthnNotEnabled{}
+	}
+
+	options, data, err := wa.BeginLogin(w, webauthn.WithUserVerification(getWebAuthnUserVerification()))
+	if err != nil {
+		return nil, err
+	}
+
+	return options, putWebAuthnSession(getWebAuthnLoginKey(u), data)
+}
+
+// ValidateWebAuthnLogin checks the response of an authenticator to a login started with BeginWebAuthnLogin.
+func ValidateWebAuthnLogin(s *xorm.Session, u *User, response io.Reader) (err error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBody(response)
+	if err != nil {
+		return &ErrInvalidWebAuthnResponse{}
+	}
+
+	data, err := popWebAuthnSession(getWebAuthnLoginKey(u))
+	if err != nil {
+		return err
+	}
+
+	wa, err := getWebAuthn()
+	if err != nil {
+		return err
+	}
+
+	w, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return err
+	}
+
+	credential, err := wa.ValidateLogin(w, *data, parsed)
+	if err != nil {
+		return &ErrInvalidWebAuthnResponse{}
+	}
+
+	return updateWebAuthnCredentialUsage(s, u, credential)
+}
+
+// BeginPasswordlessWebAuthnLogin starts a login with a passkey without asking for a username and password first.
+func BeginPasswordlessWebAuthnLogin() (options *protocol.CredentialAssertion, err error) {
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	options, data, err := wa.BeginDiscoverableLogin(webauthn.WithUserVerification(getWebAuthnUserVerification()))
+	if err != nil {
+		return nil, err
+	}
+
+	return options, putWebAuthnSession(getWebAuthnPasswordlessLoginKey(data.Challenge), data)
+}
+
+// FinishPasswordlessWebAuthnLogin checks the response of an authenticator to a login started with
+// BeginPasswordlessWebAuthnLogin and returns the user the passkey belongs to.
+func FinishPasswordlessWebAuthnLogin(s *xorm.Session, response io.Reader) (u *User, err error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBody(response)
+	if err != nil {
+		return nil, &ErrInvalidWebAuthnResponse{}
+	}
+
+	data, err := popWebAuthnSession(getWebAuthnPasswordlessLoginKey(parsed.Response.CollectedClientData.Challenge))
+	if err != nil {
+		return nil, err
+	}
+
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	var w *webAuthnUser
+	credential, err := wa.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := strconv.ParseInt(string(userHandle), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		u, err := GetUserByID(s, userID)
+		if err != nil {
+			return nil, err
+		}
+		w, err = getWebAuthnUser(s, u)
+		return w, err
+	}, *data, parsed)
+	if err != nil || w == nil {
+		return nil, &ErrInvalidWebAuthnResponse{}
+	}
+
+	return w.user, updateWebAuthnCredentialUsage(s, w.user, credential)
+}
+
+func updateWebAuthnCredentialUsage(s *xorm.Session, u *User, credential *webauthn.Credential) (err error) {
+	if credential.Authenticator.CloneWarning {
+		return &ErrInvalidWebAuthnResponse{}
+	}
+
+	_, err = s.
+		Where("user_id = ? AND credential_id = ?", u.ID, base64.RawURLEncoding.EncodeToString(credential.ID)).
+		Cols("sign_count", "last_used").
+		Update(&WebAuthnCredential{
+			SignCount: int64(credential.Authenticator.SignCount),
+			LastUsed:  time.Now(),
+		})
+	return
+}
@@ -0,0 +1,129 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-2021 Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/modules/keyvalue"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWebAuthnCredentials(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	credentials, err := GetWebAuthnCredentials(s, &User{ID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, credentials, 2)
+	assert.Equal(t, "Yubikey", credentials[0].Name)
+
+	w := &webAuthnUser{user: &User{ID: 1, Username: "user1"}, credentials: credentials}
+	assert.Equal(t, []byte("1"), w.WebAuthnID())
+	assert.Equal(t, []byte("credential1"), w.WebAuthnCredentials()[0].ID)
+	assert.Equal(t, uint32(4), w.WebAuthnCredentials()[0].Authenticator.SignCount)
+}
+
+func TestWebAuthnEnabledForUser(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	enabled, err := WebAuthnEnabledForUser(s, &User{ID: 1})
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = WebAuthnEnabledForUser(s, &User{ID: 3})
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	config.AuthWebAuthnEnabled.Set(false)
+	defer config.AuthWebAuthnEnabled.Set(true)
+	enabled, err = WebAuthnEnabledForUser(s, &User{ID: 1})
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestDeleteWebAuthnCredential(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteWebAuthnCredential(s, &User{ID: 1}, 1)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "user_webauthn_credentials", map[string]interface{}{
+			"id": 1,
+		})
+		// The user still has another credential
+		db.AssertExists(t, "user_recovery_codes", map[string]interface{}{
+			"user_id": 1,
+		}, false)
+	})
+	t.Run("last credential", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteWebAuthnCredential(s, &User{ID: 2}, 3)
+		assert.NoError(t, err)
+		db.AssertMissing(t, "user_recovery_codes", map[string]interface{}{
+			"user_id": 2,
+		})
+	})
+	t.Run("credential of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteWebAuthnCredential(s, &User{ID: 1}, 3)
+		assert.Error(t, err)
+		assert.True(t, IsErrWebAuthnCredentialDoesNotExist(err))
+	})
+}
+
+func TestWebAuthnSession(t *testing.T) {
+	t.Run("can only be used once", func(t *testing.T) {
+		err := putWebAuthnSession("webauthn_test", &webauthn.SessionData{Challenge: "challenge"})
+		assert.NoError(t, err)
+
+		data, err := popWebAuthnSession("webauthn_test")
+		assert.NoError(t, err)
+		assert.Equal(t, "challenge", data.Challenge)
+
+		_, err = popWebAuthnSession("webauthn_test")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnResponse(err))
+	})
+	t.Run("expired", func(t *testing.T) {
+		err := keyvalue.Put("webauthn_test", &webAuthnSession{
+			Data:    webauthn.SessionData{Challenge: "challenge"},
+			Created: time.Now().Add(-webAuthnSessionTimeout - time.Minute),
+		})
+		assert.NoError(t, err)
+
+		_, err = popWebAuthnSession("webauthn_test")
+		assert.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnResponse(err))
+	})
+}